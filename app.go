@@ -102,6 +102,8 @@ func (a *App) Run() error {
 			return err
 		}
 	}
+	// 启动完成之前，服务不可用
+	a.setReady(false)
 	// 依次启动服务
 	for _, srv := range a.opts.servers {
 		srv := srv
@@ -138,6 +140,7 @@ func (a *App) Run() error {
 			return err
 		}
 	}
+	a.setReady(true)
 
 	// 启动协程，监听信号
 	c := make(chan os.Signal, 1)
@@ -164,6 +167,8 @@ func (a *App) Run() error {
 
 // Stop gracefully stops the application.
 func (a *App) Stop() (err error) {
+	// 开始停止，服务不再接收新的流量
+	a.setReady(false)
 	sctx := NewContext(a.ctx, a)
 	for _, fn := range a.opts.beforeStop {
 		err = fn(sctx)
@@ -188,6 +193,15 @@ func (a *App) Stop() (err error) {
 	return err
 }
 
+// setReady reports the readiness of the application to the servers.
+func (a *App) setReady(ready bool) {
+	for _, srv := range a.opts.servers {
+		if r, ok := srv.(transport.Readier); ok {
+			r.SetReady(ready)
+		}
+	}
+}
+
 func (a *App) buildInstance() (*registry.ServiceInstance, error) {
 	endpoints := make([]string, 0, len(a.opts.endpoints))
 	for _, e := range a.opts.endpoints {
@@ -200,6 +214,10 @@ func (a *App) buildInstance() (*registry.ServiceInstance, error) {
 				if err != nil {
 					return nil, err
 				}
+				// unix socket只在本机可达，不注册到注册中心
+				if e.Scheme == "unix" || e.Scheme == "unix-abstract" {
+					continue
+				}
 				endpoints = append(endpoints, e.String())
 			}
 		}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"net/url"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -108,6 +110,23 @@ func TestApp_Metadata(t *testing.T) {
 	}
 }
 
+func TestApp_buildInstanceUnix(t *testing.T) {
+	tcp := grpc.NewServer()
+	unix := grpc.NewServer(grpc.Address("unix://" + filepath.Join(t.TempDir(), "app.sock")))
+	defer func() {
+		_ = tcp.Stop(context.Background())
+		_ = unix.Stop(context.Background())
+	}()
+	app := New(Server(tcp, unix))
+	got, err := app.buildInstance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Endpoints) != 1 || !strings.HasPrefix(got.Endpoints[0], "grpc://") {
+		t.Errorf("expect the unix socket not registered, got %v", got.Endpoints)
+	}
+}
+
 func TestApp_Endpoint(t *testing.T) {
 	v := []string{"https://go-kratos.dev", "localhost"}
 	var endpoints []*url.URL
@@ -283,3 +302,46 @@ func TestApp_Context(t *testing.T) {
 		})
 	}
 }
+
+type readyServer struct {
+	mu    sync.Mutex
+	ready []bool
+	stop  chan struct{}
+}
+
+func (s *readyServer) Start(_ context.Context) error {
+	<-s.stop
+	return nil
+}
+
+func (s *readyServer) Stop(_ context.Context) error {
+	close(s.stop)
+	return nil
+}
+
+func (s *readyServer) SetReady(ready bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ready = append(s.ready, ready)
+}
+
+func TestApp_SetReady(t *testing.T) {
+	srv := &readyServer{stop: make(chan struct{})}
+	app := New(Server(srv), AfterStart(func(_ context.Context) error {
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		if !reflect.DeepEqual(srv.ready, []bool{false}) {
+			t.Errorf("expect not ready before the AfterStart hooks are done, got %v", srv.ready)
+		}
+		return nil
+	}))
+	time.AfterFunc(100*time.Millisecond, func() {
+		_ = app.Stop()
+	})
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(srv.ready, []bool{false, true, false}) {
+		t.Errorf("expect %v, got %v", []bool{false, true, false}, srv.ready)
+	}
+}
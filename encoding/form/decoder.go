@@ -0,0 +1,95 @@
+package form
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/go-playground/form/v4"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// maxListIndex limits the list index of the keys, e.g. items[1000].name,
+// to avoid allocating huge lists from a tiny request.
+const maxListIndex = 1000
+
+var (
+	// timeLayouts are the layouts parsing time.Time and google.protobuf.Timestamp.
+	timeLayouts     = []string{time.RFC3339Nano}
+	messageDecoders = map[protoreflect.FullName]func(string) (proto.Message, error){}
+)
+
+func init() {
+	decoder.RegisterCustomTypeFunc(func(vs []string) (interface{}, error) {
+		return parseTime(vs[0])
+	}, time.Time{})
+}
+
+// FieldError is the error decoding the value of a field, Field is the
+// full key of the field, e.g. items[0].name.
+type FieldError struct {
+	Field string
+	Value string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("form: invalid value %q for field %q: %v", e.Value, e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// RegisterTypeDecoder registers the function decoding the values of the given
+// go types, e.g. decimal types or enums by name, the types are the zero values
+// of them. It is not safe for concurrent use, call it in init.
+func RegisterTypeDecoder(fn func(string) (interface{}, error), types ...interface{}) {
+	decoder.RegisterCustomTypeFunc(func(vs []string) (interface{}, error) {
+		return fn(vs[0])
+	}, types...)
+}
+
+// RegisterMessageDecoder registers the function decoding the values of the proto
+// message with the full name, e.g. google.type.Money, it takes precedence over the
+// builtin well known types. It is not safe for concurrent use, call it in init.
+func RegisterMessageDecoder(name protoreflect.FullName, fn func(string) (proto.Message, error)) {
+	messageDecoders[name] = fn
+}
+
+// RegisterTimeLayouts registers the extra layouts parsing time.Time and
+// google.protobuf.Timestamp, they are tried in order after time.RFC3339Nano.
+// It is not safe for concurrent use, call it in init.
+func RegisterTimeLayouts(layouts ...string) {
+	timeLayouts = append(timeLayouts, layouts...)
+}
+
+func parseTime(value string) (t time.Time, err error) {
+	if value == "" {
+		return t, nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err = time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return t, err
+}
+
+// fieldError converts the errors of the form decoder to the FieldError of the
+// first offending field.
+func fieldError(err error, vs url.Values) error {
+	var errs form.DecodeErrors
+	if !errors.As(err, &errs) || len(errs) == 0 {
+		return err
+	}
+	keys := make([]string, 0, len(errs))
+	for k := range errs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return &FieldError{Field: keys[0], Value: vs.Get(keys[0]), Err: errs[keys[0]]}
+}
@@ -0,0 +1,122 @@
+package form
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/go-kratos/kratos/v2/internal/testdata/complex"
+)
+
+type level int
+
+func TestDecodeListIndex(t *testing.T) {
+	vs, _ := url.ParseQuery("values[1].string_value=kratos&values[0].number_value=2")
+	list := &structpb.ListValue{}
+	if err := DecodeValues(list, vs); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Values) != 2 || list.Values[0].GetNumberValue() != 2 || list.Values[1].GetStringValue() != "kratos" {
+		t.Errorf("unexpected list: %v", list)
+	}
+
+	vs, _ = url.ParseQuery("simples[1]=b&simples[0]=a")
+	comp := &complex.Complex{}
+	if err := DecodeValues(comp, vs); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(comp.Simples) != "[a b]" {
+		t.Errorf("want [a b], got %v", comp.Simples)
+	}
+
+	vs, _ = url.ParseQuery(fmt.Sprintf("values[%d].string_value=kratos", maxListIndex+1))
+	if err := DecodeValues(&structpb.ListValue{}, vs); err == nil {
+		t.Errorf("expect the index exceeding the limit to fail")
+	}
+	vs, _ = url.ParseQuery("id[0]=1")
+	if err := DecodeValues(&complex.Complex{}, vs); err == nil {
+		t.Errorf("expect the index of a non-repeated field to fail")
+	}
+}
+
+func TestFieldError(t *testing.T) {
+	vs, _ := url.ParseQuery("int32=abc")
+	err := DecodeValues(&complex.Complex{}, vs)
+	var fe *FieldError
+	if !errors.As(err, &fe) {
+		t.Fatalf("expect FieldError, got %v", err)
+	}
+	if fe.Field != "int32" || fe.Value != "abc" {
+		t.Errorf("unexpected field error: %v", fe)
+	}
+
+	var v struct {
+		Age int `json:"age"`
+	}
+	err = codec{encoder: encoder, decoder: decoder}.Unmarshal([]byte("age=old"), &v)
+	if !errors.As(err, &fe) {
+		t.Fatalf("expect FieldError, got %v", err)
+	}
+	if fe.Field != "age" || fe.Value != "old" {
+		t.Errorf("unexpected field error: %v", fe)
+	}
+}
+
+func TestRegisterTypeDecoder(t *testing.T) {
+	RegisterTypeDecoder(func(s string) (interface{}, error) {
+		switch s {
+		case "debug":
+			return level(0), nil
+		case "info":
+			return level(1), nil
+		}
+		return nil, fmt.Errorf("unknown level %q", s)
+	}, level(0))
+	RegisterTimeLayouts("2006-01-02")
+
+	var v struct {
+		Level level     `json:"level"`
+		At    time.Time `json:"at"`
+	}
+	c := codec{encoder: encoder, decoder: decoder}
+	if err := c.Unmarshal([]byte("level=info&at=2023-01-02"), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Level != 1 || !v.At.Equal(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected value: %+v", v)
+	}
+	var fe *FieldError
+	if err := c.Unmarshal([]byte("level=fatal"), &v); !errors.As(err, &fe) || fe.Field != "level" {
+		t.Errorf("expect FieldError of level, got %v", err)
+	}
+
+	vs, _ := url.ParseQuery("timestamp=2023-01-02")
+	comp := &complex.Complex{}
+	if err := DecodeValues(comp, vs); err != nil {
+		t.Fatal(err)
+	}
+	if comp.Timestamp.AsTime().Day() != 2 {
+		t.Errorf("unexpected timestamp: %v", comp.Timestamp)
+	}
+}
+
+func TestRegisterMessageDecoder(t *testing.T) {
+	RegisterMessageDecoder("testproto.Simple", func(s string) (proto.Message, error) {
+		return &complex.Simple{Component: "component:" + s}, nil
+	})
+	defer delete(messageDecoders, "testproto.Simple")
+
+	vs, _ := url.ParseQuery("very_simple=kratos")
+	comp := &complex.Complex{}
+	if err := DecodeValues(comp, vs); err != nil {
+		t.Fatal(err)
+	}
+	if comp.GetSimple().GetComponent() != "component:kratos" {
+		t.Errorf("unexpected simple: %v", comp.Simple)
+	}
+}
@@ -75,7 +75,7 @@ func (c codec) Unmarshal(data []byte, v interface{}) error {
 		return DecodeValues(m, vs)
 	}
 
-	return c.decoder.Decode(v, vs)
+	return fieldError(c.decoder.Decode(v, vs), vs)
 }
 
 func (codec) Name() string {
@@ -24,7 +24,7 @@ import (
 func DecodeValues(msg proto.Message, values url.Values) error {
 	for key, values := range values {
 		if err := populateFieldValues(msg.ProtoReflect(), strings.Split(key, "."), values); err != nil {
-			return err
+			return &FieldError{Field: key, Value: strings.Join(values, ","), Err: err}
 		}
 	}
 	return nil
@@ -38,17 +38,33 @@ func populateFieldValues(v protoreflect.Message, fieldPath []string, values []st
 		return errors.New("no value provided")
 	}
 
-	var fd protoreflect.FieldDescriptor
+	var (
+		fd    protoreflect.FieldDescriptor
+		index int
+	)
 	for i, fieldName := range fieldPath {
-		if fd = getFieldDescriptor(v, fieldName); fd == nil {
+		if fd, index = getIndexedFieldDescriptor(v, fieldName); fd == nil {
 			// ignore unexpected field.
 			return nil
 		}
+		if index >= 0 && !fd.IsList() {
+			return fmt.Errorf("invalid path: %q is not a list", fieldName)
+		}
 
 		if i == len(fieldPath)-1 {
 			break
 		}
 
+		if index >= 0 && fd.Message() != nil {
+			// post element of repeated message, e.g. items[0].name
+			list := v.Mutable(fd).List()
+			if err := growList(list, index); err != nil {
+				return err
+			}
+			v = list.Get(index).Message()
+			continue
+		}
+
 		if fd.Message() == nil || fd.Cardinality() == protoreflect.Repeated {
 			if fd.IsMap() && len(fieldPath) > 1 {
 				// post subfield
@@ -65,6 +81,8 @@ func populateFieldValues(v protoreflect.Message, fieldPath []string, values []st
 		}
 	}
 	switch {
+	case fd.IsList() && index >= 0:
+		return populateListElement(fd, v.Mutable(fd).List(), index, values)
 	case fd.IsList():
 		return populateRepeatedField(fd, v.Mutable(fd).List(), values)
 	case fd.IsMap():
@@ -89,6 +107,23 @@ func getFieldDescriptor(v protoreflect.Message, fieldName string) protoreflect.F
 	return fd
 }
 
+// getIndexedFieldDescriptor returns the descriptor of the field and the list index
+// of it, e.g. items[0], the index is -1 if the field is not indexed.
+func getIndexedFieldDescriptor(v protoreflect.Message, fieldName string) (protoreflect.FieldDescriptor, int) {
+	if fd := getFieldDescriptor(v, fieldName); fd != nil {
+		return fd, -1
+	}
+	i := strings.LastIndexByte(fieldName, '[')
+	if i <= 0 || !strings.HasSuffix(fieldName, "]") {
+		return nil, -1
+	}
+	index, err := strconv.Atoi(fieldName[i+1 : len(fieldName)-1])
+	if err != nil || index < 0 {
+		return nil, -1
+	}
+	return getFieldDescriptor(v, fieldName[:i]), index
+}
+
 func getDescriptorByFieldAndName(fields protoreflect.FieldDescriptors, fieldName string) protoreflect.FieldDescriptor {
 	var fd protoreflect.FieldDescriptor
 	if fd = fields.ByName(protoreflect.Name(fieldName)); fd == nil {
@@ -120,6 +155,35 @@ func populateRepeatedField(fd protoreflect.FieldDescriptor, list protoreflect.Li
 	return nil
 }
 
+func populateListElement(fd protoreflect.FieldDescriptor, list protoreflect.List, index int, values []string) error {
+	if len(values) > 1 {
+		return fmt.Errorf("too many values for list element %q: %s", fd.FullName().Name(), strings.Join(values, ", "))
+	}
+	if err := growList(list, index); err != nil {
+		return err
+	}
+	if values[0] == "" {
+		return nil
+	}
+	v, err := parseField(fd, values[0])
+	if err != nil {
+		return fmt.Errorf("parsing list %q: %w", fd.FullName().Name(), err)
+	}
+	list.Set(index, v)
+	return nil
+}
+
+// growList appends the zero elements to the list until the index is valid.
+func growList(list protoreflect.List, index int) error {
+	if index > maxListIndex {
+		return fmt.Errorf("list index %d exceeds the limit %d", index, maxListIndex)
+	}
+	for list.Len() <= index {
+		list.Append(list.NewElement())
+	}
+	return nil
+}
+
 func populateMapField(fd protoreflect.FieldDescriptor, mp protoreflect.Map, fieldPath []string, values []string) error {
 	// post sub key.
 	nkey := len(fieldPath) - 1
@@ -216,13 +280,20 @@ func parseField(fd protoreflect.FieldDescriptor, value string) (protoreflect.Val
 }
 
 func parseMessage(md protoreflect.MessageDescriptor, value string) (protoreflect.Value, error) {
+	if fn, ok := messageDecoders[md.FullName()]; ok {
+		msg, err := fn(value)
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		return protoreflect.ValueOfMessage(msg.ProtoReflect()), nil
+	}
 	var msg proto.Message
 	switch md.FullName() {
 	case "google.protobuf.Timestamp":
 		if value == nullStr {
 			break
 		}
-		t, err := time.Parse(time.RFC3339Nano, value)
+		t, err := parseTime(value)
 		if err != nil {
 			return protoreflect.Value{}, err
 		}
@@ -113,3 +113,28 @@ func (mc *mergeCtx) Value(key interface{}) interface{} {
 	}
 	return mc.parent2.Value(key)
 }
+
+type withoutCancelCtx struct {
+	context.Context
+}
+
+// WithoutCancel returns a context carrying the values of the parent, which is
+// never canceled and has no deadline, e.g. for the work outliving the request.
+func WithoutCancel(parent context.Context) context.Context {
+	return withoutCancelCtx{parent}
+}
+
+// Deadline implements context.Context.
+func (withoutCancelCtx) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// Done implements context.Context.
+func (withoutCancelCtx) Done() <-chan struct{} {
+	return nil
+}
+
+// Err implements context.Context.
+func (withoutCancelCtx) Err() error {
+	return nil
+}
@@ -278,3 +278,19 @@ func Test_Err2(t *testing.T) {
 		t.Errorf("expect %v, got %v", context.Canceled, ctx.Err())
 	}
 }
+
+func TestWithoutCancel(t *testing.T) {
+	type ctxKey struct{}
+	parent, cancel := context.WithTimeout(context.WithValue(context.Background(), ctxKey{}, "v"), time.Second)
+	cancel()
+	ctx := WithoutCancel(parent)
+	if ctx.Err() != nil || ctx.Done() != nil {
+		t.Errorf("expect the context not to be canceled")
+	}
+	if _, ok := ctx.Deadline(); ok {
+		t.Errorf("expect no deadline")
+	}
+	if v := ctx.Value(ctxKey{}); v != "v" {
+		t.Errorf("expect %v, got %v", "v", v)
+	}
+}
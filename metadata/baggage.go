@@ -0,0 +1,121 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// BaggageHeader is the header of the W3C baggage, it is extracted by the servers
+// and injected by the clients of the HTTP and gRPC transports.
+const BaggageHeader = "baggage"
+
+// WithBaggage returns a new context with the baggage members of the key-value pairs
+// merged, e.g. the tenant and the experiment. The baggage is shared with OpenTelemetry.
+func WithBaggage(ctx context.Context, kv ...string) (context.Context, error) {
+	if len(kv)%2 == 1 {
+		return ctx, fmt.Errorf("metadata: WithBaggage got an odd number of input pairs for baggage: %d", len(kv))
+	}
+	b := baggage.FromContext(ctx)
+	for i := 0; i < len(kv); i += 2 {
+		// 值中的非法字符按W3C baggage的百分号编码转义，空格编码为%20而不是+
+		m, err := baggage.NewMember(kv[i], url.PathEscape(kv[i+1]))
+		if err != nil {
+			return ctx, err
+		}
+		if b, err = b.SetMember(m); err != nil {
+			return ctx, err
+		}
+	}
+	return baggage.ContextWithBaggage(ctx, b), nil
+}
+
+// GetBaggage returns the value of the baggage member in ctx.
+func GetBaggage(ctx context.Context, key string) string {
+	return unescapeBaggage(baggage.FromContext(ctx).Member(key).Value())
+}
+
+// Baggage returns the baggage members in ctx.
+func Baggage(ctx context.Context) map[string]string {
+	members := baggage.FromContext(ctx).Members()
+	m := make(map[string]string, len(members))
+	for _, member := range members {
+		m[member.Key()] = unescapeBaggage(member.Value())
+	}
+	return m
+}
+
+// ExtractBaggage returns a new context with the baggage of the header value,
+// the invalid header is ignored. The values are kept percent-encoded as they are.
+func ExtractBaggage(ctx context.Context, header string) context.Context {
+	if header == "" {
+		return ctx
+	}
+	// baggage.Parse按query解码值（+会变成空格），这里自行解析，保留百分号编码的值
+	var members []baggage.Member
+	for _, s := range strings.Split(header, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		m, err := parseBaggageMember(s)
+		if err != nil {
+			return ctx
+		}
+		members = append(members, m)
+	}
+	b, err := baggage.New(members...)
+	if err != nil {
+		return ctx
+	}
+	return baggage.ContextWithBaggage(ctx, b)
+}
+
+func parseBaggageMember(s string) (baggage.Member, error) {
+	parts := strings.Split(s, ";")
+	kv := strings.SplitN(parts[0], "=", 2)
+	if len(kv) != 2 {
+		return baggage.Member{}, fmt.Errorf("metadata: invalid baggage member: %s", s)
+	}
+	props := make([]baggage.Property, 0, len(parts)-1)
+	for _, p := range parts[1:] {
+		var (
+			prop baggage.Property
+			err  error
+		)
+		if pkv := strings.SplitN(p, "=", 2); len(pkv) == 2 {
+			prop, err = baggage.NewKeyValueProperty(strings.TrimSpace(pkv[0]), strings.TrimSpace(pkv[1]))
+		} else {
+			prop, err = baggage.NewKeyProperty(strings.TrimSpace(p))
+		}
+		if err != nil {
+			return baggage.Member{}, err
+		}
+		props = append(props, prop)
+	}
+	return baggage.NewMember(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]), props...)
+}
+
+// InjectBaggage returns the header value of the baggage in ctx, empty if there is none.
+func InjectBaggage(ctx context.Context) string {
+	// baggage.String会再按query编码一次值，这里自行拼接，值已经是百分号编码的
+	members := baggage.FromContext(ctx).Members()
+	parts := make([]string, 0, len(members))
+	for _, m := range members {
+		s := m.Key() + "=" + m.Value()
+		for _, p := range m.Properties() {
+			s += ";" + p.String()
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, ",")
+}
+
+func unescapeBaggage(v string) string {
+	if s, err := url.PathUnescape(v); err == nil {
+		return s
+	}
+	return v
+}
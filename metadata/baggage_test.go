@@ -0,0 +1,54 @@
+package metadata
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestBaggage(t *testing.T) {
+	ctx, err := WithBaggage(context.Background(), "tenant", "kratos", "experiment", "a b,c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := GetBaggage(ctx, "tenant"); v != "kratos" {
+		t.Errorf("expect %v, got %v", "kratos", v)
+	}
+	if v := GetBaggage(ctx, "experiment"); v != "a b,c" {
+		t.Errorf("expect %v, got %v", "a b,c", v)
+	}
+	if v := GetBaggage(ctx, "notfound"); v != "" {
+		t.Errorf("expect empty, got %v", v)
+	}
+
+	// 跨服务传递
+	header := InjectBaggage(ctx)
+	got := Baggage(ExtractBaggage(context.Background(), header))
+	want := map[string]string{"tenant": "kratos", "experiment": "a b,c"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("expect %v, got %v", want, got)
+	}
+
+	if _, err = WithBaggage(context.Background(), "odd"); err == nil {
+		t.Errorf("expect error for the odd pairs")
+	}
+	if _, err = WithBaggage(context.Background(), "invalid key", "v"); err == nil {
+		t.Errorf("expect error for the invalid key")
+	}
+	if got := Baggage(ExtractBaggage(context.Background(), "invalid key=v")); len(got) != 0 {
+		t.Errorf("expect the invalid header to be ignored, got %v", got)
+	}
+	// 其他语言的实现按百分号编码，+不是空格
+	if v := GetBaggage(ExtractBaggage(context.Background(), "expr=a+b%20c"), "expr"); v != "a+b c" {
+		t.Errorf("expect %v, got %v", "a+b c", v)
+	}
+	if ctx, _ = WithBaggage(context.Background(), "expr", "a b"); InjectBaggage(ctx) != "expr=a%20b" {
+		t.Errorf("expect %v, got %v", "expr=a%20b", InjectBaggage(ctx))
+	}
+	if h := InjectBaggage(ExtractBaggage(context.Background(), "k=v;p1;p2=x")); h != "k=v;p1;p2=x" {
+		t.Errorf("expect the properties to be kept, got %v", h)
+	}
+	if InjectBaggage(context.Background()) != "" {
+		t.Errorf("expect empty baggage")
+	}
+}
@@ -0,0 +1,85 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Header is the header carrying the request id, it is propagated by the HTTP and gRPC clients.
+const Header = transport.RequestIDHeader
+
+// Option is request id option.
+type Option func(*options)
+
+type options struct {
+	generate func() string
+}
+
+// WithGenerator with the generator of the request ids, default is UUID v4.
+func WithGenerator(f func() string) Option {
+	return func(o *options) {
+		o.generate = f
+	}
+}
+
+// Generator returns the generator of the options.
+func Generator(opts ...Option) func() string {
+	o := options{generate: uuid.NewString}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o.generate
+}
+
+// NewContext returns a new Context that carries the request id, it is also stored
+// in the server metadata.
+func NewContext(ctx context.Context, id string) context.Context {
+	return transport.NewRequestIDContext(ctx, id)
+}
+
+// FromContext returns the request id stored in ctx, if any.
+func FromContext(ctx context.Context) (id string, ok bool) {
+	return transport.FromRequestIDContext(ctx)
+}
+
+// Valuer returns a request id valuer for the log context, e.g.
+//
+//	log.With(logger, "request_id", requestid.Valuer())
+func Valuer() log.Valuer {
+	return func(ctx context.Context) interface{} {
+		id, _ := FromContext(ctx)
+		return id
+	}
+}
+
+// Server is a middleware reading or generating the request id, and echoing it
+// in the reply header, e.g. for the gRPC servers. The invalid incoming ids are
+// replaced by the generated ones, see transport.ValidRequestID. The HTTP servers should use
+// the RequestID filter of transport/http, so the ids are in the access logs.
+func Server(opts ...Option) middleware.Middleware {
+	generate := Generator(opts...)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			id, ok := FromContext(ctx)
+			if !ok {
+				if id = tr.RequestHeader().Get(Header); !transport.ValidRequestID(id) {
+					id = generate()
+				}
+				ctx = NewContext(ctx, id)
+			}
+			if tr.ReplyHeader() != nil {
+				tr.ReplyHeader().Set(Header, id)
+			}
+			return handler(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/metadata"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string        { return http.Header(hc).Get(key) }
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+func (hc headerCarrier) Add(key string, value string) { http.Header(hc).Add(key, value) }
+func (hc headerCarrier) Values(key string) []string   { return http.Header(hc).Values(key) }
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range hc {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type testTransport struct {
+	reqHeader   headerCarrier
+	replyHeader headerCarrier
+}
+
+func (tr *testTransport) Kind() transport.Kind            { return transport.KindGRPC }
+func (tr *testTransport) Endpoint() string                { return "" }
+func (tr *testTransport) Operation() string               { return "" }
+func (tr *testTransport) RequestHeader() transport.Header { return tr.reqHeader }
+func (tr *testTransport) ReplyHeader() transport.Header   { return tr.replyHeader }
+
+func TestContext(t *testing.T) {
+	ctx := metadata.NewServerContext(context.Background(), metadata.New(map[string][]string{"x-md-global-a": {"b"}}))
+	ctx = NewContext(ctx, "id")
+	if id, ok := FromContext(ctx); !ok || id != "id" {
+		t.Errorf("expect %v, got %v", "id", id)
+	}
+	md, _ := metadata.FromServerContext(ctx)
+	if md.Get(Header) != "id" || md.Get("x-md-global-a") != "b" {
+		t.Errorf("expect the id to be merged into the metadata, got %v", md)
+	}
+	if v := Valuer()(ctx); v != "id" {
+		t.Errorf("expect %v, got %v", "id", v)
+	}
+	if v := Valuer()(context.Background()); v != "" {
+		t.Errorf("expect empty, got %v", v)
+	}
+}
+
+func TestServer(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"propagated", "from-header", "from-header"},
+		{"generated", "", "generated"},
+		{"invalid", "<script>", "generated"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tr := &testTransport{reqHeader: headerCarrier{}, replyHeader: headerCarrier{}}
+			if test.header != "" {
+				tr.reqHeader.Set(Header, test.header)
+			}
+			ctx := transport.NewServerContext(context.Background(), tr)
+			var got string
+			h := Server(WithGenerator(func() string { return "generated" }))(func(ctx context.Context, req interface{}) (interface{}, error) {
+				got, _ = FromContext(ctx)
+				return nil, nil
+			})
+			if _, err := h(ctx, nil); err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("expect %v, got %v", test.want, got)
+			}
+			if v := tr.replyHeader.Get(Header); v != test.want {
+				t.Errorf("expect the reply header %v, got %v", test.want, v)
+			}
+		})
+	}
+}
+
+func TestGenerator(t *testing.T) {
+	a, b := Generator()(), Generator()()
+	if a == "" || a == b {
+		t.Errorf("expect unique ids, got %v %v", a, b)
+	}
+}
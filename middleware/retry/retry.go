@@ -0,0 +1,103 @@
+package retry
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+// Option is retry option.
+type Option func(*options)
+
+// WithAttempts with the max number of attempts including the first one, default is 3.
+func WithAttempts(n int) Option {
+	return func(o *options) {
+		o.attempts = n
+	}
+}
+
+// WithBackoff with the delay before the first retry, it is doubled per retry with
+// jitter and capped by max, default is 50ms and 1s.
+func WithBackoff(base, max time.Duration) Option {
+	return func(o *options) {
+		o.backoff = base
+		o.maxBackoff = max
+	}
+}
+
+// WithRetryable with the function deciding whether the error of an attempt is
+// retryable, default is the 503 and 504 errors, e.g. codes.Unavailable of gRPC.
+func WithRetryable(fn func(err error) bool) Option {
+	return func(o *options) {
+		o.retryable = fn
+	}
+}
+
+type options struct {
+	attempts   int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	retryable  func(err error) bool
+	rand       selector.Rand
+}
+
+func retryable(err error) bool {
+	return errors.IsServiceUnavailable(err) || errors.IsGatewayTimeout(err)
+}
+
+// Client is a client retry middleware, the retries avoid the nodes already tried by the
+// selector, so it is the fallback of the transport retries, e.g. the calls of the gRPC
+// client without the retry policy. It should only be used for the idempotent calls.
+func Client(opts ...Option) middleware.Middleware {
+	o := &options{
+		attempts:   3,
+		backoff:    50 * time.Millisecond,
+		maxBackoff: time.Second,
+		retryable:  retryable,
+		rand:       selector.NewRand(time.Now().UnixNano()),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			for attempt := 0; ; attempt++ {
+				reply, err := handler(selector.NewAttemptContext(ctx, attempt), req)
+				if err == nil || attempt+1 >= o.attempts || ctx.Err() != nil || !o.retryable(err) {
+					return reply, err
+				}
+				delay := o.delay(attempt + 1)
+				if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+					return reply, err
+				}
+				if p, ok := selector.FromPeerContext(ctx); ok && p.Node != nil {
+					// 重试时避开已经失败的节点
+					ctx = selector.WithExcludedNodes(ctx, p.Node.Address())
+				}
+				timer := time.NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return reply, err
+				case <-timer.C:
+				}
+			}
+		}
+	}
+}
+
+// delay returns the delay before the retry, the retry starts from 1.
+func (o *options) delay(retry int) time.Duration {
+	d := o.backoff
+	for i := 1; i < retry && d < o.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > o.maxBackoff {
+		d = o.maxBackoff
+	}
+	// equal jitter: [d/2, d)
+	return d/2 + time.Duration(o.rand.Float64()*float64(d/2))
+}
@@ -0,0 +1,76 @@
+package retry
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+func TestClient(t *testing.T) {
+	var (
+		attempts []int
+		excluded [][]string
+	)
+	addrs := []string{"127.0.0.1:9000", "127.0.0.1:9001", "127.0.0.1:9002"}
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		attempt := selector.AttemptFromContext(ctx)
+		attempts = append(attempts, attempt)
+		nodes := selector.ExcludedNodes(ctx)
+		sort.Strings(nodes)
+		excluded = append(excluded, nodes)
+		p, _ := selector.FromPeerContext(ctx)
+		p.Node = selector.NewNode("grpc", addrs[attempt], nil)
+		if attempt < 2 {
+			return nil, errors.ServiceUnavailable("UNAVAILABLE", "try again")
+		}
+		return "reply", nil
+	}
+	ctx := selector.NewPeerContext(context.Background(), &selector.Peer{})
+	reply, err := Client(WithBackoff(time.Millisecond, time.Millisecond))(next)(ctx, "req")
+	if err != nil || reply != "reply" {
+		t.Fatalf("unexpected reply: %v %v", reply, err)
+	}
+	if !reflect.DeepEqual(attempts, []int{0, 1, 2}) {
+		t.Errorf("unexpected attempts: %v", attempts)
+	}
+	want := [][]string{{}, {addrs[0]}, {addrs[0], addrs[1]}}
+	if !reflect.DeepEqual(excluded, want) {
+		t.Errorf("expect the failed nodes excluded %v, got %v", want, excluded)
+	}
+}
+
+func TestClientNotRetryable(t *testing.T) {
+	calls := 0
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		return nil, errors.BadRequest("BAD_REQUEST", "bad request")
+	}
+	if _, err := Client()(next)(context.Background(), "req"); !errors.IsBadRequest(err) || calls != 1 {
+		t.Errorf("expect no retry, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	next = func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		return nil, errors.ServiceUnavailable("UNAVAILABLE", "try again")
+	}
+	if _, err := Client(WithAttempts(2), WithBackoff(time.Millisecond, time.Millisecond))(next)(context.Background(), "req"); err == nil || calls != 2 {
+		t.Errorf("expect 2 attempts, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	retryable := func(err error) bool { return errors.IsBadRequest(err) }
+	next = func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		return nil, errors.BadRequest("BAD_REQUEST", "bad request")
+	}
+	_, _ = Client(WithRetryable(retryable), WithBackoff(time.Millisecond, time.Millisecond))(next)(context.Background(), "req")
+	if calls != 3 {
+		t.Errorf("expect 3 attempts, got %d", calls)
+	}
+}
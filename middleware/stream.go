@@ -0,0 +1,41 @@
+package middleware
+
+import "context"
+
+// Stream is the stream of a streaming call, e.g. grpc.ServerStream and grpc.ClientStream.
+type Stream interface {
+	Context() context.Context
+	SendMsg(m interface{}) error
+	RecvMsg(m interface{}) error
+}
+
+// StreamHandler defines the handler invoked by StreamMiddleware.
+type StreamHandler func(ctx context.Context, stream Stream) error
+
+// StreamMiddleware is transport middleware of the streaming calls, it can wrap the
+// stream to observe the messages.
+type StreamMiddleware func(StreamHandler) StreamHandler
+
+// ChainStream returns a StreamMiddleware that specifies the chained handler for the streams.
+func ChainStream(m ...StreamMiddleware) StreamMiddleware {
+	return func(next StreamHandler) StreamHandler {
+		for i := len(m) - 1; i >= 0; i-- {
+			next = m[i](next)
+		}
+		return next
+	}
+}
+
+// StreamFromUnary adapts the unary middleware to the streams, it is applied once per
+// stream with a nil request and reply, e.g. recovery, logging, metrics and auth, which
+// do not depend on the messages. The context returned by it is the context of the stream.
+func StreamFromUnary(m Middleware) StreamMiddleware {
+	return func(next StreamHandler) StreamHandler {
+		return func(ctx context.Context, stream Stream) error {
+			_, err := m(func(ctx context.Context, _ interface{}) (interface{}, error) {
+				return nil, next(ctx, stream)
+			})(ctx, nil)
+			return err
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testStream struct {
+	ctx context.Context
+}
+
+func (s *testStream) Context() context.Context    { return s.ctx }
+func (s *testStream) SendMsg(_ interface{}) error { return nil }
+func (s *testStream) RecvMsg(_ interface{}) error { return nil }
+
+func TestChainStream(t *testing.T) {
+	var calls []string
+	mw := func(name string) StreamMiddleware {
+		return func(next StreamHandler) StreamHandler {
+			return func(ctx context.Context, stream Stream) error {
+				calls = append(calls, name+" before")
+				err := next(ctx, stream)
+				calls = append(calls, name+" after")
+				return err
+			}
+		}
+	}
+	err := ChainStream(mw("1"), mw("2"))(func(ctx context.Context, stream Stream) error {
+		calls = append(calls, "handler")
+		return nil
+	})(context.Background(), &testStream{ctx: context.Background()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"1 before", "2 before", "handler", "2 after", "1 after"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("expect %v, got %v", want, calls)
+	}
+}
+
+func TestStreamFromUnary(t *testing.T) {
+	type key struct{}
+	wantErr := errors.New("stream error")
+	m := func(handler Handler) Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if req != nil {
+				t.Errorf("expect the nil request, got %v", req)
+			}
+			return handler(context.WithValue(ctx, key{}, "value"), req)
+		}
+	}
+	s := &testStream{ctx: context.Background()}
+	err := StreamFromUnary(m)(func(ctx context.Context, stream Stream) error {
+		if ctx.Value(key{}) != "value" {
+			t.Errorf("expect the context of the middleware")
+		}
+		if stream != s {
+			t.Errorf("expect the stream passed through")
+		}
+		return wantErr
+	})(context.Background(), s)
+	if err != wantErr {
+		t.Errorf("expect %v, got %v", wantErr, err)
+	}
+}
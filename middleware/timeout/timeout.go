@@ -0,0 +1,108 @@
+package timeout
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Option is timeout option.
+type Option func(*Matcher)
+
+// WithTimeout with the default timeout of the operations not matching any rule,
+// zero means no default timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(m *Matcher) {
+		m.timeout = d
+	}
+}
+
+// WithOperation with the timeout of the operations matching the selector:
+//   - '/*'
+//   - '/helloworld.v1.Greeter/*'
+//   - '/helloworld.v1.Greeter/SayHello'
+func WithOperation(selector string, d time.Duration) Option {
+	return func(m *Matcher) {
+		if strings.HasSuffix(selector, "*") {
+			selector = strings.TrimSuffix(selector, "*")
+			m.prefix = append(m.prefix, selector)
+			// 最长前缀优先
+			sort.Slice(m.prefix, func(i, j int) bool {
+				return m.prefix[i] > m.prefix[j]
+			})
+		}
+		m.matchs[selector] = d
+	}
+}
+
+// WithRegexp with the timeout of the operations matching the regular expression,
+// it is checked after the exact and the prefix selectors.
+func WithRegexp(expr *regexp.Regexp, d time.Duration) Option {
+	return func(m *Matcher) {
+		m.regexps = append(m.regexps, regexpTimeout{expr: expr, timeout: d})
+	}
+}
+
+type regexpTimeout struct {
+	expr    *regexp.Regexp
+	timeout time.Duration
+}
+
+// Matcher matches the timeout of an operation, by the exact selector,
+// the longest prefix selector and the regular expressions in order.
+type Matcher struct {
+	timeout time.Duration
+	prefix  []string
+	matchs  map[string]time.Duration
+	regexps []regexpTimeout
+}
+
+// NewMatcher new a timeout matcher.
+func NewMatcher(opts ...Option) *Matcher {
+	m := &Matcher{matchs: make(map[string]time.Duration)}
+	for _, o := range opts {
+		o(m)
+	}
+	return m
+}
+
+// Match returns the timeout of the operation, false if there is no timeout.
+func (m *Matcher) Match(operation string) (time.Duration, bool) {
+	if d, ok := m.matchs[operation]; ok {
+		return d, true
+	}
+	for _, prefix := range m.prefix {
+		if strings.HasPrefix(operation, prefix) {
+			return m.matchs[prefix], true
+		}
+	}
+	for _, r := range m.regexps {
+		if r.expr.MatchString(operation) {
+			return r.timeout, true
+		}
+	}
+	return m.timeout, m.timeout > 0
+}
+
+// Server is a server middleware applying the timeout by operation.
+// It can only shorten the timeout of the transport, e.g. the server timeout.
+func Server(opts ...Option) middleware.Middleware {
+	m := NewMatcher(opts...)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				if d, ok := m.Match(tr.Operation()); ok && d > 0 {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithTimeout(ctx, d)
+					defer cancel()
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package timeout
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type testTransport struct {
+	transport.Transporter
+	operation string
+}
+
+func (tr *testTransport) Operation() string {
+	return tr.operation
+}
+
+func TestMatcher(t *testing.T) {
+	m := NewMatcher(
+		WithTimeout(time.Second),
+		WithOperation("/helloworld.v1.Greeter/*", time.Second*2),
+		WithOperation("/helloworld.v1.Greeter/Upload*", time.Second*3),
+		WithOperation("/helloworld.v1.Greeter/SayHello", time.Second*4),
+		WithRegexp(regexp.MustCompile(`^/upload/.+$`), 0),
+	)
+	tests := []struct {
+		operation string
+		want      time.Duration
+		ok        bool
+	}{
+		{"/helloworld.v1.Greeter/SayHello", time.Second * 4, true},
+		{"/helloworld.v1.Greeter/UploadFile", time.Second * 3, true},
+		{"/helloworld.v1.Greeter/SayBye", time.Second * 2, true},
+		{"/upload/file", 0, true},
+		{"/other", time.Second, true},
+	}
+	for _, test := range tests {
+		got, ok := m.Match(test.operation)
+		if got != test.want || ok != test.ok {
+			t.Errorf("%s: expect %v %v, got %v %v", test.operation, test.want, test.ok, got, ok)
+		}
+	}
+	if _, ok := NewMatcher().Match("/other"); ok {
+		t.Errorf("expect no timeout")
+	}
+}
+
+func TestServer(t *testing.T) {
+	h := func(ctx context.Context, req interface{}) (interface{}, error) {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			return time.Duration(0), nil
+		}
+		return time.Until(deadline), nil
+	}
+	m := Server(WithOperation("/test", time.Second))
+	ctx := transport.NewServerContext(context.Background(), &testTransport{operation: "/test"})
+	reply, _ := m(h)(ctx, nil)
+	if d := reply.(time.Duration); d <= 0 || d > time.Second {
+		t.Errorf("expect a timeout of at most %v, got %v", time.Second, d)
+	}
+	ctx = transport.NewServerContext(context.Background(), &testTransport{operation: "/other"})
+	if reply, _ = m(h)(ctx, nil); reply.(time.Duration) != 0 {
+		t.Errorf("expect no timeout, got %v", reply)
+	}
+}
@@ -0,0 +1,91 @@
+package selector
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type affinityKey struct{}
+
+// NewAffinityContext creates a new context with session affinity key attached,
+// selections with the same key are pinned to the same node.
+func NewAffinityContext(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, affinityKey{}, key)
+}
+
+// FromAffinityContext returns the session affinity key in ctx if it exists.
+func FromAffinityContext(ctx context.Context) (key string, ok bool) {
+	key, ok = ctx.Value(affinityKey{}).(string)
+	return
+}
+
+type affinityEntry struct {
+	addr   string
+	expire time.Time
+}
+
+// affinity pins affinity keys to node addresses for a ttl.
+type affinity struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]affinityEntry
+	swept   time.Time
+}
+
+func newAffinity(ttl time.Duration) *affinity {
+	return &affinity{
+		ttl:     ttl,
+		entries: make(map[string]affinityEntry),
+		swept:   time.Now(),
+	}
+}
+
+// get returns the pinned node address of the key and refreshes its ttl.
+func (a *affinity) get(key string) (string, bool) {
+	now := time.Now()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	e, ok := a.entries[key]
+	if !ok {
+		return "", false
+	}
+	if now.After(e.expire) {
+		delete(a.entries, key)
+		return "", false
+	}
+	e.expire = now.Add(a.ttl)
+	a.entries[key] = e
+	return e.addr, true
+}
+
+// set pins the key to the node address.
+func (a *affinity) set(key, addr string) {
+	now := time.Now()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if now.Sub(a.swept) > a.ttl {
+		for k, e := range a.entries {
+			if now.After(e.expire) {
+				delete(a.entries, k)
+			}
+		}
+		a.swept = now
+	}
+	a.entries[key] = affinityEntry{addr: addr, expire: now.Add(a.ttl)}
+}
+
+// pick picks the pinned node of the key from the candidates.
+func (a *affinity) pick(key string, candidates []WeightedNode) (WeightedNode, bool) {
+	addr, ok := a.get(key)
+	if !ok {
+		return nil, false
+	}
+	for _, n := range candidates {
+		if n.Address() == addr {
+			return n, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,82 @@
+package selector
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAffinityContext(t *testing.T) {
+	ctx := NewAffinityContext(context.Background(), "session")
+	key, ok := FromAffinityContext(ctx)
+	if !ok {
+		t.Errorf("expect %v, got %v", true, ok)
+	}
+	if key != "session" {
+		t.Errorf("expect %v, got %v", "session", key)
+	}
+	if _, ok = FromAffinityContext(context.Background()); ok {
+		t.Errorf("expect %v, got %v", false, ok)
+	}
+}
+
+func TestDefaultAffinity(t *testing.T) {
+	builder := DefaultBuilder{
+		Node:        &mockWeightedNodeBuilder{},
+		Balancer:    &mockBalancerBuilder{},
+		AffinityTTL: time.Millisecond * 50,
+	}
+	selector := builder.Build()
+	var nodes []Node
+	for _, addr := range []string{"127.0.0.1:8080", "127.0.0.1:8081", "127.0.0.1:8082", "127.0.0.1:8083"} {
+		nodes = append(nodes, NewNode("http", addr, nil))
+	}
+	selector.Apply(nodes)
+
+	ctx := NewAffinityContext(context.Background(), "session")
+	pinned, done, err := selector.Select(ctx)
+	if err != nil {
+		t.Fatalf("expect %v, got %v", nil, err)
+	}
+	done(ctx, DoneInfo{})
+	for i := 0; i < 20; i++ {
+		n, done, err := selector.Select(ctx)
+		if err != nil {
+			t.Fatalf("expect %v, got %v", nil, err)
+		}
+		if n.Address() != pinned.Address() {
+			t.Errorf("expect %v, got %v", pinned.Address(), n.Address())
+		}
+		done(ctx, DoneInfo{})
+	}
+
+	// the pinned node disappears
+	var rest []Node
+	for _, n := range nodes {
+		if n.Address() != pinned.Address() {
+			rest = append(rest, n)
+		}
+	}
+	selector.Apply(rest)
+	n, _, err := selector.Select(ctx)
+	if err != nil {
+		t.Fatalf("expect %v, got %v", nil, err)
+	}
+	if n.Address() == pinned.Address() {
+		t.Errorf("expect removed node %v not to be selected", pinned.Address())
+	}
+	pinned = n
+	n, _, err = selector.Select(ctx)
+	if err != nil {
+		t.Fatalf("expect %v, got %v", nil, err)
+	}
+	if n.Address() != pinned.Address() {
+		t.Errorf("expect %v, got %v", pinned.Address(), n.Address())
+	}
+
+	// expired
+	time.Sleep(time.Millisecond * 60)
+	if _, ok := selector.(*Default).affinity.get("session"); ok {
+		t.Errorf("expect affinity to be expired")
+	}
+}
@@ -44,3 +44,15 @@ type WeightedNode interface {
 type WeightedNodeBuilder interface {
 	Build(Node) WeightedNode
 }
+
+// StatsReporter is implemented by the WeightedNode which exposes its runtime statistics.
+type StatsReporter interface {
+	// Lag is the moving average latency of the node.
+	Lag() time.Duration
+	// SuccessRate is the success rate of the node in [0, 1].
+	SuccessRate() float64
+	// Inflight is the number of inflight requests to the node.
+	Inflight() int64
+	// Weight is the runtime calculated weight.
+	Weight() float64
+}
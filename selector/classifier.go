@@ -0,0 +1,53 @@
+package selector
+
+import (
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// Outcome is the classified result of a request to a node.
+type Outcome int
+
+const (
+	// OutcomeSuccess means the node handled the request, including the client errors by default.
+	OutcomeSuccess Outcome = iota
+	// OutcomeFailure means the node failed, e.g. server errors, timeouts and network errors.
+	OutcomeFailure
+	// OutcomeOverload means the node rejected the request as overloaded, e.g. 429.
+	OutcomeOverload
+)
+
+// Failed reports whether the outcome counts against the node.
+func (o Outcome) Failed() bool {
+	return o != OutcomeSuccess
+}
+
+// ErrorClassifier classifies the request errors for the node statistics,
+// it is shared by the ewma node, the wrr error decay and the outlier detection.
+type ErrorClassifier interface {
+	Classify(err error) Outcome
+}
+
+// ErrorClassifierFunc is an adapter to allow the use of ordinary functions as ErrorClassifier.
+type ErrorClassifierFunc func(err error) Outcome
+
+// Classify calls f(err).
+func (f ErrorClassifierFunc) Classify(err error) Outcome {
+	return f(err)
+}
+
+// DefaultErrorClassifier classifies the server errors, timeouts and network errors
+// as failures, 429 as overload, and the other errors as success. It is opt-in, the
+// ewma node, the wrr and the outlier detection keep their own classification by default.
+var DefaultErrorClassifier ErrorClassifier = ErrorClassifierFunc(func(err error) Outcome {
+	switch ErrorClass(err) {
+	case ErrClassServer, ErrClassTimeout, ErrClassNetwork:
+		return OutcomeFailure
+	case ErrClassClient:
+		if errors.Code(err) == http.StatusTooManyRequests {
+			return OutcomeOverload
+		}
+	}
+	return OutcomeSuccess
+})
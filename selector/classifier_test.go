@@ -0,0 +1,55 @@
+package selector
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestDefaultErrorClassifier(t *testing.T) {
+	cases := []struct {
+		err  error
+		want Outcome
+	}{
+		{nil, OutcomeSuccess},
+		{errors.BadRequest("", ""), OutcomeSuccess},
+		{context.Canceled, OutcomeSuccess},
+		{errors.New(429, "", ""), OutcomeOverload},
+		{errors.ServiceUnavailable("", ""), OutcomeFailure},
+		{context.DeadlineExceeded, OutcomeFailure},
+		{&net.OpError{Op: "dial", Err: net.ErrClosed}, OutcomeFailure},
+	}
+	for _, c := range cases {
+		if got := DefaultErrorClassifier.Classify(c.err); got != c.want {
+			t.Errorf("%v: expect %v, got %v", c.err, c.want, got)
+		}
+	}
+	if OutcomeSuccess.Failed() || !OutcomeOverload.Failed() {
+		t.Errorf("expect only the success not to fail")
+	}
+}
+
+func TestOutlierClassifier(t *testing.T) {
+	// treat the client errors as failures
+	d := newOutlierDetector(&OutlierConfig{
+		ConsecutiveErrors: 1,
+		Classifier: ErrorClassifierFunc(func(err error) Outcome {
+			if err != nil {
+				return OutcomeFailure
+			}
+			return OutcomeSuccess
+		}),
+		MaxEjectionPercent: 100,
+	})
+	nodes := []WeightedNode{
+		&mockWeightedNode{Node: NewNode("http", "127.0.0.1:8080", nil)},
+		&mockWeightedNode{Node: NewNode("http", "127.0.0.1:9090", nil)},
+	}
+	d.apply(nodes)
+	d.report("127.0.0.1:8080", errors.BadRequest("", ""))
+	if got := d.filter(nodes); len(got) != 1 {
+		t.Errorf("expect the node to be ejected, got %v", len(got))
+	}
+}
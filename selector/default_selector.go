@@ -2,7 +2,9 @@ package selector
 
 import (
 	"context"
+	"sync"
 	"sync/atomic"
+	"time"
 )
 
 var (
@@ -10,6 +12,32 @@ var (
 	_ Builder    = (*DefaultBuilder)(nil)
 )
 
+var (
+	nodesPool = sync.Pool{New: func() interface{} {
+		return new([]Node)
+	}}
+	candidatesPool = sync.Pool{New: func() interface{} {
+		return new([]WeightedNode)
+	}}
+)
+
+func putNodes(buf *[]Node) {
+	nodes := *buf
+	for i := range nodes {
+		nodes[i] = nil
+	}
+	*buf = nodes[:0]
+	nodesPool.Put(buf)
+}
+
+func putCandidates(buf *[]WeightedNode) {
+	candidates := *buf
+	for i := range candidates {
+		candidates[i] = nil
+	}
+	*buf = candidates[:0]
+	candidatesPool.Put(buf)
+}
 
 // Default 是selector的默认实现。 它内部通过Balancer进行负载均衡
 // selector除了 balancer，还有过滤作用
@@ -20,39 +48,205 @@ type Default struct {
 	NodeBuilder WeightedNodeBuilder
 	// 通过这个balancer来做负载均衡（调用其Pick()方法）
 	Balancer Balancer
+	// Observer observes the selections, it can be overridden by WithMetrics.
+	Observer Observer
+	// FilterFallback falls back to the full healthy node set when the filters
+	// remove every node, it can be overridden by WithFilterFallback.
+	FilterFallback bool
+	// WaitForNodes waits for the next Apply bounded by the ctx instead of failing
+	// with ErrNoAvailable, it can be overridden by WithWaitForNodes.
+	WaitForNodes bool
+	// Validator validates the applied nodes, default is ValidateNode.
+	Validator func(Node) error
+	// OnInvalidNode is called with the nodes rejected by Apply, e.g. the invalid
+	// or the duplicate ones, if not nil.
+	OnInvalidNode func(Node, error)
+
+	// 通过Apply方法，将WeightedNode和构建它们的负载均衡策略一起存储到state中
+	state atomic.Value
+	// 被动的异常节点检测，为nil时不开启
+	outlier *outlierDetector
+	// 会话保持，为nil时不开启
+	affinity *affinity
+	// 流量镜像，为nil时不开启
+	mirror *mirror
+	// 手动标记的不健康节点
+	health manualHealth
+	// 保证Apply和SetBalancer串行执行
+	applyMu sync.Mutex
+	// 已移除但仍有请求在处理的节点，请求结束后终结
+	retired retired
+	// 下一次Apply时关闭，用于唤醒等待节点的请求
+	waitMu  sync.Mutex
+	applied chan struct{}
+}
+
+// state is the nodes and the balancing policy they are built for, they are
+// swapped together so a Select never pairs a balancer with the nodes of another.
+type state struct {
+	nodes []WeightedNode
+	// 运行时替换的负载均衡策略，为空时使用Balancer和NodeBuilder
+	balancer Balancer
+	builder  WeightedNodeBuilder
+}
+
+func (d *Default) load() (*state, bool) {
+	s, ok := d.state.Load().(*state)
+	return s, ok
+}
+
+func (d *Default) loadNodes() []WeightedNode {
+	if s, ok := d.load(); ok {
+		return s.nodes
+	}
+	return nil
+}
+
+func (d *Default) balancer(s *state) Balancer {
+	if s != nil && s.balancer != nil {
+		return s.balancer
+	}
+	return d.Balancer
+}
+
+func (d *Default) nodeBuilder(s *state) WeightedNodeBuilder {
+	if s != nil && s.builder != nil {
+		return s.builder
+	}
+	return d.NodeBuilder
+}
 
-	// 通过Apply方法，将WeightedNode存储到nodes中
-	nodes atomic.Value
+// SetBalancer swaps the balancer and the node builder atomically at runtime,
+// e.g. from wrr to p2c, the nodes are rebuilt with the node builder.
+// The current one is kept if the balancer or the node builder is nil.
+func (d *Default) SetBalancer(b Balancer, nb WeightedNodeBuilder) {
+	d.applyMu.Lock()
+	defer d.applyMu.Unlock()
+	s, _ := d.load()
+	if b == nil {
+		b = d.balancer(s)
+	}
+	if nb == nil {
+		nb = d.nodeBuilder(s)
+	}
+	var old []WeightedNode
+	if s != nil {
+		old = s.nodes
+	}
+	weightedNodes := make([]WeightedNode, 0, len(old))
+	for _, wn := range old {
+		weightedNodes = append(weightedNodes, nb.Build(wn.Raw()))
+	}
+	d.state.Store(&state{nodes: weightedNodes, balancer: b, builder: nb})
+	d.retired.retire(old)
 }
 
 // Select is select one node.
 func (d *Default) Select(ctx context.Context, opts ...SelectOption) (selected Node, done DoneFunc, err error) {
+	var options SelectOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	// 终结已移除且请求已处理完成的节点
+	d.retired.sweep()
+	observer := options.Observer
+	if observer == nil {
+		observer = d.Observer
+	}
+	wait := d.WaitForNodes
+	if options.WaitForNodes != nil {
+		wait = *options.WaitForNodes
+	}
+	start := time.Now()
 	var (
-		options    SelectOptions
-		candidates []WeightedNode
+		wn         WeightedNode
+		candidates int
 	)
-	// 加载所有节点
-	nodes, ok := d.nodes.Load().([]WeightedNode)
+	for {
+		var applied <-chan struct{}
+		if wait {
+			// 先获取通知再选择，避免错过两者之间的Apply
+			applied = d.appliedCh()
+		}
+		wn, done, candidates, err = d.selectNode(ctx, &options)
+		// 没有可用节点时，等待下一次Apply（如滚动发布期间）
+		if !wait || !waitApplied(ctx, applied, err) {
+			break
+		}
+	}
+	var addr string
+	if wn != nil {
+		addr = wn.Address()
+	}
+	traceSelect(ctx, &options, addr, candidates, start, err)
+	if observer != nil {
+		observer.OnSelect(ctx, Observation{
+			Address:    addr,
+			Candidates: candidates,
+			Err:        err,
+			ErrClass:   ErrorClass(err),
+		})
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if observer != nil {
+		done = observeDone(observer, wn.Address(), candidates, done)
+	}
+	return wn.Raw(), done, nil
+}
+
+func (d *Default) selectNode(ctx context.Context, options *SelectOptions) (WeightedNode, DoneFunc, int, error) {
+	var candidates []WeightedNode
+	// 加载所有节点，以及构建它们的负载均衡策略
+	s, ok := d.load()
 	if !ok {
-		return nil, nil, ErrNoAvailable
+		return nil, nil, 0, ErrNoAvailable
 	}
-	for _, o := range opts {
-		o(&options)
+	nodes := s.nodes
+	// 0. 指定节点时只使用该节点，否则剔除手动标记不健康和被驱逐的异常节点
+	if addr, ok := PinnedNode(ctx); ok {
+		if nodes = pin(nodes, addr); len(nodes) == 0 {
+			return nil, nil, 0, ErrNoAvailable
+		}
+	} else {
+		nodes = d.health.filter(nodes)
+		if d.outlier != nil {
+			nodes = d.outlier.filter(nodes)
+		}
 	}
+	nodes = exclude(ctx, nodes)
 	// 1. 走过滤器
 	if len(options.NodeFilters) > 0 {
-		newNodes := make([]Node, len(nodes))
-		for i, wc := range nodes {
-			newNodes[i] = wc
+		// 中间切片从池中获取，避免每次请求都分配内存
+		nodesBuf := nodesPool.Get().(*[]Node)
+		candidatesBuf := candidatesPool.Get().(*[]WeightedNode)
+		defer func() {
+			putNodes(nodesBuf)
+			putCandidates(candidatesBuf)
+		}()
+		newNodes := (*nodesBuf)[:0]
+		for _, wc := range nodes {
+			newNodes = append(newNodes, wc)
 		}
+		*nodesBuf = newNodes
 		// 过滤器
 		for _, filter := range options.NodeFilters {
 			newNodes = filter(ctx, newNodes)
 		}
 		// 得到候选者
-		candidates = make([]WeightedNode, len(newNodes))
-		for i, n := range newNodes {
-			candidates[i] = n.(WeightedNode)
+		candidates = (*candidatesBuf)[:0]
+		for _, n := range newNodes {
+			candidates = append(candidates, n.(WeightedNode))
+		}
+		*candidatesBuf = candidates
+		// 过滤后没有节点时，回退到全部健康节点
+		fallback := d.FilterFallback
+		if options.FilterFallback != nil {
+			fallback = *options.FilterFallback
+		}
+		if len(candidates) == 0 && fallback {
+			candidates = nodes
 		}
 	} else {
 		candidates = nodes
@@ -60,41 +254,183 @@ func (d *Default) Select(ctx context.Context, opts ...SelectOption) (selected No
 
 	if len(candidates) == 0 {
 		// 没有候选者
-		return nil, nil, ErrNoAvailable
+		return nil, nil, 0, ErrNoAvailable
+	}
+	balancer := options.Balancer
+	if balancer == nil {
+		balancer = d.balancer(s)
 	}
-	// 调用负载均衡器，执行对应的负载均衡策略，从候选节点中，选择一个节点
-	wn, done, err := d.Balancer.Pick(ctx, candidates) // 由负载均衡器，从候选节点中pick一个出来
+	wn, done, err := d.pick(ctx, balancer, candidates)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, len(candidates), err
 	}
 	p, ok := FromPeerContext(ctx)
 	if ok {
 		p.Node = wn.Raw()
 	}
-	return wn.Raw(), done, nil
+	if m, ok := FromMirrorContext(ctx); ok {
+		m.Node = nil
+		if d.mirror != nil {
+			m.Node = d.mirror.pick(ctx, nodes, wn.Address())
+		}
+	}
+	if d.outlier != nil {
+		done = d.outlierDone(wn.Address(), done)
+	}
+	return wn, done, len(candidates), nil
+}
+
+// pick picks a node from the candidates, the pinned node of the affinity key is preferred.
+func (d *Default) pick(ctx context.Context, balancer Balancer, candidates []WeightedNode) (WeightedNode, DoneFunc, error) {
+	if d.affinity == nil {
+		// 调用负载均衡器，执行对应的负载均衡策略，从候选节点中，选择一个节点
+		return balancer.Pick(ctx, candidates)
+	}
+	key, ok := FromAffinityContext(ctx)
+	if !ok {
+		return balancer.Pick(ctx, candidates)
+	}
+	if wn, ok := d.affinity.pick(key, candidates); ok {
+		return wn, wn.Pick(), nil
+	}
+	// 绑定的节点不存在时，重新选择节点并绑定
+	wn, done, err := balancer.Pick(ctx, candidates)
+	if err != nil {
+		return nil, nil, err
+	}
+	d.affinity.set(key, wn.Address())
+	return wn, done, nil
+}
+
+// outlierDone reports the RPC result to the outlier detector.
+func (d *Default) outlierDone(addr string, done DoneFunc) DoneFunc {
+	return func(ctx context.Context, di DoneInfo) {
+		d.outlier.report(addr, di.Err)
+		if done != nil {
+			done(ctx, di)
+		}
+	}
+}
+
+// Nodes returns a snapshot of the weighted nodes,
+// the nodes implementing StatsReporter expose their runtime statistics.
+func (d *Default) Nodes() []WeightedNode {
+	nodes := d.loadNodes()
+	snapshot := make([]WeightedNode, len(nodes))
+	copy(snapshot, nodes)
+	return snapshot
 }
 
 // Apply update nodes info.
 func (d *Default) Apply(nodes []Node) {
-	// 就是用新的node列表，替换老的node列表
+	d.applyMu.Lock()
+	defer d.applyMu.Unlock()
+	// 去重并剔除非法节点
+	nodes = d.validate(nodes)
+	// 清理已过期的不健康标记
+	d.health.prune(time.Now())
+	// 复用未变化节点的WeightedNode，保留其负载统计信息
+	s, _ := d.load()
+	var old []WeightedNode
+	if s != nil {
+		old = s.nodes
+	}
+	builder := d.nodeBuilder(s)
+	existing := make(map[string]WeightedNode, len(old))
+	for _, wn := range old {
+		existing[wn.Address()] = wn
+	}
 	weightedNodes := make([]WeightedNode, 0, len(nodes))
 	for _, n := range nodes {
-		weightedNodes = append(weightedNodes, d.NodeBuilder.Build(n))
+		if wn, ok := existing[n.Address()]; ok && sameNode(wn.Raw(), n) {
+			weightedNodes = append(weightedNodes, wn)
+			delete(existing, n.Address())
+			continue
+		}
+		weightedNodes = append(weightedNodes, builder.Build(n))
+	}
+	if d.outlier != nil {
+		d.outlier.apply(weightedNodes)
+	}
+	next := &state{nodes: weightedNodes}
+	if s != nil {
+		next.balancer, next.builder = s.balancer, s.builder
 	}
-	// TODO: Do not delete unchanged nodes
-	d.nodes.Store(weightedNodes)
+	d.state.Store(next)
+	d.notifyApplied()
+	// 未被复用的旧节点已被移除
+	removed := make([]WeightedNode, 0, len(existing))
+	for _, wn := range existing {
+		removed = append(removed, wn)
+	}
+	d.retired.retire(removed)
+}
+
+// sameNode reports whether the two nodes are the same instance with the same attributes.
+func sameNode(a, b Node) bool {
+	if a.Address() != b.Address() || a.Scheme() != b.Scheme() ||
+		a.ServiceName() != b.ServiceName() || a.Version() != b.Version() {
+		return false
+	}
+	wa, wb := a.InitialWeight(), b.InitialWeight()
+	if (wa == nil) != (wb == nil) || (wa != nil && *wa != *wb) {
+		return false
+	}
+	ma, mb := a.Metadata(), b.Metadata()
+	if len(ma) != len(mb) {
+		return false
+	}
+	for k, v := range ma {
+		if vb, ok := mb[k]; !ok || vb != v {
+			return false
+		}
+	}
+	return true
 }
 
 // DefaultBuilder is de
 type DefaultBuilder struct {
 	Node     WeightedNodeBuilder
 	Balancer BalancerBuilder
+	// Outlier enables passive outlier detection if not nil.
+	Outlier *OutlierConfig
+	// AffinityTTL enables session affinity if greater than 0,
+	// selections with the same affinity key are pinned to the same node for the ttl.
+	AffinityTTL time.Duration
+	// Observer observes the selections if not nil.
+	Observer Observer
+	// FilterFallback falls back to the full healthy node set when the filters remove every node.
+	FilterFallback bool
+	// WaitForNodes waits for the next Apply bounded by the ctx instead of failing with ErrNoAvailable.
+	WaitForNodes bool
+	// Validator validates the applied nodes, default is ValidateNode.
+	Validator func(Node) error
+	// OnInvalidNode is called with the nodes rejected by Apply if not nil.
+	OnInvalidNode func(Node, error)
+	// Mirror enables traffic mirroring if not nil, the shadow node is set
+	// to the Mirror of the ctx, see NewMirrorContext.
+	Mirror *MirrorConfig
 }
 
 // Build create builder
 func (db *DefaultBuilder) Build() Selector {
-	return &Default{
-		NodeBuilder: db.Node,
-		Balancer:    db.Balancer.Build(),
+	d := &Default{
+		NodeBuilder:    db.Node,
+		Balancer:       db.Balancer.Build(),
+		Observer:       db.Observer,
+		FilterFallback: db.FilterFallback,
+		WaitForNodes:   db.WaitForNodes,
+		Validator:      db.Validator,
+		OnInvalidNode:  db.OnInvalidNode,
+	}
+	if db.Outlier != nil {
+		d.outlier = newOutlierDetector(db.Outlier)
+	}
+	if db.AffinityTTL > 0 {
+		d.affinity = newAffinity(db.AffinityTTL)
+	}
+	if db.Mirror != nil {
+		d.mirror = newMirror(db.Mirror)
 	}
+	return d
 }
@@ -0,0 +1,88 @@
+package filter
+
+import (
+	"context"
+	"net"
+	"path"
+
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+// addressMatcher matches the node address by ip, cidr or glob patterns.
+type addressMatcher struct {
+	nets  []*net.IPNet
+	ips   []net.IP
+	globs []string
+}
+
+func newAddressMatcher(patterns []string) *addressMatcher {
+	m := &addressMatcher{}
+	for _, p := range patterns {
+		if _, ipNet, err := net.ParseCIDR(p); err == nil {
+			m.nets = append(m.nets, ipNet)
+			continue
+		}
+		if ip := net.ParseIP(p); ip != nil {
+			m.ips = append(m.ips, ip)
+			continue
+		}
+		m.globs = append(m.globs, p)
+	}
+	return m
+}
+
+func (m *addressMatcher) match(addr string) bool {
+	host := addr
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		host = h
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		for _, n := range m.nets {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+		for _, i := range m.ips {
+			if i.Equal(ip) {
+				return true
+			}
+		}
+	}
+	for _, g := range m.globs {
+		if ok, _ := path.Match(g, addr); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowAddress is address allow list filter, it keeps only the nodes whose address matches any of the patterns.
+// A pattern is an ip (e.g. "10.0.0.1"), a cidr (e.g. "10.0.0.0/8") or an address glob
+// in path.Match syntax (e.g. "10.0.1.*:8080").
+func AllowAddress(patterns ...string) selector.NodeFilter {
+	m := newAddressMatcher(patterns)
+	return func(_ context.Context, nodes []selector.Node) []selector.Node {
+		newNodes := make([]selector.Node, 0, len(nodes))
+		for _, n := range nodes {
+			if m.match(n.Address()) {
+				newNodes = append(newNodes, n)
+			}
+		}
+		return newNodes
+	}
+}
+
+// DenyAddress is address deny list filter, it removes the nodes whose address matches any of the patterns.
+// The patterns are the same as AllowAddress.
+func DenyAddress(patterns ...string) selector.NodeFilter {
+	m := newAddressMatcher(patterns)
+	return func(_ context.Context, nodes []selector.Node) []selector.Node {
+		newNodes := make([]selector.Node, 0, len(nodes))
+		for _, n := range nodes {
+			if !m.match(n.Address()) {
+				newNodes = append(newNodes, n)
+			}
+		}
+		return newNodes
+	}
+}
@@ -0,0 +1,52 @@
+package filter
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+func addressNodes() []selector.Node {
+	return []selector.Node{
+		selector.NewNode("http", "10.0.0.1:8080", nil),
+		selector.NewNode("http", "10.0.1.2:8080", nil),
+		selector.NewNode("http", "192.168.0.1:9090", nil),
+		selector.NewNode("http", "[::1]:8080", nil),
+	}
+}
+
+func addresses(nodes []selector.Node) []string {
+	addrs := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		addrs = append(addrs, n.Address())
+	}
+	return addrs
+}
+
+func TestAllowAddress(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		want     []string
+	}{
+		{[]string{"10.0.0.0/16"}, []string{"10.0.0.1:8080", "10.0.1.2:8080"}},
+		{[]string{"192.168.0.1", "::1"}, []string{"192.168.0.1:9090", "[::1]:8080"}},
+		{[]string{"10.0.1.*:8080"}, []string{"10.0.1.2:8080"}},
+		{[]string{"*:9090"}, []string{"192.168.0.1:9090"}},
+	}
+	for _, test := range tests {
+		got := addresses(AllowAddress(test.patterns...)(context.Background(), addressNodes()))
+		if !reflect.DeepEqual(test.want, got) {
+			t.Errorf("expect %v, got %v", test.want, got)
+		}
+	}
+}
+
+func TestDenyAddress(t *testing.T) {
+	got := addresses(DenyAddress("10.0.0.0/8", "::1")(context.Background(), addressNodes()))
+	want := []string{"192.168.0.1:9090"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("expect %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,27 @@
+package filter
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+// allower is the node with circuit breaker, see selector/node/breaker.
+type allower interface {
+	Allow() error
+}
+
+// CircuitBreaker is circuit breaker filter, it removes the nodes whose circuit is open.
+// The nodes without circuit breaker are kept.
+func CircuitBreaker() selector.NodeFilter {
+	return func(_ context.Context, nodes []selector.Node) []selector.Node {
+		newNodes := make([]selector.Node, 0, len(nodes))
+		for _, n := range nodes {
+			if a, ok := n.(allower); ok && a.Allow() != nil {
+				continue
+			}
+			newNodes = append(newNodes, n)
+		}
+		return newNodes
+	}
+}
@@ -0,0 +1,37 @@
+package filter
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+type mockAllower struct {
+	selector.Node
+	err error
+}
+
+func (n *mockAllower) Allow() error {
+	return n.err
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	nodes := []selector.Node{
+		&mockAllower{Node: selector.NewNode("http", "127.0.0.1:9090", nil)},
+		&mockAllower{Node: selector.NewNode("http", "127.0.0.2:9090", nil), err: errors.New("open")},
+		selector.NewNode("http", "127.0.0.3:9090", nil),
+	}
+	nodes = CircuitBreaker()(context.Background(), nodes)
+	if !reflect.DeepEqual(len(nodes), 2) {
+		t.Fatalf("expect %v, got %v", 2, len(nodes))
+	}
+	if !reflect.DeepEqual(nodes[0].Address(), "127.0.0.1:9090") {
+		t.Errorf("expect %v, got %v", "127.0.0.1:9090", nodes[0].Address())
+	}
+	if !reflect.DeepEqual(nodes[1].Address(), "127.0.0.3:9090") {
+		t.Errorf("expect %v, got %v", "127.0.0.3:9090", nodes[1].Address())
+	}
+}
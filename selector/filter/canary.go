@@ -0,0 +1,51 @@
+package filter
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand"
+
+	"github.com/go-kratos/kratos/v2/metadata"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+// ByPercentageToVersion is canary filter, it routes pct percent of the traffic
+// to the nodes of the version, and the rest to the other nodes.
+// The traffic is split by the hash of the client metadata value of hashKey,
+// so the same key always hits the same group, it is random if the value is absent.
+func ByPercentageToVersion(version string, pct int, hashKey string) selector.NodeFilter {
+	return func(ctx context.Context, nodes []selector.Node) []selector.Node {
+		canary := make([]selector.Node, 0, len(nodes))
+		stable := make([]selector.Node, 0, len(nodes))
+		for _, n := range nodes {
+			if n.Version() == version {
+				canary = append(canary, n)
+			} else {
+				stable = append(stable, n)
+			}
+		}
+		// 某一组没有节点时，使用另一组
+		if len(canary) == 0 {
+			return stable
+		}
+		if len(stable) == 0 {
+			return canary
+		}
+		if bucket(ctx, hashKey) < pct {
+			return canary
+		}
+		return stable
+	}
+}
+
+// bucket returns the traffic bucket in [0, 100).
+func bucket(ctx context.Context, hashKey string) int {
+	if md, ok := metadata.FromClientContext(ctx); ok && hashKey != "" {
+		if v := md.Get(hashKey); v != "" {
+			h := fnv.New32a()
+			_, _ = h.Write([]byte(v))
+			return int(h.Sum32() % 100)
+		}
+	}
+	return rand.Intn(100)
+}
@@ -0,0 +1,69 @@
+package filter
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/metadata"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+func canaryNodes() []selector.Node {
+	var nodes []selector.Node
+	nodes = append(nodes, selector.NewNode(
+		"http",
+		"127.0.0.1:9090",
+		&registry.ServiceInstance{
+			ID:      "127.0.0.1:9090",
+			Name:    "helloworld",
+			Version: "v1.0.0",
+		}))
+	nodes = append(nodes, selector.NewNode(
+		"http",
+		"127.0.0.2:9090",
+		&registry.ServiceInstance{
+			ID:      "127.0.0.2:9090",
+			Name:    "helloworld",
+			Version: "v2.0.0",
+		}))
+	return nodes
+}
+
+func TestByPercentageToVersion(t *testing.T) {
+	f := ByPercentageToVersion("v2.0.0", 20, "x-md-global-uid")
+	var canary int
+	for i := 0; i < 1000; i++ {
+		nodes := f(context.Background(), canaryNodes())
+		if !reflect.DeepEqual(len(nodes), 1) {
+			t.Fatalf("expect %v, got %v", 1, len(nodes))
+		}
+		if nodes[0].Version() == "v2.0.0" {
+			canary++
+		}
+	}
+	if canary <= 100 || canary >= 300 {
+		t.Errorf("expect canary in (100, 300), got %v", canary)
+	}
+
+	// the same hash key always hits the same group
+	for i := 0; i < 10; i++ {
+		ctx := metadata.NewClientContext(context.Background(), metadata.New(map[string][]string{
+			"x-md-global-uid": {fmt.Sprint(i)},
+		}))
+		first := f(ctx, canaryNodes())[0].Version()
+		for j := 0; j < 10; j++ {
+			if v := f(ctx, canaryNodes())[0].Version(); v != first {
+				t.Errorf("expect %v, got %v", first, v)
+			}
+		}
+	}
+
+	// no canary nodes
+	nodes := ByPercentageToVersion("v3.0.0", 100, "")(context.Background(), canaryNodes())
+	if !reflect.DeepEqual(len(nodes), 2) {
+		t.Errorf("expect %v, got %v", 2, len(nodes))
+	}
+}
@@ -0,0 +1,32 @@
+package filter
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+// inflightReporter is the node counting its inflight requests, see selector.StatsReporter.
+type inflightReporter interface {
+	Inflight() int64
+}
+
+// MaxInflight is concurrency cap filter, it removes the nodes whose inflight
+// requests reach the max, so the overload spreads across the fleet and the
+// selection fails with ErrNoAvailable only when every node is saturated.
+// The nodes without inflight statistic are kept, a max <= 0 disables the filter.
+func MaxInflight(max int64) selector.NodeFilter {
+	return func(_ context.Context, nodes []selector.Node) []selector.Node {
+		if max <= 0 {
+			return nodes
+		}
+		newNodes := make([]selector.Node, 0, len(nodes))
+		for _, n := range nodes {
+			if r, ok := n.(inflightReporter); ok && r.Inflight() >= max {
+				continue
+			}
+			newNodes = append(newNodes, n)
+		}
+		return newNodes
+	}
+}
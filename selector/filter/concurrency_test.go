@@ -0,0 +1,42 @@
+package filter
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+type mockInflight struct {
+	selector.Node
+	inflight int64
+}
+
+func (n *mockInflight) Inflight() int64 {
+	return n.inflight
+}
+
+func TestMaxInflight(t *testing.T) {
+	nodes := []selector.Node{
+		&mockInflight{Node: selector.NewNode("http", "127.0.0.1:9090", nil), inflight: 1},
+		&mockInflight{Node: selector.NewNode("http", "127.0.0.2:9090", nil), inflight: 2},
+		selector.NewNode("http", "127.0.0.3:9090", nil),
+	}
+	newNodes := MaxInflight(2)(context.Background(), append([]selector.Node{}, nodes...))
+	if !reflect.DeepEqual(len(newNodes), 2) {
+		t.Fatalf("expect %v, got %v", 2, len(newNodes))
+	}
+	if !reflect.DeepEqual(newNodes[0].Address(), "127.0.0.1:9090") {
+		t.Errorf("expect %v, got %v", "127.0.0.1:9090", newNodes[0].Address())
+	}
+	if !reflect.DeepEqual(newNodes[1].Address(), "127.0.0.3:9090") {
+		t.Errorf("expect %v, got %v", "127.0.0.3:9090", newNodes[1].Address())
+	}
+	if newNodes = MaxInflight(1)(context.Background(), nodes[:2]); len(newNodes) != 0 {
+		t.Errorf("expect %v, got %v", 0, len(newNodes))
+	}
+	if newNodes = MaxInflight(0)(context.Background(), nodes); len(newNodes) != 3 {
+		t.Errorf("expect %v, got %v", 3, len(newNodes))
+	}
+}
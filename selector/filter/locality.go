@@ -0,0 +1,69 @@
+package filter
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+const (
+	// RegionKey is the metadata key of the node region.
+	RegionKey = "region"
+	// ZoneKey is the metadata key of the node zone.
+	ZoneKey = "zone"
+)
+
+// LocalityOption is locality filter option.
+type LocalityOption func(o *localityOptions)
+
+type localityOptions struct {
+	minRatio float64
+}
+
+// WithSpilloverRatio with the minimum ratio of local nodes to all nodes,
+// when the local nodes fall below it, traffic spills over to other zones.
+func WithSpilloverRatio(ratio float64) LocalityOption {
+	return func(o *localityOptions) {
+		o.minRatio = ratio
+	}
+}
+
+// Locality is locality filter, it prefers the nodes in the same zone,
+// and then in the same region as the caller.
+func Locality(region, zone string, opts ...LocalityOption) selector.NodeFilter {
+	var options localityOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	return func(_ context.Context, nodes []selector.Node) []selector.Node {
+		if len(nodes) == 0 {
+			return nodes
+		}
+		sameZone := make([]selector.Node, 0, len(nodes))
+		sameRegion := make([]selector.Node, 0, len(nodes))
+		for _, n := range nodes {
+			md := n.Metadata()
+			if md[RegionKey] != region {
+				continue
+			}
+			sameRegion = append(sameRegion, n)
+			if md[ZoneKey] == zone {
+				sameZone = append(sameZone, n)
+			}
+		}
+		if enough(len(sameZone), len(nodes), options.minRatio) {
+			return sameZone
+		}
+		if enough(len(sameRegion), len(nodes), options.minRatio) {
+			return sameRegion
+		}
+		return nodes
+	}
+}
+
+func enough(local, total int, minRatio float64) bool {
+	if local == 0 {
+		return false
+	}
+	return float64(local)/float64(total) >= minRatio
+}
@@ -0,0 +1,67 @@
+package filter
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+func localityNodes() []selector.Node {
+	var nodes []selector.Node
+	nodes = append(nodes, selector.NewNode(
+		"http",
+		"127.0.0.1:9090",
+		&registry.ServiceInstance{
+			ID:       "127.0.0.1:9090",
+			Name:     "helloworld",
+			Metadata: map[string]string{RegionKey: "sh", ZoneKey: "sh1"},
+		}))
+	nodes = append(nodes, selector.NewNode(
+		"http",
+		"127.0.0.2:9090",
+		&registry.ServiceInstance{
+			ID:       "127.0.0.2:9090",
+			Name:     "helloworld",
+			Metadata: map[string]string{RegionKey: "sh", ZoneKey: "sh2"},
+		}))
+	nodes = append(nodes, selector.NewNode(
+		"http",
+		"127.0.0.3:9090",
+		&registry.ServiceInstance{
+			ID:       "127.0.0.3:9090",
+			Name:     "helloworld",
+			Metadata: map[string]string{RegionKey: "bj", ZoneKey: "bj1"},
+		}))
+	return nodes
+}
+
+func TestLocality(t *testing.T) {
+	nodes := Locality("sh", "sh1")(context.Background(), localityNodes())
+	if !reflect.DeepEqual(len(nodes), 1) {
+		t.Errorf("expect %v, got %v", 1, len(nodes))
+	}
+	if !reflect.DeepEqual(nodes[0].Address(), "127.0.0.1:9090") {
+		t.Errorf("expect %v, got %v", "127.0.0.1:9090", nodes[0].Address())
+	}
+
+	// spill over to the same region
+	nodes = Locality("sh", "sh1", WithSpilloverRatio(0.5))(context.Background(), localityNodes())
+	if !reflect.DeepEqual(len(nodes), 2) {
+		t.Errorf("expect %v, got %v", 2, len(nodes))
+	}
+
+	// spill over to all regions
+	nodes = Locality("sh", "sh1", WithSpilloverRatio(0.9))(context.Background(), localityNodes())
+	if !reflect.DeepEqual(len(nodes), 3) {
+		t.Errorf("expect %v, got %v", 3, len(nodes))
+	}
+
+	// no local nodes
+	nodes = Locality("gz", "gz1")(context.Background(), localityNodes())
+	if !reflect.DeepEqual(len(nodes), 3) {
+		t.Errorf("expect %v, got %v", 3, len(nodes))
+	}
+}
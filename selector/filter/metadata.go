@@ -0,0 +1,20 @@
+package filter
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+// ByMetadata is metadata filter, it keeps the nodes whose metadata value of the key equals to v.
+func ByMetadata(key, value string) selector.NodeFilter {
+	return func(_ context.Context, nodes []selector.Node) []selector.Node {
+		newNodes := make([]selector.Node, 0, len(nodes))
+		for _, n := range nodes {
+			if v, ok := n.Metadata()[key]; ok && v == value {
+				newNodes = append(newNodes, n)
+			}
+		}
+		return newNodes
+	}
+}
@@ -0,0 +1,46 @@
+package filter
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+func TestByMetadata(t *testing.T) {
+	f := ByMetadata("cluster", "blue")
+	var nodes []selector.Node
+	nodes = append(nodes, selector.NewNode(
+		"http",
+		"127.0.0.1:9090",
+		&registry.ServiceInstance{
+			ID:       "127.0.0.1:9090",
+			Name:     "helloworld",
+			Metadata: map[string]string{"cluster": "green"},
+		}))
+	nodes = append(nodes, selector.NewNode(
+		"http",
+		"127.0.0.2:9090",
+		&registry.ServiceInstance{
+			ID:       "127.0.0.2:9090",
+			Name:     "helloworld",
+			Metadata: map[string]string{"cluster": "blue"},
+		}))
+	nodes = append(nodes, selector.NewNode(
+		"http",
+		"127.0.0.3:9090",
+		&registry.ServiceInstance{
+			ID:   "127.0.0.3:9090",
+			Name: "helloworld",
+		}))
+
+	nodes = f(context.Background(), nodes)
+	if !reflect.DeepEqual(len(nodes), 1) {
+		t.Errorf("expect %v, got %v", 1, len(nodes))
+	}
+	if !reflect.DeepEqual(nodes[0].Address(), "127.0.0.2:9090") {
+		t.Errorf("expect %v, got %v", "127.0.0.2:9090", nodes[0].Address())
+	}
+}
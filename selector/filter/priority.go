@@ -0,0 +1,87 @@
+package filter
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+// PriorityKey is the metadata key of the node priority tier,
+// the lower value has the higher priority, nodes without it belong to tier 0.
+const PriorityKey = "priority"
+
+// PriorityOption is priority filter option.
+type PriorityOption func(o *priorityOptions)
+
+type priorityOptions struct {
+	minNodes int
+	switches metrics.Counter
+}
+
+// WithMinNodes with the minimum number of nodes a tier must provide,
+// otherwise the lower tiers are used as well.
+func WithMinNodes(n int) PriorityOption {
+	return func(o *priorityOptions) {
+		o.minNodes = n
+	}
+}
+
+// WithTierSwitches with tier switches counter.
+// counter: selector_priority_switches_total{service, tier}
+func WithTierSwitches(c metrics.Counter) PriorityOption {
+	return func(o *priorityOptions) {
+		o.switches = c
+	}
+}
+
+// Priority is priority filter, it only uses the lower priority tiers
+// when the higher tiers have insufficient nodes.
+func Priority(opts ...PriorityOption) selector.NodeFilter {
+	options := priorityOptions{minNodes: 1}
+	for _, o := range opts {
+		o(&options)
+	}
+	var current int64
+	return func(_ context.Context, nodes []selector.Node) []selector.Node {
+		if len(nodes) == 0 {
+			return nodes
+		}
+		tiers := make(map[int][]selector.Node)
+		levels := make([]int, 0, 1)
+		for _, n := range nodes {
+			level := priority(n)
+			if _, ok := tiers[level]; !ok {
+				levels = append(levels, level)
+			}
+			tiers[level] = append(tiers[level], n)
+		}
+		sort.Ints(levels)
+		var (
+			newNodes []selector.Node
+			level    int
+		)
+		for _, level = range levels {
+			newNodes = append(newNodes, tiers[level]...)
+			if len(newNodes) >= options.minNodes {
+				break
+			}
+		}
+		if old := atomic.SwapInt64(&current, int64(level)); old != int64(level) && options.switches != nil {
+			options.switches.With(nodes[0].ServiceName(), strconv.Itoa(level)).Inc()
+		}
+		return newNodes
+	}
+}
+
+func priority(n selector.Node) int {
+	if str, ok := n.Metadata()[PriorityKey]; ok {
+		if level, err := strconv.Atoi(str); err == nil {
+			return level
+		}
+	}
+	return 0
+}
@@ -0,0 +1,91 @@
+package filter
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+type mockCounter struct {
+	lvs   []string
+	value float64
+}
+
+func (c *mockCounter) With(lvs ...string) metrics.Counter {
+	c.lvs = lvs
+	return c
+}
+
+func (c *mockCounter) Inc() {
+	c.value++
+}
+
+func (c *mockCounter) Add(delta float64) {
+	c.value += delta
+}
+
+func priorityNodes() []selector.Node {
+	var nodes []selector.Node
+	nodes = append(nodes, selector.NewNode(
+		"http",
+		"127.0.0.1:9090",
+		&registry.ServiceInstance{
+			ID:       "127.0.0.1:9090",
+			Name:     "helloworld",
+			Metadata: map[string]string{PriorityKey: "0"},
+		}))
+	nodes = append(nodes, selector.NewNode(
+		"http",
+		"127.0.0.2:9090",
+		&registry.ServiceInstance{
+			ID:       "127.0.0.2:9090",
+			Name:     "helloworld",
+			Metadata: map[string]string{PriorityKey: "1"},
+		}))
+	nodes = append(nodes, selector.NewNode(
+		"http",
+		"127.0.0.3:9090",
+		&registry.ServiceInstance{
+			ID:       "127.0.0.3:9090",
+			Name:     "helloworld",
+			Metadata: map[string]string{PriorityKey: "1"},
+		}))
+	return nodes
+}
+
+func TestPriority(t *testing.T) {
+	c := &mockCounter{}
+	f := Priority(WithTierSwitches(c))
+	nodes := f(context.Background(), priorityNodes())
+	if !reflect.DeepEqual(len(nodes), 1) {
+		t.Errorf("expect %v, got %v", 1, len(nodes))
+	}
+	if !reflect.DeepEqual(nodes[0].Address(), "127.0.0.1:9090") {
+		t.Errorf("expect %v, got %v", "127.0.0.1:9090", nodes[0].Address())
+	}
+	if !reflect.DeepEqual(c.value, float64(0)) {
+		t.Errorf("expect %v, got %v", 0, c.value)
+	}
+
+	// failover to tier 1
+	nodes = f(context.Background(), priorityNodes()[1:])
+	if !reflect.DeepEqual(len(nodes), 2) {
+		t.Errorf("expect %v, got %v", 2, len(nodes))
+	}
+	if !reflect.DeepEqual(c.value, float64(1)) {
+		t.Errorf("expect %v, got %v", 1, c.value)
+	}
+	if !reflect.DeepEqual(c.lvs, []string{"helloworld", "1"}) {
+		t.Errorf("expect %v, got %v", []string{"helloworld", "1"}, c.lvs)
+	}
+
+	// insufficient nodes in tier 0
+	nodes = Priority(WithMinNodes(2))(context.Background(), priorityNodes())
+	if !reflect.DeepEqual(len(nodes), 3) {
+		t.Errorf("expect %v, got %v", 3, len(nodes))
+	}
+}
@@ -0,0 +1,47 @@
+package filter
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+
+	"github.com/go-kratos/kratos/v2/metadata"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+// ShuffleShard is shuffle sharding filter, each client identity is assigned
+// a deterministic shard of size nodes, which bounds the blast radius of a poison-pill caller.
+// The identity is the client metadata value of identityKey, nodes are kept as they are if it is absent.
+func ShuffleShard(size int, identityKey string) selector.NodeFilter {
+	return func(ctx context.Context, nodes []selector.Node) []selector.Node {
+		if size <= 0 || len(nodes) <= size {
+			return nodes
+		}
+		md, ok := metadata.FromClientContext(ctx)
+		if !ok {
+			return nodes
+		}
+		identity := md.Get(identityKey)
+		if identity == "" {
+			return nodes
+		}
+		// rendezvous hashing, the shard changes minimally when nodes join or leave
+		scores := make([]uint64, len(nodes))
+		indexes := make([]int, len(nodes))
+		for i, n := range nodes {
+			h := fnv.New64a()
+			_, _ = h.Write([]byte(identity))
+			_, _ = h.Write([]byte(n.Address()))
+			scores[i] = h.Sum64()
+			indexes[i] = i
+		}
+		sort.Slice(indexes, func(i, j int) bool {
+			return scores[indexes[i]] > scores[indexes[j]]
+		})
+		newNodes := make([]selector.Node, 0, size)
+		for _, i := range indexes[:size] {
+			newNodes = append(newNodes, nodes[i])
+		}
+		return newNodes
+	}
+}
@@ -0,0 +1,47 @@
+package filter
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/metadata"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+func TestShuffleShard(t *testing.T) {
+	var nodes []selector.Node
+	for i := 0; i < 10; i++ {
+		nodes = append(nodes, selector.NewNode("http", fmt.Sprintf("127.0.0.%d:9090", i), nil))
+	}
+	f := ShuffleShard(3, "x-md-global-caller")
+	ctx := func(caller string) context.Context {
+		return metadata.NewClientContext(context.Background(), metadata.New(map[string][]string{
+			"x-md-global-caller": {caller},
+		}))
+	}
+
+	shard := f(ctx("a"), nodes)
+	if !reflect.DeepEqual(len(shard), 3) {
+		t.Fatalf("expect %v, got %v", 3, len(shard))
+	}
+	// deterministic
+	if again := f(ctx("a"), nodes); !reflect.DeepEqual(shard, again) {
+		t.Errorf("expect %v, got %v", shard, again)
+	}
+	// different callers get different shards
+	var differ bool
+	for i := 0; i < 10; i++ {
+		if !reflect.DeepEqual(shard, f(ctx(fmt.Sprint(i)), nodes)) {
+			differ = true
+		}
+	}
+	if !differ {
+		t.Errorf("expect different shards for different callers")
+	}
+	// without identity
+	if all := f(context.Background(), nodes); !reflect.DeepEqual(len(all), 10) {
+		t.Errorf("expect %v, got %v", 10, len(all))
+	}
+}
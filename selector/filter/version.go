@@ -18,3 +18,8 @@ func Version(version string) selector.NodeFilter {
 		return newNodes
 	}
 }
+
+// ByVersion is version filter, it keeps the nodes of the version.
+func ByVersion(version string) selector.NodeFilter {
+	return Version(version)
+}
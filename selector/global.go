@@ -1,5 +1,7 @@
 package selector
 
+import "sync"
+
 // 全局selector构建器
 var globalSelector = &wrapSelector{}
 
@@ -20,3 +22,38 @@ func GlobalSelector() Builder {
 func SetGlobalSelector(builder Builder) {
 	globalSelector.Builder = builder
 }
+
+// 按目标服务注册的selector构建器
+var targetSelectors sync.Map
+
+// RegisterBuilderFor registers the selector builder for the target, e.g. "discovery:///payments",
+// it overrides the global selector builder for the target.
+func RegisterBuilderFor(target string, builder Builder) {
+	targetSelectors.Store(target, builder)
+}
+
+// BuilderFor returns the selector builder for the target,
+// the global selector builder is returned if not registered.
+func BuilderFor(target string) Builder {
+	if builder, ok := targetSelectors.Load(target); ok {
+		return builder.(Builder)
+	}
+	return GlobalSelector()
+}
+
+// 按名称注册的selector构建器
+var namedSelectors sync.Map
+
+// RegisterBuilder registers the selector builder by name, e.g. p2c.Name, so that the
+// clients can select it by the target, e.g. "discovery:///payments?selector=p2c".
+func RegisterBuilder(name string, builder Builder) {
+	namedSelectors.Store(name, builder)
+}
+
+// BuilderByName returns the selector builder registered by name.
+func BuilderByName(name string) (Builder, bool) {
+	if builder, ok := namedSelectors.Load(name); ok {
+		return builder.(Builder), true
+	}
+	return nil, false
+}
@@ -0,0 +1,69 @@
+package selector
+
+import (
+	"sync"
+	"time"
+)
+
+// manualHealth holds the nodes marked unhealthy administratively.
+type manualHealth struct {
+	mu        sync.RWMutex
+	unhealthy map[string]time.Time
+}
+
+// MarkUnhealthy removes the node of the address from rotation for the duration,
+// a zero or negative duration removes it until MarkHealthy is called.
+func (d *Default) MarkUnhealthy(addr string, duration time.Duration) {
+	until := time.Time{}
+	if duration > 0 {
+		until = time.Now().Add(duration)
+	}
+	d.health.mu.Lock()
+	if d.health.unhealthy == nil {
+		d.health.unhealthy = make(map[string]time.Time)
+	}
+	d.health.pruneLocked(time.Now())
+	d.health.unhealthy[addr] = until
+	d.health.mu.Unlock()
+}
+
+// MarkHealthy puts the node of the address back into rotation.
+func (d *Default) MarkHealthy(addr string) {
+	d.health.mu.Lock()
+	delete(d.health.unhealthy, addr)
+	d.health.mu.Unlock()
+}
+
+// filter removes the nodes marked unhealthy.
+func (h *manualHealth) filter(nodes []WeightedNode) []WeightedNode {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.unhealthy) == 0 {
+		return nodes
+	}
+	now := time.Now()
+	healthy := make([]WeightedNode, 0, len(nodes))
+	for _, n := range nodes {
+		if until, ok := h.unhealthy[n.Address()]; ok && (until.IsZero() || now.Before(until)) {
+			continue
+		}
+		healthy = append(healthy, n)
+	}
+	return healthy
+}
+
+// prune removes the expired marks, it is called by Apply so the marks of the
+// churned nodes do not accumulate.
+func (h *manualHealth) prune(now time.Time) {
+	h.mu.Lock()
+	h.pruneLocked(now)
+	h.mu.Unlock()
+}
+
+func (h *manualHealth) pruneLocked(now time.Time) {
+	for addr, until := range h.unhealthy {
+		if !until.IsZero() && !now.Before(until) {
+			delete(h.unhealthy, addr)
+		}
+	}
+}
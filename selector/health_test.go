@@ -0,0 +1,67 @@
+package selector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMarkUnhealthy(t *testing.T) {
+	builder := DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+	}
+	selector := builder.Build().(*Default)
+	selector.Apply([]Node{NewNode("http", "127.0.0.1:8080", nil), NewNode("http", "127.0.0.1:9090", nil)})
+
+	selector.MarkUnhealthy("127.0.0.1:8080", 0)
+	selector.MarkUnhealthy("127.0.0.1:9090", time.Millisecond*20)
+	if _, _, err := selector.Select(context.Background()); !errors.Is(err, ErrNoAvailable) {
+		t.Errorf("expect %v, got %v", ErrNoAvailable, err)
+	}
+	time.Sleep(time.Millisecond * 30)
+	n, _, err := selector.Select(context.Background())
+	if err != nil {
+		t.Fatalf("expect %v, got %v", nil, err)
+	}
+	if n.Address() != "127.0.0.1:9090" {
+		t.Errorf("expect %v, got %v", "127.0.0.1:9090", n.Address())
+	}
+
+	selector.MarkHealthy("127.0.0.1:8080")
+	selector.MarkUnhealthy("127.0.0.1:9090", 0)
+	n, _, err = selector.Select(context.Background())
+	if err != nil {
+		t.Fatalf("expect %v, got %v", nil, err)
+	}
+	if n.Address() != "127.0.0.1:8080" {
+		t.Errorf("expect %v, got %v", "127.0.0.1:8080", n.Address())
+	}
+}
+
+func TestMarkUnhealthyPrune(t *testing.T) {
+	builder := DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+	}
+	selector := builder.Build().(*Default)
+	selector.MarkUnhealthy("127.0.0.1:8080", time.Millisecond*10)
+	selector.MarkUnhealthy("127.0.0.1:9090", 0)
+	time.Sleep(time.Millisecond * 20)
+
+	selector.Apply([]Node{NewNode("http", "127.0.0.1:7070", nil)})
+	if len(selector.health.unhealthy) != 1 {
+		t.Fatalf("expect %v, got %v", 1, len(selector.health.unhealthy))
+	}
+	if _, ok := selector.health.unhealthy["127.0.0.1:9090"]; !ok {
+		t.Errorf("expect the mark without expiry to be kept")
+	}
+
+	selector.MarkUnhealthy("127.0.0.1:6060", time.Millisecond*10)
+	time.Sleep(time.Millisecond * 20)
+	selector.MarkUnhealthy("127.0.0.1:5050", time.Minute)
+	if _, ok := selector.health.unhealthy["127.0.0.1:6060"]; ok {
+		t.Errorf("expect the expired mark to be removed")
+	}
+}
@@ -0,0 +1,74 @@
+package selector
+
+import "context"
+
+type (
+	pinnedKey   struct{}
+	excludedKey struct{}
+)
+
+// WithPinnedNode returns a new context with the pinned node address attached,
+// the selection only picks the node of the address, and bypasses outlier ejection.
+func WithPinnedNode(ctx context.Context, addr string) context.Context {
+	return context.WithValue(ctx, pinnedKey{}, addr)
+}
+
+// WithExcludedNodes returns a new context with the excluded node addresses attached,
+// the selection avoids the nodes unless no other node is available.
+// It appends to the addresses already excluded in ctx.
+func WithExcludedNodes(ctx context.Context, addrs ...string) context.Context {
+	excluded := make(map[string]struct{})
+	if old, ok := ctx.Value(excludedKey{}).(map[string]struct{}); ok {
+		for addr := range old {
+			excluded[addr] = struct{}{}
+		}
+	}
+	for _, addr := range addrs {
+		excluded[addr] = struct{}{}
+	}
+	return context.WithValue(ctx, excludedKey{}, excluded)
+}
+
+// PinnedNode returns the pinned node address in ctx if it exists.
+func PinnedNode(ctx context.Context) (addr string, ok bool) {
+	addr, ok = ctx.Value(pinnedKey{}).(string)
+	return
+}
+
+// ExcludedNodes returns the excluded node addresses in ctx.
+func ExcludedNodes(ctx context.Context) []string {
+	excluded, _ := ctx.Value(excludedKey{}).(map[string]struct{})
+	addrs := make([]string, 0, len(excluded))
+	for addr := range excluded {
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// pin keeps the node of the address.
+func pin(nodes []WeightedNode, addr string) []WeightedNode {
+	for _, n := range nodes {
+		if n.Address() == addr {
+			return []WeightedNode{n}
+		}
+	}
+	return nil
+}
+
+// exclude removes the excluded nodes in ctx, the nodes are returned as they are if all of them are excluded.
+func exclude(ctx context.Context, nodes []WeightedNode) []WeightedNode {
+	excluded, ok := ctx.Value(excludedKey{}).(map[string]struct{})
+	if !ok || len(excluded) == 0 {
+		return nodes
+	}
+	newNodes := make([]WeightedNode, 0, len(nodes))
+	for _, n := range nodes {
+		if _, ok := excluded[n.Address()]; !ok {
+			newNodes = append(newNodes, n)
+		}
+	}
+	if len(newNodes) == 0 {
+		return nodes
+	}
+	return newNodes
+}
@@ -0,0 +1,68 @@
+package selector
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+)
+
+func TestHintContext(t *testing.T) {
+	ctx := WithPinnedNode(context.Background(), "127.0.0.1:8080")
+	addr, ok := PinnedNode(ctx)
+	if !ok || addr != "127.0.0.1:8080" {
+		t.Errorf("expect %v, got %v", "127.0.0.1:8080", addr)
+	}
+	ctx = WithExcludedNodes(context.Background(), "127.0.0.1:8080")
+	ctx = WithExcludedNodes(ctx, "127.0.0.1:9090")
+	addrs := ExcludedNodes(ctx)
+	sort.Strings(addrs)
+	if len(addrs) != 2 || addrs[0] != "127.0.0.1:8080" || addrs[1] != "127.0.0.1:9090" {
+		t.Errorf("expect %v, got %v", []string{"127.0.0.1:8080", "127.0.0.1:9090"}, addrs)
+	}
+}
+
+func TestDefaultHint(t *testing.T) {
+	builder := DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+	}
+	selector := builder.Build()
+	selector.Apply([]Node{
+		NewNode("http", "127.0.0.1:8080", nil),
+		NewNode("http", "127.0.0.1:9090", nil),
+		NewNode("http", "127.0.0.1:7070", nil),
+	})
+
+	ctx := WithPinnedNode(context.Background(), "127.0.0.1:9090")
+	for i := 0; i < 10; i++ {
+		n, _, err := selector.Select(ctx)
+		if err != nil {
+			t.Fatalf("expect %v, got %v", nil, err)
+		}
+		if n.Address() != "127.0.0.1:9090" {
+			t.Errorf("expect %v, got %v", "127.0.0.1:9090", n.Address())
+		}
+	}
+	_, _, err := selector.Select(WithPinnedNode(context.Background(), "127.0.0.1:6060"))
+	if !errors.Is(err, ErrNoAvailable) {
+		t.Errorf("expect %v, got %v", ErrNoAvailable, err)
+	}
+
+	ctx = WithExcludedNodes(context.Background(), "127.0.0.1:8080", "127.0.0.1:9090")
+	for i := 0; i < 10; i++ {
+		n, _, err := selector.Select(ctx)
+		if err != nil {
+			t.Fatalf("expect %v, got %v", nil, err)
+		}
+		if n.Address() != "127.0.0.1:7070" {
+			t.Errorf("expect %v, got %v", "127.0.0.1:7070", n.Address())
+		}
+	}
+
+	// all nodes are excluded
+	ctx = WithExcludedNodes(ctx, "127.0.0.1:7070")
+	if _, _, err = selector.Select(ctx); err != nil {
+		t.Errorf("expect %v, got %v", nil, err)
+	}
+}
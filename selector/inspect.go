@@ -0,0 +1,81 @@
+package selector
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Inspector is implemented by the selector which exports its runtime state for debugging.
+type Inspector interface {
+	Inspect() Snapshot
+}
+
+// Snapshot is the serializable runtime state of a selector.
+type Snapshot struct {
+	Nodes []NodeSnapshot `json:"nodes"`
+}
+
+// NodeSnapshot is the serializable runtime state of a weighted node.
+type NodeSnapshot struct {
+	Address     string            `json:"address"`
+	Scheme      string            `json:"scheme"`
+	ServiceName string            `json:"service_name"`
+	Version     string            `json:"version"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Weight      float64           `json:"weight"`
+	// Available is false if the node is marked unhealthy or ejected as an outlier.
+	Available   bool          `json:"available"`
+	PickElapsed time.Duration `json:"pick_elapsed"`
+	// The statistics are present if the node implements StatsReporter.
+	Lag         time.Duration `json:"lag,omitempty"`
+	SuccessRate float64       `json:"success_rate,omitempty"`
+	Inflight    int64         `json:"inflight,omitempty"`
+}
+
+// Inspect returns a snapshot of the nodes and their runtime statistics.
+func (d *Default) Inspect() Snapshot {
+	nodes := d.loadNodes()
+	available := d.health.filter(nodes)
+	if d.outlier != nil {
+		available = d.outlier.filter(available)
+	}
+	availableSet := make(map[string]struct{}, len(available))
+	for _, wn := range available {
+		availableSet[wn.Address()] = struct{}{}
+	}
+	snapshot := Snapshot{Nodes: make([]NodeSnapshot, 0, len(nodes))}
+	for _, wn := range nodes {
+		_, ok := availableSet[wn.Address()]
+		ns := NodeSnapshot{
+			Address:     wn.Address(),
+			Scheme:      wn.Scheme(),
+			ServiceName: wn.ServiceName(),
+			Version:     wn.Version(),
+			Metadata:    wn.Metadata(),
+			Weight:      wn.Weight(),
+			Available:   ok,
+			PickElapsed: wn.PickElapsed(),
+		}
+		if s, ok := wn.(StatsReporter); ok {
+			ns.Lag = s.Lag()
+			ns.SuccessRate = s.SuccessRate()
+			ns.Inflight = s.Inflight()
+		}
+		snapshot.Nodes = append(snapshot.Nodes, ns)
+	}
+	return snapshot
+}
+
+// InspectHandler returns an HTTP handler serving the snapshot of the selector as JSON,
+// it can be registered as a debug route, e.g. srv.Handle("/debug/selector", InspectHandler(client)).
+func InspectHandler(i Inspector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(i.Inspect()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package selector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	builder := DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+	}
+	selector := builder.Build().(*Default)
+	selector.Apply([]Node{NewNode("http", "127.0.0.1:8080", nil), NewNode("http", "127.0.0.1:9090", nil)})
+	selector.MarkUnhealthy("127.0.0.1:9090", 0)
+	if _, _, err := selector.Select(context.Background()); err != nil {
+		t.Fatalf("expect %v, got %v", nil, err)
+	}
+	snapshot := selector.Inspect()
+	if !reflect.DeepEqual(2, len(snapshot.Nodes)) {
+		t.Fatalf("expect %v, got %v", 2, len(snapshot.Nodes))
+	}
+	if !snapshot.Nodes[0].Available || snapshot.Nodes[1].Available {
+		t.Errorf("expect only the first node to be available, got %+v", snapshot.Nodes)
+	}
+	if !reflect.DeepEqual(float64(100), snapshot.Nodes[0].Weight) {
+		t.Errorf("expect %v, got %v", 100, snapshot.Nodes[0].Weight)
+	}
+
+	rec := httptest.NewRecorder()
+	InspectHandler(selector).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/selector", nil))
+	var got Snapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("expect %v, got %v", nil, err)
+	}
+	if !reflect.DeepEqual("127.0.0.1:9090", got.Nodes[1].Address) {
+		t.Errorf("expect %v, got %v", "127.0.0.1:9090", got.Nodes[1].Address)
+	}
+}
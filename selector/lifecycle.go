@@ -0,0 +1,61 @@
+package selector
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Finalizer is implemented by the WeightedNode which holds resources.
+// Finalize is called once the node is removed by Apply or SetBalancer
+// and has no inflight request.
+type Finalizer interface {
+	Finalize()
+}
+
+// inflightReporter is implemented by the WeightedNode which counts its inflight requests.
+type inflightReporter interface {
+	Inflight() int64
+}
+
+// retired holds the removed nodes until their inflight requests are done.
+type retired struct {
+	mu    sync.Mutex
+	count int32
+	nodes []WeightedNode
+}
+
+// retire tracks the removed nodes which need to be finalized.
+func (r *retired) retire(nodes []WeightedNode) {
+	r.mu.Lock()
+	for _, wn := range nodes {
+		if _, ok := wn.(Finalizer); ok {
+			r.nodes = append(r.nodes, wn)
+		}
+	}
+	atomic.StoreInt32(&r.count, int32(len(r.nodes)))
+	r.mu.Unlock()
+	r.sweep()
+}
+
+// sweep finalizes the removed nodes without inflight requests.
+func (r *retired) sweep() {
+	if atomic.LoadInt32(&r.count) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	nodes := r.nodes[:0]
+	for _, wn := range r.nodes {
+		if ir, ok := wn.(inflightReporter); ok && ir.Inflight() > 0 {
+			nodes = append(nodes, wn)
+			continue
+		}
+		wn.(Finalizer).Finalize()
+	}
+	// 清空尾部引用，避免已终结的节点无法被回收
+	for i := len(nodes); i < len(r.nodes); i++ {
+		r.nodes[i] = nil
+	}
+	r.nodes = nodes
+	atomic.StoreInt32(&r.count, int32(len(nodes)))
+}
@@ -0,0 +1,66 @@
+package selector
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+type mockFinalizerNode struct {
+	mockWeightedNode
+
+	inflight  int64
+	finalized int32
+}
+
+func (n *mockFinalizerNode) Pick() DoneFunc {
+	atomic.AddInt64(&n.inflight, 1)
+	return func(ctx context.Context, di DoneInfo) {
+		atomic.AddInt64(&n.inflight, -1)
+	}
+}
+
+func (n *mockFinalizerNode) Inflight() int64 {
+	return atomic.LoadInt64(&n.inflight)
+}
+
+func (n *mockFinalizerNode) Finalize() {
+	atomic.AddInt32(&n.finalized, 1)
+}
+
+type mockFinalizerNodeBuilder struct{}
+
+func (b *mockFinalizerNodeBuilder) Build(n Node) WeightedNode {
+	return &mockFinalizerNode{mockWeightedNode: mockWeightedNode{Node: n}}
+}
+
+func TestRetiredNodes(t *testing.T) {
+	builder := DefaultBuilder{
+		Node:     &mockFinalizerNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+	}
+	selector := builder.Build().(*Default)
+	selector.Apply([]Node{NewNode("http", "127.0.0.1:8080", nil), NewNode("http", "127.0.0.1:9090", nil)})
+	nodes := selector.Nodes()
+	busy, idle := nodes[0].(*mockFinalizerNode), nodes[1].(*mockFinalizerNode)
+	done := busy.Pick()
+
+	selector.Apply([]Node{NewNode("http", "127.0.0.1:7070", nil)})
+	if atomic.LoadInt32(&idle.finalized) != 1 {
+		t.Errorf("expect the idle node to be finalized, got %v", idle.finalized)
+	}
+	if atomic.LoadInt32(&busy.finalized) != 0 {
+		t.Errorf("expect the busy node not to be finalized, got %v", busy.finalized)
+	}
+
+	done(context.Background(), DoneInfo{})
+	if _, _, err := selector.Select(context.Background()); err != nil {
+		t.Errorf("expect %v, got %v", nil, err)
+	}
+	if atomic.LoadInt32(&busy.finalized) != 1 {
+		t.Errorf("expect the busy node to be finalized, got %v", busy.finalized)
+	}
+	if len(selector.retired.nodes) != 0 {
+		t.Errorf("expect no retired nodes, got %v", len(selector.retired.nodes))
+	}
+}
@@ -0,0 +1,160 @@
+package selector
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// LoadReportKey is the reply metadata key of the backend load report,
+// the value is in ORCA text format, e.g. "TEXT cpu_utilization=0.3, queue_utilization=0.1".
+const LoadReportKey = "endpoint-load-metrics"
+
+// LoadReportBinaryKey is the gRPC trailer key of the backend load report, the value is
+// the serialized ORCA proto xds.data.orca.v3.OrcaLoadReport.
+const LoadReportBinaryKey = "endpoint-load-metrics-bin"
+
+// ORCA OrcaLoadReport的字段编号
+const (
+	orcaCPUUtilization protowire.Number = 1
+	orcaUtilization    protowire.Number = 5
+)
+
+// LoadReport is the load reported by the backend, e.g. ORCA metrics.
+type LoadReport struct {
+	// CPUUtilization is the cpu utilization in [0, 1].
+	CPUUtilization float64
+	// QueueUtilization is the request queue utilization in [0, 1].
+	QueueUtilization float64
+}
+
+// Utilization returns the max utilization of the report.
+func (r *LoadReport) Utilization() float64 {
+	if r.QueueUtilization > r.CPUUtilization {
+		return r.QueueUtilization
+	}
+	return r.CPUUtilization
+}
+
+// LoadFromDoneInfo returns the load report of the done info,
+// it is parsed from the reply metadata if not set, in text or binary format.
+func LoadFromDoneInfo(di DoneInfo) (*LoadReport, bool) {
+	if di.Load != nil {
+		return di.Load, true
+	}
+	if di.ReplyMD == nil {
+		return nil, false
+	}
+	if r, ok := ParseLoadReport(di.ReplyMD.Get(LoadReportKey)); ok {
+		return r, true
+	}
+	return ParseLoadReportBinary([]byte(di.ReplyMD.Get(LoadReportBinaryKey)))
+}
+
+// ParseLoadReportBinary parses the load report of the serialized ORCA proto, the queue
+// utilization is the "queue" entry of its named utilization.
+func ParseLoadReportBinary(b []byte) (*LoadReport, bool) {
+	var (
+		report LoadReport
+		found  bool
+	)
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, false
+		}
+		b = b[n:]
+		switch {
+		case num == orcaCPUUtilization && typ == protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(b)
+			if n < 0 {
+				return nil, false
+			}
+			report.CPUUtilization = math.Float64frombits(v)
+			found = true
+			b = b[n:]
+		case num == orcaUtilization && typ == protowire.BytesType:
+			entry, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return nil, false
+			}
+			if k, v, ok := parseUtilizationEntry(entry); ok && k == "queue" {
+				report.QueueUtilization = v
+				found = true
+			}
+			b = b[n:]
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return nil, false
+			}
+			b = b[n:]
+		}
+	}
+	if !found || report.CPUUtilization < 0 || report.QueueUtilization < 0 {
+		return nil, false
+	}
+	return &report, true
+}
+
+// parseUtilizationEntry parses the map<string, double> entry of the named utilization.
+func parseUtilizationEntry(b []byte) (key string, value float64, ok bool) {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return "", 0, false
+		}
+		b = b[n:]
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			key, n = protowire.ConsumeString(b)
+		case num == 2 && typ == protowire.Fixed64Type:
+			var v uint64
+			v, n = protowire.ConsumeFixed64(b)
+			value = math.Float64frombits(v)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return "", 0, false
+		}
+		b = b[n:]
+	}
+	return key, value, true
+}
+
+// ParseLoadReport parses the load report in ORCA text format.
+func ParseLoadReport(text string) (*LoadReport, bool) {
+	text = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), "TEXT"))
+	if text == "" {
+		return nil, false
+	}
+	var (
+		report LoadReport
+		found  bool
+	)
+	for _, kv := range strings.Split(text, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(kv), "=")
+		if !ok {
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 {
+			continue
+		}
+		switch k {
+		case "cpu_utilization":
+			report.CPUUtilization = f
+			found = true
+		case "queue_utilization":
+			report.QueueUtilization = f
+			found = true
+		}
+	}
+	if !found {
+		return nil, false
+	}
+	return &report, true
+}
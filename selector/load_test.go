@@ -0,0 +1,78 @@
+package selector
+
+import (
+	"math"
+	"net/http"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestParseLoadReport(t *testing.T) {
+	r, ok := ParseLoadReport("TEXT cpu_utilization=0.3, queue_utilization=0.5, rps_fractional=10")
+	if !ok {
+		t.Fatalf("expect %v, got %v", true, ok)
+	}
+	if r.CPUUtilization != 0.3 || r.QueueUtilization != 0.5 {
+		t.Errorf("expect %v and %v, got %v and %v", 0.3, 0.5, r.CPUUtilization, r.QueueUtilization)
+	}
+	if r.Utilization() != 0.5 {
+		t.Errorf("expect %v, got %v", 0.5, r.Utilization())
+	}
+	if _, ok = ParseLoadReport(""); ok {
+		t.Errorf("expect %v, got %v", false, ok)
+	}
+	if _, ok = ParseLoadReport("TEXT rps_fractional=10"); ok {
+		t.Errorf("expect %v, got %v", false, ok)
+	}
+}
+
+func TestLoadFromDoneInfo(t *testing.T) {
+	if _, ok := LoadFromDoneInfo(DoneInfo{}); ok {
+		t.Errorf("expect %v, got %v", false, ok)
+	}
+	r, ok := LoadFromDoneInfo(DoneInfo{Load: &LoadReport{CPUUtilization: 0.1}})
+	if !ok || r.CPUUtilization != 0.1 {
+		t.Errorf("expect %v, got %v", 0.1, r)
+	}
+	header := http.Header{}
+	header.Set(LoadReportKey, "cpu_utilization=0.2")
+	r, ok = LoadFromDoneInfo(DoneInfo{ReplyMD: header})
+	if !ok || r.CPUUtilization != 0.2 {
+		t.Errorf("expect %v, got %v", 0.2, r)
+	}
+}
+
+func TestParseLoadReportBinary(t *testing.T) {
+	// OrcaLoadReport{cpu_utilization: 0.3, rps_fractional: 10, utilization: {"queue": 0.5}}
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, math.Float64bits(0.3))
+	b = protowire.AppendTag(b, 6, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, math.Float64bits(10))
+	var entry []byte
+	entry = protowire.AppendTag(entry, 1, protowire.BytesType)
+	entry = protowire.AppendString(entry, "queue")
+	entry = protowire.AppendTag(entry, 2, protowire.Fixed64Type)
+	entry = protowire.AppendFixed64(entry, math.Float64bits(0.5))
+	b = protowire.AppendTag(b, 5, protowire.BytesType)
+	b = protowire.AppendBytes(b, entry)
+
+	r, ok := ParseLoadReportBinary(b)
+	if !ok || r.CPUUtilization != 0.3 || r.QueueUtilization != 0.5 {
+		t.Errorf("expect %v and %v, got %v", 0.3, 0.5, r)
+	}
+	if _, ok = ParseLoadReportBinary(b[:len(b)-1]); ok {
+		t.Errorf("expect the truncated report invalid")
+	}
+	if _, ok = ParseLoadReportBinary(nil); ok {
+		t.Errorf("expect %v, got %v", false, ok)
+	}
+
+	header := http.Header{}
+	header.Set(LoadReportBinaryKey, string(b))
+	r, ok = LoadFromDoneInfo(DoneInfo{ReplyMD: header})
+	if !ok || r.CPUUtilization != 0.3 {
+		t.Errorf("expect %v, got %v", 0.3, r)
+	}
+}
@@ -0,0 +1,93 @@
+package selector
+
+import (
+	"context"
+	"time"
+)
+
+type mirrorKey struct{}
+
+// MirrorConfig is traffic mirroring config, a fraction of the requests is
+// teed to a shadow node, e.g. a new version for dark launch.
+type MirrorConfig struct {
+	// Filters select the shadow nodes, e.g. filter.Version("v2.0.0").
+	Filters []NodeFilter
+	// Percent is the percent of the requests mirrored in [0, 100].
+	Percent float64
+	// Rand is the source of randomness, default is seeded with the current time.
+	Rand Rand
+}
+
+// Mirror is the shadow node selected for traffic mirroring. The shadow
+// requests are excluded from the node statistics, so there is no done callback.
+type Mirror struct {
+	// Node is the shadow node, nil if the request is not mirrored.
+	Node Node
+}
+
+// NewMirrorContext creates a new context with the mirror attached,
+// the selector sets the shadow node to it on Select.
+func NewMirrorContext(ctx context.Context, m *Mirror) context.Context {
+	return context.WithValue(ctx, mirrorKey{}, m)
+}
+
+// FromMirrorContext returns the mirror in ctx if it exists.
+func FromMirrorContext(ctx context.Context) (m *Mirror, ok bool) {
+	m, ok = ctx.Value(mirrorKey{}).(*Mirror)
+	return
+}
+
+// MirrorBuilder returns the builder with the traffic mirroring of the config enabled,
+// the builders other than *DefaultBuilder, e.g. the custom ones, are returned as they are.
+func MirrorBuilder(b Builder, c *MirrorConfig) Builder {
+	if c == nil {
+		return b
+	}
+	if w, ok := b.(*wrapSelector); ok {
+		// GlobalSelector返回的是包装后的构建器
+		b = w.Builder
+	}
+	db, ok := b.(*DefaultBuilder)
+	if !ok {
+		return b
+	}
+	cp := *db
+	cp.Mirror = c
+	return &cp
+}
+
+// mirror selects the shadow nodes.
+type mirror struct {
+	filters []NodeFilter
+	percent float64
+	r       Rand
+}
+
+func newMirror(c *MirrorConfig) *mirror {
+	m := &mirror{filters: c.Filters, percent: c.Percent, r: c.Rand}
+	if m.r == nil {
+		m.r = NewRand(time.Now().UnixNano())
+	}
+	return m
+}
+
+// pick picks a shadow node other than the primary one, it returns nil
+// if the request is not mirrored or there is no shadow node.
+func (m *mirror) pick(ctx context.Context, nodes []WeightedNode, primary string) Node {
+	if m.percent <= 0 || m.r.Float64()*100 >= m.percent {
+		return nil
+	}
+	shadows := make([]Node, 0, len(nodes))
+	for _, n := range nodes {
+		if n.Address() != primary {
+			shadows = append(shadows, n)
+		}
+	}
+	for _, filter := range m.filters {
+		shadows = filter(ctx, shadows)
+	}
+	if len(shadows) == 0 {
+		return nil
+	}
+	return shadows[m.r.Intn(len(shadows))].(WeightedNode).Raw()
+}
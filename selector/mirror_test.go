@@ -0,0 +1,69 @@
+package selector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+func TestMirror(t *testing.T) {
+	builder := DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+		Mirror: &MirrorConfig{
+			Filters: []NodeFilter{mockFilter("v2.0.0")},
+			Percent: 50,
+			Rand:    &SequenceRand{Floats: []float64{0.2, 0.8}},
+		},
+	}
+	selector := builder.Build()
+	selector.Apply([]Node{
+		NewNode("http", "127.0.0.1:8080", &registry.ServiceInstance{Version: "v1.0.0"}),
+		NewNode("http", "127.0.0.1:9090", &registry.ServiceInstance{Version: "v2.0.0"}),
+	})
+	var m Mirror
+	ctx := NewMirrorContext(context.Background(), &m)
+	n, _, err := selector.Select(ctx, WithNodeFilter(mockFilter("v1.0.0")))
+	if err != nil {
+		t.Fatalf("expect %v, got %v", nil, err)
+	}
+	if n.Address() != "127.0.0.1:8080" {
+		t.Errorf("expect %v, got %v", "127.0.0.1:8080", n.Address())
+	}
+	if m.Node == nil || m.Node.Address() != "127.0.0.1:9090" {
+		t.Errorf("expect the shadow node %v, got %v", "127.0.0.1:9090", m.Node)
+	}
+	// not mirrored
+	if _, _, err = selector.Select(ctx, WithNodeFilter(mockFilter("v1.0.0"))); err != nil {
+		t.Fatalf("expect %v, got %v", nil, err)
+	}
+	if m.Node != nil {
+		t.Errorf("expect no shadow node, got %v", m.Node.Address())
+	}
+	// the primary node is never the shadow node
+	if _, _, err = selector.Select(ctx, WithNodeFilter(mockFilter("v2.0.0"))); err != nil {
+		t.Fatalf("expect %v, got %v", nil, err)
+	}
+	if m.Node != nil {
+		t.Errorf("expect no shadow node, got %v", m.Node.Address())
+	}
+}
+
+func TestMirrorBuilder(t *testing.T) {
+	c := &MirrorConfig{Percent: 100}
+	db := &DefaultBuilder{Node: &mockWeightedNodeBuilder{}, Balancer: &mockBalancerBuilder{}}
+	b, ok := MirrorBuilder(db, c).(*DefaultBuilder)
+	if !ok || b.Mirror != c {
+		t.Fatalf("expect the mirror config to be set")
+	}
+	if db.Mirror != nil {
+		t.Errorf("expect the builder not to be modified")
+	}
+	if MirrorBuilder(db, nil) != Builder(db) {
+		t.Errorf("expect the builder to be returned without the config")
+	}
+	if b, ok := MirrorBuilder(&wrapSelector{db}, c).(*DefaultBuilder); !ok || b.Mirror != c {
+		t.Errorf("expect the wrapped builder to be unwrapped")
+	}
+}
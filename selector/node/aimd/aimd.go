@@ -0,0 +1,167 @@
+package aimd
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+const (
+	defaultInitialLimit = 20
+	defaultMinLimit     = 1
+	defaultMaxLimit     = 1000
+	defaultBackoff      = 0.9
+	defaultTolerance    = 2.0
+	// the weight ratio of the node above its limit
+	overloadRatio = 0.01
+)
+
+// ErrLimitExceeded is returned by Allow when the inflight requests of the node exceed its limit.
+var ErrLimitExceeded = errors.New("aimd: concurrency limit exceeded")
+
+var (
+	_ selector.WeightedNode        = (*Node)(nil)
+	_ selector.WeightedNodeBuilder = (*Builder)(nil)
+	_ selector.Finalizer           = (*Node)(nil)
+)
+
+// Builder is adaptive concurrency limit node builder, it wraps the nodes built by the inner builder.
+// The limit of each node increases additively on fast successes, and decreases multiplicatively
+// on failures or when the latency rises above the tolerance of the minimum latency (AIMD).
+type Builder struct {
+	// Builder is the inner weighted node builder.
+	Builder selector.WeightedNodeBuilder
+	// InitialLimit, MinLimit and MaxLimit are the bounds of the concurrency limit, default 20, 1 and 1000.
+	InitialLimit int
+	MinLimit     int
+	MaxLimit     int
+	// Backoff is the ratio to decrease the limit, default 0.9.
+	Backoff float64
+	// Tolerance is the ratio of the latency to the minimum latency considered as congestion, default 2.
+	Tolerance float64
+}
+
+// Build create a weighted node with adaptive concurrency limit.
+func (b *Builder) Build(n selector.Node) selector.WeightedNode {
+	node := &Node{
+		WeightedNode: b.Builder.Build(n),
+		limit:        defaultInitialLimit,
+		minLimit:     defaultMinLimit,
+		maxLimit:     defaultMaxLimit,
+		backoff:      defaultBackoff,
+		tolerance:    defaultTolerance,
+	}
+	if b.InitialLimit > 0 {
+		node.limit = float64(b.InitialLimit)
+	}
+	if b.MinLimit > 0 {
+		node.minLimit = float64(b.MinLimit)
+	}
+	if b.MaxLimit > 0 {
+		node.maxLimit = float64(b.MaxLimit)
+	}
+	if b.Backoff > 0 && b.Backoff < 1 {
+		node.backoff = b.Backoff
+	}
+	if b.Tolerance > 1 {
+		node.tolerance = b.Tolerance
+	}
+	return node
+}
+
+// Node is weighted node with adaptive concurrency limit.
+type Node struct {
+	selector.WeightedNode
+
+	inflight int64
+
+	mu        sync.Mutex
+	limit     float64
+	minRTT    time.Duration
+	minLimit  float64
+	maxLimit  float64
+	backoff   float64
+	tolerance float64
+}
+
+// Limit returns the current concurrency limit of the node.
+func (n *Node) Limit() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return int(n.limit)
+}
+
+// Allow reports whether the inflight requests of the node are under its limit.
+func (n *Node) Allow() error {
+	if atomic.LoadInt64(&n.inflight) >= int64(n.Limit()) {
+		return ErrLimitExceeded
+	}
+	return nil
+}
+
+// Weight is the inner weight, it is deprioritized when the node is above its limit.
+func (n *Node) Weight() float64 {
+	if n.Allow() != nil {
+		return n.WeightedNode.Weight() * overloadRatio
+	}
+	return n.WeightedNode.Weight()
+}
+
+// Pick pick the node, and adjusts the limit with the result.
+func (n *Node) Pick() selector.DoneFunc {
+	start := time.Now()
+	atomic.AddInt64(&n.inflight, 1)
+	done := n.WeightedNode.Pick()
+	return func(ctx context.Context, di selector.DoneInfo) {
+		atomic.AddInt64(&n.inflight, -1)
+		latency := di.Latency
+		if latency <= 0 {
+			latency = time.Since(start)
+		}
+		n.update(latency, di.Err)
+		done(ctx, di)
+	}
+}
+
+func (n *Node) update(latency time.Duration, err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.minRTT == 0 || latency < n.minRTT {
+		n.minRTT = latency
+	} else {
+		// 缓慢上调最小延迟，适应后端的正常变化
+		n.minRTT += (latency - n.minRTT) / 100
+	}
+	switch selector.ErrorClass(err) {
+	case selector.ErrClassServer, selector.ErrClassTimeout, selector.ErrClassNetwork:
+		n.limit *= n.backoff
+	default:
+		if float64(latency) > float64(n.minRTT)*n.tolerance {
+			n.limit *= n.backoff
+		} else {
+			n.limit += 1 / n.limit
+		}
+	}
+	if n.limit < n.minLimit {
+		n.limit = n.minLimit
+	}
+	if n.limit > n.maxLimit {
+		n.limit = n.maxLimit
+	}
+}
+
+// Finalize finalizes the inner node if it is a selector.Finalizer.
+func (n *Node) Finalize() {
+	if f, ok := n.WeightedNode.(selector.Finalizer); ok {
+		f.Finalize()
+	}
+}
+
+// Inflight is the number of inflight requests to the node.
+func (n *Node) Inflight() int64 {
+	return atomic.LoadInt64(&n.inflight)
+}
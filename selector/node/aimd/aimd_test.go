@@ -0,0 +1,72 @@
+package aimd
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/selector/node/direct"
+)
+
+func TestAIMD(t *testing.T) {
+	b := &Builder{
+		Builder:      &direct.Builder{},
+		InitialLimit: 2,
+		MaxLimit:     3,
+	}
+	wn := b.Build(selector.NewNode(
+		"http",
+		"127.0.0.1:9090",
+		&registry.ServiceInstance{
+			ID:        "127.0.0.1:9090",
+			Name:      "helloworld",
+			Version:   "v1.0.0",
+			Endpoints: []string{"http://127.0.0.1:9090"},
+			Metadata:  map[string]string{"weight": "10"},
+		}))
+	n := wn.(*Node)
+	if !reflect.DeepEqual(2, n.Limit()) {
+		t.Errorf("expect %v, got %v", 2, n.Limit())
+	}
+	done1 := wn.Pick()
+	done2 := wn.Pick()
+	if !errors.Is(n.Allow(), ErrLimitExceeded) {
+		t.Errorf("expect %v, got %v", ErrLimitExceeded, n.Allow())
+	}
+	if !reflect.DeepEqual(float64(10)*overloadRatio, wn.Weight()) {
+		t.Errorf("expect %v, got %v", float64(10)*overloadRatio, wn.Weight())
+	}
+	done1(context.Background(), selector.DoneInfo{Latency: time.Millisecond})
+	done2(context.Background(), selector.DoneInfo{Latency: time.Millisecond})
+	if n.Allow() != nil {
+		t.Errorf("expect %v, got %v", nil, n.Allow())
+	}
+	if !reflect.DeepEqual(float64(10), wn.Weight()) {
+		t.Errorf("expect %v, got %v", float64(10), wn.Weight())
+	}
+
+	// additive increase up to the max limit
+	for i := 0; i < 10; i++ {
+		wn.Pick()(context.Background(), selector.DoneInfo{Latency: time.Millisecond})
+	}
+	if !reflect.DeepEqual(3, n.Limit()) {
+		t.Errorf("expect %v, got %v", 3, n.Limit())
+	}
+
+	// multiplicative decrease on latency rising and failures
+	wn.Pick()(context.Background(), selector.DoneInfo{Latency: time.Millisecond * 10})
+	wn.Pick()(context.Background(), selector.DoneInfo{Latency: time.Millisecond, Err: errors.ServiceUnavailable("", "")})
+	if !reflect.DeepEqual(2, n.Limit()) {
+		t.Errorf("expect %v, got %v", 2, n.Limit())
+	}
+	for i := 0; i < 50; i++ {
+		wn.Pick()(context.Background(), selector.DoneInfo{Err: errors.ServiceUnavailable("", "")})
+	}
+	if !reflect.DeepEqual(defaultMinLimit, n.Limit()) {
+		t.Errorf("expect %v, got %v", defaultMinLimit, n.Limit())
+	}
+}
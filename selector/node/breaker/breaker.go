@@ -0,0 +1,76 @@
+package breaker
+
+import (
+	"context"
+
+	"github.com/go-kratos/aegis/circuitbreaker"
+	"github.com/go-kratos/aegis/circuitbreaker/sre"
+
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+var (
+	_ selector.WeightedNode        = (*Node)(nil)
+	_ selector.WeightedNodeBuilder = (*Builder)(nil)
+	_ selector.Finalizer           = (*Node)(nil)
+)
+
+// Node is weighted node with a circuit breaker.
+type Node struct {
+	selector.WeightedNode
+
+	breaker circuitbreaker.CircuitBreaker
+}
+
+// Builder is circuit breaker node builder, it wraps the nodes built by the inner builder.
+type Builder struct {
+	// Builder is the inner weighted node builder.
+	Builder selector.WeightedNodeBuilder
+	// Breaker creates the circuit breaker of a node, default is the sre breaker.
+	Breaker func() circuitbreaker.CircuitBreaker
+}
+
+// Build create a weighted node with circuit breaker.
+func (b *Builder) Build(n selector.Node) selector.WeightedNode {
+	var breaker circuitbreaker.CircuitBreaker
+	if b.Breaker != nil {
+		breaker = b.Breaker()
+	} else {
+		breaker = sre.NewBreaker()
+	}
+	return &Node{WeightedNode: b.Builder.Build(n), breaker: breaker}
+}
+
+// Allow reports whether the circuit of the node allows requests.
+func (n *Node) Allow() error {
+	return n.breaker.Allow()
+}
+
+// Pick pick the node, and feeds the circuit breaker with the result.
+func (n *Node) Pick() selector.DoneFunc {
+	done := n.WeightedNode.Pick()
+	return func(ctx context.Context, di selector.DoneInfo) {
+		switch selector.ErrorClass(di.Err) {
+		case selector.ErrClassServer, selector.ErrClassTimeout, selector.ErrClassNetwork:
+			n.breaker.MarkFailed()
+		default:
+			n.breaker.MarkSuccess()
+		}
+		done(ctx, di)
+	}
+}
+
+// Finalize finalizes the inner node if it is a selector.Finalizer.
+func (n *Node) Finalize() {
+	if f, ok := n.WeightedNode.(selector.Finalizer); ok {
+		f.Finalize()
+	}
+}
+
+// Inflight is the number of inflight requests reported by the inner node.
+func (n *Node) Inflight() int64 {
+	if s, ok := n.WeightedNode.(selector.StatsReporter); ok {
+		return s.Inflight()
+	}
+	return 0
+}
@@ -0,0 +1,78 @@
+package breaker
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/aegis/circuitbreaker"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/selector/node/direct"
+)
+
+type mockBreaker struct {
+	success int
+	failed  int
+}
+
+func (b *mockBreaker) Allow() error {
+	if b.failed > 0 {
+		return circuitbreaker.ErrNotAllowed
+	}
+	return nil
+}
+
+func (b *mockBreaker) MarkSuccess() {
+	b.success++
+}
+
+func (b *mockBreaker) MarkFailed() {
+	b.failed++
+}
+
+func TestBreaker(t *testing.T) {
+	cb := &mockBreaker{}
+	b := &Builder{
+		Builder: &direct.Builder{},
+		Breaker: func() circuitbreaker.CircuitBreaker { return cb },
+	}
+	wn := b.Build(selector.NewNode(
+		"http",
+		"127.0.0.1:9090",
+		&registry.ServiceInstance{
+			ID:        "127.0.0.1:9090",
+			Name:      "helloworld",
+			Version:   "v1.0.0",
+			Endpoints: []string{"http://127.0.0.1:9090"},
+			Metadata:  map[string]string{"weight": "10"},
+		}))
+	if !reflect.DeepEqual(float64(10), wn.Weight()) {
+		t.Errorf("expect %v, got %v", float64(10), wn.Weight())
+	}
+	if !reflect.DeepEqual("127.0.0.1:9090", wn.Raw().Address()) {
+		t.Errorf("expect %v, got %v", "127.0.0.1:9090", wn.Raw().Address())
+	}
+	n := wn.(*Node)
+	wn.Pick()(context.Background(), selector.DoneInfo{Err: errors.BadRequest("", "")})
+	if n.Allow() != nil {
+		t.Errorf("expect %v, got %v", nil, n.Allow())
+	}
+	wn.Pick()(context.Background(), selector.DoneInfo{Err: errors.ServiceUnavailable("", "")})
+	if n.Allow() == nil {
+		t.Errorf("expect %v, got %v", circuitbreaker.ErrNotAllowed, n.Allow())
+	}
+	if cb.success != 1 || cb.failed != 1 {
+		t.Errorf("expect %v and %v, got %v and %v", 1, 1, cb.success, cb.failed)
+	}
+}
+
+func TestDefaultBreaker(t *testing.T) {
+	b := &Builder{Builder: &direct.Builder{}}
+	wn := b.Build(selector.NewNode("http", "127.0.0.1:9090", nil))
+	if err := wn.(*Node).Allow(); err != nil {
+		t.Errorf("expect %v, got %v", nil, err)
+	}
+}
@@ -0,0 +1,145 @@
+package digest
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+const (
+	quantile    = 0.99
+	window      = time.Second * 30
+	compression = 100
+	// the min number of samples to estimate the quantile
+	minSamples = 10
+	// the latency of the node without enough samples, to probe it
+	probeLatency = time.Millisecond
+)
+
+var (
+	_ selector.WeightedNode        = (*Node)(nil)
+	_ selector.WeightedNodeBuilder = (*Builder)(nil)
+)
+
+// Node is endpoint instance tracking its latency distribution with a t-digest.
+type Node struct {
+	selector.Node
+
+	lastPick int64
+	inflight int64
+
+	mu       sync.Mutex
+	cur      *tdigest
+	prev     *tdigest
+	rotated  time.Time
+	quantile float64
+	window   time.Duration
+	comp     float64
+}
+
+// Builder is digest node builder.
+type Builder struct {
+	// Quantile is the latency quantile of the node, default 0.99.
+	Quantile float64
+	// Window is the interval to rotate the digest so that the old samples expire, default 30s.
+	Window time.Duration
+	// Compression is the compression of the t-digest, higher is more accurate, default 100.
+	Compression float64
+}
+
+// Build create a weighted node.
+func (b *Builder) Build(n selector.Node) selector.WeightedNode {
+	node := &Node{
+		Node:     n,
+		quantile: quantile,
+		window:   window,
+		comp:     compression,
+		rotated:  time.Now(),
+	}
+	if b.Quantile > 0 && b.Quantile < 1 {
+		node.quantile = b.Quantile
+	}
+	if b.Window > 0 {
+		node.window = b.Window
+	}
+	if b.Compression > 0 {
+		node.comp = b.Compression
+	}
+	node.cur = newTDigest(node.comp)
+	node.prev = newTDigest(node.comp)
+	return node
+}
+
+// Pick pick the node, and records the latency of the request.
+func (n *Node) Pick() selector.DoneFunc {
+	start := time.Now()
+	atomic.StoreInt64(&n.lastPick, start.UnixNano())
+	atomic.AddInt64(&n.inflight, 1)
+	return func(ctx context.Context, di selector.DoneInfo) {
+		atomic.AddInt64(&n.inflight, -1)
+		latency := di.Latency
+		if latency <= 0 {
+			latency = time.Since(start)
+		}
+		n.mu.Lock()
+		n.rotate(time.Now())
+		n.cur.add(float64(latency))
+		n.mu.Unlock()
+	}
+}
+
+// rotate expires the samples older than two windows.
+func (n *Node) rotate(now time.Time) {
+	elapsed := now.Sub(n.rotated)
+	if elapsed < n.window {
+		return
+	}
+	n.prev, n.cur = n.cur, newTDigest(n.comp)
+	if elapsed >= n.window*2 {
+		n.prev = newTDigest(n.comp)
+	}
+	n.rotated = now
+}
+
+// Quantile returns the latency at the configured quantile,
+// it returns false if the node has not enough samples.
+func (n *Node) Quantile() (time.Duration, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.rotate(time.Now())
+	// 当前窗口样本不足时，使用上一个窗口
+	d := n.cur
+	if d.count < minSamples {
+		d = n.prev
+	}
+	if d.count < minSamples {
+		return 0, false
+	}
+	return time.Duration(d.quantile(n.quantile)), true
+}
+
+// Weight is the reciprocal of the latency quantile in seconds,
+// the node without enough samples weighs as a 1ms latency so that it is probed.
+func (n *Node) Weight() float64 {
+	latency, ok := n.Quantile()
+	if !ok || latency < probeLatency {
+		latency = probeLatency
+	}
+	return float64(time.Second) / float64(latency)
+}
+
+// Inflight is the number of inflight requests to the node.
+func (n *Node) Inflight() int64 {
+	return atomic.LoadInt64(&n.inflight)
+}
+
+func (n *Node) PickElapsed() time.Duration {
+	return time.Duration(time.Now().UnixNano() - atomic.LoadInt64(&n.lastPick))
+}
+
+func (n *Node) Raw() selector.Node {
+	return n.Node
+}
@@ -0,0 +1,62 @@
+package digest
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+func TestTDigest(t *testing.T) {
+	td := newTDigest(compression)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100000; i++ {
+		td.add(r.Float64() * 1000)
+	}
+	for _, q := range []float64{0.5, 0.9, 0.99} {
+		if got := td.quantile(q); math.Abs(got-q*1000) > 10 {
+			t.Errorf("expect %v, got %v", q*1000, got)
+		}
+	}
+	if len(td.centroids) > compression*2 {
+		t.Errorf("expect at most %v centroids, got %v", compression*2, len(td.centroids))
+	}
+}
+
+func TestNode(t *testing.T) {
+	b := &Builder{Quantile: 0.9, Window: time.Hour}
+	wn := b.Build(selector.NewNode("http", "127.0.0.1:9090", nil))
+	n := wn.(*Node)
+	if _, ok := n.Quantile(); ok {
+		t.Errorf("expect no quantile without samples")
+	}
+	if got := wn.Weight(); got != float64(time.Second/probeLatency) {
+		t.Errorf("expect %v, got %v", float64(time.Second/probeLatency), got)
+	}
+	for i := 1; i <= 100; i++ {
+		done := wn.Pick()
+		if n.Inflight() != 1 {
+			t.Errorf("expect %v, got %v", 1, n.Inflight())
+		}
+		done(context.Background(), selector.DoneInfo{Latency: time.Duration(i) * time.Millisecond})
+	}
+	la, ok := n.Quantile()
+	if !ok {
+		t.Fatalf("expect the quantile to be estimated")
+	}
+	if la < time.Millisecond*85 || la > time.Millisecond*95 {
+		t.Errorf("expect about %v, got %v", time.Millisecond*90, la)
+	}
+
+	// the samples expire after two windows
+	n.mu.Lock()
+	n.window = time.Millisecond
+	n.mu.Unlock()
+	time.Sleep(time.Millisecond * 5)
+	if _, ok := n.Quantile(); ok {
+		t.Errorf("expect the samples to expire")
+	}
+}
@@ -0,0 +1,100 @@
+package digest
+
+import (
+	"math"
+	"sort"
+)
+
+// centroid is a cluster of samples.
+type centroid struct {
+	mean  float64
+	count float64
+}
+
+// tdigest is a merging t-digest, it estimates the quantiles of a stream of
+// samples in bounded memory, and is accurate at the tails.
+type tdigest struct {
+	compression float64
+	centroids   []centroid
+	buf         []centroid
+	count       float64
+}
+
+func newTDigest(compression float64) *tdigest {
+	return &tdigest{compression: compression}
+}
+
+// add adds a sample.
+func (t *tdigest) add(x float64) {
+	t.buf = append(t.buf, centroid{mean: x, count: 1})
+	t.count++
+	if len(t.buf) >= int(t.compression)*5 {
+		t.compress()
+	}
+}
+
+// compress merges the buffered samples into the centroids.
+func (t *tdigest) compress() {
+	if len(t.buf) == 0 {
+		return
+	}
+	all := append(t.centroids, t.buf...)
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+	merged := make([]centroid, 0, len(t.centroids)+1)
+	cur := all[0]
+	var soFar float64
+	limit := t.qLimit(0)
+	for _, next := range all[1:] {
+		proposed := cur.count + next.count
+		// 越靠近两端，质心越小，尾部分位数越精确
+		if (soFar+proposed)/t.count <= limit {
+			cur.mean += (next.mean - cur.mean) * next.count / proposed
+			cur.count = proposed
+			continue
+		}
+		soFar += cur.count
+		merged = append(merged, cur)
+		limit = t.qLimit(soFar / t.count)
+		cur = next
+	}
+	t.centroids = append(merged, cur)
+	t.buf = t.buf[:0]
+}
+
+// quantile returns the estimated value at the quantile q in [0, 1].
+func (t *tdigest) quantile(q float64) float64 {
+	t.compress()
+	n := len(t.centroids)
+	if n == 0 {
+		return 0
+	}
+	if n == 1 {
+		return t.centroids[0].mean
+	}
+	target := q * t.count
+	var cumulative float64
+	if target <= t.centroids[0].count/2 {
+		return t.centroids[0].mean
+	}
+	// 在相邻质心的中心之间线性插值
+	for i := 0; i < n-1; i++ {
+		left := cumulative + t.centroids[i].count/2
+		right := cumulative + t.centroids[i].count + t.centroids[i+1].count/2
+		if target <= right {
+			frac := (target - left) / (right - left)
+			return t.centroids[i].mean + frac*(t.centroids[i+1].mean-t.centroids[i].mean)
+		}
+		cumulative += t.centroids[i].count
+	}
+	return t.centroids[n-1].mean
+}
+
+// qLimit returns the max quantile the centroid starting at q can cover,
+// with the scale function k(q) = δ/(2π) * asin(2q-1).
+func (t *tdigest) qLimit(q float64) float64 {
+	k := t.compression / (2 * math.Pi) * math.Asin(2*q-1)
+	if k+1 >= t.compression/4 {
+		return 1
+	}
+	return (math.Sin((k+1)*2*math.Pi/t.compression) + 1) / 2
+}
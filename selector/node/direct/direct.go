@@ -15,6 +15,7 @@ const (
 var (
 	_ selector.WeightedNode        = (*Node)(nil)
 	_ selector.WeightedNodeBuilder = (*Builder)(nil)
+	_ selector.StatsReporter       = (*Node)(nil)
 )
 
 // Node is endpoint instance
@@ -23,30 +24,51 @@ type Node struct {
 
 	// last lastPick timestamp
 	lastPick int64
+	// created timestamp
+	created   int64
+	slowStart time.Duration
+
+	// statistic of the requests
+	inflight int64
+	lag      int64
+	total    uint64
+	failures uint64
 }
 
 // Builder is direct node builder
-type Builder struct{}
+type Builder struct {
+	// SlowStart is the warm-up window, a new node ramps up to its full weight during it.
+	SlowStart time.Duration
+}
 
 // Build create node
-func (*Builder) Build(n selector.Node) selector.WeightedNode {
-	return &Node{Node: n, lastPick: 0}
+func (b *Builder) Build(n selector.Node) selector.WeightedNode {
+	return &Node{Node: n, lastPick: 0, created: time.Now().UnixNano(), slowStart: b.SlowStart}
 }
 
 func (n *Node) Pick() selector.DoneFunc {
 	now := time.Now().UnixNano()
 	atomic.StoreInt64(&n.lastPick, now)
-	return func(ctx context.Context, di selector.DoneInfo) {}
+	atomic.AddInt64(&n.inflight, 1)
+	return func(ctx context.Context, di selector.DoneInfo) {
+		atomic.AddInt64(&n.inflight, -1)
+		atomic.StoreInt64(&n.lag, time.Now().UnixNano()-now)
+		atomic.AddUint64(&n.total, 1)
+		if di.Err != nil {
+			atomic.AddUint64(&n.failures, 1)
+		}
+	}
 }
 
 // 计算节点的负载，因为是direct，所以使用默认值（defaultWeight）
 
 // Weight is node effective weight
 func (n *Node) Weight() float64 {
+	weight := float64(defaultWeight)
 	if n.InitialWeight() != nil {
-		return float64(*n.InitialWeight())
+		weight = float64(*n.InitialWeight())
 	}
-	return defaultWeight
+	return weight * selector.SlowStartRatio(n.created, n.slowStart)
 }
 
 // PickElapsed 这个节点在最近两次被pick的时间间隔
@@ -57,3 +79,22 @@ func (n *Node) PickElapsed() time.Duration {
 func (n *Node) Raw() selector.Node {
 	return n.Node
 }
+
+// Lag is the latency of the latest request.
+func (n *Node) Lag() time.Duration {
+	return time.Duration(atomic.LoadInt64(&n.lag))
+}
+
+// SuccessRate is the success rate of all the requests.
+func (n *Node) SuccessRate() float64 {
+	total := atomic.LoadUint64(&n.total)
+	if total == 0 {
+		return 1
+	}
+	return float64(total-atomic.LoadUint64(&n.failures)) / float64(total)
+}
+
+// Inflight is the number of inflight requests to the node.
+func (n *Node) Inflight() int64 {
+	return atomic.LoadInt64(&n.inflight)
+}
@@ -68,3 +68,53 @@ func TestDirectDefaultWeight(t *testing.T) {
 		t.Errorf("time.Millisecond*5 >= wn.PickElapsed()(%s)", wn.PickElapsed())
 	}
 }
+
+func TestDirectSlowStart(t *testing.T) {
+	b := &Builder{SlowStart: time.Millisecond * 100}
+	wn := b.Build(selector.NewNode(
+		"http",
+		"127.0.0.1:9090",
+		&registry.ServiceInstance{
+			ID:        "127.0.0.1:9090",
+			Name:      "helloworld",
+			Version:   "v1.0.0",
+			Endpoints: []string{"http://127.0.0.1:9090"},
+		}))
+
+	if float64(10) > wn.Weight() {
+		t.Errorf("float64(10) > wn.Weight()(%v)", wn.Weight())
+	}
+	if float64(50) <= wn.Weight() {
+		t.Errorf("float64(50) <= wn.Weight()(%v)", wn.Weight())
+	}
+	time.Sleep(time.Millisecond * 100)
+	if !reflect.DeepEqual(float64(100), wn.Weight()) {
+		t.Errorf("expect %v, got %v", float64(100), wn.Weight())
+	}
+}
+
+func TestDirectStats(t *testing.T) {
+	b := &Builder{}
+	wn := b.Build(selector.NewNode("http", "127.0.0.1:9090", nil))
+	stats := wn.(selector.StatsReporter)
+	if !reflect.DeepEqual(float64(1), stats.SuccessRate()) {
+		t.Errorf("expect %v, got %v", float64(1), stats.SuccessRate())
+	}
+	done := wn.Pick()
+	done2 := wn.Pick()
+	if !reflect.DeepEqual(int64(2), stats.Inflight()) {
+		t.Errorf("expect %v, got %v", 2, stats.Inflight())
+	}
+	time.Sleep(time.Millisecond * 10)
+	done(context.Background(), selector.DoneInfo{})
+	done2(context.Background(), selector.DoneInfo{Err: context.DeadlineExceeded})
+	if !reflect.DeepEqual(int64(0), stats.Inflight()) {
+		t.Errorf("expect %v, got %v", 0, stats.Inflight())
+	}
+	if !reflect.DeepEqual(0.5, stats.SuccessRate()) {
+		t.Errorf("expect %v, got %v", 0.5, stats.SuccessRate())
+	}
+	if time.Millisecond*10 > stats.Lag() {
+		t.Errorf("time.Millisecond*10 > stats.Lag()(%v)", stats.Lag())
+	}
+}
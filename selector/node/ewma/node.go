@@ -18,11 +18,18 @@ const (
 	tau = int64(time.Millisecond * 600)
 	// if statistic not collected,we add a big lag penalty to endpoint
 	penalty = uint64(time.Second * 10)
+	// the bounds of the interval to predict the lag of inflight requests
+	minPredictInterval = int64(time.Millisecond * 5)
+	maxPredictInterval = int64(time.Millisecond * 200)
+	// the max number of inflight requests tracked for the lag prediction
+	maxInflights = 1024
 )
 
 var (
 	_ selector.WeightedNode        = (*Node)(nil)
 	_ selector.WeightedNodeBuilder = (*Builder)(nil)
+	_ selector.StatsReporter       = (*Node)(nil)
+	_ selector.Finalizer           = (*Node)(nil)
 )
 
 // Node 一个后端服务节点实例
@@ -34,36 +41,106 @@ type Node struct {
 	lag     int64
 	success uint64
 	// 这个节点正在处理的请求数量（只是对于单个client的请求数量），如果有多个客户端的话，数量应该不止这个数
-	inflight  int64
-	inflights *list.List
+	inflight     int64
+	inflights    *list.List
+	maxInflights int
 	// last collected timestamp
 	stamp     int64
 	predictTs int64
 	predict   int64
 	// request number in a period time
 	reqs int64
+	// moving average of the backend reported utilization, in float64 bits
+	utilization uint64
 	// last 最近一次被负载均衡器选中的时间戳
 	// last lastPick timestamp
 	lastPick int64
+	// created timestamp
+	created   int64
+	slowStart time.Duration
+
+	tau                int64
+	penalty            uint64
+	minPredictInterval int64
+	maxPredictInterval int64
 
 	errHandler func(err error) (isErr bool)
+	classifier selector.ErrorClassifier
 	lk         sync.RWMutex
 }
 
+// defaultClassifier is the classification of the ewma node before the classifiers were pluggable.
+var defaultClassifier = selector.ErrorClassifierFunc(func(err error) selector.Outcome {
+	var netErr net.Error
+	if errors.Is(context.DeadlineExceeded, err) || errors.Is(context.Canceled, err) ||
+		errors.IsServiceUnavailable(err) || errors.IsGatewayTimeout(err) || errors.As(err, &netErr) {
+		return selector.OutcomeFailure
+	}
+	return selector.OutcomeSuccess
+})
+
 // Builder is ewma node builder.
 type Builder struct {
+	// ErrHandler reports the additional errors counting as failures.
 	ErrHandler func(err error) (isErr bool)
+	// Classifier classifies the errors counting as failures, default counts the timeouts, the cancellations,
+	// 503, 504 and the network errors. Use selector.DefaultErrorClassifier to count all the server errors and 429.
+	Classifier selector.ErrorClassifier
+	// SlowStart is the warm-up window, a new node ramps up to its full weight during it.
+	SlowStart time.Duration
+	// Tau is the mean lifetime of the moving average, default 600ms.
+	Tau time.Duration
+	// Penalty is the lag penalty of the node without statistic, default 10s.
+	Penalty time.Duration
+	// InitialSuccess is the initial success rate in (0, 1], default 1.
+	InitialSuccess float64
+	// MinPredictInterval and MaxPredictInterval are the bounds of the interval
+	// to predict the lag of inflight requests, default 5ms and 200ms.
+	MinPredictInterval time.Duration
+	MaxPredictInterval time.Duration
+	// MaxInflights is the max number of inflight requests tracked for the
+	// lag prediction, the oldest one is dropped when exceeded, default 1024.
+	MaxInflights int
 }
 
 // Build create a weighted node.
 func (b *Builder) Build(n selector.Node) selector.WeightedNode {
 	s := &Node{
-		Node:       n,
-		lag:        0,
-		success:    1000,
-		inflight:   1,
-		inflights:  list.New(),
-		errHandler: b.ErrHandler,
+		Node:               n,
+		lag:                0,
+		success:            1000,
+		inflight:           1,
+		inflights:          list.New(),
+		maxInflights:       maxInflights,
+		created:            time.Now().UnixNano(),
+		slowStart:          b.SlowStart,
+		tau:                tau,
+		penalty:            penalty,
+		minPredictInterval: minPredictInterval,
+		maxPredictInterval: maxPredictInterval,
+		errHandler:         b.ErrHandler,
+		classifier:         b.Classifier,
+	}
+	if s.classifier == nil {
+		s.classifier = defaultClassifier
+	}
+	if b.Tau > 0 {
+		s.tau = int64(b.Tau)
+	}
+	if b.Penalty > 0 {
+		s.penalty = uint64(b.Penalty)
+	}
+	if b.InitialSuccess > 0 && b.InitialSuccess <= 1 {
+		s.success = uint64(b.InitialSuccess * 1000)
+	}
+	if b.MinPredictInterval > 0 {
+		s.minPredictInterval = int64(b.MinPredictInterval)
+	}
+	if b.MaxPredictInterval > 0 {
+		s.maxPredictInterval = int64(b.MaxPredictInterval)
+	}
+	if b.MaxInflights > 0 {
+		s.maxInflights = b.MaxInflights
 	}
 	return s
 }
@@ -77,11 +154,11 @@ func (n *Node) load() (load uint64) {
 	avgLag := atomic.LoadInt64(&n.lag)
 	lastPredictTs := atomic.LoadInt64(&n.predictTs)
 	predictInterval := avgLag / 5
-	if predictInterval < int64(time.Millisecond*5) {
-		predictInterval = int64(time.Millisecond * 5)
+	if predictInterval < n.minPredictInterval {
+		predictInterval = n.minPredictInterval
 	}
-	if predictInterval > int64(time.Millisecond*200) {
-		predictInterval = int64(time.Millisecond * 200)
+	if predictInterval > n.maxPredictInterval {
+		predictInterval = n.maxPredictInterval
 	}
 	if now-lastPredictTs > predictInterval && atomic.CompareAndSwapInt64(&n.predictTs, lastPredictTs, now) {
 		var (
@@ -109,7 +186,7 @@ func (n *Node) load() (load uint64) {
 	if avgLag == 0 {
 		// penalty is the penalty value when there is no data when the node is just started.
 		// The default value is 1e9 * 10
-		load = penalty * uint64(atomic.LoadInt64(&n.inflight))
+		load = n.penalty * uint64(atomic.LoadInt64(&n.inflight))
 		return
 	}
 	predict := atomic.LoadInt64(&n.predict)
@@ -117,6 +194,10 @@ func (n *Node) load() (load uint64) {
 		avgLag = predict
 	}
 	load = uint64(avgLag) * uint64(atomic.LoadInt64(&n.inflight))
+	// 结合后端上报的负载（如cpu使用率）
+	if u := math.Float64frombits(atomic.LoadUint64(&n.utilization)); u > 0 {
+		load = uint64(float64(load) * (1 + u))
+	}
 	return
 }
 
@@ -129,6 +210,10 @@ func (n *Node) Pick() selector.DoneFunc {
 	atomic.AddInt64(&n.reqs, 1)
 	n.lk.Lock()
 	e := n.inflights.PushBack(now)
+	// done未被调用的请求会一直留在链表中，超过上限时丢弃最早的请求
+	if n.inflights.Len() > n.maxInflights {
+		n.inflights.Remove(n.inflights.Front())
+	}
 	n.lk.Unlock()
 	return func(ctx context.Context, di selector.DoneInfo) {
 		n.lk.Lock()
@@ -148,7 +233,7 @@ func (n *Node) Pick() selector.DoneFunc {
 		if td < 0 {
 			td = 0
 		}
-		w := math.Exp(float64(-td) / float64(tau))
+		w := math.Exp(float64(-td) / float64(n.tau))
 
 		// 计算本次请求延迟，并保存 （从pick 到 RPC请求完成）
 		start := e.Value.(int64)
@@ -171,9 +256,7 @@ func (n *Node) Pick() selector.DoneFunc {
 			if n.errHandler != nil && n.errHandler(di.Err) {
 				success = 0
 			}
-			var netErr net.Error
-			if errors.Is(context.DeadlineExceeded, di.Err) || errors.Is(context.Canceled, di.Err) ||
-				errors.IsServiceUnavailable(di.Err) || errors.IsGatewayTimeout(di.Err) || errors.As(di.Err, &netErr) {
+			if n.classifier.Classify(di.Err).Failed() {
 				success = 0
 			}
 		}
@@ -184,12 +267,21 @@ func (n *Node) Pick() selector.DoneFunc {
 		// success 是本次的实际值（成功为1000，失败为0）
 		success = uint64(float64(oldSuc)*w + float64(success)*(1.0-w))
 		atomic.StoreUint64(&n.success, success)
+
+		// 后端上报负载维度的ewma计算
+		if report, ok := selector.LoadFromDoneInfo(di); ok {
+			u := report.Utilization()
+			if old := math.Float64frombits(atomic.LoadUint64(&n.utilization)); old > 0 {
+				u = old*w + u*(1.0-w)
+			}
+			atomic.StoreUint64(&n.utilization, math.Float64bits(u))
+		}
 	}
 }
 
 // Weight is node effective weight.
 func (n *Node) Weight() (weight float64) {
-	weight = float64(n.health()*uint64(time.Second)) / float64(n.load())
+	weight = float64(n.health()*uint64(time.Second)) / float64(n.load()) * selector.SlowStartRatio(n.created, n.slowStart)
 	return
 }
 
@@ -200,3 +292,27 @@ func (n *Node) PickElapsed() time.Duration {
 func (n *Node) Raw() selector.Node {
 	return n.Node
 }
+
+// Lag is the moving average latency of the node.
+func (n *Node) Lag() time.Duration {
+	return time.Duration(atomic.LoadInt64(&n.lag))
+}
+
+// SuccessRate is the moving average success rate of the node.
+func (n *Node) SuccessRate() float64 {
+	return float64(n.health()) / 1000
+}
+
+// Inflight is the number of inflight requests to the node.
+func (n *Node) Inflight() int64 {
+	// inflight starts from 1 to avoid zero load
+	return atomic.LoadInt64(&n.inflight) - 1
+}
+
+// Finalize releases the inflight requests tracked by the removed node.
+func (n *Node) Finalize() {
+	n.lk.Lock()
+	// 使用新的链表，未完成请求的Remove对其不生效
+	n.inflights = list.New()
+	n.lk.Unlock()
+}
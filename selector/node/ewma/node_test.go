@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/selector"
 )
@@ -125,3 +126,153 @@ func TestDirectErrorHandler(t *testing.T) {
 		t.Errorf("float64(60000) <= wn.Weight()(%v)", wn.Weight())
 	}
 }
+
+func TestDirectClassifier(t *testing.T) {
+	for _, c := range []struct {
+		classifier selector.ErrorClassifier
+		failed     bool
+	}{
+		{nil, false},
+		{selector.DefaultErrorClassifier, true},
+	} {
+		wn := (&Builder{Classifier: c.classifier}).Build(selector.NewNode("http", "127.0.0.1:9090", nil))
+		done := wn.Pick()
+		done(context.Background(), selector.DoneInfo{Err: errors.InternalServer("", "")})
+		if failed := wn.(*Node).health() < 1000; failed != c.failed {
+			t.Errorf("expect failed %v, got %v", c.failed, failed)
+		}
+	}
+}
+
+func TestDirectSlowStart(t *testing.T) {
+	b := &Builder{SlowStart: time.Millisecond * 100}
+	wn := b.Build(selector.NewNode(
+		"http",
+		"127.0.0.1:9090",
+		&registry.ServiceInstance{
+			ID:        "127.0.0.1:9090",
+			Name:      "helloworld",
+			Version:   "v1.0.0",
+			Endpoints: []string{"http://127.0.0.1:9090"},
+		}))
+
+	if float64(10) > wn.Weight() {
+		t.Errorf("float64(10) > wn.Weight()(%v)", wn.Weight())
+	}
+	if float64(50) <= wn.Weight() {
+		t.Errorf("float64(50) <= wn.Weight()(%v)", wn.Weight())
+	}
+	time.Sleep(time.Millisecond * 100)
+	if !reflect.DeepEqual(float64(100), wn.Weight()) {
+		t.Errorf("expect %v, got %v", float64(100), wn.Weight())
+	}
+}
+
+func TestDirectOptions(t *testing.T) {
+	b := &Builder{
+		Tau:                time.Millisecond * 100,
+		Penalty:            time.Second,
+		InitialSuccess:     0.5,
+		MinPredictInterval: time.Millisecond,
+		MaxPredictInterval: time.Millisecond * 100,
+	}
+	wn := b.Build(selector.NewNode(
+		"http",
+		"127.0.0.1:9090",
+		&registry.ServiceInstance{
+			ID:        "127.0.0.1:9090",
+			Name:      "helloworld",
+			Version:   "v1.0.0",
+			Endpoints: []string{"http://127.0.0.1:9090"},
+		}))
+
+	// 500 * time.Second / time.Second
+	if !reflect.DeepEqual(float64(500), wn.Weight()) {
+		t.Errorf("expect %v, got %v", 500, wn.Weight())
+	}
+	n := wn.(*Node)
+	if !reflect.DeepEqual(int64(time.Millisecond*100), n.tau) {
+		t.Errorf("expect %v, got %v", int64(time.Millisecond*100), n.tau)
+	}
+	if !reflect.DeepEqual(int64(time.Millisecond), n.minPredictInterval) {
+		t.Errorf("expect %v, got %v", int64(time.Millisecond), n.minPredictInterval)
+	}
+	if !reflect.DeepEqual(int64(time.Millisecond*100), n.maxPredictInterval) {
+		t.Errorf("expect %v, got %v", int64(time.Millisecond*100), n.maxPredictInterval)
+	}
+}
+
+func TestDirectLoadReport(t *testing.T) {
+	b := &Builder{}
+	newNode := func() selector.WeightedNode {
+		return b.Build(selector.NewNode(
+			"http",
+			"127.0.0.1:9090",
+			&registry.ServiceInstance{
+				ID:        "127.0.0.1:9090",
+				Name:      "helloworld",
+				Version:   "v1.0.0",
+				Endpoints: []string{"http://127.0.0.1:9090"},
+			}))
+	}
+	idle, busy := newNode(), newNode()
+	for _, wn := range []selector.WeightedNode{idle, busy} {
+		done := wn.Pick()
+		time.Sleep(time.Millisecond * 10)
+		var load *selector.LoadReport
+		if wn == busy {
+			load = &selector.LoadReport{CPUUtilization: 0.9}
+		}
+		done(context.Background(), selector.DoneInfo{Load: load})
+	}
+	if idle.Weight() <= busy.Weight() {
+		t.Errorf("idle.Weight()(%v) <= busy.Weight()(%v)", idle.Weight(), busy.Weight())
+	}
+}
+
+func TestDirectStats(t *testing.T) {
+	b := &Builder{}
+	wn := b.Build(selector.NewNode("http", "127.0.0.1:9090", nil))
+	stats := wn.(selector.StatsReporter)
+	if !reflect.DeepEqual(float64(1), stats.SuccessRate()) {
+		t.Errorf("expect %v, got %v", float64(1), stats.SuccessRate())
+	}
+	done := wn.Pick()
+	if !reflect.DeepEqual(int64(1), stats.Inflight()) {
+		t.Errorf("expect %v, got %v", 1, stats.Inflight())
+	}
+	time.Sleep(time.Millisecond * 10)
+	done(context.Background(), selector.DoneInfo{})
+	if !reflect.DeepEqual(int64(0), stats.Inflight()) {
+		t.Errorf("expect %v, got %v", 0, stats.Inflight())
+	}
+	if time.Millisecond*10 > stats.Lag() {
+		t.Errorf("time.Millisecond*10 > stats.Lag()(%v)", stats.Lag())
+	}
+}
+
+func TestDirectMaxInflights(t *testing.T) {
+	b := &Builder{MaxInflights: 2}
+	wn := b.Build(selector.NewNode("http", "127.0.0.1:9090", nil))
+	n := wn.(*Node)
+	var dones []selector.DoneFunc
+	for i := 0; i < 3; i++ {
+		dones = append(dones, wn.Pick())
+	}
+	if !reflect.DeepEqual(2, n.inflights.Len()) {
+		t.Errorf("expect %v, got %v", 2, n.inflights.Len())
+	}
+	n.Finalize()
+	if !reflect.DeepEqual(0, n.inflights.Len()) {
+		t.Errorf("expect %v, got %v", 0, n.inflights.Len())
+	}
+	for _, done := range dones {
+		done(context.Background(), selector.DoneInfo{})
+	}
+	if !reflect.DeepEqual(0, n.inflights.Len()) {
+		t.Errorf("expect %v, got %v", 0, n.inflights.Len())
+	}
+	if !reflect.DeepEqual(int64(0), n.Inflight()) {
+		t.Errorf("expect %v, got %v", 0, n.Inflight())
+	}
+}
@@ -0,0 +1,82 @@
+package selector
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// Error classes of the selection observations.
+const (
+	ErrClassOK          = "ok"
+	ErrClassNoAvailable = "no_available"
+	ErrClassCanceled    = "canceled"
+	ErrClassTimeout     = "timeout"
+	ErrClassNetwork     = "network"
+	ErrClassClient      = "client_error"
+	ErrClassServer      = "server_error"
+)
+
+// Observation is the info of a selection or a done callback.
+type Observation struct {
+	// Address is the selected node address, empty if no node is selected.
+	Address string
+	// Candidates is the number of candidates after filtering.
+	Candidates int
+	// Latency is the time elapsed since the node was selected, zero on selection.
+	Latency time.Duration
+	// Err is the selection error or the RPC response error.
+	Err error
+	// ErrClass is the class of Err.
+	ErrClass string
+}
+
+// Observer observes the selections and the done callbacks, it enables
+// per-node traffic and latency metrics without wrapping every balancer.
+type Observer interface {
+	// OnSelect is called on every Select.
+	OnSelect(ctx context.Context, o Observation)
+	// OnDone is called when the RPC invoke done.
+	OnDone(ctx context.Context, o Observation)
+}
+
+// ErrorClass returns the class of the error.
+func ErrorClass(err error) string {
+	if err == nil {
+		return ErrClassOK
+	}
+	var netErr net.Error
+	switch {
+	case errors.Is(err, ErrNoAvailable):
+		return ErrClassNoAvailable
+	case errors.Is(err, context.Canceled):
+		return ErrClassCanceled
+	case errors.Is(err, context.DeadlineExceeded) || errors.IsGatewayTimeout(err):
+		return ErrClassTimeout
+	case errors.As(err, &netErr):
+		return ErrClassNetwork
+	case errors.Code(err) < 500:
+		return ErrClassClient
+	default:
+		return ErrClassServer
+	}
+}
+
+// observeDone reports the done callback to the observer.
+func observeDone(o Observer, addr string, candidates int, done DoneFunc) DoneFunc {
+	start := time.Now()
+	return func(ctx context.Context, di DoneInfo) {
+		o.OnDone(ctx, Observation{
+			Address:    addr,
+			Candidates: candidates,
+			Latency:    time.Since(start),
+			Err:        di.Err,
+			ErrClass:   ErrorClass(di.Err),
+		})
+		if done != nil {
+			done(ctx, di)
+		}
+	}
+}
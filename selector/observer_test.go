@@ -0,0 +1,95 @@
+package selector
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+type mockObserver struct {
+	mu      sync.Mutex
+	selects []Observation
+	dones   []Observation
+}
+
+func (o *mockObserver) OnSelect(_ context.Context, ob Observation) {
+	o.mu.Lock()
+	o.selects = append(o.selects, ob)
+	o.mu.Unlock()
+}
+
+func (o *mockObserver) OnDone(_ context.Context, ob Observation) {
+	o.mu.Lock()
+	o.dones = append(o.dones, ob)
+	o.mu.Unlock()
+}
+
+func TestErrorClass(t *testing.T) {
+	tests := []struct {
+		err   error
+		class string
+	}{
+		{nil, ErrClassOK},
+		{ErrNoAvailable, ErrClassNoAvailable},
+		{context.Canceled, ErrClassCanceled},
+		{context.DeadlineExceeded, ErrClassTimeout},
+		{errors.GatewayTimeout("", ""), ErrClassTimeout},
+		{&net.OpError{Op: "dial", Err: net.ErrClosed}, ErrClassNetwork},
+		{errors.BadRequest("", ""), ErrClassClient},
+		{errors.InternalServer("", ""), ErrClassServer},
+	}
+	for _, test := range tests {
+		if class := ErrorClass(test.err); class != test.class {
+			t.Errorf("expect %v, got %v", test.class, class)
+		}
+	}
+}
+
+func TestDefaultObserver(t *testing.T) {
+	o := &mockObserver{}
+	builder := DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+		Observer: o,
+	}
+	selector := builder.Build()
+	if _, _, err := selector.Select(context.Background()); !errors.Is(err, ErrNoAvailable) {
+		t.Errorf("expect %v, got %v", ErrNoAvailable, err)
+	}
+	selector.Apply([]Node{NewNode("http", "127.0.0.1:8080", nil), NewNode("http", "127.0.0.1:9090", nil)})
+	n, done, err := selector.Select(context.Background())
+	if err != nil {
+		t.Fatalf("expect %v, got %v", nil, err)
+	}
+	done(context.Background(), DoneInfo{Err: errors.InternalServer("", "")})
+
+	if len(o.selects) != 2 {
+		t.Fatalf("expect %v, got %v", 2, len(o.selects))
+	}
+	if o.selects[0].ErrClass != ErrClassNoAvailable {
+		t.Errorf("expect %v, got %v", ErrClassNoAvailable, o.selects[0].ErrClass)
+	}
+	if o.selects[1].Address != n.Address() || o.selects[1].Candidates != 2 {
+		t.Errorf("expect %v and %v, got %v and %v", n.Address(), 2, o.selects[1].Address, o.selects[1].Candidates)
+	}
+	if len(o.dones) != 1 {
+		t.Fatalf("expect %v, got %v", 1, len(o.dones))
+	}
+	if o.dones[0].ErrClass != ErrClassServer {
+		t.Errorf("expect %v, got %v", ErrClassServer, o.dones[0].ErrClass)
+	}
+
+	// override the observer per call
+	o2 := &mockObserver{}
+	_, done, _ = selector.Select(context.Background(), WithMetrics(o2))
+	done(context.Background(), DoneInfo{})
+	if len(o2.selects) != 1 || len(o2.dones) != 1 {
+		t.Errorf("expect %v and %v, got %v and %v", 1, 1, len(o2.selects), len(o2.dones))
+	}
+	if len(o.selects) != 2 {
+		t.Errorf("expect %v, got %v", 2, len(o.selects))
+	}
+}
@@ -3,6 +3,13 @@ package selector
 // SelectOptions is Select Options.
 type SelectOptions struct {
 	NodeFilters []NodeFilter
+	Observer    Observer
+	// FilterFallback overrides the filter fallback of the selector if not nil.
+	FilterFallback *bool
+	// Balancer overrides the balancer of the selector if not nil.
+	Balancer Balancer
+	// WaitForNodes overrides the wait for nodes of the selector if not nil.
+	WaitForNodes *bool
 }
 
 // SelectOption is Selector option.
@@ -14,3 +21,35 @@ func WithNodeFilter(fn ...NodeFilter) SelectOption {
 		opts.NodeFilters = fn
 	}
 }
+
+// WithMetrics with observer, it overrides the observer of the selector.
+func WithMetrics(o Observer) SelectOption {
+	return func(opts *SelectOptions) {
+		opts.Observer = o
+	}
+}
+
+// WithFilterFallback with filter fallback, the selector falls back to the
+// full healthy node set when the filters remove every node.
+func WithFilterFallback(fallback bool) SelectOption {
+	return func(opts *SelectOptions) {
+		opts.FilterFallback = &fallback
+	}
+}
+
+// WithBalancer with balancer, it overrides the balancer of the selector
+// for this call. The balancer is shared by every call using it, so it
+// should be built once and reused.
+func WithBalancer(b Balancer) SelectOption {
+	return func(opts *SelectOptions) {
+		opts.Balancer = b
+	}
+}
+
+// WithWaitForNodes with wait for nodes, the selector waits for the next Apply
+// bounded by the ctx instead of failing with ErrNoAvailable.
+func WithWaitForNodes(wait bool) SelectOption {
+	return func(opts *SelectOptions) {
+		opts.WaitForNodes = &wait
+	}
+}
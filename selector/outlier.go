@@ -0,0 +1,186 @@
+package selector
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+const (
+	defaultConsecutiveErrors  = 5
+	defaultBaseEjectionTime   = time.Second * 30
+	defaultMaxEjectionTime    = time.Minute * 5
+	defaultMaxEjectionPercent = 10
+)
+
+// OutlierConfig is passive outlier detection config.
+type OutlierConfig struct {
+	// ConsecutiveErrors is the number of consecutive failures before a node is ejected.
+	ConsecutiveErrors int
+	// BaseEjectionTime is the ejection time of the first ejection,
+	// it grows exponentially with the number of ejections.
+	BaseEjectionTime time.Duration
+	// MaxEjectionTime is the maximum ejection time.
+	MaxEjectionTime time.Duration
+	// MaxEjectionPercent is the maximum percent of nodes that can be ejected at the same time,
+	// at least one node can be ejected regardless of it, like envoy. The ejected nodes are
+	// still selected if fewer than (100-MaxEjectionPercent)% of the nodes are left, e.g. the
+	// only node of a service.
+	MaxEjectionPercent int
+	// Classifier classifies the errors counting as failures, default counts the timeouts,
+	// the network errors and the codes >= 500.
+	Classifier ErrorClassifier
+}
+
+type outlierState struct {
+	consecutive  int
+	ejections    int
+	ejectedUntil time.Time
+	// probation is set after re-admission, any failure ejects the node again.
+	probation bool
+}
+
+// outlierDetector tracks failures by node address and ejects outliers.
+type outlierDetector struct {
+	consecutiveErrors  int
+	baseEjectionTime   time.Duration
+	maxEjectionTime    time.Duration
+	maxEjectionPercent int
+	classifier         ErrorClassifier
+
+	mu     sync.Mutex
+	total  int
+	states map[string]*outlierState
+}
+
+func newOutlierDetector(c *OutlierConfig) *outlierDetector {
+	d := &outlierDetector{
+		consecutiveErrors:  c.ConsecutiveErrors,
+		baseEjectionTime:   c.BaseEjectionTime,
+		maxEjectionTime:    c.MaxEjectionTime,
+		maxEjectionPercent: c.MaxEjectionPercent,
+		classifier:         c.Classifier,
+		states:             make(map[string]*outlierState),
+	}
+	if d.consecutiveErrors <= 0 {
+		d.consecutiveErrors = defaultConsecutiveErrors
+	}
+	if d.baseEjectionTime <= 0 {
+		d.baseEjectionTime = defaultBaseEjectionTime
+	}
+	if d.maxEjectionTime <= 0 {
+		d.maxEjectionTime = defaultMaxEjectionTime
+	}
+	if d.maxEjectionPercent <= 0 {
+		d.maxEjectionPercent = defaultMaxEjectionPercent
+	}
+	if d.classifier == nil {
+		d.classifier = outlierClassifier
+	}
+	return d
+}
+
+// apply drops the states of the nodes which no longer exist.
+func (d *outlierDetector) apply(nodes []WeightedNode) {
+	addrs := make(map[string]struct{}, len(nodes))
+	for _, n := range nodes {
+		addrs[n.Address()] = struct{}{}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for addr := range d.states {
+		if _, ok := addrs[addr]; !ok {
+			delete(d.states, addr)
+		}
+	}
+	d.total = len(nodes)
+}
+
+// filter removes the ejected nodes.
+func (d *outlierDetector) filter(nodes []WeightedNode) []WeightedNode {
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var ejected int
+	for _, n := range nodes {
+		if s, ok := d.states[n.Address()]; ok && now.Before(s.ejectedUntil) {
+			ejected++
+		}
+	}
+	if ejected == 0 {
+		return nodes
+	}
+	healthy := make([]WeightedNode, 0, len(nodes)-ejected)
+	for _, n := range nodes {
+		if s, ok := d.states[n.Address()]; ok && now.Before(s.ejectedUntil) {
+			continue
+		}
+		healthy = append(healthy, n)
+	}
+	// 恐慌阈值：剩余的节点过少时不驱逐，避免单节点或小集群不可用
+	if len(healthy) == 0 || len(healthy)*100 < len(nodes)*(100-d.maxEjectionPercent) {
+		return nodes
+	}
+	return healthy
+}
+
+// report records the result of a request to the node.
+func (d *outlierDetector) report(addr string, err error) {
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s, ok := d.states[addr]
+	if !ok {
+		s = &outlierState{}
+		d.states[addr] = s
+	}
+	if !d.classifier.Classify(err).Failed() {
+		s.consecutive = 0
+		if s.probation && now.After(s.ejectedUntil) {
+			s.probation = false
+			s.ejections = 0
+		}
+		return
+	}
+	s.consecutive++
+	if !s.probation && s.consecutive < d.consecutiveErrors {
+		return
+	}
+	if now.Before(s.ejectedUntil) || !d.canEject(now) {
+		return
+	}
+	ejection := d.baseEjectionTime << s.ejections
+	if ejection > d.maxEjectionTime || ejection <= 0 {
+		ejection = d.maxEjectionTime
+	}
+	s.ejections++
+	s.consecutive = 0
+	s.probation = true
+	s.ejectedUntil = now.Add(ejection)
+}
+
+func (d *outlierDetector) canEject(now time.Time) bool {
+	var ejected int
+	for _, s := range d.states {
+		if now.Before(s.ejectedUntil) {
+			ejected++
+		}
+	}
+	// 与envoy一致，即使比例不足一个节点，也允许驱逐一个
+	return ejected == 0 || (ejected+1)*100 <= d.total*d.maxEjectionPercent
+}
+
+// outlierClassifier is the classification of the outlier detection before the classifiers were pluggable.
+var outlierClassifier = ErrorClassifierFunc(func(err error) Outcome {
+	if err == nil {
+		return OutcomeSuccess
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) || errors.Code(err) >= 500 {
+		return OutcomeFailure
+	}
+	return OutcomeSuccess
+})
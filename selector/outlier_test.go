@@ -0,0 +1,147 @@
+package selector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+)
+
+func mockWeightedNodes(addrs ...string) []WeightedNode {
+	nodes := make([]WeightedNode, 0, len(addrs))
+	for _, addr := range addrs {
+		nodes = append(nodes, &mockWeightedNode{Node: NewNode("http", addr, nil)})
+	}
+	return nodes
+}
+
+func TestOutlierDetector(t *testing.T) {
+	d := newOutlierDetector(&OutlierConfig{
+		ConsecutiveErrors:  2,
+		BaseEjectionTime:   time.Millisecond * 50,
+		MaxEjectionPercent: 50,
+	})
+	nodes := mockWeightedNodes("127.0.0.1:8080", "127.0.0.1:9090")
+	d.apply(nodes)
+
+	d.report("127.0.0.1:8080", kerrors.ServiceUnavailable("", ""))
+	if got := d.filter(nodes); len(got) != 2 {
+		t.Errorf("expect %v, got %v", 2, len(got))
+	}
+	d.report("127.0.0.1:8080", kerrors.ServiceUnavailable("", ""))
+	got := d.filter(nodes)
+	if len(got) != 1 {
+		t.Fatalf("expect %v, got %v", 1, len(got))
+	}
+	if got[0].Address() != "127.0.0.1:9090" {
+		t.Errorf("expect %v, got %v", "127.0.0.1:9090", got[0].Address())
+	}
+
+	// max ejection percent
+	d.report("127.0.0.1:9090", errors.New("unknown"))
+	d.report("127.0.0.1:9090", errors.New("unknown"))
+	if got := d.filter(nodes); len(got) != 1 {
+		t.Errorf("expect %v, got %v", 1, len(got))
+	}
+
+	// re-admitted after ejection, a failure during probation ejects it again with a longer time
+	time.Sleep(time.Millisecond * 60)
+	if got := d.filter(nodes); len(got) != 2 {
+		t.Errorf("expect %v, got %v", 2, len(got))
+	}
+	d.report("127.0.0.1:8080", context.DeadlineExceeded)
+	if got := d.filter(nodes); len(got) != 1 {
+		t.Errorf("expect %v, got %v", 1, len(got))
+	}
+	time.Sleep(time.Millisecond * 60)
+	if got := d.filter(nodes); len(got) != 1 {
+		t.Errorf("expect %v, got %v", 1, len(got))
+	}
+	time.Sleep(time.Millisecond * 50)
+	d.report("127.0.0.1:8080", nil)
+	if got := d.filter(nodes); len(got) != 2 {
+		t.Errorf("expect %v, got %v", 2, len(got))
+	}
+
+	// client errors are not outliers
+	d.report("127.0.0.1:8080", kerrors.BadRequest("", ""))
+	d.report("127.0.0.1:8080", kerrors.BadRequest("", ""))
+	if got := d.filter(nodes); len(got) != 2 {
+		t.Errorf("expect %v, got %v", 2, len(got))
+	}
+
+	d.apply(nodes[1:])
+	if _, ok := d.states["127.0.0.1:8080"]; ok {
+		t.Errorf("expect state of removed node to be dropped")
+	}
+}
+
+func TestDefaultOutlier(t *testing.T) {
+	builder := DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+		Outlier: &OutlierConfig{
+			ConsecutiveErrors:  1,
+			BaseEjectionTime:   time.Minute,
+			MaxEjectionPercent: 50,
+		},
+	}
+	selector := builder.Build()
+	selector.Apply([]Node{NewNode("http", "127.0.0.1:8080", nil), NewNode("http", "127.0.0.1:9090", nil)})
+	n, done, err := selector.Select(context.Background())
+	if err != nil {
+		t.Fatalf("expect %v, got %v", nil, err)
+	}
+	done(context.Background(), DoneInfo{Err: kerrors.ServiceUnavailable("", "")})
+	for i := 0; i < 10; i++ {
+		selected, done, err := selector.Select(context.Background())
+		if err != nil {
+			t.Fatalf("expect %v, got %v", nil, err)
+		}
+		if selected.Address() == n.Address() {
+			t.Errorf("expect ejected node %v not to be selected", n.Address())
+		}
+		done(context.Background(), DoneInfo{})
+	}
+}
+
+func TestOutlierEjectAtLeastOne(t *testing.T) {
+	d := newOutlierDetector(&OutlierConfig{
+		ConsecutiveErrors:  1,
+		BaseEjectionTime:   time.Minute,
+		MaxEjectionPercent: 50,
+	})
+	nodes := mockWeightedNodes("127.0.0.1:8080", "127.0.0.1:9090", "127.0.0.1:7070")
+	d.apply(nodes)
+	d.report("127.0.0.1:8080", context.DeadlineExceeded)
+	if got := d.filter(nodes); len(got) != 2 {
+		t.Errorf("expect one node to be ejected, got %v", len(got))
+	}
+	d.report("127.0.0.1:9090", context.DeadlineExceeded)
+	if got := d.filter(nodes); len(got) != 2 {
+		t.Errorf("expect only one node to be ejected, got %v", len(got))
+	}
+}
+
+func TestOutlierPanicThreshold(t *testing.T) {
+	d := newOutlierDetector(&OutlierConfig{
+		ConsecutiveErrors: 1,
+		BaseEjectionTime:  time.Minute,
+	})
+	// 单节点服务不会因驱逐而不可用
+	single := mockWeightedNodes("127.0.0.1:8080")
+	d.apply(single)
+	d.report("127.0.0.1:8080", context.DeadlineExceeded)
+	if got := d.filter(single); len(got) != 1 {
+		t.Errorf("expect the only node to be kept, got %v", len(got))
+	}
+	// 剩余节点少于(100-MaxEjectionPercent)%时不驱逐
+	nodes := mockWeightedNodes("127.0.0.1:8080", "127.0.0.1:9090")
+	d.apply(nodes)
+	d.report("127.0.0.1:9090", context.DeadlineExceeded)
+	if got := d.filter(nodes); len(got) != 2 {
+		t.Errorf("expect the nodes to be kept below the panic threshold, got %v", len(got))
+	}
+}
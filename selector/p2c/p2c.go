@@ -19,11 +19,58 @@ const (
 
 var _ selector.Balancer = (*Balancer)(nil)
 
+func init() {
+	// 按名称注册，客户端可以通过目标的selector参数选择，例如 ?selector=p2c
+	selector.RegisterBuilder(Name, NewBuilder())
+}
+
 // Option is p2c builder option.
 type Option func(o *options)
 
 // options is p2c builder options
-type options struct{}
+type options struct {
+	slowStart time.Duration
+	forcePick time.Duration
+	node      *ewma.Builder
+	rand      selector.Rand
+	outlier   *selector.OutlierConfig
+}
+
+// WithSlowStart with the warm-up window of new nodes.
+func WithSlowStart(d time.Duration) Option {
+	return func(o *options) {
+		o.slowStart = d
+	}
+}
+
+// WithForcePick with the interval to force pick the node not picked recently,
+// it probes the stale node to update its statistic, negative disables it.
+func WithForcePick(d time.Duration) Option {
+	return func(o *options) {
+		o.forcePick = d
+	}
+}
+
+// WithNodeBuilder with the ewma node builder, it tunes the decay rules of the node statistic.
+func WithNodeBuilder(b *ewma.Builder) Option {
+	return func(o *options) {
+		o.node = b
+	}
+}
+
+// WithRand with the source of randomness, e.g. a seeded or sequence rand in tests.
+func WithRand(r selector.Rand) Option {
+	return func(o *options) {
+		o.rand = r
+	}
+}
+
+// WithOutlier with the passive outlier detection ejecting the failing nodes.
+func WithOutlier(c *selector.OutlierConfig) Option {
+	return func(o *options) {
+		o.outlier = c
+	}
+}
 
 // New creates a p2c selector.
 func New(opts ...Option) selector.Selector {
@@ -32,9 +79,10 @@ func New(opts ...Option) selector.Selector {
 
 // Balancer is p2c selector.
 type Balancer struct {
-	mu     sync.Mutex
-	r      *rand.Rand
-	picked int64
+	mu        sync.Mutex
+	r         selector.Rand
+	picked    int64
+	forcePick time.Duration
 }
 
 // choose two distinct nodes.
@@ -71,7 +119,7 @@ func (s *Balancer) Pick(_ context.Context, nodes []selector.WeightedNode) (selec
 
 	// If the failed node has never been selected once during forceGap, it is forced to be selected once
 	// Take advantage of forced opportunities to trigger updates of success rate and delay
-	if upc.PickElapsed() > forcePick && atomic.CompareAndSwapInt64(&s.picked, 0, 1) {
+	if s.forcePick > 0 && upc.PickElapsed() > s.forcePick && atomic.CompareAndSwapInt64(&s.picked, 0, 1) {
 		pc = upc
 		atomic.StoreInt64(&s.picked, 0)
 	}
@@ -85,16 +133,39 @@ func NewBuilder(opts ...Option) selector.Builder {
 	for _, opt := range opts {
 		opt(&option)
 	}
+	node := &ewma.Builder{}
+	if option.node != nil {
+		nb := *option.node
+		node = &nb
+	}
+	if option.slowStart > 0 {
+		node.SlowStart = option.slowStart
+	}
 	return &selector.DefaultBuilder{
-		Balancer: &Builder{},
-		Node:     &ewma.Builder{},
+		Balancer: &Builder{ForcePick: option.forcePick, Rand: option.rand},
+		Node:     node,
+		Outlier:  option.outlier,
 	}
 }
 
 // Builder is p2c builder
-type Builder struct{}
+type Builder struct {
+	// ForcePick is the interval to force pick the stale node,
+	// zero uses the default 3s, negative disables it.
+	ForcePick time.Duration
+	// Rand is the source of randomness, default is seeded with the current time.
+	Rand selector.Rand
+}
 
 // Build creates Balancer
 func (b *Builder) Build() selector.Balancer {
-	return &Balancer{r: rand.New(rand.NewSource(time.Now().UnixNano()))}
+	fp := b.ForcePick
+	if fp == 0 {
+		fp = forcePick
+	}
+	r := b.Rand
+	if r == nil {
+		r = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return &Balancer{r: r, forcePick: fp}
 }
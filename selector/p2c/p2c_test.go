@@ -13,6 +13,7 @@ import (
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/selector"
 	"github.com/go-kratos/kratos/v2/selector/filter"
+	"github.com/go-kratos/kratos/v2/selector/node/ewma"
 )
 
 func TestWrr3(t *testing.T) {
@@ -120,3 +121,77 @@ func TestOne(t *testing.T) {
 		t.Errorf("expect %v, got %v", "127.0.0.0:8080", n.Address())
 	}
 }
+
+func TestForcePick(t *testing.T) {
+	b := NewBuilder(WithForcePick(-1), WithNodeBuilder(&ewma.Builder{Tau: time.Millisecond * 100}))
+	db := b.(*selector.DefaultBuilder)
+	if !reflect.DeepEqual(time.Millisecond*100, db.Node.(*ewma.Builder).Tau) {
+		t.Errorf("expect %v, got %v", time.Millisecond*100, db.Node.(*ewma.Builder).Tau)
+	}
+	if bl := db.Balancer.Build().(*Balancer); bl.forcePick >= 0 {
+		t.Errorf("expect force pick to be disabled, got %v", bl.forcePick)
+	}
+	if bl := (&Builder{}).Build().(*Balancer); bl.forcePick != forcePick {
+		t.Errorf("expect %v, got %v", forcePick, bl.forcePick)
+	}
+
+	for _, fp := range []time.Duration{-1, time.Millisecond * 10} {
+		p2c := NewBuilder(WithForcePick(fp)).Build()
+		var nodes []selector.Node
+		for i := 0; i < 2; i++ {
+			addr := fmt.Sprintf("127.0.0.%d:8080", i)
+			nodes = append(nodes, selector.NewNode("http", addr, &registry.ServiceInstance{ID: addr}))
+		}
+		p2c.Apply(nodes)
+		// make 127.0.0.0:8080 much slower than the other node
+		_, done, _ := p2c.Select(selector.WithPinnedNode(context.Background(), "127.0.0.0:8080"))
+		time.Sleep(time.Millisecond * 50)
+		done(context.Background(), selector.DoneInfo{})
+		_, done, _ = p2c.Select(selector.WithPinnedNode(context.Background(), "127.0.0.1:8080"))
+		done(context.Background(), selector.DoneInfo{})
+		time.Sleep(time.Millisecond * 20)
+
+		var slow int
+		for i := 0; i < 100; i++ {
+			n, done, _ := p2c.Select(context.Background())
+			done(context.Background(), selector.DoneInfo{})
+			if n.Address() == "127.0.0.0:8080" {
+				slow++
+			}
+		}
+		if fp < 0 && slow != 0 {
+			t.Errorf("expect %v, got %v", 0, slow)
+		}
+		if fp > 0 && slow == 0 {
+			t.Errorf("expect the slow node to be force picked, got %v", slow)
+		}
+	}
+}
+
+func TestWithRand(t *testing.T) {
+	// prePick draws a in [0,3) and b in [0,2), b is shifted if b >= a
+	p2c := New(WithRand(&selector.SequenceRand{Ints: []int{0, 1}}), WithForcePick(-1))
+	var nodes []selector.Node
+	for i := 0; i < 3; i++ {
+		addr := fmt.Sprintf("127.0.0.%d:8080", i)
+		nodes = append(nodes, selector.NewNode("http", addr, &registry.ServiceInstance{ID: addr}))
+	}
+	p2c.Apply(nodes)
+	for i := 0; i < 10; i++ {
+		n, done, err := p2c.Select(context.Background())
+		if err != nil {
+			t.Fatalf("expect %v, got %v", nil, err)
+		}
+		done(context.Background(), selector.DoneInfo{})
+		if n.Address() == "127.0.0.1:8080" {
+			t.Errorf("expect 127.0.0.1:8080 never to be chosen, got %v", n.Address())
+		}
+	}
+}
+
+func TestWithOutlier(t *testing.T) {
+	c := &selector.OutlierConfig{ConsecutiveErrors: 1}
+	if b := NewBuilder(WithOutlier(c)).(*selector.DefaultBuilder); b.Outlier != c {
+		t.Errorf("expect the outlier config to be set, got %v", b.Outlier)
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 )
 
-type peerKey struct{}
+type (
+	peerKey    struct{}
+	attemptKey struct{}
+)
 
 // Peer contains the information of the peer for an RPC, such as the address
 // and authentication information.
@@ -23,3 +26,14 @@ func FromPeerContext(ctx context.Context) (p *Peer, ok bool) {
 	p, ok = ctx.Value(peerKey{}).(*Peer)
 	return
 }
+
+// NewAttemptContext creates a new context with the attempt index attached, it is used by retries.
+func NewAttemptContext(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// AttemptFromContext returns the attempt index in ctx, it is 0 if not set.
+func AttemptFromContext(ctx context.Context) int {
+	attempt, _ := ctx.Value(attemptKey{}).(int)
+	return attempt
+}
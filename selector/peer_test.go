@@ -22,3 +22,13 @@ func TestNotPeer(t *testing.T) {
 		t.Fatalf("test no peer found peer!")
 	}
 }
+
+func TestAttempt(t *testing.T) {
+	if attempt := AttemptFromContext(context.Background()); attempt != 0 {
+		t.Errorf("expect %v, got %v", 0, attempt)
+	}
+	ctx := NewAttemptContext(context.Background(), 2)
+	if attempt := AttemptFromContext(ctx); attempt != 2 {
+		t.Errorf("expect %v, got %v", 2, attempt)
+	}
+}
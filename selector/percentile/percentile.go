@@ -0,0 +1,169 @@
+package percentile
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/selector/node/digest"
+)
+
+const (
+	// Name is percentile balancer name
+	Name = "percentile"
+	// the latency quantile of the eligible nodes is within tolerance times the best one
+	tolerance = 1.5
+)
+
+var _ selector.Balancer = (*Balancer)(nil)
+
+// quantiler is the node tracking its latency quantile, see selector/node/digest.
+type quantiler interface {
+	Quantile() (time.Duration, bool)
+}
+
+// Option is percentile builder option.
+type Option func(o *options)
+
+// options is percentile builder options
+type options struct {
+	node      digest.Builder
+	target    time.Duration
+	tolerance float64
+	rand      selector.Rand
+}
+
+// WithQuantile with the latency quantile of the nodes, default 0.99.
+func WithQuantile(q float64) Option {
+	return func(o *options) {
+		o.node.Quantile = q
+	}
+}
+
+// WithWindow with the interval to expire the latency samples, default 30s.
+func WithWindow(d time.Duration) Option {
+	return func(o *options) {
+		o.node.Window = d
+	}
+}
+
+// WithTarget with the target latency, the nodes whose latency quantile is
+// below it are eligible. It overrides the tolerance.
+func WithTarget(d time.Duration) Option {
+	return func(o *options) {
+		o.target = d
+	}
+}
+
+// WithTolerance with the tolerance, the nodes whose latency quantile is within
+// tolerance times the best one are eligible, default 1.5.
+func WithTolerance(t float64) Option {
+	return func(o *options) {
+		o.tolerance = t
+	}
+}
+
+// WithRand with the source of randomness, e.g. a seeded or sequence rand in tests.
+func WithRand(r selector.Rand) Option {
+	return func(o *options) {
+		o.rand = r
+	}
+}
+
+// New creates a percentile selector.
+func New(opts ...Option) selector.Selector {
+	return NewBuilder(opts...).Build()
+}
+
+// Balancer is percentile balancer, it picks randomly among the nodes whose
+// latency quantile is below the target, as an alternative to ewma for the
+// workloads with heavy-tailed latencies where averages mislead.
+// The nodes without enough samples are always eligible so that they are probed.
+type Balancer struct {
+	mu        sync.Mutex
+	r         selector.Rand
+	target    time.Duration
+	tolerance float64
+}
+
+// Pick pick a node.
+func (b *Balancer) Pick(_ context.Context, nodes []selector.WeightedNode) (selector.WeightedNode, selector.DoneFunc, error) {
+	if len(nodes) == 0 {
+		return nil, nil, selector.ErrNoAvailable
+	}
+	latencies := make([]time.Duration, len(nodes))
+	var (
+		best   selector.WeightedNode
+		bestLa time.Duration
+	)
+	for i, n := range nodes {
+		latencies[i] = -1
+		q, ok := n.(quantiler)
+		if !ok {
+			continue
+		}
+		if la, ok := q.Quantile(); ok {
+			latencies[i] = la
+			if best == nil || la < bestLa {
+				best, bestLa = n, la
+			}
+		}
+	}
+	target := b.target
+	if target <= 0 {
+		target = time.Duration(float64(bestLa) * b.tolerance)
+	}
+	eligible := make([]selector.WeightedNode, 0, len(nodes))
+	for i, n := range nodes {
+		if latencies[i] < 0 || latencies[i] <= target {
+			eligible = append(eligible, n)
+		}
+	}
+	// 所有节点都超过目标延迟时，选择延迟最低的节点
+	if len(eligible) == 0 {
+		return best, best.Pick(), nil
+	}
+	b.mu.Lock()
+	selected := eligible[b.r.Intn(len(eligible))]
+	b.mu.Unlock()
+	return selected, selected.Pick(), nil
+}
+
+// NewBuilder returns a selector builder with percentile balancer
+func NewBuilder(opts ...Option) selector.Builder {
+	var option options
+	for _, opt := range opts {
+		opt(&option)
+	}
+	node := option.node
+	return &selector.DefaultBuilder{
+		Balancer: &Builder{Target: option.target, Tolerance: option.tolerance, Rand: option.rand},
+		Node:     &node,
+	}
+}
+
+// Builder is percentile builder
+type Builder struct {
+	// Target is the target latency, the nodes whose latency quantile is below it are eligible.
+	Target time.Duration
+	// Tolerance is used if the target is not set, the nodes whose latency quantile
+	// is within tolerance times the best one are eligible, default 1.5.
+	Tolerance float64
+	// Rand is the source of randomness, default is seeded with the current time.
+	Rand selector.Rand
+}
+
+// Build creates Balancer
+func (b *Builder) Build() selector.Balancer {
+	t := b.Tolerance
+	if t < 1 {
+		t = tolerance
+	}
+	r := b.Rand
+	if r == nil {
+		r = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return &Balancer{r: r, target: b.Target, tolerance: t}
+}
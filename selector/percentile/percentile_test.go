@@ -0,0 +1,81 @@
+package percentile
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+func newSelector(t *testing.T, opts ...Option) selector.Selector {
+	s := New(opts...)
+	var nodes []selector.Node
+	for i := 0; i < 3; i++ {
+		addr := fmt.Sprintf("127.0.0.%d:8080", i)
+		nodes = append(nodes, selector.NewNode("http", addr, &registry.ServiceInstance{ID: addr}))
+	}
+	s.Apply(nodes)
+	// 127.0.0.0:8080 has a heavy tail, 127.0.0.1:8080 is fast, 127.0.0.2:8080 has no samples
+	for addr, latency := range map[string]func(i int) time.Duration{
+		"127.0.0.0:8080": func(i int) time.Duration {
+			if i%10 == 0 {
+				return time.Second
+			}
+			return time.Millisecond
+		},
+		"127.0.0.1:8080": func(int) time.Duration { return time.Millisecond * 5 },
+	} {
+		for i := 0; i < 100; i++ {
+			_, done, err := s.Select(selector.WithPinnedNode(context.Background(), addr))
+			if err != nil {
+				t.Fatalf("expect %v, got %v", nil, err)
+			}
+			done(context.Background(), selector.DoneInfo{Latency: latency(i)})
+		}
+	}
+	return s
+}
+
+func TestPercentile(t *testing.T) {
+	s := newSelector(t, WithQuantile(0.95))
+	counts := map[string]int{}
+	for i := 0; i < 100; i++ {
+		n, done, err := s.Select(context.Background())
+		if err != nil {
+			t.Fatalf("expect %v, got %v", nil, err)
+		}
+		done(context.Background(), selector.DoneInfo{Latency: time.Millisecond * 5})
+		counts[n.Address()]++
+	}
+	if counts["127.0.0.0:8080"] != 0 {
+		t.Errorf("expect the heavy-tailed node not to be picked, got %v", counts["127.0.0.0:8080"])
+	}
+	if counts["127.0.0.1:8080"] == 0 || counts["127.0.0.2:8080"] == 0 {
+		t.Errorf("expect the fast and the unprobed node to be picked, got %v", counts)
+	}
+}
+
+func TestPercentileTarget(t *testing.T) {
+	s := newSelector(t, WithQuantile(0.95), WithTarget(time.Millisecond))
+	// no node is below the target, the best one is picked
+	n, _, err := s.Select(context.Background(), selector.WithNodeFilter(func(_ context.Context, nodes []selector.Node) []selector.Node {
+		return nodes[:2]
+	}))
+	if err != nil {
+		t.Fatalf("expect %v, got %v", nil, err)
+	}
+	if !reflect.DeepEqual("127.0.0.1:8080", n.Address()) {
+		t.Errorf("expect %v, got %v", "127.0.0.1:8080", n.Address())
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	b := (&Builder{}).Build()
+	if _, _, err := b.Pick(context.Background(), []selector.WeightedNode{}); err == nil {
+		t.Errorf("expect %v, got %v", selector.ErrNoAvailable, err)
+	}
+}
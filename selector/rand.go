@@ -0,0 +1,67 @@
+package selector
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+)
+
+// Rand is the source of randomness of the balancers, it must be safe for concurrent use.
+type Rand interface {
+	// Intn returns a non-negative pseudo-random number in [0,n).
+	Intn(n int) int
+	// Float64 returns a pseudo-random number in [0.0,1.0).
+	Float64() float64
+}
+
+// NewRand returns a Rand seeded with the seed, it is safe for concurrent use.
+func NewRand(seed int64) Rand {
+	return &lockedRand{r: rand.New(rand.NewSource(seed))}
+}
+
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (r *lockedRand) Intn(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.Intn(n)
+}
+
+func (r *lockedRand) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.Float64()
+}
+
+// SequenceRand is a Rand returning the values in sequence cyclically,
+// it forces specific pick sequences in balancer tests.
+type SequenceRand struct {
+	// Ints is the sequence of Intn, the value is taken modulo n.
+	Ints []int
+	// Floats is the sequence of Float64.
+	Floats []float64
+
+	i uint64
+	f uint64
+}
+
+// Intn returns the next int of the sequence modulo n, 0 if the sequence is empty.
+func (r *SequenceRand) Intn(n int) int {
+	if len(r.Ints) == 0 {
+		return 0
+	}
+	i := atomic.AddUint64(&r.i, 1) - 1
+	return r.Ints[i%uint64(len(r.Ints))] % n
+}
+
+// Float64 returns the next float of the sequence, 0 if the sequence is empty.
+func (r *SequenceRand) Float64() float64 {
+	if len(r.Floats) == 0 {
+		return 0
+	}
+	f := atomic.AddUint64(&r.f, 1) - 1
+	return r.Floats[f%uint64(len(r.Floats))]
+}
@@ -0,0 +1,36 @@
+package selector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewRand(t *testing.T) {
+	r1, r2 := NewRand(1), NewRand(1)
+	for i := 0; i < 10; i++ {
+		if a, b := r1.Intn(100), r2.Intn(100); a != b {
+			t.Errorf("expect %v, got %v", a, b)
+		}
+		if a, b := r1.Float64(), r2.Float64(); a != b {
+			t.Errorf("expect %v, got %v", a, b)
+		}
+	}
+}
+
+func TestSequenceRand(t *testing.T) {
+	r := &SequenceRand{Ints: []int{0, 1, 5}, Floats: []float64{0.5}}
+	var ints []int
+	for i := 0; i < 4; i++ {
+		ints = append(ints, r.Intn(3))
+	}
+	if !reflect.DeepEqual([]int{0, 1, 2, 0}, ints) {
+		t.Errorf("expect %v, got %v", []int{0, 1, 2, 0}, ints)
+	}
+	if r.Float64() != 0.5 {
+		t.Errorf("expect %v, got %v", 0.5, r.Float64())
+	}
+	empty := &SequenceRand{}
+	if empty.Intn(3) != 0 || empty.Float64() != 0 {
+		t.Errorf("expect zero values from the empty sequence")
+	}
+}
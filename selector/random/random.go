@@ -15,14 +15,44 @@ const (
 
 var _ selector.Balancer = (*Balancer)(nil) // Name is balancer name
 
+func init() {
+	// 按名称注册，客户端可以通过目标的selector参数选择，例如 ?selector=random
+	selector.RegisterBuilder(Name, NewBuilder())
+}
+
 // Option is random builder option.
 type Option func(o *options)
 
 // options is random builder options
-type options struct{}
+type options struct {
+	rand    selector.Rand
+	outlier *selector.OutlierConfig
+}
+
+// WithRand with the source of randomness, e.g. a seeded or sequence rand in tests.
+func WithRand(r selector.Rand) Option {
+	return func(o *options) {
+		o.rand = r
+	}
+}
+
+// WithOutlier with the passive outlier detection ejecting the failing nodes.
+func WithOutlier(c *selector.OutlierConfig) Option {
+	return func(o *options) {
+		o.outlier = c
+	}
+}
+
+// globalRand is the math/rand top-level functions.
+type globalRand struct{}
+
+func (globalRand) Intn(n int) int   { return rand.Intn(n) }
+func (globalRand) Float64() float64 { return rand.Float64() }
 
 // Balancer is a random balancer.
-type Balancer struct{}
+type Balancer struct {
+	r selector.Rand
+}
 
 // New a random selector.
 func New(opts ...Option) selector.Selector {
@@ -34,7 +64,11 @@ func (p *Balancer) Pick(_ context.Context, nodes []selector.WeightedNode) (selec
 	if len(nodes) == 0 {
 		return nil, nil, selector.ErrNoAvailable
 	}
-	cur := rand.Intn(len(nodes))
+	r := p.r
+	if r == nil {
+		r = globalRand{}
+	}
+	cur := r.Intn(len(nodes))
 	selected := nodes[cur]
 	d := selected.Pick()
 	return selected, d, nil
@@ -48,16 +82,20 @@ func NewBuilder(opts ...Option) selector.Builder {
 	}
 	return &selector.DefaultBuilder{
 		// selector 内部使用的是random负载均衡
-		Balancer: &Builder{},
+		Balancer: &Builder{Rand: option.rand},
 		// WeightedNode用到是directNode
-		Node: &direct.Builder{},
+		Node:    &direct.Builder{},
+		Outlier: option.outlier,
 	}
 }
 
 // Builder is random builder
-type Builder struct{}
+type Builder struct {
+	// Rand is the source of randomness, default is the math/rand top-level functions.
+	Rand selector.Rand
+}
 
 // Build creates Balancer
 func (b *Builder) Build() selector.Balancer {
-	return &Balancer{}
+	return &Balancer{r: b.Rand}
 }
@@ -2,6 +2,7 @@ package random
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
 	"github.com/go-kratos/kratos/v2/registry"
@@ -69,3 +70,58 @@ func TestEmpty(t *testing.T) {
 		t.Errorf("expect nil, got %v", err)
 	}
 }
+
+func TestRandomWithRand(t *testing.T) {
+	random := New(WithRand(&selector.SequenceRand{Ints: []int{1, 0, 1}}))
+	var nodes []selector.Node
+	for _, addr := range []string{"127.0.0.1:8080", "127.0.0.1:9090"} {
+		nodes = append(nodes, selector.NewNode("http", addr, &registry.ServiceInstance{ID: addr}))
+	}
+	random.Apply(nodes)
+	var got []string
+	for i := 0; i < 3; i++ {
+		n, _, err := random.Select(context.Background())
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		got = append(got, n.Address())
+	}
+	want := []string{"127.0.0.1:9090", "127.0.0.1:8080", "127.0.0.1:9090"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("expect %v, got %v", want, got)
+	}
+}
+
+func TestWithOutlier(t *testing.T) {
+	for _, b := range []selector.Builder{
+		NewBuilder(WithOutlier(&selector.OutlierConfig{ConsecutiveErrors: 1, MaxEjectionPercent: 50})),
+		NewWeightedBuilder(WithOutlier(&selector.OutlierConfig{ConsecutiveErrors: 1, MaxEjectionPercent: 50})),
+	} {
+		random := b.Build()
+		random.Apply([]selector.Node{
+			selector.NewNode("http", "127.0.0.1:8080", nil),
+			selector.NewNode("http", "127.0.0.1:9090", nil),
+		})
+		_, done, err := random.Select(selector.WithPinnedNode(context.Background(), "127.0.0.1:8080"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		done(context.Background(), selector.DoneInfo{Err: context.DeadlineExceeded})
+		for i := 0; i < 10; i++ {
+			n, done, err := random.Select(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			done(context.Background(), selector.DoneInfo{})
+			if n.Address() != "127.0.0.1:9090" {
+				t.Errorf("expect the failing node to be ejected, got %v", n.Address())
+			}
+		}
+	}
+}
+
+func TestRegisterBuilder(t *testing.T) {
+	if _, ok := selector.BuilderByName(Name); !ok {
+		t.Errorf("expect %s to be registered", Name)
+	}
+}
@@ -0,0 +1,197 @@
+package random
+
+import (
+	"context"
+	"hash/maphash"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/selector/node/direct"
+)
+
+const (
+	// WeightedName is weighted random balancer name
+	WeightedName = "weighted_random"
+	// the alias table is rebuilt periodically to catch the runtime weight changes, e.g. slow start.
+	aliasRefresh = time.Second
+	// the max number of the candidate sets cached, e.g. filtered by the node filters.
+	maxAliasTables = 16
+)
+
+var _ selector.Balancer = (*WeightedBalancer)(nil)
+
+// WeightedBalancer is a weighted random balancer, it honors the node weight,
+// and picks a node in O(1) with the alias method. The alias tables are cached
+// by the candidate set, so the different filtered sets do not rebuild each other.
+type WeightedBalancer struct {
+	// last is the table of the last pick, it is hit without the lock if the candidates
+	// are the same nodes, the slices may be reused by the selector for other candidates.
+	last   atomic.Value // *aliasTable
+	mu     sync.Mutex
+	seed   maphash.Seed
+	tables map[uint64]*aliasTable
+	r      selector.Rand
+}
+
+// aliasTable is the Vose's alias table of the candidates.
+type aliasTable struct {
+	nodes []selector.WeightedNode
+	prob  []float64
+	alias []int
+	built time.Time
+}
+
+// NewWeighted a weighted random selector.
+func NewWeighted(opts ...Option) selector.Selector {
+	return NewWeightedBuilder(opts...).Build()
+}
+
+// Pick is pick a weighted node.
+func (p *WeightedBalancer) Pick(_ context.Context, nodes []selector.WeightedNode) (selector.WeightedNode, selector.DoneFunc, error) {
+	if len(nodes) == 0 {
+		return nil, nil, selector.ErrNoAvailable
+	}
+	t := p.table(nodes)
+	r := p.r
+	if r == nil {
+		r = globalRand{}
+	}
+	i := r.Intn(len(t.nodes))
+	if r.Float64() >= t.prob[i] {
+		i = t.alias[i]
+	}
+	selected := t.nodes[i]
+	d := selected.Pick()
+	return selected, d, nil
+}
+
+// table returns the alias table of the candidates, it is rebuilt if it is stale.
+func (p *WeightedBalancer) table(nodes []selector.WeightedNode) *aliasTable {
+	now := time.Now()
+	if t, ok := p.last.Load().(*aliasTable); ok && t.fresh(now) && t.same(nodes) {
+		return t
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.tables == nil {
+		p.seed = maphash.MakeSeed()
+		p.tables = make(map[uint64]*aliasTable)
+	}
+	var h maphash.Hash
+	h.SetSeed(p.seed)
+	for _, n := range nodes {
+		_, _ = h.WriteString(n.Address())
+		_ = h.WriteByte(0)
+	}
+	key := h.Sum64()
+	t, ok := p.tables[key]
+	if !ok || !t.fresh(now) || !t.same(nodes) {
+		if !ok && len(p.tables) >= maxAliasTables {
+			// 候选集合过多时整体淘汰
+			p.tables = make(map[uint64]*aliasTable)
+		}
+		t = newAliasTable(nodes)
+		p.tables[key] = t
+	}
+	p.last.Store(t)
+	return t
+}
+
+func (t *aliasTable) fresh(now time.Time) bool {
+	return now.Sub(t.built) <= aliasRefresh
+}
+
+// same reports whether the table is built from the same candidates.
+func (t *aliasTable) same(nodes []selector.WeightedNode) bool {
+	if len(t.nodes) != len(nodes) {
+		return false
+	}
+	for i, n := range nodes {
+		if t.nodes[i] != n {
+			return false
+		}
+	}
+	return true
+}
+
+func newAliasTable(nodes []selector.WeightedNode) *aliasTable {
+	n := len(nodes)
+	t := &aliasTable{
+		nodes: make([]selector.WeightedNode, n),
+		prob:  make([]float64, n),
+		alias: make([]int, n),
+		built: time.Now(),
+	}
+	copy(t.nodes, nodes)
+	var total float64
+	weights := make([]float64, n)
+	for i, node := range nodes {
+		if w := node.Weight(); w > 0 {
+			weights[i] = w
+			total += w
+		}
+	}
+	if total == 0 {
+		// 所有节点权重都为0时，退化为随机
+		for i := range t.prob {
+			t.prob[i] = 1
+		}
+		return t
+	}
+	small := make([]int, 0, n)
+	large := make([]int, 0, n)
+	scaled := make([]float64, n)
+	for i, w := range weights {
+		scaled[i] = w * float64(n) / total
+		if scaled[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+	for len(small) > 0 && len(large) > 0 {
+		s, l := small[len(small)-1], large[len(large)-1]
+		small = small[:len(small)-1]
+		t.prob[s] = scaled[s]
+		t.alias[s] = l
+		scaled[l] = scaled[l] + scaled[s] - 1
+		if scaled[l] < 1 {
+			large = large[:len(large)-1]
+			small = append(small, l)
+		}
+	}
+	// 浮点误差导致的剩余项，概率为1
+	for _, i := range large {
+		t.prob[i] = 1
+	}
+	for _, i := range small {
+		t.prob[i] = 1
+	}
+	return t
+}
+
+// NewWeightedBuilder returns a selector builder with weighted random balancer
+func NewWeightedBuilder(opts ...Option) selector.Builder {
+	var option options
+	for _, opt := range opts {
+		opt(&option)
+	}
+	return &selector.DefaultBuilder{
+		Balancer: &WeightedBuilder{Rand: option.rand},
+		Node:     &direct.Builder{},
+		Outlier:  option.outlier,
+	}
+}
+
+// WeightedBuilder is weighted random builder
+type WeightedBuilder struct {
+	// Rand is the source of randomness, default is the math/rand top-level functions.
+	Rand selector.Rand
+}
+
+// Build creates Balancer
+func (b *WeightedBuilder) Build() selector.Balancer {
+	return &WeightedBalancer{r: b.Rand}
+}
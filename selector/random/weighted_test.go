@@ -0,0 +1,127 @@
+package random
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/selector/filter"
+	"github.com/go-kratos/kratos/v2/selector/node/direct"
+)
+
+func TestWeighted(t *testing.T) {
+	random := NewWeighted()
+	var nodes []selector.Node
+	nodes = append(nodes, selector.NewNode(
+		"http",
+		"127.0.0.1:8080",
+		&registry.ServiceInstance{
+			ID:       "127.0.0.1:8080",
+			Version:  "v2.0.0",
+			Metadata: map[string]string{"weight": "10"},
+		}))
+	nodes = append(nodes, selector.NewNode(
+		"http",
+		"127.0.0.1:9090",
+		&registry.ServiceInstance{
+			ID:       "127.0.0.1:9090",
+			Version:  "v2.0.0",
+			Metadata: map[string]string{"weight": "30"},
+		}))
+	nodes = append(nodes, selector.NewNode(
+		"http",
+		"127.0.0.1:7070",
+		&registry.ServiceInstance{
+			ID:       "127.0.0.1:7070",
+			Version:  "v1.0.0",
+			Metadata: map[string]string{"weight": "60"},
+		}))
+	random.Apply(nodes)
+	var count1, count2 int
+	for i := 0; i < 4000; i++ {
+		n, done, err := random.Select(context.Background(), selector.WithNodeFilter(filter.Version("v2.0.0")))
+		if err != nil {
+			t.Errorf("expect no error, got %v", err)
+		}
+		if done == nil {
+			t.Errorf("expect not nil, got nil")
+		}
+		if n == nil {
+			t.Errorf("expect not nil, got nil")
+		}
+		done(context.Background(), selector.DoneInfo{})
+		if n.Address() == "127.0.0.1:8080" {
+			count1++
+		} else if n.Address() == "127.0.0.1:9090" {
+			count2++
+		}
+	}
+	if count1 <= 800 || count1 >= 1200 {
+		t.Errorf("expect count1 in (800, 1200), got %d", count1)
+	}
+	if count2 <= 2800 || count2 >= 3200 {
+		t.Errorf("expect count2 in (2800, 3200), got %d", count2)
+	}
+}
+
+func TestWeightedEmpty(t *testing.T) {
+	b := &WeightedBalancer{}
+	_, _, err := b.Pick(context.Background(), []selector.WeightedNode{})
+	if err == nil {
+		t.Errorf("expect error, got %v", err)
+	}
+}
+
+func TestWeightedTableCache(t *testing.T) {
+	b := &WeightedBalancer{}
+	var nodes []selector.WeightedNode
+	for _, addr := range []string{"127.0.0.1:8080", "127.0.0.1:9090", "127.0.0.1:9091"} {
+		nodes = append(nodes, (&direct.Builder{}).Build(selector.NewNode("http", addr, nil)))
+	}
+	all := b.table(nodes)
+	if b.table(nodes) != all {
+		t.Errorf("expect the table of the same candidates to be reused")
+	}
+	// 交替的过滤结果不互相淘汰
+	a := b.table([]selector.WeightedNode{nodes[0], nodes[1]})
+	c := b.table([]selector.WeightedNode{nodes[1], nodes[2]})
+	if b.table([]selector.WeightedNode{nodes[0], nodes[1]}) != a || b.table([]selector.WeightedNode{nodes[1], nodes[2]}) != c {
+		t.Errorf("expect the tables of the filtered candidates to be cached")
+	}
+	if b.table(nodes) != all {
+		t.Errorf("expect the table of all the candidates to be kept")
+	}
+}
+
+func TestWeightedAlternateFilters(t *testing.T) {
+	random := NewWeighted()
+	var nodes []selector.Node
+	for i, addr := range []string{"127.0.0.1:8080", "127.0.0.1:8081", "127.0.0.1:9090", "127.0.0.1:9091"} {
+		version := "v1.0.0"
+		if i >= 2 {
+			version = "v2.0.0"
+		}
+		nodes = append(nodes, selector.NewNode("http", addr, &registry.ServiceInstance{
+			ID:       addr,
+			Version:  version,
+			Metadata: map[string]string{"weight": "10"},
+		}))
+	}
+	random.Apply(nodes)
+	// 过滤后的候选节点复用池中的切片，不能命中其他过滤结果的表
+	for i := 0; i < 200; i++ {
+		version := "v1.0.0"
+		if i%2 == 1 {
+			version = "v2.0.0"
+		}
+		n, done, err := random.Select(context.Background(), selector.WithNodeFilter(filter.Version(version)))
+		if err != nil {
+			t.Fatalf("expect %v, got %v", nil, err)
+		}
+		done(context.Background(), selector.DoneInfo{})
+		if n.Version() != version {
+			t.Fatalf("expect the node of %v, got %v", version, n.Version())
+		}
+	}
+}
@@ -2,6 +2,7 @@ package selector
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/errors"
 )
@@ -71,6 +72,17 @@ type DoneInfo struct {
 	BytesSent bool
 	// BytesReceived indicates if any byte has been received from the server.
 	BytesReceived bool
+	// Load is the load reported by the backend, nil if not reported.
+	Load *LoadReport
+
+	// Latency is the time elapsed from the node is picked to the RPC is done.
+	Latency time.Duration
+	// RequestSize is the number of bytes sent to the server, zero if unknown.
+	RequestSize int64
+	// ResponseSize is the number of bytes received from the server, zero if unknown.
+	ResponseSize int64
+	// Attempt is the attempt index of the RPC, starts from 0.
+	Attempt int
 }
 
 // ReplyMD is Reply Metadata.
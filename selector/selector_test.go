@@ -3,6 +3,7 @@ package selector
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/rand"
 	"reflect"
 	"sync/atomic"
@@ -289,3 +290,258 @@ func TestGlobalSelector(t *testing.T) {
 		t.Errorf("expect %v, got %v", nil, gBuilder)
 	}
 }
+
+func TestApplyIncremental(t *testing.T) {
+	builder := DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+	}
+	selector := builder.Build().(*Default)
+	nodes := []Node{
+		NewNode("http", "127.0.0.1:8080", &registry.ServiceInstance{Version: "v1.0.0"}),
+		NewNode("http", "127.0.0.1:9090", &registry.ServiceInstance{Version: "v1.0.0"}),
+	}
+	selector.Apply(nodes)
+	before := selector.loadNodes()
+
+	selector.Apply([]Node{
+		NewNode("http", "127.0.0.1:8080", &registry.ServiceInstance{Version: "v1.0.0"}),
+		NewNode("http", "127.0.0.1:9090", &registry.ServiceInstance{Version: "v2.0.0"}),
+		NewNode("http", "127.0.0.1:7070", &registry.ServiceInstance{Version: "v1.0.0"}),
+	})
+	after := selector.loadNodes()
+	if len(after) != 3 {
+		t.Fatalf("expect %v, got %v", 3, len(after))
+	}
+	if after[0] != before[0] {
+		t.Errorf("expect unchanged node to be reused")
+	}
+	if after[1] == before[1] {
+		t.Errorf("expect changed node to be rebuilt")
+	}
+	if after[1].Version() != "v2.0.0" {
+		t.Errorf("expect %v, got %v", "v2.0.0", after[1].Version())
+	}
+}
+
+func BenchmarkDefaultSelect(b *testing.B) {
+	builder := DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+	}
+	selector := builder.Build()
+	var nodes []Node
+	for i := 0; i < 100; i++ {
+		version := "v1.0.0"
+		if i%2 == 0 {
+			version = "v2.0.0"
+		}
+		nodes = append(nodes, NewNode("http", fmt.Sprintf("127.0.0.%d:8080", i), &registry.ServiceInstance{Version: version}))
+	}
+	selector.Apply(nodes)
+	b.Run("without_filters", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, done, _ := selector.Select(context.Background())
+			done(context.Background(), DoneInfo{})
+		}
+	})
+	b.Run("with_filters", func(b *testing.B) {
+		b.ReportAllocs()
+		filter := WithNodeFilter(mockFilter("v2.0.0"))
+		for i := 0; i < b.N; i++ {
+			_, done, _ := selector.Select(context.Background(), filter)
+			done(context.Background(), DoneInfo{})
+		}
+	})
+}
+
+func TestBuilderFor(t *testing.T) {
+	builder := DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+	}
+	SetGlobalSelector(&builder)
+	target := DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockMustErrorBalancerBuilder{},
+	}
+	RegisterBuilderFor("discovery:///payments", &target)
+
+	if b := BuilderFor("discovery:///payments"); b != &target {
+		t.Errorf("expect %v, got %v", &target, b)
+	}
+	if b := BuilderFor("discovery:///orders"); b != GlobalSelector() {
+		t.Errorf("expect %v, got %v", GlobalSelector(), b)
+	}
+}
+
+func TestBuilderByName(t *testing.T) {
+	builder := DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+	}
+	RegisterBuilder("mock", &builder)
+
+	if b, ok := BuilderByName("mock"); !ok || b != &builder {
+		t.Errorf("expect %v, got %v", &builder, b)
+	}
+	if _, ok := BuilderByName("none"); ok {
+		t.Errorf("expect the builder not registered")
+	}
+}
+
+func TestDefaultNodes(t *testing.T) {
+	builder := DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+	}
+	selector := builder.Build().(*Default)
+	if nodes := selector.Nodes(); len(nodes) != 0 {
+		t.Errorf("expect %v, got %v", 0, len(nodes))
+	}
+	selector.Apply([]Node{NewNode("http", "127.0.0.1:8080", nil), NewNode("http", "127.0.0.1:9090", nil)})
+	nodes := selector.Nodes()
+	if len(nodes) != 2 {
+		t.Fatalf("expect %v, got %v", 2, len(nodes))
+	}
+	nodes[0] = nil
+	if selector.Nodes()[0] == nil {
+		t.Errorf("expect the snapshot to be a copy")
+	}
+}
+
+func TestFilterFallback(t *testing.T) {
+	builder := DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+	}
+	selector := builder.Build()
+	selector.Apply([]Node{
+		NewNode("http", "127.0.0.1:8080", &registry.ServiceInstance{Version: "v1.0.0"}),
+	})
+	_, _, err := selector.Select(context.Background(), WithNodeFilter(mockFilter("v2.0.0")))
+	if !errors.Is(ErrNoAvailable, err) {
+		t.Errorf("expect %v, got %v", ErrNoAvailable, err)
+	}
+	n, _, err := selector.Select(context.Background(), WithNodeFilter(mockFilter("v2.0.0")), WithFilterFallback(true))
+	if err != nil {
+		t.Fatalf("expect %v, got %v", nil, err)
+	}
+	if n.Address() != "127.0.0.1:8080" {
+		t.Errorf("expect %v, got %v", "127.0.0.1:8080", n.Address())
+	}
+
+	// per selector
+	builder.FilterFallback = true
+	selector = builder.Build()
+	selector.Apply([]Node{
+		NewNode("http", "127.0.0.1:8080", &registry.ServiceInstance{Version: "v1.0.0"}),
+	})
+	if _, _, err = selector.Select(context.Background(), WithNodeFilter(mockFilter("v2.0.0"))); err != nil {
+		t.Errorf("expect %v, got %v", nil, err)
+	}
+	_, _, err = selector.Select(context.Background(), WithNodeFilter(mockFilter("v2.0.0")), WithFilterFallback(false))
+	if !errors.Is(ErrNoAvailable, err) {
+		t.Errorf("expect %v, got %v", ErrNoAvailable, err)
+	}
+}
+
+type mockSwappedNode struct {
+	mockWeightedNode
+}
+
+type mockSwappedNodeBuilder struct{}
+
+func (b *mockSwappedNodeBuilder) Build(n Node) WeightedNode {
+	return &mockSwappedNode{mockWeightedNode{Node: n}}
+}
+
+func TestSetBalancer(t *testing.T) {
+	builder := DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+	}
+	selector := builder.Build().(*Default)
+	selector.Apply([]Node{NewNode("http", "127.0.0.1:8080", nil)})
+	if _, _, err := selector.Select(context.Background()); err != nil {
+		t.Errorf("expect %v, got %v", nil, err)
+	}
+
+	selector.SetBalancer(&mockMustErrorBalancer{}, nil)
+	if _, _, err := selector.Select(context.Background()); !errors.Is(errNodeNotMatch, err) {
+		t.Errorf("expect %v, got %v", errNodeNotMatch, err)
+	}
+
+	selector.SetBalancer(&mockBalancer{}, &mockSwappedNodeBuilder{})
+	if _, ok := selector.Nodes()[0].(*mockSwappedNode); !ok {
+		t.Errorf("expect nodes to be rebuilt, got %T", selector.Nodes()[0])
+	}
+	selector.Apply([]Node{NewNode("http", "127.0.0.1:8080", nil), NewNode("http", "127.0.0.1:9090", nil)})
+	for _, n := range selector.Nodes() {
+		if _, ok := n.(*mockSwappedNode); !ok {
+			t.Errorf("expect nodes to be built by the new builder, got %T", n)
+		}
+	}
+	if _, _, err := selector.Select(context.Background()); err != nil {
+		t.Errorf("expect %v, got %v", nil, err)
+	}
+}
+
+// mockSwappedBalancer only picks the nodes built by the mockSwappedNodeBuilder.
+type mockSwappedBalancer struct{}
+
+func (b *mockSwappedBalancer) Pick(_ context.Context, nodes []WeightedNode) (WeightedNode, DoneFunc, error) {
+	if _, ok := nodes[0].(*mockSwappedNode); !ok {
+		return nil, nil, errNodeNotMatch
+	}
+	return nodes[0], nodes[0].Pick(), nil
+}
+
+func TestSetBalancerConcurrent(t *testing.T) {
+	builder := DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+	}
+	selector := builder.Build().(*Default)
+	selector.Apply([]Node{NewNode("http", "127.0.0.1:8080", nil)})
+	stop := make(chan struct{})
+	swapped := make(chan struct{})
+	go func() {
+		defer close(swapped)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if i%2 == 0 {
+				selector.SetBalancer(&mockSwappedBalancer{}, &mockSwappedNodeBuilder{})
+			} else {
+				selector.SetBalancer(&mockBalancer{}, &mockWeightedNodeBuilder{})
+			}
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		if _, _, err := selector.Select(context.Background()); err != nil {
+			t.Fatalf("expect the balancer to pick the nodes of its builder, got %v", err)
+		}
+	}
+	close(stop)
+	<-swapped
+}
+
+func TestWithBalancer(t *testing.T) {
+	builder := DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+	}
+	selector := builder.Build()
+	selector.Apply([]Node{NewNode("http", "127.0.0.1:8080", nil)})
+	if _, _, err := selector.Select(context.Background(), WithBalancer(&mockMustErrorBalancer{})); !errors.Is(errNodeNotMatch, err) {
+		t.Errorf("expect %v, got %v", errNodeNotMatch, err)
+	}
+	if _, _, err := selector.Select(context.Background()); err != nil {
+		t.Errorf("expect %v, got %v", nil, err)
+	}
+}
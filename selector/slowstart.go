@@ -0,0 +1,24 @@
+package selector
+
+import "time"
+
+// minimum fraction of the weight a warming up node starts with
+const slowStartMinRatio = 0.1
+
+// SlowStartRatio returns the ratio of the full weight of the node created at the unix
+// nanoseconds during the slow start window, it ramps up linearly from 0.1 to 1, and it
+// is 1 if the window is not greater than 0.
+func SlowStartRatio(created int64, window time.Duration) float64 {
+	if window <= 0 {
+		return 1
+	}
+	elapsed := time.Now().UnixNano() - created
+	if elapsed >= int64(window) {
+		return 1
+	}
+	ratio := float64(elapsed) / float64(window)
+	if ratio < slowStartMinRatio {
+		ratio = slowStartMinRatio
+	}
+	return ratio
+}
@@ -0,0 +1,22 @@
+package selector
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSlowStartRatio(t *testing.T) {
+	now := time.Now().UnixNano()
+	if r := SlowStartRatio(now, 0); r != 1 {
+		t.Errorf("expect %v, got %v", 1, r)
+	}
+	if r := SlowStartRatio(now, time.Minute); r != slowStartMinRatio {
+		t.Errorf("expect %v, got %v", slowStartMinRatio, r)
+	}
+	if r := SlowStartRatio(now-int64(time.Second*30), time.Minute); r < 0.49 || r > 0.51 {
+		t.Errorf("expect about %v, got %v", 0.5, r)
+	}
+	if r := SlowStartRatio(now-int64(time.Minute), time.Minute); r != 1 {
+		t.Errorf("expect %v, got %v", 1, r)
+	}
+}
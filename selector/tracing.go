@@ -0,0 +1,46 @@
+package selector
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traceSelect records the selection as a span event if a recording span exists in ctx.
+func traceSelect(ctx context.Context, options *SelectOptions, addr string, candidates int, start time.Time, err error) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	attrs := []attribute.KeyValue{
+		attribute.Int("selector.candidates", candidates),
+		attribute.Int64("selector.pick_latency_us", time.Since(start).Microseconds()),
+	}
+	if addr != "" {
+		attrs = append(attrs, attribute.String("selector.node", addr))
+	}
+	if len(options.NodeFilters) > 0 {
+		attrs = append(attrs, attribute.StringSlice("selector.filters", filterNames(options.NodeFilters)))
+	}
+	if err != nil {
+		attrs = append(attrs, attribute.String("selector.error", err.Error()))
+	}
+	span.AddEvent("selector.select", trace.WithAttributes(attrs...))
+}
+
+// filterNames returns the function names of the filters.
+func filterNames(filters []NodeFilter) []string {
+	names := make([]string, 0, len(filters))
+	for _, f := range filters {
+		name := "unknown"
+		if fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer()); fn != nil {
+			name = fn.Name()
+		}
+		names = append(names, name)
+	}
+	return names
+}
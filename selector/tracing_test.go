@@ -0,0 +1,51 @@
+package selector
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+func TestTraceSelect(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+	ctx, span := tp.Tracer("test").Start(context.Background(), "select")
+
+	builder := DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+	}
+	selector := builder.Build()
+	selector.Apply([]Node{NewNode("http", "127.0.0.1:8080", &registry.ServiceInstance{Version: "v1.0.0"})})
+	if _, _, err := selector.Select(ctx, WithNodeFilter(mockFilter("v1.0.0"))); err != nil {
+		t.Fatalf("expect %v, got %v", nil, err)
+	}
+	span.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expect %v, got %v", 1, len(spans))
+	}
+	events := spans[0].Events()
+	if len(events) != 1 || events[0].Name != "selector.select" {
+		t.Fatalf("expect event %v, got %v", "selector.select", events)
+	}
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range events[0].Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if v := attrs["selector.node"].AsString(); v != "127.0.0.1:8080" {
+		t.Errorf("expect %v, got %v", "127.0.0.1:8080", v)
+	}
+	if v := attrs["selector.candidates"].AsInt64(); v != 1 {
+		t.Errorf("expect %v, got %v", 1, v)
+	}
+	if v := attrs["selector.filters"].AsStringSlice(); len(v) != 1 {
+		t.Errorf("expect %v, got %v", 1, len(v))
+	}
+}
@@ -0,0 +1,65 @@
+package selector
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+)
+
+var (
+	// ErrEmptyAddress is returned by ValidateNode for the node without address.
+	ErrEmptyAddress = errors.New("selector: node address is empty")
+	// ErrDuplicateNode is reported for the node whose address is already applied.
+	ErrDuplicateNode = errors.New("selector: duplicate node address")
+)
+
+// ValidateNode is the default node validator, it rejects the nodes whose address is empty or
+// is neither a host:port nor a host, e.g. "api.example.com" of the endpoint without the port,
+// which is dialed on the default port of the scheme. The address of the nodes of the unix
+// scheme is the path of the unix socket.
+func ValidateNode(n Node) error {
+	addr := n.Address()
+	if addr == "" {
+		return ErrEmptyAddress
+	}
+	if n.Scheme() == "unix" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return nil
+	}
+	if u, err := url.Parse("//" + addr); err != nil || u.Host != addr {
+		return fmt.Errorf("selector: invalid node address %q", addr)
+	}
+	return nil
+}
+
+// validate removes the invalid nodes and the duplicate nodes with the same address,
+// the first node of an address is kept.
+func (d *Default) validate(nodes []Node) []Node {
+	validator := d.Validator
+	if validator == nil {
+		validator = ValidateNode
+	}
+	seen := make(map[string]struct{}, len(nodes))
+	valid := make([]Node, 0, len(nodes))
+	for _, n := range nodes {
+		err := validator(n)
+		if err == nil {
+			if _, ok := seen[n.Address()]; ok {
+				// 注册中心中重复的实例会使该节点的流量翻倍
+				err = ErrDuplicateNode
+			}
+		}
+		if err != nil {
+			if d.OnInvalidNode != nil {
+				d.OnInvalidNode(n, err)
+			}
+			continue
+		}
+		seen[n.Address()] = struct{}{}
+		valid = append(valid, n)
+	}
+	return valid
+}
@@ -0,0 +1,53 @@
+package selector
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestApplyValidate(t *testing.T) {
+	var rejected []error
+	builder := DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+		OnInvalidNode: func(_ Node, err error) {
+			rejected = append(rejected, err)
+		},
+	}
+	selector := builder.Build().(*Default)
+	selector.Apply([]Node{
+		NewNode("http", "127.0.0.1:8080", nil),
+		NewNode("http", "127.0.0.1:8080", nil),
+		NewNode("http", "", nil),
+		NewNode("http", "127.0.0.1 8080", nil),
+		NewNode("http", "127.0.0.1:9090", nil),
+		NewNode("https", "api.example.com", nil),
+	})
+	nodes := selector.Nodes()
+	if !reflect.DeepEqual(3, len(nodes)) {
+		t.Fatalf("expect %v, got %v", 3, len(nodes))
+	}
+	if nodes[0].Address() != "127.0.0.1:8080" || nodes[1].Address() != "127.0.0.1:9090" || nodes[2].Address() != "api.example.com" {
+		t.Errorf("expect the valid nodes, got %v %v %v", nodes[0].Address(), nodes[1].Address(), nodes[2].Address())
+	}
+	if !reflect.DeepEqual(3, len(rejected)) {
+		t.Fatalf("expect %v, got %v", 3, len(rejected))
+	}
+	if !errors.Is(rejected[0], ErrDuplicateNode) || !errors.Is(rejected[1], ErrEmptyAddress) {
+		t.Errorf("expect %v and %v, got %v", ErrDuplicateNode, ErrEmptyAddress, rejected)
+	}
+
+	// unix socket
+	selector.Apply([]Node{NewNode("unix", "/tmp/app.sock", nil), NewNode("grpc", "/tmp/app.sock", nil)})
+	if nodes = selector.Nodes(); len(nodes) != 1 || nodes[0].Scheme() != "unix" {
+		t.Errorf("expect the unix socket node, got %v", nodes)
+	}
+
+	// custom validator
+	selector.Validator = func(Node) error { return nil }
+	selector.Apply([]Node{NewNode("http", "127.0.0.1 8080", nil)})
+	if !reflect.DeepEqual(1, len(selector.Nodes())) {
+		t.Errorf("expect %v, got %v", 1, len(selector.Nodes()))
+	}
+}
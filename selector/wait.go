@@ -0,0 +1,40 @@
+package selector
+
+import (
+	"context"
+	"errors"
+)
+
+// appliedCh returns the channel closed by the next Apply.
+func (d *Default) appliedCh() <-chan struct{} {
+	d.waitMu.Lock()
+	defer d.waitMu.Unlock()
+	if d.applied == nil {
+		d.applied = make(chan struct{})
+	}
+	return d.applied
+}
+
+// notifyApplied wakes up the selections waiting for the next Apply.
+func (d *Default) notifyApplied() {
+	d.waitMu.Lock()
+	if d.applied != nil {
+		close(d.applied)
+		d.applied = nil
+	}
+	d.waitMu.Unlock()
+}
+
+// waitApplied waits for the next Apply if the selection failed with ErrNoAvailable,
+// it returns false if the ctx is done first.
+func waitApplied(ctx context.Context, ch <-chan struct{}, err error) bool {
+	if !errors.Is(err, ErrNoAvailable) {
+		return false
+	}
+	select {
+	case <-ch:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,49 @@
+package selector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitForNodes(t *testing.T) {
+	builder := DefaultBuilder{
+		Node:         &mockWeightedNodeBuilder{},
+		Balancer:     &mockBalancerBuilder{},
+		WaitForNodes: true,
+	}
+	selector := builder.Build()
+	go func() {
+		time.Sleep(time.Millisecond * 20)
+		selector.Apply([]Node{NewNode("http", "127.0.0.1:8080", nil)})
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	n, _, err := selector.Select(ctx)
+	if err != nil {
+		t.Fatalf("expect %v, got %v", nil, err)
+	}
+	if n.Address() != "127.0.0.1:8080" {
+		t.Errorf("expect %v, got %v", "127.0.0.1:8080", n.Address())
+	}
+
+	// the wait is bounded by the ctx
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+	if _, _, err = selector.Select(ctx, WithNodeFilter(mockFilter("v2.0.0"))); !errors.Is(err, ErrNoAvailable) {
+		t.Errorf("expect %v, got %v", ErrNoAvailable, err)
+	}
+	if ctx.Err() == nil {
+		t.Errorf("expect the selection to wait for the ctx")
+	}
+
+	// the option overrides the selector
+	start := time.Now()
+	if _, _, err = selector.Select(context.Background(), WithNodeFilter(mockFilter("v2.0.0")), WithWaitForNodes(false)); !errors.Is(err, ErrNoAvailable) {
+		t.Errorf("expect %v, got %v", ErrNoAvailable, err)
+	}
+	if time.Since(start) > time.Millisecond*100 {
+		t.Errorf("expect the selection not to wait, got %v", time.Since(start))
+	}
+}
@@ -3,6 +3,7 @@ package wrr
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/selector"
 	"github.com/go-kratos/kratos/v2/selector/node/direct"
@@ -15,16 +16,88 @@ const (
 
 var _ selector.Balancer = (*Balancer)(nil) // Name is balancer name
 
+func init() {
+	// 按名称注册，客户端可以通过目标的selector参数选择，例如 ?selector=wrr
+	selector.RegisterBuilder(Name, NewBuilder())
+}
+
 // Option is wrr builder option.
 type Option func(o *options)
 
 // options is wrr builder options
-type options struct{}
+type options struct {
+	slowStart  time.Duration
+	maxWeight  float64
+	minWeight  float64
+	errorDecay float64
+	recovery   float64
+	classifier selector.ErrorClassifier
+	outlier    *selector.OutlierConfig
+}
+
+// WithSlowStart with the warm-up window of new nodes.
+func WithSlowStart(d time.Duration) Option {
+	return func(o *options) {
+		o.slowStart = d
+	}
+}
+
+// WithMaxWeight with the maximum effective weight of a node, zero means unbounded.
+func WithMaxWeight(w float64) Option {
+	return func(o *options) {
+		o.maxWeight = w
+	}
+}
+
+// WithMinWeight with the minimum effective weight of a node.
+func WithMinWeight(w float64) Option {
+	return func(o *options) {
+		o.minWeight = w
+	}
+}
+
+// WithErrorDecay with the ratio in (0, 1) the effective weight is multiplied by on errors,
+// zero disables the decay.
+func WithErrorDecay(ratio float64) Option {
+	return func(o *options) {
+		o.errorDecay = ratio
+	}
+}
+
+// WithRecovery with the ratio of the full weight the effective weight recovers by on successes, default 0.1.
+func WithRecovery(ratio float64) Option {
+	return func(o *options) {
+		o.recovery = ratio
+	}
+}
+
+// WithErrorClassifier with the classifier of the errors decaying the weight,
+// default decays on the server errors, the timeouts and the network errors.
+func WithErrorClassifier(c selector.ErrorClassifier) Option {
+	return func(o *options) {
+		o.classifier = c
+	}
+}
+
+// WithOutlier with the passive outlier detection ejecting the failing nodes.
+func WithOutlier(c *selector.OutlierConfig) Option {
+	return func(o *options) {
+		o.outlier = c
+	}
+}
 
 // Balancer is a wrr balancer.
 type Balancer struct {
 	mu            sync.Mutex
 	currentWeight map[string]float64
+	// the ratio of the node weight decayed by errors, absent means 1
+	factor map[string]float64
+
+	maxWeight  float64
+	minWeight  float64
+	errorDecay float64
+	recovery   float64
+	classifier selector.ErrorClassifier
 }
 
 // New random a selector.
@@ -44,10 +117,11 @@ func (p *Balancer) Pick(_ context.Context, nodes []selector.WeightedNode) (selec
 	// nginx wrr load balancing algorithm: http://blog.csdn.net/zhangskd/article/details/50194069
 	p.mu.Lock()
 	for _, node := range nodes {
-		totalWeight += node.Weight()
+		ew := p.effectiveWeight(node)
+		totalWeight += ew
 		cwt := p.currentWeight[node.Address()]
 		// current += effectiveWeight
-		cwt += node.Weight()
+		cwt += ew
 		p.currentWeight[node.Address()] = cwt
 		if selected == nil || selectWeight < cwt {
 			selectWeight = cwt
@@ -58,9 +132,50 @@ func (p *Balancer) Pick(_ context.Context, nodes []selector.WeightedNode) (selec
 	p.mu.Unlock()
 
 	d := selected.Pick()
+	if p.errorDecay > 0 {
+		d = p.decayDone(selected.Address(), d)
+	}
 	return selected, d, nil
 }
 
+// effectiveWeight returns the decayed and clamped weight of the node.
+func (p *Balancer) effectiveWeight(node selector.WeightedNode) float64 {
+	w := node.Weight()
+	if f, ok := p.factor[node.Address()]; ok {
+		w *= f
+	}
+	if p.maxWeight > 0 && w > p.maxWeight {
+		w = p.maxWeight
+	}
+	if w < p.minWeight {
+		w = p.minWeight
+	}
+	return w
+}
+
+// decayDone decays the weight of the node on errors, and recovers it on successes.
+func (p *Balancer) decayDone(addr string, done selector.DoneFunc) selector.DoneFunc {
+	return func(ctx context.Context, di selector.DoneInfo) {
+		p.mu.Lock()
+		f, ok := p.factor[addr]
+		if !ok {
+			f = 1
+		}
+		if p.classifier.Classify(di.Err).Failed() {
+			f *= p.errorDecay
+		} else {
+			f += p.recovery
+		}
+		if f >= 1 {
+			delete(p.factor, addr)
+		} else {
+			p.factor[addr] = f
+		}
+		p.mu.Unlock()
+		done(ctx, di)
+	}
+}
+
 // NewBuilder returns a selector builder with wrr balancer
 func NewBuilder(opts ...Option) selector.Builder {
 	var option options
@@ -68,15 +183,59 @@ func NewBuilder(opts ...Option) selector.Builder {
 		opt(&option)
 	}
 	return &selector.DefaultBuilder{
-		Balancer: &Builder{},
-		Node:     &direct.Builder{},
+		Node: &direct.Builder{SlowStart: option.slowStart},
+		Balancer: &Builder{
+			MaxWeight:  option.maxWeight,
+			MinWeight:  option.minWeight,
+			ErrorDecay: option.errorDecay,
+			Recovery:   option.recovery,
+			Classifier: option.classifier,
+		},
+		Outlier: option.outlier,
 	}
 }
 
 // Builder is wrr builder
-type Builder struct{}
+type Builder struct {
+	// MaxWeight and MinWeight clamp the effective weight, zero MaxWeight means unbounded.
+	MaxWeight float64
+	MinWeight float64
+	// ErrorDecay is the ratio in (0, 1) the effective weight is multiplied by on errors, zero disables it.
+	ErrorDecay float64
+	// Recovery is the ratio of the full weight recovered on successes, default 0.1.
+	Recovery float64
+	// Classifier classifies the errors decaying the weight, default decays on the server errors,
+	// the timeouts and the network errors.
+	Classifier selector.ErrorClassifier
+}
 
 // Build creates Balancer
 func (b *Builder) Build() selector.Balancer {
-	return &Balancer{currentWeight: make(map[string]float64)}
+	recovery := b.Recovery
+	if recovery <= 0 {
+		recovery = 0.1
+	}
+	classifier := b.Classifier
+	if classifier == nil {
+		classifier = defaultClassifier
+	}
+	return &Balancer{
+		classifier:    classifier,
+		currentWeight: make(map[string]float64),
+		factor:        make(map[string]float64),
+		maxWeight:     b.MaxWeight,
+		minWeight:     b.MinWeight,
+		errorDecay:    b.ErrorDecay,
+		recovery:      recovery,
+	}
 }
+
+// defaultClassifier is the classification of the error decay before the classifiers were pluggable,
+// 429 is not decayed.
+var defaultClassifier = selector.ErrorClassifierFunc(func(err error) selector.Outcome {
+	switch selector.ErrorClass(err) {
+	case selector.ErrClassServer, selector.ErrClassTimeout, selector.ErrClassNetwork:
+		return selector.OutcomeFailure
+	}
+	return selector.OutcomeSuccess
+})
@@ -5,9 +5,11 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/selector"
 	"github.com/go-kratos/kratos/v2/selector/filter"
+	"github.com/go-kratos/kratos/v2/selector/node/direct"
 )
 
 func TestWrr(t *testing.T) {
@@ -64,3 +66,94 @@ func TestEmpty(t *testing.T) {
 		t.Errorf("expect no error, got %v", err)
 	}
 }
+
+func TestWrrClamp(t *testing.T) {
+	wrr := New(WithMaxWeight(20), WithMinWeight(10))
+	var nodes []selector.Node
+	for addr, weight := range map[string]string{"127.0.0.1:8080": "1", "127.0.0.1:9090": "100"} {
+		nodes = append(nodes, selector.NewNode(
+			"http",
+			addr,
+			&registry.ServiceInstance{
+				ID:       addr,
+				Metadata: map[string]string{"weight": weight},
+			}))
+	}
+	wrr.Apply(nodes)
+	var count1, count2 int
+	for i := 0; i < 90; i++ {
+		n, done, err := wrr.Select(context.Background())
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		done(context.Background(), selector.DoneInfo{})
+		if n.Address() == "127.0.0.1:8080" {
+			count1++
+		} else {
+			count2++
+		}
+	}
+	if !reflect.DeepEqual(count1, 30) {
+		t.Errorf("expect 30, got %d", count1)
+	}
+	if !reflect.DeepEqual(count2, 60) {
+		t.Errorf("expect 60, got %d", count2)
+	}
+}
+
+func TestWrrErrorDecay(t *testing.T) {
+	b := &Builder{ErrorDecay: 0.5, Recovery: 0.25}
+	p := b.Build().(*Balancer)
+	wn := (&direct.Builder{}).Build(selector.NewNode("http", "127.0.0.1:8080", nil))
+	_, done, err := p.Pick(context.Background(), []selector.WeightedNode{wn})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	done(context.Background(), selector.DoneInfo{Err: errors.ServiceUnavailable("", "")})
+	if !reflect.DeepEqual(float64(50), p.effectiveWeight(wn)) {
+		t.Errorf("expect %v, got %v", 50, p.effectiveWeight(wn))
+	}
+	_, done, _ = p.Pick(context.Background(), []selector.WeightedNode{wn})
+	done(context.Background(), selector.DoneInfo{Err: errors.BadRequest("", "")})
+	if !reflect.DeepEqual(float64(75), p.effectiveWeight(wn)) {
+		t.Errorf("expect %v, got %v", 75, p.effectiveWeight(wn))
+	}
+	_, done, _ = p.Pick(context.Background(), []selector.WeightedNode{wn})
+	done(context.Background(), selector.DoneInfo{})
+	if !reflect.DeepEqual(float64(100), p.effectiveWeight(wn)) {
+		t.Errorf("expect %v, got %v", 100, p.effectiveWeight(wn))
+	}
+}
+
+func TestWrrErrorDecayTooManyRequests(t *testing.T) {
+	p := (&Builder{ErrorDecay: 0.5}).Build().(*Balancer)
+	wn := (&direct.Builder{}).Build(selector.NewNode("http", "127.0.0.1:8080", nil))
+	_, done, _ := p.Pick(context.Background(), []selector.WeightedNode{wn})
+	done(context.Background(), selector.DoneInfo{Err: errors.New(429, "", "")})
+	if !reflect.DeepEqual(float64(100), p.effectiveWeight(wn)) {
+		t.Errorf("expect %v, got %v", 100, p.effectiveWeight(wn))
+	}
+}
+
+func TestWrrErrorClassifier(t *testing.T) {
+	b := NewBuilder(WithErrorDecay(0.5), WithErrorClassifier(selector.ErrorClassifierFunc(func(err error) selector.Outcome {
+		if errors.IsBadRequest(err) {
+			return selector.OutcomeFailure
+		}
+		return selector.OutcomeSuccess
+	}))).(*selector.DefaultBuilder)
+	p := b.Balancer.Build().(*Balancer)
+	wn := (&direct.Builder{}).Build(selector.NewNode("http", "127.0.0.1:8080", nil))
+	_, done, _ := p.Pick(context.Background(), []selector.WeightedNode{wn})
+	done(context.Background(), selector.DoneInfo{Err: errors.BadRequest("", "")})
+	if !reflect.DeepEqual(float64(50), p.effectiveWeight(wn)) {
+		t.Errorf("expect %v, got %v", 50, p.effectiveWeight(wn))
+	}
+}
+
+func TestWithOutlier(t *testing.T) {
+	c := &selector.OutlierConfig{ConsecutiveErrors: 1}
+	if b := NewBuilder(WithOutlier(c)).(*selector.DefaultBuilder); b.Outlier != c {
+		t.Errorf("expect the outlier config to be set, got %v", b.Outlier)
+	}
+}
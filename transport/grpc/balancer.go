@@ -1,9 +1,18 @@
 package grpc
 
 import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
 	"google.golang.org/grpc/balancer"
 	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/serviceconfig"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
 
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/selector"
@@ -15,31 +24,103 @@ const (
 )
 
 var (
-	_ base.PickerBuilder = (*balancerBuilder)(nil)
-	_ balancer.Picker    = (*balancerPicker)(nil)
+	_ base.PickerBuilder    = (*balancerBuilder)(nil)
+	_ balancer.Builder      = (*targetBalancerBuilder)(nil)
+	_ balancer.ConfigParser = (*targetBalancerBuilder)(nil)
+	_ balancer.Picker       = (*balancerPicker)(nil)
 )
 
 func init() {
+	balancer.Register(&targetBalancerBuilder{})
+}
+
+// targetBalancerBuilder builds the balancer with the selector builder registered for the dial target.
+type targetBalancerBuilder struct{}
+
+// Build creates a grpc Balancer.
+func (*targetBalancerBuilder) Build(cc balancer.ClientConn, opts balancer.BuildOptions) balancer.Balancer {
+	pb := &balancerBuilder{
+		builder: selector.BuilderFor(opts.Target.URL.String()),
+		scheme:  nodeScheme(opts.Target.URL.Scheme),
+	}
 	// 借助grpc原生的baseBalancer做封装
-	b := base.NewBalancerBuilder(
-		balancerName,
-		&balancerBuilder{
-			builder: selector.GlobalSelector(),
-		},
-		base.Config{HealthCheck: true},
-	)
-	balancer.Register(b)
+	return &targetBalancer{
+		Balancer: base.NewBalancerBuilder(
+			balancerName,
+			pb,
+			base.Config{HealthCheck: true},
+		).Build(cc, opts),
+		picker: pb,
+	}
+}
+
+// ParseConfig parses the config of the balancer in the service config.
+func (*targetBalancerBuilder) ParseConfig(js json.RawMessage) (serviceconfig.LoadBalancingConfig, error) {
+	var c lbConfig
+	if err := json.Unmarshal(js, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Name returns the name of balancer.
+func (*targetBalancerBuilder) Name() string {
+	return balancerName
+}
+
+// lbConfig is the config of the balancer in the service config.
+type lbConfig struct {
+	serviceconfig.LoadBalancingConfig `json:"-"`
+	// Warmup is the id of the warmup waiting for the ready nodes.
+	Warmup string `json:"warmup,omitempty"`
+	// Breaker enables the circuit breakers of the subconns.
+	Breaker bool `json:"breaker,omitempty"`
+	// Mirror is the id of the traffic mirroring config.
+	Mirror string `json:"mirror,omitempty"`
+}
+
+// targetBalancer reports the resolved nodes to the picker builder.
+type targetBalancer struct {
+	balancer.Balancer
+	picker *balancerBuilder
+}
+
+func (b *targetBalancer) UpdateClientConnState(s balancer.ClientConnState) error {
+	if c, ok := s.BalancerConfig.(*lbConfig); ok {
+		if c.Warmup != "" {
+			b.picker.warmup = loadWarmup(c.Warmup)
+		}
+		if c.Breaker && b.picker.breakers == nil {
+			b.picker.breakers = subConnBreakers{}
+		}
+		if c.Mirror != "" {
+			b.picker.mirror = loadMirror(c.Mirror)
+		}
+	}
+	b.picker.total = len(s.ResolverState.Addresses)
+	return b.Balancer.UpdateClientConnState(s)
 }
 
 // 在这里称为balancerBuilder，实际在grpc中，是baseBalancer中的pickerBuilder
 type balancerBuilder struct {
 	builder selector.Builder
+	scheme  string
+	// warmup 不为nil时，上报就绪的节点数
+	warmup *warmup
+	total  int
+	// breakers 不为nil时，按SubConn熔断
+	breakers subConnBreakers
+	// mirror 不为nil时，开启流量镜像
+	mirror *selector.MirrorConfig
 }
 
 // 在什么情况下，这个方法会被调用？ 应该是grpc中服务节点触发变化的时候
 
 // Build creates a grpc Picker.
 func (b *balancerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if b.warmup != nil {
+		b.warmup.update(len(info.ReadySCs), b.total)
+	}
 	if len(info.ReadySCs) == 0 {
 		// Block the RPC until a new picker is available via UpdateState().
 		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
@@ -50,12 +131,24 @@ func (b *balancerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
 	for conn, info := range info.ReadySCs {
 		ins, _ := info.Address.Attributes.Value("rawServiceInstance").(*registry.ServiceInstance)
 		nodes = append(nodes, &grpcNode{
-			Node:    selector.NewNode("grpc", info.Address.Addr, ins),
+			Node:    selector.NewNode(b.scheme, info.Address.Addr, ins),
 			subConn: conn,
 		})
 	}
-	p := &balancerPicker{
-		selector: b.builder.Build(),
+	builder := b.builder
+	p := &balancerPicker{}
+	if b.mirror != nil {
+		builder = selector.MirrorBuilder(builder, b.mirror)
+		// 影子请求直接使用镜像节点的SubConn
+		p.conns = make(map[string]balancer.SubConn, len(info.ReadySCs))
+		for conn, info := range info.ReadySCs {
+			p.conns[info.Address.Addr] = conn
+		}
+	}
+	p.selector = builder.Build()
+	if b.breakers != nil {
+		b.breakers = b.breakers.update(info.ReadySCs)
+		p.breakers = b.breakers
 	}
 	p.selector.Apply(nodes)
 	return p
@@ -64,16 +157,30 @@ func (b *balancerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
 // balancerPicker is a grpc picker.
 type balancerPicker struct {
 	selector selector.Selector
+	breakers subConnBreakers
+	// 流量镜像时，节点地址对应的SubConn
+	conns map[string]balancer.SubConn
 }
 
 // Pick pick instances.
 func (p *balancerPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	if addr, ok := shadowNode(info.Ctx); ok {
+		// 影子请求不经过selector，不计入节点的统计
+		conn, ok := p.conns[addr]
+		if !ok {
+			return balancer.PickResult{}, status.Error(codes.Unavailable, "shadow node is not available")
+		}
+		return balancer.PickResult{SubConn: conn}, nil
+	}
 	var filters []selector.NodeFilter
 	if tr, ok := transport.FromClientContext(info.Ctx); ok {
 		if gtr, ok := tr.(*Transport); ok {
 			filters = gtr.NodeFilters()
 		}
 	}
+	if p.breakers != nil {
+		filters = append(filters[:len(filters):len(filters)], p.breakers.filter)
+	}
 
 	// done 执行完成grpc请求之后，调用done方法，来做一些统计，用于计算负载吧？
 	n, done, err := p.selector.Select(info.Ctx, selector.WithNodeFilter(filters...))
@@ -81,19 +188,72 @@ func (p *balancerPicker) Pick(info balancer.PickInfo) (balancer.PickResult, erro
 		return balancer.PickResult{}, err
 	}
 
+	start := time.Now()
+	conn := n.(*grpcNode).subConn
 	return balancer.PickResult{
-		SubConn: n.(*grpcNode).subConn,
+		SubConn: conn,
 		Done: func(di balancer.DoneInfo) {
-			done(info.Ctx, selector.DoneInfo{
+			if p.breakers != nil {
+				p.breakers.mark(conn, di.Err)
+			}
+			doneInfo := selector.DoneInfo{
 				Err:           di.Err,
 				BytesSent:     di.BytesSent,
 				BytesReceived: di.BytesReceived,
 				ReplyMD:       Trailer(di.Trailer),
-			})
+				Latency:       time.Since(start),
+				Attempt:       selector.AttemptFromContext(info.Ctx),
+			}
+			if ps, ok := info.Ctx.Value(payloadSizeKey{}).(*payloadSize); ok {
+				doneInfo.RequestSize = atomic.LoadInt64(&ps.sent)
+				doneInfo.ResponseSize = atomic.LoadInt64(&ps.received)
+			}
+			// 后端在trailer中上报的ORCA负载
+			doneInfo.Load, _ = selector.LoadFromDoneInfo(doneInfo)
+			done(info.Ctx, doneInfo)
 		},
 	}, nil
 }
 
+type payloadSizeKey struct{}
+
+// payloadSize is the sizes of the messages of a call attempt.
+type payloadSize struct {
+	sent     int64
+	received int64
+}
+
+var _ stats.Handler = payloadSizeHandler{}
+
+// payloadSizeHandler records the sizes of the request and reply messages, the picker
+// reports them in the DoneInfo, since balancer.DoneInfo of grpc does not have them.
+type payloadSizeHandler struct{}
+
+func (payloadSizeHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	// 每次尝试都会调用，pick使用的是这个ctx
+	return context.WithValue(ctx, payloadSizeKey{}, &payloadSize{})
+}
+
+func (payloadSizeHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	ps, ok := ctx.Value(payloadSizeKey{}).(*payloadSize)
+	if !ok {
+		return
+	}
+	// 流式调用的收发可能在不同的goroutine
+	switch p := s.(type) {
+	case *stats.OutPayload:
+		atomic.AddInt64(&ps.sent, int64(p.Length))
+	case *stats.InPayload:
+		atomic.AddInt64(&ps.received, int64(p.Length))
+	}
+}
+
+func (payloadSizeHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (payloadSizeHandler) HandleConn(context.Context, stats.ConnStats) {}
+
 // Trailer is a grpc trailer MD.
 type Trailer metadata.MD
 
@@ -5,7 +5,9 @@ import (
 	"reflect"
 	"testing"
 
+	"google.golang.org/grpc/balancer"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
 
 	"github.com/go-kratos/kratos/v2/selector"
 )
@@ -30,3 +32,55 @@ func TestFilters(t *testing.T) {
 		t.Errorf("expect %v, got %v", 1, len(o.filters))
 	}
 }
+
+func TestTargetBalancerBuilder(t *testing.T) {
+	b := &targetBalancerBuilder{}
+	if !reflect.DeepEqual(balancerName, b.Name()) {
+		t.Errorf("expect %v, got %v", balancerName, b.Name())
+	}
+	if bl := b.Build(nil, balancer.BuildOptions{}); bl == nil {
+		t.Errorf("expect not nil, got nil")
+	}
+}
+
+type doneSelector struct {
+	done selector.DoneInfo
+}
+
+func (s *doneSelector) Apply([]selector.Node) {}
+
+func (s *doneSelector) Select(context.Context, ...selector.SelectOption) (selector.Node, selector.DoneFunc, error) {
+	n := &grpcNode{Node: selector.NewNode("grpc", "127.0.0.1:9000", nil)}
+	return n, func(_ context.Context, di selector.DoneInfo) { s.done = di }, nil
+}
+
+func TestBalancerPickerLoad(t *testing.T) {
+	s := &doneSelector{}
+	p := &balancerPicker{selector: s}
+	res, err := p.Pick(balancer.PickInfo{Ctx: context.Background()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Done(balancer.DoneInfo{Trailer: metadata.Pairs(selector.LoadReportKey, "TEXT cpu_utilization=0.4")})
+	if s.done.Load == nil || s.done.Load.CPUUtilization != 0.4 {
+		t.Errorf("expect the load reported, got %v", s.done.Load)
+	}
+}
+
+func TestBalancerPickerSize(t *testing.T) {
+	h := payloadSizeHandler{}
+	ctx := h.TagRPC(context.Background(), &stats.RPCTagInfo{})
+	h.HandleRPC(ctx, &stats.OutPayload{Length: 10})
+	h.HandleRPC(ctx, &stats.InPayload{Length: 20})
+	h.HandleRPC(ctx, &stats.InPayload{Length: 5})
+	s := &doneSelector{}
+	p := &balancerPicker{selector: s}
+	res, err := p.Pick(balancer.PickInfo{Ctx: ctx})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Done(balancer.DoneInfo{})
+	if s.done.RequestSize != 10 || s.done.ResponseSize != 25 {
+		t.Errorf("expect the sizes 10 and 25, got %v %v", s.done.RequestSize, s.done.ResponseSize)
+	}
+}
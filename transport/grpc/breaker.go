@@ -0,0 +1,73 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/go-kratos/aegis/circuitbreaker"
+	"github.com/go-kratos/aegis/circuitbreaker/sre"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+// WithSubConnBreaker with the circuit breaker of each connected node, the nodes failing
+// with the server errors are filtered out of the selector, so a single bad node does not
+// fail the calls while the healthy ones are connected. The breaker is the SRE one, the
+// tripped nodes are picked by a small probability as the probes, and recover once the
+// probes succeed. The nodes are not filtered out if all of them are tripped. It does
+// not apply to the xDS targets.
+func WithSubConnBreaker() ClientOption {
+	return func(o *clientOptions) {
+		o.breaker = true
+	}
+}
+
+// subConnBreakers holds the breakers of the ready subconns, it is not modified once
+// the picker is built.
+type subConnBreakers map[balancer.SubConn]circuitbreaker.CircuitBreaker
+
+// update returns the breakers of the ready subconns, the ones of the subconns still
+// ready are kept.
+func (bs subConnBreakers) update(ready map[balancer.SubConn]base.SubConnInfo) subConnBreakers {
+	next := make(subConnBreakers, len(ready))
+	for conn := range ready {
+		if b, ok := bs[conn]; ok {
+			next[conn] = b
+		} else {
+			next[conn] = sre.NewBreaker()
+		}
+	}
+	return next
+}
+
+// filter filters out the nodes of the tripped subconns.
+func (bs subConnBreakers) filter(_ context.Context, nodes []selector.Node) []selector.Node {
+	allowed := make([]selector.Node, 0, len(nodes))
+	for _, n := range nodes {
+		if gn, ok := n.(*grpcNode); ok {
+			if b, ok := bs[gn.subConn]; ok && b.Allow() != nil {
+				continue
+			}
+		}
+		allowed = append(allowed, n)
+	}
+	if len(allowed) == 0 {
+		return nodes
+	}
+	return allowed
+}
+
+// mark marks the result of the call on the subconn, only the server errors are failures.
+func (bs subConnBreakers) mark(conn balancer.SubConn, err error) {
+	b, ok := bs[conn]
+	if !ok {
+		return
+	}
+	if err != nil && errors.FromError(err).Code >= 500 {
+		b.MarkFailed()
+		return
+	}
+	b.MarkSuccess()
+}
@@ -0,0 +1,80 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/aegis/circuitbreaker"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+type fakeSubConn struct {
+	balancer.SubConn
+	addr string
+}
+
+type fakeBreaker struct {
+	open             bool
+	success, failure int
+}
+
+func (b *fakeBreaker) Allow() error {
+	if b.open {
+		return circuitbreaker.ErrNotAllowed
+	}
+	return nil
+}
+
+func (b *fakeBreaker) MarkSuccess() { b.success++ }
+
+func (b *fakeBreaker) MarkFailed() { b.failure++ }
+
+func TestSubConnBreakers(t *testing.T) {
+	a, b := &fakeSubConn{addr: "a"}, &fakeSubConn{addr: "b"}
+	ba, bb := &fakeBreaker{}, &fakeBreaker{}
+	bs := subConnBreakers{a: ba, b: bb}
+
+	next := bs.update(map[balancer.SubConn]base.SubConnInfo{a: {}, &fakeSubConn{addr: "c"}: {}})
+	if len(next) != 2 || next[a] != ba {
+		t.Fatalf("expect the breaker of the ready subconn kept, got %v", next)
+	}
+
+	nodes := []selector.Node{
+		&grpcNode{Node: selector.NewNode("grpc", "a", nil), subConn: a},
+		&grpcNode{Node: selector.NewNode("grpc", "b", nil), subConn: b},
+	}
+	ba.open = true
+	if got := bs.filter(context.Background(), nodes); len(got) != 1 || got[0].Address() != "b" {
+		t.Errorf("expect the tripped node filtered out, got %v", got)
+	}
+	bb.open = true
+	if got := bs.filter(context.Background(), nodes); len(got) != 2 {
+		t.Errorf("expect no node filtered out if all tripped, got %v", got)
+	}
+
+	bs.mark(a, nil)
+	bs.mark(a, status.Error(codes.NotFound, "not found"))
+	bs.mark(a, status.Error(codes.Unavailable, "unavailable"))
+	bs.mark(&fakeSubConn{addr: "c"}, nil)
+	if ba.success != 2 || ba.failure != 1 {
+		t.Errorf("expect 2 successes and 1 failure, got %d %d", ba.success, ba.failure)
+	}
+}
+
+func TestSubConnBreakerConfig(t *testing.T) {
+	o := &clientOptions{balancerName: balancerName}
+	WithSubConnBreaker()(o)
+	c, err := (&targetBalancerBuilder{}).ParseConfig([]byte(`{"breaker":true}`))
+	if err != nil || !c.(*lbConfig).Breaker {
+		t.Fatalf("expect the breaker enabled, got %v %v", c, err)
+	}
+	if sc := defaultServiceConfig(o); !strings.Contains(sc, `{"selector":{"breaker":true}}`) {
+		t.Errorf("unexpected service config %s", sc)
+	}
+}
@@ -0,0 +1,68 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// CallConfig is the defaults of the unary calls of the methods, they are overridden per
+// call by the call options, e.g. the ones appended by AppendCallOptions in the middleware,
+// and the deadline of the context shorter than the timeout.
+type CallConfig struct {
+	// Timeout overrides the timeout of the client if it is positive.
+	Timeout time.Duration
+	// WaitForReady blocks the calls until the connections are ready or the deadline is
+	// exceeded, instead of failing fast if no connection is ready.
+	WaitForReady bool
+	// CaptureHeader is called with the response header and trailer once the call returns,
+	// e.g. to record the version of the server, without the call options of each call.
+	CaptureHeader func(ctx context.Context, method string, header, trailer transport.Header)
+}
+
+// WithCallConfig with the call config of the methods, e.g. "/helloworld.Greeter/SayHello",
+// or "/helloworld.Greeter/" for all the methods of the service, all the methods if empty.
+// The config of the method overrides the one of the service.
+func WithCallConfig(c CallConfig, methods ...string) ClientOption {
+	return func(o *clientOptions) {
+		if o.callConfigs == nil {
+			o.callConfigs = make(callConfigs)
+		}
+		if len(methods) == 0 {
+			o.callConfigs[""] = c
+		}
+		for _, m := range methods {
+			o.callConfigs["/"+strings.TrimPrefix(m, "/")] = c
+		}
+	}
+}
+
+// callConfigs is the call configs by the method, the service or "" for all the methods.
+type callConfigs map[string]CallConfig
+
+// match returns the call config of the method.
+func (cs callConfigs) match(method string) (CallConfig, bool) {
+	if len(cs) == 0 {
+		return CallConfig{}, false
+	}
+	if c, ok := cs[method]; ok {
+		return c, true
+	}
+	if c, ok := cs[method[:strings.LastIndex(method, "/")+1]]; ok {
+		return c, true
+	}
+	c, ok := cs[""]
+	return c, ok
+}
+
+// callOptions returns the default call options of the config.
+func (c CallConfig) callOptions() []grpc.CallOption {
+	if c.WaitForReady {
+		return []grpc.CallOption{grpc.WaitForReady(true)}
+	}
+	return nil
+}
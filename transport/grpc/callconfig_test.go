@@ -0,0 +1,86 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	grpcmd "google.golang.org/grpc/metadata"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+func TestCallConfigMatch(t *testing.T) {
+	o := &clientOptions{}
+	WithCallConfig(CallConfig{Timeout: time.Second})(o)
+	WithCallConfig(CallConfig{Timeout: 2 * time.Second}, "helloworld.Greeter/")(o)
+	WithCallConfig(CallConfig{Timeout: 3 * time.Second}, "/helloworld.Greeter/SayHello")(o)
+	for method, want := range map[string]time.Duration{
+		"/helloworld.Greeter/SayHello":       3 * time.Second,
+		"/helloworld.Greeter/SayHelloStream": 2 * time.Second,
+		"/other.Service/Call":                time.Second,
+	} {
+		if c, ok := o.callConfigs.match(method); !ok || c.Timeout != want {
+			t.Errorf("%s: expect %v, got %v", method, want, c.Timeout)
+		}
+	}
+	if _, ok := callConfigs(nil).match("/helloworld.Greeter/SayHello"); ok {
+		t.Error("expect no config matched")
+	}
+}
+
+func TestCallConfigInterceptor(t *testing.T) {
+	var captured string
+	o := &clientOptions{}
+	WithCallConfig(CallConfig{
+		Timeout:      5 * time.Second,
+		WaitForReady: true,
+		CaptureHeader: func(_ context.Context, _ string, header, _ transport.Header) {
+			captured = header.Get("version")
+		},
+	}, "/helloworld.Greeter/SayHello")(o)
+
+	var override bool
+	m := func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if override {
+				AppendCallOptions(ctx, grpc.WaitForReady(false))
+			}
+			return handler(ctx, req)
+		}
+	}
+	f := unaryClientInterceptor([]middleware.Middleware{m}, time.Second, nil, o.callConfigs)
+	invoke := func(method string) (remaining time.Duration, waitForReady bool) {
+		err := f(context.Background(), method, &struct{}{}, &struct{}{}, &grpc.ClientConn{},
+			func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				d, _ := ctx.Deadline()
+				remaining = time.Until(d).Round(time.Second)
+				for _, opt := range opts {
+					switch o := opt.(type) {
+					case grpc.FailFastCallOption:
+						waitForReady = !o.FailFast
+					case grpc.HeaderCallOption:
+						*o.HeaderAddr = grpcmd.Pairs("version", "v1")
+					}
+				}
+				return nil
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	if d, wait := invoke("/helloworld.Greeter/SayHello"); d != 5*time.Second || !wait || captured != "v1" {
+		t.Errorf("expect the call config applied, got %v %v %q", d, wait, captured)
+	}
+	override = true
+	if _, wait := invoke("/helloworld.Greeter/SayHello"); wait {
+		t.Error("expect the wait for ready overridden by the middleware")
+	}
+	captured = ""
+	if d, wait := invoke("/helloworld.Greeter/SayHelloStream"); d != time.Second || wait || captured != "" {
+		t.Errorf("expect the defaults of the client, got %v %v %q", d, wait, captured)
+	}
+}
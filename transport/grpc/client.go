@@ -3,15 +3,20 @@ package grpc
 import (
 	"context"
 	"crypto/tls"
-	"fmt"
+	"errors"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	grpcinsecure "google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
 	grpcmd "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
 
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metadata"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/selector"
@@ -32,7 +37,9 @@ func init() {
 // ClientOption is gRPC client option.
 type ClientOption func(o *clientOptions)
 
-// WithEndpoint with client endpoint.
+// WithEndpoint with client endpoint, e.g. discovery:///helloworld, 127.0.0.1:9000,
+// static://10.0.0.1:9000,10.0.0.2:9000 balanced by the selector without the discovery,
+// or the unix socket unix:///tmp/app.sock and unix-abstract:app.
 func WithEndpoint(endpoint string) ClientOption {
 	return func(o *clientOptions) {
 		o.endpoint = endpoint
@@ -61,6 +68,15 @@ func WithMiddleware(m ...middleware.Middleware) ClientOption {
 	}
 }
 
+// WithStreamMiddleware with client middleware of the streaming calls, the handler
+// returns once the stream is established, so the middleware observing the messages
+// or the end of the stream should wrap the stream.
+func WithStreamMiddleware(m ...middleware.StreamMiddleware) ClientOption {
+	return func(o *clientOptions) {
+		o.streamMws = m
+	}
+}
+
 // WithDiscovery with client discovery.
 func WithDiscovery(d registry.Discovery) ClientOption {
 	return func(o *clientOptions) {
@@ -123,12 +139,25 @@ type clientOptions struct {
 	timeout                time.Duration
 	discovery              registry.Discovery
 	middleware             []middleware.Middleware
+	streamMws              []middleware.StreamMiddleware
 	ints                   []grpc.UnaryClientInterceptor
 	streamInts             []grpc.StreamClientInterceptor
 	grpcOpts               []grpc.DialOption
 	balancerName           string
 	filters                []selector.NodeFilter
 	printDiscoveryDebugLog bool
+	methodConfigs          []methodConfig
+	keepalive              *keepalive.ClientParameters
+	xds                    bool
+	xdsResolver            resolver.Builder
+	warmup                 *warmup
+	compressor             string
+	compressMin            int
+	limits                 []grpc.DialOption
+	deadlineMargin         time.Duration
+	breaker                bool
+	callConfigs            callConfigs
+	mirror                 *mirror
 }
 
 // Dial returns a GRPC connection.
@@ -151,13 +180,27 @@ func dial(ctx context.Context, insecure bool, opts ...ClientOption) (*grpc.Clien
 	for _, o := range opts {
 		o(&options)
 	}
+	if options.xds && len(options.methodConfigs) > 0 {
+		// xDS的service config由控制面下发，默认的service config不生效
+		return nil, errors.New("grpc: WithRetryPolicy does not apply to the xDS targets, configure the retry policy by the control plane")
+	}
 	ints := []grpc.UnaryClientInterceptor{
-		unaryClientInterceptor(options.middleware, options.timeout, options.filters),
+		unaryClientInterceptor(options.middleware, options.timeout, options.filters, options.callConfigs),
 	}
 	sints := []grpc.StreamClientInterceptor{
-		streamClientInterceptor(options.filters),
+		streamClientInterceptor(options.streamMws, options.filters),
 	}
 
+	if options.compressMin > 0 {
+		ints = append(ints, compressionInterceptor(options.compressMin))
+	}
+	if options.deadlineMargin > 0 {
+		ints = append(ints, deadlineInterceptor(options.deadlineMargin))
+	}
+	if options.mirror != nil && !options.xds {
+		options.mirror.register()
+		ints = append(ints, mirrorInterceptor())
+	}
 	if len(options.ints) > 0 {
 		ints = append(ints, options.ints...)
 	}
@@ -165,13 +208,28 @@ func dial(ctx context.Context, insecure bool, opts ...ClientOption) (*grpc.Clien
 		sints = append(sints, options.streamInts...)
 	}
 	grpcOpts := []grpc.DialOption{
-		// 指定 负载均衡器
-		grpc.WithDefaultServiceConfig(fmt.Sprintf(`{"loadBalancingConfig": [{"%s":{}}],"healthCheckConfig":{"serviceName":""}}`, options.balancerName)),
 		grpc.WithChainUnaryInterceptor(ints...),
 		grpc.WithChainStreamInterceptor(sints...),
 	}
 
-	if options.discovery != nil {
+	if options.warmup != nil && !options.xds {
+		options.warmup.register()
+	}
+	endpoint := options.endpoint
+	if options.xds {
+		// 服务发现和负载均衡由xDS控制面下发
+		endpoint = xdsTarget(endpoint)
+		if options.xdsResolver != nil {
+			grpcOpts = append(grpcOpts, grpc.WithResolvers(options.xdsResolver))
+		}
+	} else {
+		// 指定 负载均衡器
+		grpcOpts = append(grpcOpts,
+			grpc.WithDefaultServiceConfig(defaultServiceConfig(&options)),
+			grpc.WithStatsHandler(payloadSizeHandler{}),
+		)
+	}
+	if options.discovery != nil && !options.xds {
 		// 指定服务发现
 		grpcOpts = append(grpcOpts,
 			grpc.WithResolvers(
@@ -188,28 +246,47 @@ func dial(ctx context.Context, insecure bool, opts ...ClientOption) (*grpc.Clien
 	if options.tlsConf != nil {
 		grpcOpts = append(grpcOpts, grpc.WithTransportCredentials(credentials.NewTLS(options.tlsConf)))
 	}
+	if options.keepalive != nil {
+		grpcOpts = append(grpcOpts, grpc.WithKeepaliveParams(*options.keepalive))
+	}
+	if options.compressor != "" {
+		grpcOpts = append(grpcOpts, grpc.WithDefaultCallOptions(grpc.UseCompressor(options.compressor)))
+	}
+	if len(options.limits) > 0 {
+		grpcOpts = append(grpcOpts, options.limits...)
+	}
 	if len(options.grpcOpts) > 0 {
 		grpcOpts = append(grpcOpts, options.grpcOpts...)
 	}
 	// 真实连接gRPC
-	return grpc.DialContext(ctx, options.endpoint, grpcOpts...)
+	conn, err := grpc.DialContext(ctx, endpoint, grpcOpts...)
+	if err != nil || options.warmup == nil || options.xds {
+		return conn, err
+	}
+	return conn, options.warmup.wait(ctx, conn)
 }
 
-func unaryClientInterceptor(ms []middleware.Middleware, timeout time.Duration, filters []selector.NodeFilter) grpc.UnaryClientInterceptor {
+func unaryClientInterceptor(ms []middleware.Middleware, timeout time.Duration, filters []selector.NodeFilter, configs callConfigs) grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 		ctx = transport.NewClientContext(ctx, &Transport{
 			endpoint:    cc.Target(),
 			operation:   method,
-			reqHeader:   headerCarrier{},
+			reqHeader:   requestHeader(ctx),
 			nodeFilters: filters,
 		})
+		timeout := timeout
+		config, ok := configs.match(method)
+		if ok && config.Timeout > 0 {
+			timeout = config.Timeout
+		}
 		if timeout > 0 {
 			var cancel context.CancelFunc
 			ctx, cancel = context.WithTimeout(ctx, timeout)
 			defer cancel()
 		}
 		h := func(ctx context.Context, req interface{}) (interface{}, error) {
-			if tr, ok := transport.FromClientContext(ctx); ok {
+			tr, ok := transport.FromClientContext(ctx)
+			if ok {
 				header := tr.RequestHeader()
 				keys := header.Keys()
 				keyvals := make([]string, 0, len(keys))
@@ -218,7 +295,23 @@ func unaryClientInterceptor(ms []middleware.Middleware, timeout time.Duration, f
 				}
 				ctx = grpcmd.AppendToOutgoingContext(ctx, keyvals...)
 			}
-			return reply, invoker(ctx, method, req, reply, cc, opts...)
+			gtr, ok := tr.(*Transport)
+			if !ok {
+				return reply, invoker(ctx, method, req, reply, cc, opts...)
+			}
+			// 响应的header和trailer回填到transport，中间件在调用返回后可读
+			// 默认的调用选项在前，调用时指定的选项覆盖它们
+			var header, trailer grpcmd.MD
+			callOpts := append(config.callOptions(), opts...)
+			callOpts = append(callOpts, gtr.callOpts...)
+			callOpts = append(callOpts, grpc.Header(&header), grpc.Trailer(&trailer))
+			err := invoker(ctx, method, req, reply, cc, callOpts...)
+			gtr.replyHeader = headerCarrier(header)
+			gtr.replyTrailer = headerCarrier(trailer)
+			if config.CaptureHeader != nil {
+				config.CaptureHeader(ctx, method, gtr.replyHeader, gtr.replyTrailer)
+			}
+			return reply, err
 		}
 		if len(ms) > 0 {
 			h = middleware.Chain(ms...)(h)
@@ -230,7 +323,19 @@ func unaryClientInterceptor(ms []middleware.Middleware, timeout time.Duration, f
 	}
 }
 
-func streamClientInterceptor(filters []selector.NodeFilter) grpc.StreamClientInterceptor {
+// requestHeader returns the request header carrying the request id and the baggage of ctx.
+func requestHeader(ctx context.Context) headerCarrier {
+	header := headerCarrier{}
+	if id, ok := transport.FromRequestIDContext(ctx); ok {
+		header.Set(transport.RequestIDHeader, id)
+	}
+	if b := metadata.InjectBaggage(ctx); b != "" {
+		header.Set(metadata.BaggageHeader, b)
+	}
+	return header
+}
+
+func streamClientInterceptor(ms []middleware.StreamMiddleware, filters []selector.NodeFilter) grpc.StreamClientInterceptor {
 	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) { // nolint
 		ctx = transport.NewClientContext(ctx, &Transport{
 			endpoint:    cc.Target(),
@@ -238,8 +343,67 @@ func streamClientInterceptor(filters []selector.NodeFilter) grpc.StreamClientInt
 			reqHeader:   headerCarrier{},
 			nodeFilters: filters,
 		})
+		for k, vs := range requestHeader(ctx) {
+			ctx = grpcmd.AppendToOutgoingContext(ctx, k, vs[0])
+		}
 		var p selector.Peer
 		ctx = selector.NewPeerContext(ctx, &p)
-		return streamer(ctx, desc, cc, method, opts...)
+		if len(ms) == 0 {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+		// 中间件包裹的stream在建立之后才可用
+		pending := &pendingStream{ctx: ctx}
+		var cs grpc.ClientStream
+		h := func(ctx context.Context, stream middleware.Stream) error {
+			if tr, ok := transport.FromClientContext(ctx); ok {
+				if tr, ok := tr.(*Transport); ok {
+					opts = append(opts[:len(opts):len(opts)], tr.callOpts...)
+				}
+			}
+			s, err := streamer(ctx, desc, cc, method, opts...)
+			if err != nil {
+				return err
+			}
+			pending.ClientStream = s
+			cs = &clientStream{ClientStream: s, stream: stream}
+			return nil
+		}
+		if err := middleware.ChainStream(ms...)(h)(ctx, pending); err != nil {
+			return nil, err
+		}
+		if cs == nil {
+			return nil, errStreamNotEstablished
+		}
+		return cs, nil
 	}
 }
+
+// errStreamNotEstablished is returned if the stream middleware returns without calling the handler.
+var errStreamNotEstablished = status.Error(codes.Internal, "grpc: the stream is not established by the middleware")
+
+// pendingStream is the client stream passed to the middleware before it is established.
+type pendingStream struct {
+	grpc.ClientStream
+	ctx context.Context
+}
+
+func (s *pendingStream) Context() context.Context {
+	if s.ClientStream == nil {
+		return s.ctx
+	}
+	return s.ClientStream.Context()
+}
+
+// clientStream is the client stream with the messages going through the stream of the middleware.
+type clientStream struct {
+	grpc.ClientStream
+	stream middleware.Stream
+}
+
+func (s *clientStream) SendMsg(m interface{}) error {
+	return s.stream.SendMsg(m)
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	return s.stream.RecvMsg(m)
+}
@@ -4,13 +4,19 @@ import (
 	"context"
 	"crypto/tls"
 	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"google.golang.org/grpc"
+	grpcmd "google.golang.org/grpc/metadata"
 
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/metadata"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport"
 )
 
 func TestWithEndpoint(t *testing.T) {
@@ -79,7 +85,7 @@ func EmptyMiddleware() middleware.Middleware {
 }
 
 func TestUnaryClientInterceptor(t *testing.T) {
-	f := unaryClientInterceptor([]middleware.Middleware{EmptyMiddleware()}, time.Duration(100), nil)
+	f := unaryClientInterceptor([]middleware.Middleware{EmptyMiddleware()}, time.Duration(100), nil, nil)
 	req := &struct{}{}
 	resp := &struct{}{}
 
@@ -92,6 +98,33 @@ func TestUnaryClientInterceptor(t *testing.T) {
 	}
 }
 
+func TestUnaryClientInterceptorPropagation(t *testing.T) {
+	f := unaryClientInterceptor(nil, 0, nil, nil)
+	ctx := transport.NewRequestIDContext(context.Background(), "id")
+	ctx, _ = metadata.WithBaggage(ctx, "tenant", "kratos")
+	var got, baggage string
+	err := f(ctx, "hello", &struct{}{}, &struct{}{}, &grpc.ClientConn{},
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			md, _ := grpcmd.FromOutgoingContext(ctx)
+			if vals := md.Get(transport.RequestIDHeader); len(vals) > 0 {
+				got = vals[0]
+			}
+			if vals := md.Get(metadata.BaggageHeader); len(vals) > 0 {
+				baggage = vals[0]
+			}
+			return nil
+		})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if got != "id" {
+		t.Errorf("expect %v, got %v", "id", got)
+	}
+	if baggage != "tenant=kratos" {
+		t.Errorf("expect %v, got %v", "tenant=kratos", baggage)
+	}
+}
+
 func TestWithUnaryInterceptor(t *testing.T) {
 	o := &clientOptions{}
 	v := []grpc.UnaryClientInterceptor{
@@ -143,3 +176,46 @@ func TestDialConn(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestStaticTarget(t *testing.T) {
+	hits := make([]int32, 2)
+	var addrs []string
+	for i := range hits {
+		i := i
+		srv := NewServer(Middleware(func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				atomic.AddInt32(&hits[i], 1)
+				return handler(ctx, req)
+			}
+		}))
+		pb.RegisterGreeterServer(srv, &server{})
+		u, err := srv.Endpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			_ = srv.Start(context.Background())
+		}()
+		defer func() {
+			_ = srv.Stop(context.Background())
+		}()
+		addrs = append(addrs, u.Host)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := DialInsecure(ctx, WithEndpoint("static://"+strings.Join(addrs, ",")), WithWarmup(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := pb.NewGreeterClient(conn)
+	for i := 0; i < 20; i++ {
+		if _, err = client.SayHello(ctx, &pb.HelloRequest{Name: "kratos"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if atomic.LoadInt32(&hits[0]) == 0 || atomic.LoadInt32(&hits[1]) == 0 {
+		t.Errorf("expect both the nodes called, got %v", hits)
+	}
+}
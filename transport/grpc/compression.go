@@ -0,0 +1,73 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/protobuf/proto"
+
+	// init gzip compressor
+	_ "google.golang.org/grpc/encoding/gzip"
+)
+
+// Compressors with the compressors registered, e.g. the zstd one, the server decompresses
+// the requests of them and compresses the responses by the same compressor as the request.
+// The gzip compressor is registered by default. The compressors are registered globally,
+// so the option should be used at the initialization.
+func Compressors(cs ...encoding.Compressor) ServerOption {
+	return func(s *Server) {
+		for _, c := range cs {
+			encoding.RegisterCompressor(c)
+		}
+	}
+}
+
+// WithCompressors with the compressors registered, like the server option Compressors.
+func WithCompressors(cs ...encoding.Compressor) ClientOption {
+	return func(o *clientOptions) {
+		for _, c := range cs {
+			encoding.RegisterCompressor(c)
+		}
+	}
+}
+
+// WithCompressor with the name of the compressor of the requests, e.g. "gzip", the
+// server replies by the same compressor. It is overridden per call by grpc.UseCompressor,
+// e.g. by AppendCallOptions in the client middleware.
+func WithCompressor(name string) ClientOption {
+	return func(o *clientOptions) {
+		o.compressor = name
+	}
+}
+
+// WithCompressionThreshold with the min size of the request messages compressed, the
+// smaller ones are sent uncompressed since the compression costs more than it saves.
+// It applies to the unary calls.
+func WithCompressionThreshold(size int) ClientOption {
+	return func(o *clientOptions) {
+		o.compressMin = size
+	}
+}
+
+// compressionInterceptor sends the requests smaller than the threshold uncompressed.
+func compressionInterceptor(threshold int) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if m, ok := req.(proto.Message); ok && compressed(opts) && proto.Size(m) < threshold {
+			opts = append(opts[:len(opts):len(opts)], grpc.UseCompressor(encoding.Identity))
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// compressed reports whether the call is compressed by the call options, the last
+// compressor of them applies.
+func compressed(opts []grpc.CallOption) bool {
+	name := ""
+	for _, o := range opts {
+		if c, ok := o.(grpc.CompressorCallOption); ok {
+			name = c.CompressorType
+		}
+	}
+	return name != "" && name != encoding.Identity
+}
@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+)
+
+// countCompressor counts the compressed and decompressed messages of the gzip compressor.
+type countCompressor struct {
+	encoding.Compressor
+	compressed, decompressed int32
+}
+
+func (c *countCompressor) Name() string { return "test-gzip" }
+
+func (c *countCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	atomic.AddInt32(&c.compressed, 1)
+	return c.Compressor.Compress(w)
+}
+
+func (c *countCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	atomic.AddInt32(&c.decompressed, 1)
+	return c.Compressor.Decompress(r)
+}
+
+func TestCompressed(t *testing.T) {
+	tests := []struct {
+		opts []grpc.CallOption
+		want bool
+	}{
+		{nil, false},
+		{[]grpc.CallOption{grpc.UseCompressor("gzip")}, true},
+		{[]grpc.CallOption{grpc.UseCompressor("gzip"), grpc.UseCompressor(encoding.Identity)}, false},
+		{[]grpc.CallOption{grpc.UseCompressor(encoding.Identity), grpc.UseCompressor("gzip")}, true},
+	}
+	for i, tt := range tests {
+		if got := compressed(tt.opts); got != tt.want {
+			t.Errorf("%d: expect %v, got %v", i, tt.want, got)
+		}
+	}
+}
+
+func TestCompression(t *testing.T) {
+	c := &countCompressor{Compressor: encoding.GetCompressor("gzip")}
+	srv := NewServer(Compressors(c))
+	pb.RegisterGreeterServer(srv, &server{})
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	defer func() {
+		_ = srv.Stop(context.Background())
+	}()
+
+	conn, err := DialInsecure(context.Background(),
+		WithEndpoint(u.Host),
+		WithOptions(grpc.WithBlock()),
+		WithCompressors(c),
+		WithCompressor(c.Name()),
+		WithCompressionThreshold(64),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := pb.NewGreeterClient(conn)
+
+	if _, err = client.SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&c.compressed); n != 0 {
+		t.Errorf("expect the small request uncompressed, got %d", n)
+	}
+	name := strings.Repeat("kratos", 20)
+	reply, err := client.SayHello(context.Background(), &pb.HelloRequest{Name: name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Message != "Hello "+name {
+		t.Errorf("unexpected reply %q", reply.Message)
+	}
+	// 请求和响应都被压缩
+	if atomic.LoadInt32(&c.compressed) != 2 || atomic.LoadInt32(&c.decompressed) != 2 {
+		t.Errorf("expect the request and the reply compressed, got %d/%d", c.compressed, c.decompressed)
+	}
+}
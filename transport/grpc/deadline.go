@@ -0,0 +1,31 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// WithDeadlineMargin with the margin subtracted from the deadline of the unary calls,
+// leaving the time for the client to handle the reply or the timeout error. The deadline
+// is propagated to the server by the grpc-timeout header, e.g. the remaining one of the
+// request served by transport/http, instead of re-applying the full default timeout.
+func WithDeadlineMargin(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.deadlineMargin = d
+	}
+}
+
+// deadlineInterceptor shortens the deadline of the calls by the margin, the margin is not
+// applied if the remaining time is less than it.
+func deadlineInterceptor(margin time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) > margin {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithDeadline(ctx, deadline.Add(-margin))
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
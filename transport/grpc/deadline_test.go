@@ -0,0 +1,40 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func TestDeadlineInterceptor(t *testing.T) {
+	var remaining time.Duration
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		remaining = 0
+		if d, ok := ctx.Deadline(); ok {
+			remaining = time.Until(d).Round(time.Second)
+		}
+		return nil
+	}
+	i := deadlineInterceptor(time.Second)
+	for timeout, want := range map[time.Duration]time.Duration{0: 0, 500 * time.Millisecond: time.Second / 2, 5 * time.Second: 4 * time.Second} {
+		ctx := context.Background()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		_ = i(ctx, "/foo", nil, nil, nil, invoker)
+		if timeout == 500*time.Millisecond {
+			// 剩余时间小于margin时不缩短
+			if remaining > time.Second {
+				t.Errorf("%v: unexpected deadline %v", timeout, remaining)
+			}
+			continue
+		}
+		if remaining != want {
+			t.Errorf("%v: expected %v got %v", timeout, want, remaining)
+		}
+	}
+}
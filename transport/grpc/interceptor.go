@@ -2,11 +2,13 @@ package grpc
 
 import (
 	"context"
+	"strings"
 
 	"google.golang.org/grpc"
 	grpcmd "google.golang.org/grpc/metadata"
 
 	ic "github.com/go-kratos/kratos/v2/internal/context"
+	"github.com/go-kratos/kratos/v2/metadata"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
 )
@@ -17,15 +19,20 @@ func (s *Server) unaryServerInterceptor() grpc.UnaryServerInterceptor {
 		ctx, cancel := ic.Merge(ctx, s.baseCtx)
 		defer cancel()
 		md, _ := grpcmd.FromIncomingContext(ctx)
-		replyHeader := grpcmd.MD{}
+		ctx = extractBaggage(ctx, md)
+		replyHeader, replyTrailer := grpcmd.MD{}, grpcmd.MD{}
 		tr := &Transport{
-			operation:   info.FullMethod,
-			reqHeader:   headerCarrier(md),
-			replyHeader: headerCarrier(replyHeader),
+			operation:    info.FullMethod,
+			reqHeader:    headerCarrier(md),
+			replyHeader:  headerCarrier(replyHeader),
+			replyTrailer: headerCarrier(replyTrailer),
 		}
 		if s.endpoint != nil {
 			tr.endpoint = s.endpoint.String()
 		}
+		if id, ok := s.peerIdentity(ctx); ok {
+			ctx = transport.NewIdentityContext(ctx, id)
+		}
 		ctx = transport.NewServerContext(ctx, tr)
 		if s.timeout > 0 {
 			ctx, cancel = context.WithTimeout(ctx, s.timeout)
@@ -41,10 +48,21 @@ func (s *Server) unaryServerInterceptor() grpc.UnaryServerInterceptor {
 		if len(replyHeader) > 0 {
 			_ = grpc.SetHeader(ctx, replyHeader)
 		}
+		if len(replyTrailer) > 0 {
+			_ = grpc.SetTrailer(ctx, replyTrailer)
+		}
 		return reply, err
 	}
 }
 
+// extractBaggage returns a new context with the baggage of the incoming metadata.
+func extractBaggage(ctx context.Context, md grpcmd.MD) context.Context {
+	if vals := md.Get(metadata.BaggageHeader); len(vals) > 0 {
+		return metadata.ExtractBaggage(ctx, strings.Join(vals, ","))
+	}
+	return ctx
+}
+
 // wrappedStream is rewrite grpc stream's context
 type wrappedStream struct {
 	grpc.ServerStream
@@ -68,20 +86,53 @@ func (s *Server) streamServerInterceptor() grpc.StreamServerInterceptor {
 		ctx, cancel := ic.Merge(ss.Context(), s.baseCtx)
 		defer cancel()
 		md, _ := grpcmd.FromIncomingContext(ctx)
-		replyHeader := grpcmd.MD{}
+		ctx = extractBaggage(ctx, md)
+		if id, ok := s.peerIdentity(ctx); ok {
+			ctx = transport.NewIdentityContext(ctx, id)
+		}
+		replyHeader, replyTrailer := grpcmd.MD{}, grpcmd.MD{}
 		ctx = transport.NewServerContext(ctx, &Transport{
-			endpoint:    s.endpoint.String(),
-			operation:   info.FullMethod,
-			reqHeader:   headerCarrier(md),
-			replyHeader: headerCarrier(replyHeader),
+			endpoint:     s.endpoint.String(),
+			operation:    info.FullMethod,
+			reqHeader:    headerCarrier(md),
+			replyHeader:  headerCarrier(replyHeader),
+			replyTrailer: headerCarrier(replyTrailer),
 		})
 
-		ws := NewWrappedStream(ctx, ss)
-
-		err := handler(srv, ws)
+		var err error
+		if len(s.streamMws) > 0 {
+			h := func(ctx context.Context, stream middleware.Stream) error {
+				return handler(srv, &serverStream{ServerStream: ss, ctx: ctx, stream: stream})
+			}
+			err = middleware.ChainStream(s.streamMws...)(h)(ctx, NewWrappedStream(ctx, ss))
+		} else {
+			err = handler(srv, NewWrappedStream(ctx, ss))
+		}
 		if len(replyHeader) > 0 {
 			_ = grpc.SetHeader(ctx, replyHeader)
 		}
+		if len(replyTrailer) > 0 {
+			ss.SetTrailer(replyTrailer)
+		}
 		return err
 	}
 }
+
+// serverStream is the server stream with the context and the stream of the middleware.
+type serverStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	stream middleware.Stream
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *serverStream) SendMsg(m interface{}) error {
+	return s.stream.SendMsg(m)
+}
+
+func (s *serverStream) RecvMsg(m interface{}) error {
+	return s.stream.RecvMsg(m)
+}
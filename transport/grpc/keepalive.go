@@ -0,0 +1,47 @@
+package grpc
+
+import (
+	"time"
+
+	"google.golang.org/grpc/keepalive"
+)
+
+// KeepaliveParams with the keepalive parameters of the server, e.g. the max idle time and
+// the ping interval of the connections. It overrides the MaxConnectionAge set before it.
+func KeepaliveParams(p keepalive.ServerParameters) ServerOption {
+	return func(s *Server) {
+		s.keepalive = &p
+	}
+}
+
+// KeepaliveEnforcementPolicy with the keepalive enforcement policy of the server, the
+// connections of the clients pinging more often than MinTime are closed by GOAWAY,
+// default MinTime is 5 minutes.
+func KeepaliveEnforcementPolicy(p keepalive.EnforcementPolicy) ServerOption {
+	return func(s *Server) {
+		s.enforcement = &p
+	}
+}
+
+// MaxConnectionAge with the max age of the connections, they are closed by GOAWAY after
+// the age with a jitter of +/-10%, and the in-flight calls are given the grace to complete.
+// It rebalances the long-lived connections behind the L4 load balancers during the rolling
+// restarts and the scaling.
+func MaxConnectionAge(age, grace time.Duration) ServerOption {
+	return func(s *Server) {
+		if s.keepalive == nil {
+			s.keepalive = &keepalive.ServerParameters{}
+		}
+		s.keepalive.MaxConnectionAge = age
+		s.keepalive.MaxConnectionAgeGrace = grace
+	}
+}
+
+// WithKeepaliveParams with the keepalive parameters of the client, it pings the idle
+// connections to detect the broken ones. The Time should not be less than the MinTime
+// of the KeepaliveEnforcementPolicy of the servers, or the connections are closed.
+func WithKeepaliveParams(p keepalive.ClientParameters) ClientOption {
+	return func(o *clientOptions) {
+		o.keepalive = &p
+	}
+}
@@ -0,0 +1,75 @@
+package grpc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+)
+
+func TestKeepaliveOptions(t *testing.T) {
+	s := &Server{}
+	MaxConnectionAge(time.Minute, 10*time.Second)(s)
+	if s.keepalive.MaxConnectionAge != time.Minute || s.keepalive.MaxConnectionAgeGrace != 10*time.Second {
+		t.Errorf("unexpected keepalive: %+v", s.keepalive)
+	}
+	p := keepalive.ServerParameters{MaxConnectionIdle: time.Minute}
+	KeepaliveParams(p)(s)
+	MaxConnectionAge(time.Hour, time.Second)(s)
+	want := keepalive.ServerParameters{MaxConnectionIdle: time.Minute, MaxConnectionAge: time.Hour, MaxConnectionAgeGrace: time.Second}
+	if !reflect.DeepEqual(*s.keepalive, want) {
+		t.Errorf("expect %+v, got %+v", want, *s.keepalive)
+	}
+	ep := keepalive.EnforcementPolicy{MinTime: time.Second, PermitWithoutStream: true}
+	KeepaliveEnforcementPolicy(ep)(s)
+	if !reflect.DeepEqual(*s.enforcement, ep) {
+		t.Errorf("expect %+v, got %+v", ep, *s.enforcement)
+	}
+
+	o := &clientOptions{}
+	cp := keepalive.ClientParameters{Time: 10 * time.Second, Timeout: time.Second}
+	WithKeepaliveParams(cp)(o)
+	if !reflect.DeepEqual(*o.keepalive, cp) {
+		t.Errorf("expect %+v, got %+v", cp, *o.keepalive)
+	}
+}
+
+func TestMaxConnectionAge(t *testing.T) {
+	srv := NewServer(
+		MaxConnectionAge(100*time.Millisecond, time.Second),
+		KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{MinTime: 10 * time.Second, PermitWithoutStream: true}),
+	)
+	pb.RegisterGreeterServer(srv, &server{})
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	defer func() {
+		_ = srv.Stop(context.Background())
+	}()
+	conn, err := DialInsecure(context.Background(),
+		WithEndpoint(u.Host),
+		WithOptions(grpc.WithBlock()),
+		WithKeepaliveParams(keepalive.ClientParameters{Time: 10 * time.Second, PermitWithoutStream: true}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := pb.NewGreeterClient(conn)
+	// 连接到期后重连，调用不受影响
+	for i := 0; i < 3; i++ {
+		if _, err = client.SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"}, grpc.WaitForReady(true)); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(150 * time.Millisecond)
+	}
+}
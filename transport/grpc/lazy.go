@@ -0,0 +1,99 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// lazyService is the service registered by RegisterLazy, the calls are dispatched to
+// the current implementation.
+type lazyService struct {
+	desc        *grpc.ServiceDesc
+	constructor func(context.Context) (interface{}, error)
+	impl        atomic.Value
+}
+
+// RegisterLazy registers the service of the desc, the implementation is created by the
+// constructor when the server is started, e.g. after the config and the DB are ready,
+// instead of before kratos.New. The server fails to start with the error of the
+// constructor. The constructor is not called if the implementation is replaced before.
+func (s *Server) RegisterLazy(desc *grpc.ServiceDesc, constructor func(context.Context) (interface{}, error)) {
+	ls := &lazyService{desc: desc, constructor: constructor}
+	sd := *desc
+	sd.Methods = make([]grpc.MethodDesc, len(desc.Methods))
+	for i, m := range desc.Methods {
+		handler := m.Handler
+		m.Handler = func(_ interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			impl, err := ls.load()
+			if err != nil {
+				return nil, err
+			}
+			return handler(impl, ctx, dec, interceptor)
+		}
+		sd.Methods[i] = m
+	}
+	sd.Streams = make([]grpc.StreamDesc, len(desc.Streams))
+	for i, st := range desc.Streams {
+		handler := st.Handler
+		st.Handler = func(_ interface{}, stream grpc.ServerStream) error {
+			impl, err := ls.load()
+			if err != nil {
+				return err
+			}
+			return handler(impl, stream)
+		}
+		sd.Streams[i] = st
+	}
+	// 实现在启动时才创建，注册时不做类型检查
+	s.Server.RegisterService(&sd, nil)
+	s.lazy = append(s.lazy, ls)
+}
+
+// Replace replaces the implementation of the service registered by RegisterLazy, e.g.
+// by a mock in the tests, it applies to the subsequent calls.
+func (s *Server) Replace(serviceName string, impl interface{}) error {
+	for _, ls := range s.lazy {
+		if ls.desc.ServiceName != serviceName {
+			continue
+		}
+		if v := reflect.ValueOf(impl); !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+			return fmt.Errorf("grpc: the implementation of service %q is nil", serviceName)
+		}
+		if ht := reflect.TypeOf(ls.desc.HandlerType).Elem(); !reflect.TypeOf(impl).Implements(ht) {
+			return fmt.Errorf("grpc: the implementation of type %T does not satisfy %v", impl, ht)
+		}
+		ls.impl.Store(&impl)
+		return nil
+	}
+	return fmt.Errorf("grpc: service %q is not registered lazily", serviceName)
+}
+
+// constructLazy creates the implementations of the lazy services not replaced.
+func (s *Server) constructLazy(ctx context.Context) error {
+	for _, ls := range s.lazy {
+		if ls.impl.Load() != nil {
+			continue
+		}
+		impl, err := ls.constructor(ctx)
+		if err != nil {
+			return fmt.Errorf("grpc: construct service %q: %w", ls.desc.ServiceName, err)
+		}
+		if err = s.Replace(ls.desc.ServiceName, impl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ls *lazyService) load() (interface{}, error) {
+	if p, ok := ls.impl.Load().(*interface{}); ok {
+		return *p, nil
+	}
+	return nil, status.Errorf(codes.Unavailable, "grpc: service %q is not constructed", ls.desc.ServiceName)
+}
@@ -0,0 +1,81 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+)
+
+type mockGreeter struct {
+	server
+}
+
+func (mockGreeter) SayHello(context.Context, *pb.HelloRequest) (*pb.HelloReply, error) {
+	return &pb.HelloReply{Message: "mock"}, nil
+}
+
+func TestRegisterLazy(t *testing.T) {
+	srv := NewServer()
+	constructed := false
+	srv.RegisterLazy(&pb.Greeter_ServiceDesc, func(context.Context) (interface{}, error) {
+		constructed = true
+		return &server{}, nil
+	})
+	if err := srv.Replace("unknown", &server{}); err == nil {
+		t.Error("expect the error of the unknown service")
+	}
+	if err := srv.Replace(pb.Greeter_ServiceDesc.ServiceName, struct{}{}); err == nil {
+		t.Error("expect the error of the implementation type")
+	}
+	if err := srv.Replace(pb.Greeter_ServiceDesc.ServiceName, nil); err == nil {
+		t.Error("expect the error of the nil implementation")
+	}
+	if err := srv.Replace(pb.Greeter_ServiceDesc.ServiceName, (*server)(nil)); err == nil {
+		t.Error("expect the error of the nil implementation")
+	}
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	defer func() {
+		_ = srv.Stop(context.Background())
+	}()
+	conn, err := DialInsecure(context.Background(), WithEndpoint(u.Host), WithOptions(grpc.WithBlock()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := pb.NewGreeterClient(conn)
+
+	reply, err := client.SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !constructed || reply.Message != "Hello kratos" {
+		t.Errorf("expect the constructed implementation, got %s", reply.Message)
+	}
+	if err = srv.Replace(pb.Greeter_ServiceDesc.ServiceName, &mockGreeter{}); err != nil {
+		t.Fatal(err)
+	}
+	if reply, err = client.SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"}); err != nil || reply.Message != "mock" {
+		t.Errorf("expect the replaced implementation, got %v %v", reply, err)
+	}
+}
+
+func TestRegisterLazyError(t *testing.T) {
+	srv := NewServer()
+	want := errors.New("db not ready")
+	srv.RegisterLazy(&pb.Greeter_ServiceDesc, func(context.Context) (interface{}, error) {
+		return nil, want
+	})
+	if err := srv.Start(context.Background()); !errors.Is(err, want) {
+		t.Errorf("expect %v, got %v", want, err)
+	}
+}
@@ -0,0 +1,86 @@
+package grpc
+
+import (
+	"google.golang.org/grpc"
+)
+
+// MaxRecvMsgSize with the max size of the messages received by the server, default is
+// 4MB, the larger requests fail with ResourceExhausted.
+func MaxRecvMsgSize(n int) ServerOption {
+	return func(s *Server) {
+		s.limits = append(s.limits, grpc.MaxRecvMsgSize(n))
+	}
+}
+
+// MaxSendMsgSize with the max size of the messages sent by the server, default is
+// math.MaxInt32, the larger replies fail with ResourceExhausted.
+func MaxSendMsgSize(n int) ServerOption {
+	return func(s *Server) {
+		s.limits = append(s.limits, grpc.MaxSendMsgSize(n))
+	}
+}
+
+// MaxHeaderListSize with the max size of the header list accepted by the server,
+// default is 16MB.
+func MaxHeaderListSize(n uint32) ServerOption {
+	return func(s *Server) {
+		s.limits = append(s.limits, grpc.MaxHeaderListSize(n))
+	}
+}
+
+// InitialWindowSize with the initial flow control window of the streams, default is
+// 64KB, a larger window speeds up the large messages on the high latency networks.
+// The window is dynamic by the BDP estimation if it is less than 64KB.
+func InitialWindowSize(n int32) ServerOption {
+	return func(s *Server) {
+		s.limits = append(s.limits, grpc.InitialWindowSize(n))
+	}
+}
+
+// InitialConnWindowSize with the initial flow control window of the connections,
+// default is 64KB, the window is dynamic by the BDP estimation if it is less than 64KB.
+func InitialConnWindowSize(n int32) ServerOption {
+	return func(s *Server) {
+		s.limits = append(s.limits, grpc.InitialConnWindowSize(n))
+	}
+}
+
+// WithMaxRecvMsgSize with the max size of the messages received by the client, default
+// is 4MB, the larger replies fail with ResourceExhausted.
+func WithMaxRecvMsgSize(n int) ClientOption {
+	return func(o *clientOptions) {
+		o.limits = append(o.limits, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(n)))
+	}
+}
+
+// WithMaxSendMsgSize with the max size of the messages sent by the client, default is
+// math.MaxInt32, the larger requests fail with ResourceExhausted.
+func WithMaxSendMsgSize(n int) ClientOption {
+	return func(o *clientOptions) {
+		o.limits = append(o.limits, grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(n)))
+	}
+}
+
+// WithMaxHeaderListSize with the max size of the header list accepted by the client,
+// default is 16MB.
+func WithMaxHeaderListSize(n uint32) ClientOption {
+	return func(o *clientOptions) {
+		o.limits = append(o.limits, grpc.WithMaxHeaderListSize(n))
+	}
+}
+
+// WithInitialWindowSize with the initial flow control window of the streams, like the
+// server option InitialWindowSize.
+func WithInitialWindowSize(n int32) ClientOption {
+	return func(o *clientOptions) {
+		o.limits = append(o.limits, grpc.WithInitialWindowSize(n))
+	}
+}
+
+// WithInitialConnWindowSize with the initial flow control window of the connections,
+// like the server option InitialConnWindowSize.
+func WithInitialConnWindowSize(n int32) ClientOption {
+	return func(o *clientOptions) {
+		o.limits = append(o.limits, grpc.WithInitialConnWindowSize(n))
+	}
+}
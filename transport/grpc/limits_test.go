@@ -0,0 +1,59 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+)
+
+func TestMsgSizeLimits(t *testing.T) {
+	const size = 5 << 20
+	srv := NewServer(MaxRecvMsgSize(2*size), MaxSendMsgSize(2*size), InitialWindowSize(1<<20), InitialConnWindowSize(1<<20))
+	pb.RegisterGreeterServer(srv, &server{})
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	defer func() {
+		_ = srv.Stop(context.Background())
+	}()
+
+	name := strings.Repeat("k", size)
+	dial := func(opts ...ClientOption) pb.GreeterClient {
+		opts = append(opts, WithEndpoint(u.Host), WithOptions(grpc.WithBlock()))
+		conn, err := DialInsecure(context.Background(), opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+		return pb.NewGreeterClient(conn)
+	}
+
+	// 默认4MB的限制
+	_, err = dial().SayHello(context.Background(), &pb.HelloRequest{Name: name})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expect %v, got %v", codes.ResourceExhausted, err)
+	}
+
+	client := dial(WithMaxRecvMsgSize(2*size), WithMaxSendMsgSize(2*size), WithMaxHeaderListSize(1<<20), WithInitialWindowSize(1<<20), WithInitialConnWindowSize(1<<20))
+	reply, err := client.SayHello(context.Background(), &pb.HelloRequest{Name: name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.Message) != len("Hello ")+size {
+		t.Errorf("unexpected reply size %d", len(reply.Message))
+	}
+	_, err = dial(WithMaxSendMsgSize(size/2)).SayHello(context.Background(), &pb.HelloRequest{Name: name})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expect %v, got %v", codes.ResourceExhausted, err)
+	}
+}
@@ -0,0 +1,96 @@
+package grpc
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	icontext "github.com/go-kratos/kratos/v2/internal/context"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+// 流量镜像的配置，以service config中的id关联到balancer
+var (
+	mirrors  sync.Map
+	mirrorID int64
+)
+
+// WithMirror with client traffic mirroring, a fraction of the unary calls is teed to a
+// shadow node selected by the config, e.g. a new version for dark launch. The shadow
+// calls are sent in the background with the timeout of the call, their replies are
+// discarded and they are excluded from the node statistics. It applies to the selectors
+// built by selector.DefaultBuilder, e.g. the builtin ones, and does not apply to the
+// xDS targets.
+func WithMirror(c *selector.MirrorConfig) ClientOption {
+	return func(o *clientOptions) {
+		o.mirror = &mirror{config: c}
+	}
+}
+
+// mirror is the traffic mirroring of a connection.
+type mirror struct {
+	id     string
+	config *selector.MirrorConfig
+}
+
+// register registers the mirror for the balancer by the id, the balancer may be
+// rebuilt during the lifetime of the connection, so it is never unregistered.
+func (m *mirror) register() {
+	m.id = strconv.FormatInt(atomic.AddInt64(&mirrorID, 1), 10)
+	mirrors.Store(m.id, m.config)
+}
+
+// loadMirror returns the mirror config of the id.
+func loadMirror(id string) *selector.MirrorConfig {
+	if c, ok := mirrors.Load(id); ok {
+		return c.(*selector.MirrorConfig)
+	}
+	return nil
+}
+
+type shadowKey struct{}
+
+// shadowNode returns the address of the shadow node of the call if it is a shadow one.
+func shadowNode(ctx context.Context) (string, bool) {
+	addr, ok := ctx.Value(shadowKey{}).(string)
+	return addr, ok
+}
+
+// mirrorInterceptor sends a copy of the call to the shadow node selected by the picker.
+func mirrorInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		var timeout time.Duration
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout = time.Until(deadline)
+		}
+		var m selector.Mirror
+		err := invoker(selector.NewMirrorContext(ctx, &m), method, req, reply, cc, opts...)
+		if m.Node == nil {
+			return err
+		}
+		in, ok := req.(proto.Message)
+		if !ok {
+			return err
+		}
+		// 调用返回后请求可能被调用方修改，复制一份给影子请求
+		in = proto.Clone(in)
+		out := reflect.New(reflect.TypeOf(reply).Elem()).Interface()
+		// 影子请求不随原请求取消，调用选项可能引用原请求的变量，不传递
+		sctx := context.WithValue(icontext.WithoutCancel(ctx), shadowKey{}, m.Node.Address())
+		cancel := context.CancelFunc(func() {})
+		if timeout > 0 {
+			sctx, cancel = context.WithTimeout(sctx, timeout)
+		}
+		go func() {
+			defer cancel()
+			_ = invoker(sctx, method, in, out, cc)
+		}()
+		return err
+	}
+}
@@ -0,0 +1,70 @@
+package grpc
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+func TestWithMirror(t *testing.T) {
+	var hits, names int64
+	count := func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if r, ok := req.(*pb.HelloRequest); ok && r.Name == "kratos" {
+			atomic.AddInt64(&names, 1)
+		}
+		atomic.AddInt64(&hits, 1)
+		return handler(ctx, req)
+	}
+	var addrs []string
+	for i := 0; i < 2; i++ {
+		srv := NewServer(UnaryInterceptor(count))
+		pb.RegisterGreeterServer(srv, &server{})
+		u, err := srv.Endpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			_ = srv.Start(context.Background())
+		}()
+		defer func() {
+			_ = srv.Stop(context.Background())
+		}()
+		addrs = append(addrs, u.Host)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := DialInsecure(ctx,
+		WithEndpoint("direct:///"+addrs[0]+","+addrs[1]),
+		WithWarmup(2),
+		WithMirror(&selector.MirrorConfig{Percent: 100}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for i := 0; i < 3; i++ {
+		req := &pb.HelloRequest{Name: "kratos"}
+		if _, err = pb.NewGreeterClient(conn).SayHello(ctx, req); err != nil {
+			t.Fatal(err)
+		}
+		// 调用返回后修改请求不影响影子请求
+		req.Name = ""
+	}
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt64(&hits) < 6 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 10)
+	}
+	if n := atomic.LoadInt64(&hits); n != 6 {
+		t.Errorf("expect every call to be mirrored, got %d calls", n)
+	}
+	if n := atomic.LoadInt64(&names); n != 6 {
+		t.Errorf("expect the request to be copied to the shadow node, got %d requests", n)
+	}
+}
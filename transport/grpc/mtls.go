@@ -0,0 +1,79 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// ClientCAs with the CAs verifying the client certificates, the clients are
+// required to present a valid certificate unless ClientAuth is set. It takes
+// effect with TLSConfig.
+func ClientCAs(pool *x509.CertPool) ServerOption {
+	return func(s *Server) {
+		s.clientCAs = pool
+	}
+}
+
+// ClientAuth with the policy of the client certificates,
+// default is tls.RequireAndVerifyClientCert if ClientCAs or VerifyClientCert is set.
+func ClientAuth(t tls.ClientAuthType) ServerOption {
+	return func(s *Server) {
+		s.clientAuth = t
+	}
+}
+
+// VerifyClientCert with the verifier of the client certificates, it is called in
+// the handshake with the identity of the client, the handshake fails if it returns
+// an error, e.g. the SPIFFE ID is not trusted.
+func VerifyClientCert(f func(transport.Identity) error) ServerOption {
+	return func(s *Server) {
+		s.verifyPeer = f
+	}
+}
+
+// mtlsConfig returns the TLS config verifying the client certificates.
+func (s *Server) mtlsConfig() *tls.Config {
+	if s.tlsConf == nil || (s.clientCAs == nil && s.verifyPeer == nil && s.clientAuth == tls.NoClientCert) {
+		return s.tlsConf
+	}
+	c := s.tlsConf.Clone()
+	if s.clientCAs != nil {
+		c.ClientCAs = s.clientCAs
+	}
+	c.ClientAuth = s.clientAuth
+	if c.ClientAuth == tls.NoClientCert {
+		c.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	if verify := s.verifyPeer; verify != nil {
+		c.VerifyConnection = func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return nil
+			}
+			return verify(transport.NewIdentity(cs.PeerCertificates[0]))
+		}
+	}
+	return c
+}
+
+// peerIdentity returns the identity of the verified client certificate of the call.
+func (s *Server) peerIdentity(ctx context.Context) (transport.Identity, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return transport.Identity{}, false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.PeerCertificates) == 0 {
+		return transport.Identity{}, false
+	}
+	// 未经CA或自定义校验的证书不可信
+	if len(info.State.VerifiedChains) == 0 && s.verifyPeer == nil {
+		return transport.Identity{}, false
+	}
+	return transport.NewIdentity(info.State.PeerCertificates[0]), true
+}
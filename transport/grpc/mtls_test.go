@@ -0,0 +1,147 @@
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func (c testCert) tls() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key, Leaf: c.cert}
+}
+
+func newTestCert(t *testing.T, tmpl *x509.Certificate, parent *testCert) testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl.SerialNumber = big.NewInt(time.Now().UnixNano())
+	tmpl.NotBefore = time.Now().Add(-time.Hour)
+	tmpl.NotAfter = time.Now().Add(time.Hour)
+	parentCert, parentKey := tmpl, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parentCert, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testCert{cert: cert, key: key}
+}
+
+func TestMTLS(t *testing.T) {
+	ca := newTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "ca"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+	serverCert := newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "server"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, &ca)
+	spiffeID, _ := url.Parse("spiffe://example.org/ns/default/sa/api")
+	clientCert := newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "client"},
+		URIs:        []*url.URL{spiffeID},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, &ca)
+	untrusted := newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "untrusted"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, nil)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	var identity transport.Identity
+	srv := NewServer(
+		Address("127.0.0.1:0"),
+		TLSConfig(&tls.Config{Certificates: []tls.Certificate{serverCert.tls()}}),
+		ClientCAs(pool),
+		VerifyClientCert(func(id transport.Identity) error {
+			if id.SPIFFEID != spiffeID.String() {
+				return errors.New("untrusted spiffe id")
+			}
+			return nil
+		}),
+		Middleware(func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				identity, _ = transport.FromIdentityContext(ctx)
+				return handler(ctx, req)
+			}
+		}),
+	)
+	pb.RegisterGreeterServer(srv, &server{})
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	defer func() {
+		_ = srv.Stop(context.Background())
+	}()
+
+	call := func(certs ...tls.Certificate) error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		conn, err := Dial(ctx, WithEndpoint(u.Host), WithTLSConfig(&tls.Config{RootCAs: pool, Certificates: certs}))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		_, err = pb.NewGreeterClient(conn).SayHello(ctx, &pb.HelloRequest{Name: "kratos"})
+		return err
+	}
+	if err = call(clientCert.tls()); err != nil {
+		t.Fatal(err)
+	}
+	if identity.SPIFFEID != spiffeID.String() || identity.CommonName != "client" {
+		t.Errorf("unexpected identity: %+v", identity)
+	}
+	if err = call(); err == nil {
+		t.Errorf("expect error without client certificate")
+	}
+	if err = call(untrusted.tls()); err == nil {
+		t.Errorf("expect error with untrusted client certificate")
+	}
+}
+
+func TestMTLSConfig(t *testing.T) {
+	if c := NewServer(ClientCAs(x509.NewCertPool())).mtlsConfig(); c != nil {
+		t.Errorf("expect nil without TLSConfig, got %v", c)
+	}
+	conf := &tls.Config{}
+	srv := NewServer(TLSConfig(conf), ClientAuth(tls.VerifyClientCertIfGiven))
+	if c := srv.mtlsConfig(); c.ClientAuth != tls.VerifyClientCertIfGiven {
+		t.Errorf("expect %v, got %v", tls.VerifyClientCertIfGiven, c.ClientAuth)
+	}
+	if conf.ClientAuth != tls.NoClientCert {
+		t.Errorf("expect the original config not to be modified")
+	}
+}
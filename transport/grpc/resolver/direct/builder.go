@@ -8,21 +8,41 @@ import (
 
 func init() {
 	resolver.Register(NewBuilder())
+	resolver.Register(NewStaticBuilder())
 }
 
-type directBuilder struct{}
+type directBuilder struct {
+	scheme string
+}
 
 // NewBuilder creates a directBuilder which is used to factory direct resolvers.
 // example:
 //
 //	direct://<authority>/127.0.0.1:9000,127.0.0.2:9000
 func NewBuilder() resolver.Builder {
-	return &directBuilder{}
+	return &directBuilder{scheme: "direct"}
+}
+
+// NewStaticBuilder creates a directBuilder of the static targets, the addresses are
+// balanced by the kratos selector without the discovery.
+// example:
+//
+//	static://127.0.0.1:9000,127.0.0.2:9000
+func NewStaticBuilder() resolver.Builder {
+	return &directBuilder{scheme: "static"}
 }
 
 func (d *directBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	// 地址列表在path中，或者static://后的authority中
+	list := strings.TrimPrefix(target.URL.Path, "/")
+	if list == "" {
+		list = target.URL.Host
+	}
 	addrs := make([]resolver.Address, 0)
-	for _, addr := range strings.Split(strings.TrimPrefix(target.URL.Path, "/"), ",") {
+	for _, addr := range strings.Split(list, ",") {
+		if addr == "" {
+			continue
+		}
 		addrs = append(addrs, resolver.Address{Addr: addr})
 	}
 	err := cc.UpdateState(resolver.State{
@@ -35,5 +55,5 @@ func (d *directBuilder) Build(target resolver.Target, cc resolver.ClientConn, _
 }
 
 func (d *directBuilder) Scheme() string {
-	return "direct"
+	return d.scheme
 }
@@ -2,6 +2,7 @@ package direct
 
 import (
 	"errors"
+	"net/url"
 	"reflect"
 	"testing"
 
@@ -14,16 +15,21 @@ func TestDirectBuilder_Scheme(t *testing.T) {
 	if !reflect.DeepEqual(b.Scheme(), "direct") {
 		t.Errorf("expect %v, got %v", "direct", b.Scheme())
 	}
+	if s := NewStaticBuilder().Scheme(); s != "static" {
+		t.Errorf("expect %v, got %v", "static", s)
+	}
 }
 
 type mockConn struct {
 	needUpdateStateErr bool
+	state              resolver.State
 }
 
-func (m *mockConn) UpdateState(resolver.State) error {
+func (m *mockConn) UpdateState(s resolver.State) error {
 	if m.needUpdateStateErr {
 		return errors.New("mock test needUpdateStateErr")
 	}
+	m.state = s
 	return nil
 }
 
@@ -52,3 +58,20 @@ func TestDirectBuilder_Build(t *testing.T) {
 		t.Errorf("expect needUpdateStateErr, got nil")
 	}
 }
+
+func TestStaticBuilder_Build(t *testing.T) {
+	for _, target := range []string{"static://127.0.0.1:9000,127.0.0.2:9000", "static:///127.0.0.1:9000,127.0.0.2:9000", "direct://authority/127.0.0.1:9000,127.0.0.2:9000"} {
+		u, err := url.Parse(target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cc := &mockConn{}
+		if _, err = NewStaticBuilder().Build(resolver.Target{URL: *u}, cc, resolver.BuildOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		want := []resolver.Address{{Addr: "127.0.0.1:9000"}, {Addr: "127.0.0.2:9000"}}
+		if !reflect.DeepEqual(cc.state.Addresses, want) {
+			t.Errorf("%s: expect %v, got %v", target, want, cc.state.Addresses)
+		}
+	}
+}
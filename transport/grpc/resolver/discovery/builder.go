@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"google.golang.org/grpc/resolver"
 
+	"github.com/go-kratos/kratos/v2/metrics"
 	"github.com/go-kratos/kratos/v2/registry"
 )
 
@@ -53,22 +54,42 @@ func PrintDebugLog(p bool) Option {
 	}
 }
 
+// WithResolveInterval with the min interval of the re-resolutions requested by grpc-go
+// once the connections are lost, default is 1s, the requests within it are coalesced.
+func WithResolveInterval(d time.Duration) Option {
+	return func(b *builder) {
+		b.resolveInterval = d
+	}
+}
+
+// WithResolveCounter with the counter of the re-resolutions, labeled by the service
+// name and the result: requested, throttled, resolved or error, e.g. for alerting on
+// the re-resolution storms.
+func WithResolveCounter(c metrics.Counter) Option {
+	return func(b *builder) {
+		b.resolveCounter = c
+	}
+}
+
 type builder struct {
-	discoverer registry.Discovery
-	timeout    time.Duration
-	insecure   bool
-	subsetSize int
-	debugLog   bool
+	discoverer      registry.Discovery
+	timeout         time.Duration
+	insecure        bool
+	subsetSize      int
+	debugLog        bool
+	resolveInterval time.Duration
+	resolveCounter  metrics.Counter
 }
 
 // NewBuilder creates a builder which is used to factory registry resolvers.
 func NewBuilder(d registry.Discovery, opts ...Option) resolver.Builder {
 	b := &builder{
-		discoverer: d,
-		timeout:    time.Second * 10,
-		insecure:   false,
-		debugLog:   true,
-		subsetSize: 25,
+		discoverer:      d,
+		timeout:         time.Second * 10,
+		insecure:        false,
+		debugLog:        true,
+		subsetSize:      25,
+		resolveInterval: time.Second,
 	}
 	for _, o := range opts {
 		o(b)
@@ -84,8 +105,9 @@ func (b *builder) Build(target resolver.Target, cc resolver.ClientConn, _ resolv
 
 	done := make(chan struct{}, 1)
 	ctx, cancel := context.WithCancel(context.Background())
+	serviceName := strings.TrimPrefix(target.URL.Path, "/")
 	go func() {
-		w, err := b.discoverer.Watch(ctx, strings.TrimPrefix(target.URL.Path, "/"))
+		w, err := b.discoverer.Watch(ctx, serviceName)
 		watchRes.w = w
 		watchRes.err = err
 		close(done)
@@ -112,8 +134,15 @@ func (b *builder) Build(target resolver.Target, cc resolver.ClientConn, _ resolv
 		debugLog:    b.debugLog,
 		subsetSize:  b.subsetSize,
 		selecterKey: uuid.New().String(),
+		discoverer:  b.discoverer,
+		serviceName: serviceName,
+		resolveNow:  make(chan struct{}, 1),
+		minInterval: b.resolveInterval,
+		timeout:     b.timeout,
+		counter:     b.resolveCounter,
 	}
 	go r.watch()
+	go r.resolve()
 	return r, nil
 }
 
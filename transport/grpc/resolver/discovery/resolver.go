@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"sync"
 	"time"
 
 	"google.golang.org/grpc/attributes"
@@ -12,6 +13,7 @@ import (
 	"github.com/go-kratos/aegis/subset"
 	"github.com/go-kratos/kratos/v2/internal/endpoint"
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
 	"github.com/go-kratos/kratos/v2/registry"
 )
 
@@ -26,6 +28,15 @@ type discoveryResolver struct {
 	debugLog    bool
 	selecterKey string
 	subsetSize  int
+
+	// 连接断开（如GOAWAY）时grpc-go调用ResolveNow，从注册中心重新获取节点
+	discoverer  registry.Discovery
+	serviceName string
+	resolveNow  chan struct{}
+	minInterval time.Duration
+	timeout     time.Duration
+	counter     metrics.Counter
+	mu          sync.Mutex
 }
 
 func (r *discoveryResolver) watch() {
@@ -50,7 +61,47 @@ func (r *discoveryResolver) watch() {
 	}
 }
 
+// resolve fetches the instances on the ResolveNow requests, at most once per the min
+// interval, so the storms of the requests in the rolling restarts are coalesced.
+func (r *discoveryResolver) resolve() {
+	var last time.Time
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-r.resolveNow:
+		}
+		if wait := r.minInterval - time.Since(last); wait > 0 {
+			r.count("throttled")
+			select {
+			case <-r.ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		}
+		last = time.Now()
+		ctx, cancel := context.WithTimeout(r.ctx, r.timeout)
+		ins, err := r.discoverer.GetService(ctx, r.serviceName)
+		cancel()
+		if err != nil {
+			r.count("error")
+			log.Errorf("[resolver] Failed to resolve discovery endpoint: %v", err)
+			continue
+		}
+		r.count("resolved")
+		r.update(ins)
+	}
+}
+
+func (r *discoveryResolver) count(result string) {
+	if r.counter != nil {
+		r.counter.With(r.serviceName, result).Inc()
+	}
+}
+
 func (r *discoveryResolver) update(ins []*registry.ServiceInstance) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	var (
 		endpoints = make(map[string]struct{})
 		filtered  = make([]*registry.ServiceInstance, 0, len(ins))
@@ -108,7 +159,16 @@ func (r *discoveryResolver) Close() {
 	}
 }
 
-func (r *discoveryResolver) ResolveNow(_ resolver.ResolveNowOptions) {}
+// ResolveNow is called by grpc-go once a connection is lost, e.g. by the GOAWAY of the
+// server, the instances are fetched again instead of waiting for the watcher.
+func (r *discoveryResolver) ResolveNow(_ resolver.ResolveNowOptions) {
+	r.count("requested")
+	select {
+	case r.resolveNow <- struct{}{}:
+	default:
+		// 已有等待中的重新解析，合并
+	}
+}
 
 func parseAttributes(md map[string]string) (a *attributes.Attributes) {
 	for k, v := range md {
@@ -4,11 +4,14 @@ import (
 	"context"
 	"errors"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"google.golang.org/grpc/resolver"
 
+	"github.com/go-kratos/kratos/v2/metrics"
 	"github.com/go-kratos/kratos/v2/registry"
 )
 
@@ -125,3 +128,100 @@ func TestParseAttributes(t *testing.T) {
 		t.Errorf("expect nil, got %v", x.Value("notfound"))
 	}
 }
+
+type resolveDiscovery struct {
+	mockDiscovery
+	calls chan string
+}
+
+func (d *resolveDiscovery) GetService(_ context.Context, name string) ([]*registry.ServiceInstance, error) {
+	d.calls <- name
+	return []*registry.ServiceInstance{{Name: name, Endpoints: []string{"grpc://127.0.0.1:9000"}}}, nil
+}
+
+type stateConn struct {
+	resolver.ClientConn
+	states chan resolver.State
+}
+
+func (c *stateConn) UpdateState(s resolver.State) error {
+	c.states <- s
+	return nil
+}
+
+type resolveCounter struct {
+	lvs    []string
+	mu     *sync.Mutex
+	counts map[string]int
+}
+
+func (c *resolveCounter) With(lvs ...string) metrics.Counter {
+	return &resolveCounter{lvs: lvs, mu: c.mu, counts: c.counts}
+}
+
+func (c *resolveCounter) Inc() { c.Add(1) }
+
+func (c *resolveCounter) Add(float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[strings.Join(c.lvs, "/")]++
+}
+
+func (c *resolveCounter) get(k string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[k]
+}
+
+func TestResolveNow(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d := &resolveDiscovery{calls: make(chan string, 10)}
+	cc := &stateConn{states: make(chan resolver.State, 10)}
+	counter := &resolveCounter{mu: &sync.Mutex{}, counts: make(map[string]int)}
+	r := &discoveryResolver{
+		cc:          cc,
+		ctx:         ctx,
+		cancel:      cancel,
+		insecure:    true,
+		discoverer:  d,
+		serviceName: "helloworld",
+		resolveNow:  make(chan struct{}, 1),
+		minInterval: 200 * time.Millisecond,
+		timeout:     time.Second,
+		counter:     counter,
+	}
+	go r.resolve()
+
+	r.ResolveNow(resolver.ResolveNowOptions{})
+	if name := <-d.calls; name != "helloworld" {
+		t.Errorf("expect helloworld, got %s", name)
+	}
+	if s := <-cc.states; len(s.Addresses) != 1 || s.Addresses[0].Addr != "127.0.0.1:9000" {
+		t.Errorf("unexpected state %v", s)
+	}
+	// 最小间隔内的请求被合并，最多再解析两次（进行中的一次和等待中的一次）
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		r.ResolveNow(resolver.ResolveNowOptions{})
+	}
+	<-d.calls
+	if time.Since(start) < 100*time.Millisecond {
+		t.Error("expect the re-resolution throttled")
+	}
+	n := 1
+	for done := false; !done; {
+		select {
+		case <-d.calls:
+			n++
+		case <-time.After(500 * time.Millisecond):
+			done = true
+		}
+	}
+	if n > 2 {
+		t.Errorf("expect the requests coalesced, got %d resolutions", n)
+	}
+	if counter.get("helloworld/requested") != 6 || counter.get("helloworld/resolved") != n+1 || counter.get("helloworld/throttled") != n {
+		t.Errorf("unexpected counts %v", counter.counts)
+	}
+}
@@ -0,0 +1,156 @@
+package grpc
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// RetryPolicy is the gRPC retry policy of the methods, the calls failed with the retryable
+// codes are retried by gRPC until the response headers or messages are received, see gRFC A6.
+type RetryPolicy struct {
+	// MaxAttempts is the max number of attempts including the first one, default is 3,
+	// it is capped at 5 by gRPC.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, default is 50ms.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay of the backoff, default is 1s.
+	MaxBackoff time.Duration
+	// BackoffMultiplier multiplies the delay per retry, default is 2.
+	BackoffMultiplier float64
+	// RetryableCodes is the retryable status codes, default is codes.Unavailable.
+	RetryableCodes []codes.Code
+}
+
+// WithRetryPolicy with the retry policy of the methods, e.g. "/helloworld.Greeter/SayHello",
+// or "/helloworld.Greeter/" for all the methods of the service, all the methods if empty.
+// The policy of the method overrides the one of the service, and the later policy of the
+// same method replaces the earlier one. Hedging is not implemented
+// by grpc-go, and the middleware/retry is the fallback avoiding the nodes already tried.
+// It does not apply to the xDS targets, the dial fails with WithXDS.
+func WithRetryPolicy(policy RetryPolicy, methods ...string) ClientOption {
+	return func(o *clientOptions) {
+		if policy.MaxAttempts <= 0 {
+			policy.MaxAttempts = 3
+		}
+		if policy.InitialBackoff <= 0 {
+			policy.InitialBackoff = 50 * time.Millisecond
+		}
+		if policy.MaxBackoff <= 0 {
+			policy.MaxBackoff = time.Second
+		}
+		if policy.BackoffMultiplier <= 0 {
+			policy.BackoffMultiplier = 2
+		}
+		if len(policy.RetryableCodes) == 0 {
+			policy.RetryableCodes = []codes.Code{codes.Unavailable}
+		}
+		mc := methodConfig{
+			Name: methodNames(methods),
+			RetryPolicy: &retryPolicy{
+				MaxAttempts:          policy.MaxAttempts,
+				InitialBackoff:       duration(policy.InitialBackoff),
+				MaxBackoff:           duration(policy.MaxBackoff),
+				BackoffMultiplier:    policy.BackoffMultiplier,
+				RetryableStatusCodes: policy.RetryableCodes,
+			},
+		}
+		o.methodConfigs = mergeMethodConfig(o.methodConfigs, mc)
+	}
+}
+
+// mergeMethodConfig appends the method config, the names of it are removed from the
+// configs before, since the duplicate names make the service config invalid.
+func mergeMethodConfig(configs []methodConfig, mc methodConfig) []methodConfig {
+	names := make(map[methodName]struct{}, len(mc.Name))
+	unique := mc.Name[:0:0]
+	for _, n := range mc.Name {
+		if _, ok := names[n]; !ok {
+			names[n] = struct{}{}
+			unique = append(unique, n)
+		}
+	}
+	mc.Name = unique
+	merged := make([]methodConfig, 0, len(configs)+1)
+	for _, c := range configs {
+		kept := c.Name[:0:0]
+		for _, n := range c.Name {
+			if _, ok := names[n]; !ok {
+				kept = append(kept, n)
+			}
+		}
+		if len(kept) > 0 {
+			c.Name = kept
+			merged = append(merged, c)
+		}
+	}
+	return append(merged, mc)
+}
+
+// serviceConfig is the default service config of the client.
+type serviceConfig struct {
+	LoadBalancingConfig []map[string]lbConfig `json:"loadBalancingConfig"`
+	HealthCheckConfig   healthCheckConfig     `json:"healthCheckConfig"`
+	MethodConfig        []methodConfig        `json:"methodConfig,omitempty"`
+}
+
+type healthCheckConfig struct {
+	ServiceName string `json:"serviceName"`
+}
+
+type methodConfig struct {
+	Name        []methodName `json:"name"`
+	RetryPolicy *retryPolicy `json:"retryPolicy,omitempty"`
+}
+
+type methodName struct {
+	Service string `json:"service,omitempty"`
+	Method  string `json:"method,omitempty"`
+}
+
+type retryPolicy struct {
+	MaxAttempts          int          `json:"maxAttempts"`
+	InitialBackoff       string       `json:"initialBackoff"`
+	MaxBackoff           string       `json:"maxBackoff"`
+	BackoffMultiplier    float64      `json:"backoffMultiplier"`
+	RetryableStatusCodes []codes.Code `json:"retryableStatusCodes"`
+}
+
+// methodNames returns the names of the method config, e.g. /helloworld.Greeter/SayHello.
+func methodNames(methods []string) []methodName {
+	if len(methods) == 0 {
+		return []methodName{{}}
+	}
+	names := make([]methodName, 0, len(methods))
+	for _, m := range methods {
+		service, method, _ := strings.Cut(strings.TrimPrefix(m, "/"), "/")
+		names = append(names, methodName{Service: service, Method: method})
+	}
+	return names
+}
+
+// duration returns the duration of the service config, e.g. 0.05s.
+func duration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// defaultServiceConfig returns the default service config of the client.
+func defaultServiceConfig(o *clientOptions) string {
+	var lb lbConfig
+	if o.warmup != nil {
+		lb.Warmup = o.warmup.id
+	}
+	if o.mirror != nil {
+		lb.Mirror = o.mirror.id
+	}
+	lb.Breaker = o.breaker
+	sc := serviceConfig{
+		LoadBalancingConfig: []map[string]lbConfig{{o.balancerName: lb}},
+		MethodConfig:        o.methodConfigs,
+	}
+	b, _ := json.Marshal(sc)
+	return string(b)
+}
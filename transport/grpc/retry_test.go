@@ -0,0 +1,98 @@
+package grpc
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+)
+
+func TestDefaultServiceConfig(t *testing.T) {
+	o := &clientOptions{balancerName: balancerName}
+	if sc := defaultServiceConfig(o); sc != `{"loadBalancingConfig":[{"selector":{}}],"healthCheckConfig":{"serviceName":""}}` {
+		t.Errorf("unexpected service config: %s", sc)
+	}
+	WithRetryPolicy(RetryPolicy{RetryableCodes: []codes.Code{codes.Unavailable, codes.Aborted}}, "/helloworld.Greeter/SayHello", "/helloworld.Greeter/")(o)
+	want := `{"loadBalancingConfig":[{"selector":{}}],"healthCheckConfig":{"serviceName":""},"methodConfig":[{"name":[{"service":"helloworld.Greeter","method":"SayHello"},{"service":"helloworld.Greeter"}],` +
+		`"retryPolicy":{"maxAttempts":3,"initialBackoff":"0.05s","maxBackoff":"1s","backoffMultiplier":2,"retryableStatusCodes":[14,10]}}]}`
+	if sc := defaultServiceConfig(o); sc != want {
+		t.Errorf("expect %s, got %s", want, sc)
+	}
+}
+
+type flakyServer struct {
+	pb.UnimplementedGreeterServer
+	calls int32
+}
+
+func (s *flakyServer) SayHello(_ context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
+	if atomic.AddInt32(&s.calls, 1) < 3 {
+		return nil, errors.ServiceUnavailable("UNAVAILABLE", "try again")
+	}
+	return &pb.HelloReply{Message: "Hello " + in.Name}, nil
+}
+
+func TestWithRetryPolicy(t *testing.T) {
+	fs := &flakyServer{}
+	srv := NewServer()
+	pb.RegisterGreeterServer(srv, fs)
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	defer func() {
+		_ = srv.Stop(context.Background())
+	}()
+
+	conn, err := DialInsecure(context.Background(),
+		WithEndpoint(u.Host),
+		WithOptions(grpc.WithBlock()),
+		WithRetryPolicy(RetryPolicy{InitialBackoff: time.Millisecond}, "/helloworld.Greeter/"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	reply, err := pb.NewGreeterClient(conn).SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Message != "Hello kratos" || atomic.LoadInt32(&fs.calls) != 3 {
+		t.Errorf("unexpected reply: %s, calls: %d", reply.Message, fs.calls)
+	}
+}
+
+func TestWithRetryPolicyDuplicate(t *testing.T) {
+	o := &clientOptions{balancerName: balancerName}
+	WithRetryPolicy(RetryPolicy{MaxAttempts: 2}, "/helloworld.Greeter/SayHello", "/helloworld.Greeter/SayHello", "/helloworld.Greeter/")(o)
+	WithRetryPolicy(RetryPolicy{MaxAttempts: 4}, "/helloworld.Greeter/")(o)
+	if len(o.methodConfigs) != 2 {
+		t.Fatalf("expect %v, got %v", 2, len(o.methodConfigs))
+	}
+	if names := o.methodConfigs[0].Name; len(names) != 1 || names[0].Method != "SayHello" {
+		t.Errorf("expect the names to be merged, got %v", names)
+	}
+	if mc := o.methodConfigs[1]; mc.RetryPolicy.MaxAttempts != 4 {
+		t.Errorf("expect the later policy to replace the earlier one, got %v", mc.RetryPolicy.MaxAttempts)
+	}
+
+	// 重复的名称会使service config非法
+	conn, err := DialInsecure(context.Background(),
+		WithEndpoint("127.0.0.1:0"),
+		WithRetryPolicy(RetryPolicy{}),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 5}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.Close()
+}
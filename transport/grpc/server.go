@@ -3,8 +3,10 @@ package grpc
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"net"
 	"net/url"
+	"sync"
 	"time"
 
 	"google.golang.org/grpc"
@@ -12,6 +14,7 @@ import (
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
 
 	apimd "github.com/go-kratos/kratos/v2/api/metadata"
@@ -26,6 +29,7 @@ import (
 var (
 	_ transport.Server     = (*Server)(nil)
 	_ transport.Endpointer = (*Server)(nil)
+	_ transport.Readier    = (*Server)(nil)
 )
 
 // ServerOption is gRPC server option.
@@ -38,7 +42,8 @@ func Network(network string) ServerOption {
 	}
 }
 
-// Address with server address.
+// Address with server address, it is the unix socket of the address like
+// unix:///tmp/app.sock or unix-abstract:app, e.g. for the communication with the sidecar.
 func Address(addr string) ServerOption {
 	return func(s *Server) {
 		s.address = addr
@@ -65,6 +70,25 @@ func Middleware(m ...middleware.Middleware) ServerOption {
 	}
 }
 
+// StreamMiddleware with server middleware of the streaming calls, the unary middleware
+// can be adapted by middleware.StreamFromUnary, e.g.
+//
+//	StreamMiddleware(middleware.StreamFromUnary(recovery.Recovery()))
+func StreamMiddleware(m ...middleware.StreamMiddleware) ServerOption {
+	return func(s *Server) {
+		s.streamMws = m
+	}
+}
+
+// Admin with the gRPC admin services, i.e. channelz for the connection-level debugging,
+// and csds if the xDS is enabled by importing google.golang.org/grpc/xds. They are opt-in
+// since they expose the internals of the connections, and should not be public.
+func Admin() ServerOption {
+	return func(s *Server) {
+		s.admin = true
+	}
+}
+
 // CustomHealth Checks server.
 func CustomHealth() ServerOption {
 	return func(s *Server) {
@@ -112,6 +136,9 @@ type Server struct {
 	*grpc.Server
 	baseCtx      context.Context
 	tlsConf      *tls.Config
+	clientCAs    *x509.CertPool
+	clientAuth   tls.ClientAuthType
+	verifyPeer   func(transport.Identity) error
 	lis          net.Listener
 	err          error
 	network      string
@@ -119,13 +146,24 @@ type Server struct {
 	endpoint     *url.URL
 	timeout      time.Duration
 	middleware   matcher.Matcher
+	streamMws    []middleware.StreamMiddleware
 	unaryInts    []grpc.UnaryServerInterceptor
 	streamInts   []grpc.StreamServerInterceptor
 	grpcOpts     []grpc.ServerOption
 	health       *health.Server
 	customHealth bool
 	metadata     *apimd.Server
+	admin        bool
+	keepalive    *keepalive.ServerParameters
+	enforcement  *keepalive.EnforcementPolicy
+	limits       []grpc.ServerOption
+	tap          *tapLimiter
+	lazy         []*lazyService
 	adminClean   func()
+	readyMu      sync.Mutex
+	managed      bool
+	ready        bool
+	started      bool
 }
 
 // NewServer creates a gRPC server by options.
@@ -147,6 +185,11 @@ func NewServer(opts ...ServerOption) *Server {
 	streamInts := []grpc.StreamServerInterceptor{
 		srv.streamServerInterceptor(),
 	}
+	if srv.tap != nil {
+		// 最外层的拦截器释放并发计数，保证请求结束时释放
+		unaryInts = append([]grpc.UnaryServerInterceptor{srv.tap.unaryInterceptor()}, unaryInts...)
+		streamInts = append([]grpc.StreamServerInterceptor{srv.tap.streamInterceptor()}, streamInts...)
+	}
 	if len(srv.unaryInts) > 0 {
 		unaryInts = append(unaryInts, srv.unaryInts...)
 	}
@@ -157,22 +200,41 @@ func NewServer(opts ...ServerOption) *Server {
 		grpc.ChainUnaryInterceptor(unaryInts...),
 		grpc.ChainStreamInterceptor(streamInts...),
 	}
+	if srv.tap != nil {
+		grpcOpts = append(grpcOpts, grpc.InTapHandle(srv.tap.handle))
+	}
 	if srv.tlsConf != nil {
-		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(srv.tlsConf)))
+		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(srv.mtlsConfig())))
+	}
+	if srv.keepalive != nil {
+		grpcOpts = append(grpcOpts, grpc.KeepaliveParams(*srv.keepalive))
+	}
+	if srv.enforcement != nil {
+		grpcOpts = append(grpcOpts, grpc.KeepaliveEnforcementPolicy(*srv.enforcement))
+	}
+	if len(srv.limits) > 0 {
+		grpcOpts = append(grpcOpts, srv.limits...)
 	}
 	if len(srv.grpcOpts) > 0 {
 		grpcOpts = append(grpcOpts, srv.grpcOpts...)
 	}
 	srv.Server = grpc.NewServer(grpcOpts...)
+	if srv.tap != nil {
+		srv.tap.srv = srv.Server
+	}
 	srv.metadata = apimd.NewServer(srv.Server)
 	// internal register
 	if !srv.customHealth {
 		grpc_health_v1.RegisterHealthServer(srv.Server, srv.health)
 	}
+	// 启动之前，服务不可用
+	srv.health.Shutdown()
 	apimd.RegisterMetadataServer(srv.Server, srv.metadata)
 	reflection.Register(srv.Server)
 	// admin register
-	srv.adminClean, _ = admin.Register(srv.Server)
+	if srv.admin {
+		srv.adminClean, _ = admin.Register(srv.Server)
+	}
 	return srv
 }
 
@@ -189,6 +251,7 @@ func (s *Server) Use(selector string, m ...middleware.Middleware) {
 // examples:
 //
 //	grpc://127.0.0.1:9000?isSecure=false
+//	unix:///tmp/app.sock
 func (s *Server) Endpoint() (*url.URL, error) {
 	if err := s.listenAndEndpoint(); err != nil {
 		return nil, s.err
@@ -198,12 +261,15 @@ func (s *Server) Endpoint() (*url.URL, error) {
 
 // Start start the gRPC server.
 func (s *Server) Start(ctx context.Context) error {
+	if err := s.constructLazy(ctx); err != nil {
+		return err
+	}
 	if err := s.listenAndEndpoint(); err != nil {
 		return s.err
 	}
 	s.baseCtx = ctx
 	log.Infof("[gRPC] server listening on: %s", s.lis.Addr().String())
-	s.health.Resume()
+	s.setServing(func() { s.started = true })
 	return s.Serve(s.lis)
 }
 
@@ -212,15 +278,42 @@ func (s *Server) Stop(_ context.Context) error {
 	if s.adminClean != nil {
 		s.adminClean()
 	}
-	s.health.Shutdown()
+	s.setServing(func() { s.started = false })
 	s.GracefulStop()
 	log.Info("[gRPC] server stopping")
 	return nil
 }
 
+// SetReady sets the readiness of the application, the health service is SERVING once
+// the server is started and ready, so that the gRPC probes and the client health checking
+// work out of the box. It is called by kratos.App, and the server is ready once it is
+// started if it is never called.
+func (s *Server) SetReady(ready bool) {
+	s.setServing(func() {
+		s.managed = true
+		s.ready = ready
+	})
+}
+
+// setServing updates the state by fn, and sets the serving status of the health service.
+func (s *Server) setServing(fn func()) {
+	s.readyMu.Lock()
+	defer s.readyMu.Unlock()
+	fn()
+	if s.started && (!s.managed || s.ready) {
+		s.health.Resume()
+	} else {
+		s.health.Shutdown()
+	}
+}
+
 func (s *Server) listenAndEndpoint() error {
 	if s.lis == nil {
-		lis, err := net.Listen(s.network, s.address)
+		network, address := s.network, s.address
+		if path, ok := unixSocket(network, address); ok {
+			network, address = "unix", path
+		}
+		lis, err := net.Listen(network, address)
 		if err != nil {
 			s.err = err
 			return err
@@ -228,6 +321,10 @@ func (s *Server) listenAndEndpoint() error {
 		s.lis = lis
 	}
 	if s.endpoint == nil {
+		if e, ok := unixEndpoint(s.lis.Addr()); ok {
+			s.endpoint = e
+			return s.err
+		}
 		addr, err := host.Extract(s.address, s.lis)
 		if err != nil {
 			s.err = err
@@ -4,19 +4,26 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"io"
 	"net"
 	"net/url"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	grpcmd "google.golang.org/grpc/metadata"
 
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/internal/matcher"
 	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/metadata"
 	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/recovery"
 	"github.com/go-kratos/kratos/v2/transport"
 )
 
@@ -280,6 +287,25 @@ func TestServer_unaryServerInterceptor(t *testing.T) {
 	}
 }
 
+func TestServer_unaryServerInterceptorBaggage(t *testing.T) {
+	srv := &Server{
+		baseCtx:    context.Background(),
+		middleware: matcher.New(),
+	}
+	ctx := grpcmd.NewIncomingContext(context.Background(), grpcmd.Pairs(metadata.BaggageHeader, "tenant=kratos"))
+	var got string
+	_, err := srv.unaryServerInterceptor()(ctx, &struct{}{}, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		got = metadata.GetBaggage(ctx, "tenant")
+		return &testResp{}, nil
+	})
+	if err != nil {
+		t.Errorf("expect %v, got %v", nil, err)
+	}
+	if got != "kratos" {
+		t.Errorf("expect %v, got %v", "kratos", got)
+	}
+}
+
 func TestListener(t *testing.T) {
 	lis, err := net.Listen("tcp", ":0")
 	if err != nil {
@@ -294,3 +320,169 @@ func TestListener(t *testing.T) {
 		t.Errorf("expect not empty")
 	}
 }
+
+type countStream struct {
+	middleware.Stream
+	sent, recv *int32
+}
+
+func (s *countStream) SendMsg(m interface{}) error {
+	atomic.AddInt32(s.sent, 1)
+	return s.Stream.SendMsg(m)
+}
+
+func (s *countStream) RecvMsg(m interface{}) error {
+	err := s.Stream.RecvMsg(m)
+	if err == nil {
+		atomic.AddInt32(s.recv, 1)
+	}
+	return err
+}
+
+func countStreamMiddleware(sent, recv *int32) middleware.StreamMiddleware {
+	return func(next middleware.StreamHandler) middleware.StreamHandler {
+		return func(ctx context.Context, stream middleware.Stream) error {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				tr, _ = transport.FromClientContext(ctx)
+			}
+			// 忽略客户端的健康检查
+			if tr.Operation() != "/helloworld.Greeter/SayHelloStream" {
+				return next(ctx, stream)
+			}
+			return next(ctx, &countStream{Stream: stream, sent: sent, recv: recv})
+		}
+	}
+}
+
+func TestStreamMiddleware(t *testing.T) {
+	var (
+		operation        atomic.Value
+		srvSent, srvRecv int32
+		cliSent, cliRecv int32
+	)
+	srv := NewServer(
+		StreamMiddleware(
+			middleware.StreamFromUnary(recovery.Recovery()),
+			middleware.StreamFromUnary(func(handler middleware.Handler) middleware.Handler {
+				return func(ctx context.Context, req interface{}) (interface{}, error) {
+					if tr, ok := transport.FromServerContext(ctx); ok && strings.Contains(tr.Operation(), "Greeter") {
+						operation.Store(tr.Operation())
+					}
+					return handler(ctx, req)
+				}
+			}),
+			countStreamMiddleware(&srvSent, &srvRecv),
+		),
+	)
+	pb.RegisterGreeterServer(srv, &server{})
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	defer func() {
+		_ = srv.Stop(context.Background())
+	}()
+
+	conn, err := DialInsecure(context.Background(),
+		WithEndpoint(u.Host),
+		WithOptions(grpc.WithBlock()),
+		WithStreamMiddleware(countStreamMiddleware(&cliSent, &cliRecv)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := pb.NewGreeterClient(conn)
+
+	stream, err := client.SayHelloStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a", "b"} {
+		if err = stream.Send(&pb.HelloRequest{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = stream.Recv(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = stream.Recv(); err != io.EOF {
+		t.Errorf("expect EOF, got %v", err)
+	}
+	if op, _ := operation.Load().(string); op != "/helloworld.Greeter/SayHelloStream" {
+		t.Errorf("unexpected operation: %q", op)
+	}
+	if atomic.LoadInt32(&srvSent) != 2 || atomic.LoadInt32(&srvRecv) != 2 || cliSent != 2 || cliRecv != 2 {
+		t.Errorf("unexpected messages: server %d/%d, client %d/%d", srvSent, srvRecv, cliSent, cliRecv)
+	}
+
+	stream, err = client.SayHelloStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = stream.Send(&pb.HelloRequest{Name: "panic"})
+	if _, err = stream.Recv(); errors.FromError(err).GetCode() != 500 {
+		t.Errorf("expect the panic recovered, got %v", err)
+	}
+}
+
+func TestServerSetReady(t *testing.T) {
+	srv := NewServer()
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.SetReady(false)
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	defer func() {
+		_ = srv.Stop(context.Background())
+	}()
+	// 客户端的健康检查会避开NOT_SERVING的服务，直接连接
+	conn, err := grpc.Dial(u.Host, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := grpc_health_v1.NewHealthClient(conn)
+	check := func() grpc_health_v1.HealthCheckResponse_ServingStatus {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		resp, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}, grpc.WaitForReady(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.Status
+	}
+	if status := check(); status != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("expect NOT_SERVING before ready, got %v", status)
+	}
+	srv.SetReady(true)
+	if status := check(); status != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("expect SERVING once ready, got %v", status)
+	}
+	srv.SetReady(false)
+	if status := check(); status != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("expect NOT_SERVING while draining, got %v", status)
+	}
+}
+
+func TestAdmin(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		var opts []ServerOption
+		if enabled {
+			opts = append(opts, Admin())
+		}
+		srv := NewServer(opts...)
+		_, ok := srv.GetServiceInfo()["grpc.channelz.v1.Channelz"]
+		if ok != enabled {
+			t.Errorf("expect the channelz service registered %v, got %v", enabled, ok)
+		}
+		_ = srv.Stop(context.Background())
+	}
+}
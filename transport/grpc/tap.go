@@ -0,0 +1,139 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/tap"
+)
+
+// MaxInflight with the max in-flight RPCs of the server, zero means no limit. The excess
+// RPCs are rejected with Unavailable by the tap handle before the streams are created,
+// cheaper than the ratelimit middleware under severe overload. The tap handle of the
+// server is taken, so grpc.InTapHandle should not be set by Options. The health service
+// is not limited.
+func MaxInflight(n int) ServerOption {
+	return func(s *Server) {
+		s.tapLimiter().maxInflight = int64(n)
+	}
+}
+
+// MaxQPS with the max RPCs per second of the server, the bursts up to the qps are
+// allowed, zero means no limit. The excess RPCs are rejected like MaxInflight.
+func MaxQPS(qps int) ServerOption {
+	return func(s *Server) {
+		l := s.tapLimiter()
+		l.qps = float64(qps)
+		l.tokens = float64(qps)
+	}
+}
+
+func (s *Server) tapLimiter() *tapLimiter {
+	if s.tap == nil {
+		s.tap = &tapLimiter{}
+	}
+	return s.tap
+}
+
+// healthPrefix is the prefix of the methods of the health service.
+const healthPrefix = "/grpc.health.v1.Health/"
+
+// inflightKey marks the RPC counted as in-flight, it is released by the interceptors.
+type inflightKey struct{}
+
+// tapLimiter limits the in-flight RPCs and the QPS by the tap handle.
+type tapLimiter struct {
+	srv         *grpc.Server
+	maxInflight int64
+	inflight    int64
+
+	mu     sync.Mutex
+	qps    float64
+	tokens float64
+	last   time.Time
+
+	once    sync.Once
+	methods map[string]bool
+}
+
+// handle is called in the transport goroutine, it must be cheap.
+func (l *tapLimiter) handle(ctx context.Context, info *tap.Info) (context.Context, error) {
+	// 健康检查不限制，包括客户端长期持有的Watch流，避免过载时节点被摘除
+	if strings.HasPrefix(info.FullMethodName, healthPrefix) {
+		return ctx, nil
+	}
+	if l.qps > 0 && !l.take(time.Now()) {
+		return nil, status.Error(codes.Unavailable, "grpc: rate limit exceeded")
+	}
+	if l.maxInflight <= 0 {
+		return ctx, nil
+	}
+	// 未知方法的请求不经过拦截器，不计入并发
+	if !l.known(info.FullMethodName) {
+		return ctx, nil
+	}
+	if atomic.AddInt64(&l.inflight, 1) > l.maxInflight {
+		atomic.AddInt64(&l.inflight, -1)
+		return nil, status.Error(codes.Unavailable, "grpc: too many in-flight requests")
+	}
+	return context.WithValue(ctx, inflightKey{}, new(int32)), nil
+}
+
+// take takes a token of the bucket refilled by the qps.
+func (l *tapLimiter) take(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.qps
+		if l.tokens > l.qps {
+			l.tokens = l.qps
+		}
+	}
+	l.last = now
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// known reports whether the method is registered, the services are registered before
+// the server is started, so they are loaded once by the first RPC.
+func (l *tapLimiter) known(method string) bool {
+	l.once.Do(func() {
+		l.methods = make(map[string]bool)
+		for name, info := range l.srv.GetServiceInfo() {
+			for _, m := range info.Methods {
+				l.methods["/"+name+"/"+m.Name] = true
+			}
+		}
+	})
+	return l.methods[method]
+}
+
+// release releases the in-flight RPC of the context once.
+func (l *tapLimiter) release(ctx context.Context) {
+	if p, ok := ctx.Value(inflightKey{}).(*int32); ok && atomic.CompareAndSwapInt32(p, 0, 1) {
+		atomic.AddInt64(&l.inflight, -1)
+	}
+}
+
+func (l *tapLimiter) unaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		defer l.release(ctx)
+		return handler(ctx, req)
+	}
+}
+
+func (l *tapLimiter) streamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		defer l.release(ss.Context())
+		return handler(srv, ss)
+	}
+}
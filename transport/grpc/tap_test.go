@@ -0,0 +1,84 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+)
+
+func TestMaxInflight(t *testing.T) {
+	srv := NewServer(MaxInflight(1))
+	pb.RegisterGreeterServer(srv, &server{})
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	defer func() {
+		_ = srv.Stop(context.Background())
+	}()
+	conn, err := DialInsecure(context.Background(), WithEndpoint(u.Host), WithOptions(grpc.WithBlock()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := pb.NewGreeterClient(conn)
+
+	if _, err = client.SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"}); err != nil {
+		t.Fatal(err)
+	}
+	// 未结束的流占用唯一的并发
+	stream, err := client.SayHelloStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = stream.Send(&pb.HelloRequest{Name: "kratos"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"}); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected unavailable got %v", err)
+	}
+	_ = stream.CloseSend()
+	if _, err = stream.Recv(); err == nil {
+		t.Fatal("expected the stream finished")
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		_, err = client.SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"})
+		if err == nil || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("expected the in-flight released got %v", err)
+	}
+}
+
+func TestTapLimiterTake(t *testing.T) {
+	l := &tapLimiter{qps: 2, tokens: 2}
+	now := time.Now()
+	if !l.take(now) || !l.take(now) {
+		t.Fatal("expected the burst allowed")
+	}
+	if l.take(now) {
+		t.Fatal("expected rejected")
+	}
+	if !l.take(now.Add(500 * time.Millisecond)) {
+		t.Fatal("expected the bucket refilled")
+	}
+	if l.take(now.Add(500 * time.Millisecond)) {
+		t.Fatal("expected rejected")
+	}
+}
@@ -1,6 +1,9 @@
 package grpc
 
 import (
+	"context"
+
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 
 	"github.com/go-kratos/kratos/v2/selector"
@@ -15,7 +18,10 @@ type Transport struct {
 	operation   string
 	reqHeader   headerCarrier
 	replyHeader headerCarrier
-	nodeFilters []selector.NodeFilter
+	// replyTrailer 服务端由中间件设置，客户端在调用完成后可读
+	replyTrailer headerCarrier
+	nodeFilters  []selector.NodeFilter
+	callOpts     []grpc.CallOption
 }
 
 // Kind returns the transport kind.
@@ -38,16 +44,33 @@ func (tr *Transport) RequestHeader() transport.Header {
 	return tr.reqHeader
 }
 
-// ReplyHeader returns the reply header.
+// ReplyHeader returns the reply header, it is the response header of the unary client
+// calls once the call returns.
 func (tr *Transport) ReplyHeader() transport.Header {
 	return tr.replyHeader
 }
 
+// ReplyTrailer returns the reply trailer, it is set by the server middleware, and it is
+// the response trailer of the unary client calls once the call returns.
+func (tr *Transport) ReplyTrailer() transport.Header {
+	return tr.replyTrailer
+}
+
 // NodeFilters returns the client select filters.
 func (tr *Transport) NodeFilters() []selector.NodeFilter {
 	return tr.nodeFilters
 }
 
+// AppendCallOptions appends the gRPC call options of the client call, e.g. in the client
+// middleware to select the compressor or wait for ready per call.
+func AppendCallOptions(ctx context.Context, opts ...grpc.CallOption) {
+	if tr, ok := transport.FromClientContext(ctx); ok {
+		if tr, ok := tr.(*Transport); ok {
+			tr.callOpts = append(tr.callOpts, opts...)
+		}
+	}
+}
+
 type headerCarrier metadata.MD
 
 // Get returns the value associated with the passed key.
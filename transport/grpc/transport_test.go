@@ -1,10 +1,16 @@
 package grpc
 
 import (
+	"context"
 	"reflect"
 	"sort"
 	"testing"
 
+	"google.golang.org/grpc"
+	grpcmd "google.golang.org/grpc/metadata"
+
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
 )
 
@@ -68,3 +74,59 @@ func TestHeaderCarrier_Keys(t *testing.T) {
 		t.Errorf("expect %v, got %v", want, keys)
 	}
 }
+
+func TestTransport_CallOptionsAndReply(t *testing.T) {
+	srv := NewServer(Middleware(func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				tr.ReplyHeader().Set("x-reply", "header")
+				tr.(*Transport).ReplyTrailer().Set("x-trailer", "trailer")
+			}
+			return handler(ctx, req)
+		}
+	}))
+	pb.RegisterGreeterServer(srv, &server{})
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	defer func() {
+		_ = srv.Stop(context.Background())
+	}()
+
+	var (
+		header, trailer string
+		md              grpcmd.MD
+	)
+	conn, err := DialInsecure(context.Background(),
+		WithEndpoint(u.Host),
+		WithOptions(grpc.WithBlock()),
+		WithMiddleware(func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				AppendCallOptions(ctx, grpc.Header(&md))
+				reply, err := handler(ctx, req)
+				if tr, ok := transport.FromClientContext(ctx); ok {
+					header = tr.ReplyHeader().Get("x-reply")
+					trailer = tr.(*Transport).ReplyTrailer().Get("x-trailer")
+				}
+				return reply, err
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err = pb.NewGreeterClient(conn).SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"}); err != nil {
+		t.Fatal(err)
+	}
+	if header != "header" || trailer != "trailer" {
+		t.Errorf("unexpected reply header %q, trailer %q", header, trailer)
+	}
+	if v := md.Get("x-reply"); len(v) != 1 || v[0] != "header" {
+		t.Errorf("expect the call option applied, got %v", md)
+	}
+}
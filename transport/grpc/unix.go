@@ -0,0 +1,53 @@
+package grpc
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+const (
+	unixScheme         = "unix"
+	unixAbstractScheme = "unix-abstract"
+)
+
+// unixSocket returns the unix socket of the address, e.g. unix:///tmp/app.sock,
+// unix-abstract:app or the path of the unix network, the abstract one starts with '@'.
+func unixSocket(network, address string) (string, bool) {
+	switch {
+	case strings.HasPrefix(address, unixAbstractScheme+":"):
+		return "@" + strings.TrimPrefix(address, unixAbstractScheme+":"), true
+	case strings.HasPrefix(address, unixScheme+"://"):
+		return strings.TrimPrefix(address, unixScheme+"://"), true
+	case strings.HasPrefix(address, unixScheme+":"):
+		return strings.TrimPrefix(address, unixScheme+":"), true
+	case network == "unix":
+		return address, true
+	}
+	return "", false
+}
+
+// nodeScheme returns the scheme of the selector nodes of the dial target, the nodes
+// of the unix socket targets are of the unix scheme.
+func nodeScheme(target string) string {
+	if target == unixScheme || target == unixAbstractScheme {
+		return unixScheme
+	}
+	return "grpc"
+}
+
+// unixEndpoint returns the endpoint of the unix socket address, it is dialed by the
+// unix and unix-abstract resolvers of gRPC.
+func unixEndpoint(addr net.Addr) (*url.URL, bool) {
+	ua, ok := addr.(*net.UnixAddr)
+	if !ok {
+		return nil, false
+	}
+	switch {
+	case strings.HasPrefix(ua.Name, "@"):
+		return &url.URL{Scheme: unixAbstractScheme, Opaque: ua.Name[1:]}, true
+	case strings.HasPrefix(ua.Name, "/"):
+		return &url.URL{Scheme: unixScheme, Path: ua.Name}, true
+	}
+	return &url.URL{Scheme: unixScheme, Opaque: ua.Name}, true
+}
@@ -0,0 +1,85 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+)
+
+func TestUnixSocket(t *testing.T) {
+	tests := []struct {
+		network, address string
+		path             string
+		ok               bool
+	}{
+		{"tcp", "unix:///tmp/app.sock", "/tmp/app.sock", true},
+		{"tcp", "unix:app.sock", "app.sock", true},
+		{"tcp", "unix-abstract:app", "@app", true},
+		{"unix", "/tmp/app.sock", "/tmp/app.sock", true},
+		{"tcp", ":9000", "", false},
+	}
+	for _, tt := range tests {
+		path, ok := unixSocket(tt.network, tt.address)
+		if path != tt.path || ok != tt.ok {
+			t.Errorf("%s %s: expect %q %v, got %q %v", tt.network, tt.address, tt.path, tt.ok, path, ok)
+		}
+	}
+}
+
+func TestUnixEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"/tmp/app.sock": "unix:///tmp/app.sock",
+		"app.sock":      "unix:app.sock",
+		"@app":          "unix-abstract:app",
+	}
+	for name, want := range tests {
+		e, ok := unixEndpoint(&net.UnixAddr{Net: "unix", Name: name})
+		if !ok || e.String() != want {
+			t.Errorf("%s: expect %s, got %v", name, want, e)
+		}
+	}
+	if _, ok := unixEndpoint(&net.TCPAddr{}); ok {
+		t.Error("expect the tcp address not unix")
+	}
+}
+
+func TestServerUnix(t *testing.T) {
+	addrs := []string{"unix://" + filepath.Join(t.TempDir(), "app.sock")}
+	if runtime.GOOS == "linux" {
+		addrs = append(addrs, "unix-abstract:kratos-test")
+	}
+	for _, addr := range addrs {
+		srv := NewServer(Address(addr))
+		pb.RegisterGreeterServer(srv, &server{})
+		u, err := srv.Endpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.String() != addr {
+			t.Errorf("expect endpoint %s, got %s", addr, u)
+		}
+		go func() {
+			_ = srv.Start(context.Background())
+		}()
+
+		conn, err := DialInsecure(context.Background(), WithEndpoint(u.String()), WithOptions(grpc.WithBlock()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		reply, err := pb.NewGreeterClient(conn).SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reply.Message != "Hello kratos" {
+			t.Errorf("unexpected reply %q", reply.Message)
+		}
+		_ = conn.Close()
+		_ = srv.Stop(context.Background())
+	}
+}
@@ -0,0 +1,75 @@
+package grpc
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+)
+
+// 预热中的连接，以service config中的id关联到balancer
+var (
+	warmups  sync.Map
+	warmupID int64
+)
+
+// WithWarmup with the number of the resolved nodes connected before the dial returns,
+// eliminating the latency spikes of the first requests after the startup. The nodes are
+// ready once they are connected and pass the health check of the gRPC health service.
+// It waits for all the nodes if there are fewer of them, bounded by the ctx of the dial,
+// the dial fails with the error of the ctx if it is done before. It does not apply to
+// the xDS targets.
+func WithWarmup(n int) ClientOption {
+	return func(o *clientOptions) {
+		o.warmup = &warmup{n: n, done: make(chan struct{})}
+	}
+}
+
+// warmup waits for the ready nodes of a connection.
+type warmup struct {
+	id   string
+	n    int
+	once sync.Once
+	done chan struct{}
+}
+
+// update reports the ready nodes and the total resolved nodes.
+func (w *warmup) update(ready, total int) {
+	want := w.n
+	if total < want {
+		want = total
+	}
+	if want > 0 && ready >= want {
+		w.once.Do(func() {
+			close(w.done)
+		})
+	}
+}
+
+// register registers the warmup for the balancer by the id.
+func (w *warmup) register() {
+	w.id = strconv.FormatInt(atomic.AddInt64(&warmupID, 1), 10)
+	warmups.Store(w.id, w)
+}
+
+// wait waits for the nodes of the connection ready, the connection is closed if it fails.
+func (w *warmup) wait(ctx context.Context, conn *grpc.ClientConn) error {
+	defer warmups.Delete(w.id)
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		_ = conn.Close()
+		return ctx.Err()
+	}
+}
+
+// loadWarmup returns the warmup of the id.
+func loadWarmup(id string) *warmup {
+	if w, ok := warmups.Load(id); ok {
+		return w.(*warmup)
+	}
+	return nil
+}
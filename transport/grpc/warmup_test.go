@@ -0,0 +1,74 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+)
+
+func TestWarmupUpdate(t *testing.T) {
+	w := &warmup{n: 2, done: make(chan struct{})}
+	w.update(0, 0)
+	w.update(1, 3)
+	select {
+	case <-w.done:
+		t.Fatal("expect not warmed up")
+	default:
+	}
+	w.update(1, 1)
+	select {
+	case <-w.done:
+	default:
+		t.Fatal("expect warmed up with all the nodes")
+	}
+	// 重复上报
+	w.update(2, 2)
+}
+
+func TestWithWarmup(t *testing.T) {
+	var addrs []string
+	for i := 0; i < 2; i++ {
+		srv := NewServer()
+		pb.RegisterGreeterServer(srv, &server{})
+		u, err := srv.Endpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			_ = srv.Start(context.Background())
+		}()
+		defer func() {
+			_ = srv.Stop(context.Background())
+		}()
+		addrs = append(addrs, u.Host)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := DialInsecure(ctx, WithEndpoint("direct:///"+addrs[0]+","+addrs[1]), WithWarmup(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err = pb.NewGreeterClient(conn).SayHello(ctx, &pb.HelloRequest{Name: "kratos"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 不可达的节点
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unreachable := lis.Addr().String()
+	_ = lis.Close()
+	ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err = DialInsecure(ctx, WithEndpoint("direct:///"+addrs[0]+","+unreachable), WithWarmup(2))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect %v, got %v", context.DeadlineExceeded, err)
+	}
+}
@@ -0,0 +1,34 @@
+package grpc
+
+import (
+	"strings"
+
+	"google.golang.org/grpc/resolver"
+)
+
+const xdsScheme = "xds"
+
+// WithXDS with the xDS resolver of the endpoint, the endpoint is resolved and balanced by
+// the xDS control plane, e.g. Istio or Traffic Director, instead of the discovery and the
+// balancer of kratos, while the middleware still applies. The endpoint without the scheme
+// is dialed as xds:///endpoint.
+// The builder is e.g. the one returned by NewXDSResolverWithConfigForTesting of the package
+// google.golang.org/grpc/xds with the bootstrap config, it is nil to use the resolver
+// registered by importing the package, which reads the bootstrap config by the environment
+// GRPC_XDS_BOOTSTRAP or GRPC_XDS_BOOTSTRAP_CONFIG.
+// The retry policy is configured by the control plane too, the dial fails with
+// WithRetryPolicy, the middleware/retry is the fallback.
+func WithXDS(builder resolver.Builder) ClientOption {
+	return func(o *clientOptions) {
+		o.xds = true
+		o.xdsResolver = builder
+	}
+}
+
+// xdsTarget returns the xds target of the endpoint.
+func xdsTarget(endpoint string) string {
+	if strings.Contains(endpoint, "://") {
+		return endpoint
+	}
+	return xdsScheme + ":///" + endpoint
+}
@@ -0,0 +1,82 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+func TestXDSTarget(t *testing.T) {
+	tests := map[string]string{
+		"helloworld":          "xds:///helloworld",
+		"xds:///helloworld":   "xds:///helloworld",
+		"xds://td/helloworld": "xds://td/helloworld",
+	}
+	for endpoint, want := range tests {
+		if got := xdsTarget(endpoint); got != want {
+			t.Errorf("%s: expect %s, got %s", endpoint, want, got)
+		}
+	}
+}
+
+func TestWithXDS(t *testing.T) {
+	srv := NewServer()
+	pb.RegisterGreeterServer(srv, &server{})
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	defer func() {
+		_ = srv.Stop(context.Background())
+	}()
+
+	// 模拟xDS控制面下发的地址
+	r := manual.NewBuilderWithScheme(xdsScheme)
+	r.InitialState(resolver.State{Addresses: []resolver.Address{{Addr: u.Host}}})
+	var called bool
+	conn, err := DialInsecure(context.Background(),
+		WithEndpoint("helloworld"),
+		WithXDS(r),
+		WithOptions(grpc.WithBlock()),
+		WithMiddleware(func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				called = true
+				return handler(ctx, req)
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if conn.Target() != "xds:///helloworld" {
+		t.Errorf("unexpected target: %s", conn.Target())
+	}
+	reply, err := pb.NewGreeterClient(conn).SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Message != "Hello kratos" || !called {
+		t.Errorf("unexpected reply %q, middleware called %v", reply.Message, called)
+	}
+}
+
+func TestWithXDSRetryPolicy(t *testing.T) {
+	_, err := DialInsecure(context.Background(),
+		WithEndpoint("helloworld"),
+		WithXDS(manual.NewBuilderWithScheme(xdsScheme)),
+		WithRetryPolicy(RetryPolicy{}),
+	)
+	if err == nil {
+		t.Error("expect the error of the retry policy with the xDS target")
+	}
+}
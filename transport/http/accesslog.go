@@ -0,0 +1,221 @@
+package http
+
+import (
+	"bufio"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// The fields of the access logs.
+const (
+	AccessLogMethod    = "method"
+	AccessLogPath      = "path"
+	AccessLogQuery     = "query"
+	AccessLogStatus    = "status"
+	AccessLogBytes     = "bytes"
+	AccessLogLatency   = "latency"
+	AccessLogRemoteIP  = "remote_ip"
+	AccessLogUserAgent = "user_agent"
+	AccessLogTraceID   = "trace_id"
+)
+
+var defaultAccessLogFields = []string{
+	AccessLogMethod, AccessLogPath, AccessLogStatus, AccessLogBytes,
+	AccessLogLatency, AccessLogRemoteIP, AccessLogTraceID,
+}
+
+// AccessLogOption is access log filter option.
+type AccessLogOption func(*accessLogOptions)
+
+type accessLogOptions struct {
+	logger  log.Logger
+	fields  []string
+	sample  float64
+	proxies []*net.IPNet
+}
+
+// AccessLogFields with the fields of the access logs, default is method, path,
+// status, bytes, latency, remote_ip and trace_id.
+func AccessLogFields(fields ...string) AccessLogOption {
+	return func(o *accessLogOptions) {
+		o.fields = fields
+	}
+}
+
+// AccessLogSampling with the ratio of the logged requests in [0, 1], the server
+// errors are always logged, default is 1.
+func AccessLogSampling(ratio float64) AccessLogOption {
+	return func(o *accessLogOptions) {
+		o.sample = ratio
+	}
+}
+
+// AccessLogTrustedProxies with the CIDRs of the trusted proxies, the remote IP is
+// taken from the X-Forwarded-For header if the request is from them, e.g. 10.0.0.0/8.
+// The invalid CIDRs are ignored.
+func AccessLogTrustedProxies(cidrs ...string) AccessLogOption {
+	return func(o *accessLogOptions) {
+		for _, cidr := range cidrs {
+			if !strings.Contains(cidr, "/") {
+				if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+					cidr += "/32"
+				} else {
+					cidr += "/128"
+				}
+			}
+			if _, n, err := net.ParseCIDR(cidr); err == nil {
+				o.proxies = append(o.proxies, n)
+			}
+		}
+	}
+}
+
+// AccessLog returns a filter logging the requests with the structured fields,
+// unlike the logging middleware it sees all the requests, e.g. the static files
+// and the requests rejected by the other filters.
+func AccessLog(logger log.Logger, opts ...AccessLogOption) FilterFunc {
+	o := &accessLogOptions{
+		logger: logger,
+		fields: defaultAccessLogFields,
+		sample: 1,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			rw := &accessLogWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rw, req)
+			level := log.LevelInfo
+			if rw.status >= http.StatusInternalServerError {
+				level = log.LevelError
+			} else if o.sample < 1 && rand.Float64() >= o.sample {
+				return
+			}
+			kvs := make([]interface{}, 0, len(o.fields)*2)
+			for _, f := range o.fields {
+				kvs = append(kvs, f, o.value(f, req, rw, start))
+			}
+			_ = log.WithContext(req.Context(), o.logger).Log(level, kvs...)
+		})
+	}
+}
+
+func (o *accessLogOptions) value(field string, req *http.Request, rw *accessLogWriter, start time.Time) interface{} {
+	switch field {
+	case AccessLogMethod:
+		return req.Method
+	case AccessLogPath:
+		return req.URL.Path
+	case AccessLogQuery:
+		return req.URL.RawQuery
+	case AccessLogStatus:
+		return rw.status
+	case AccessLogBytes:
+		return rw.bytes
+	case AccessLogLatency:
+		return time.Since(start).Seconds()
+	case AccessLogRemoteIP:
+		return o.remoteIP(req)
+	case AccessLogUserAgent:
+		return req.UserAgent()
+	case AccessLogTraceID:
+		return traceID(req)
+	}
+	return ""
+}
+
+// remoteIP returns the client IP, the X-Forwarded-For header is walked from the right
+// and the first IP which is not a trusted proxy is the client.
+func (o *accessLogOptions) remoteIP(req *http.Request) string {
+	ip, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		ip = req.RemoteAddr
+	}
+	if !o.trusted(ip) {
+		return ip
+	}
+	hops := strings.Split(strings.Join(req.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		ip = hop
+		if !o.trusted(hop) {
+			break
+		}
+	}
+	return ip
+}
+
+func (o *accessLogOptions) trusted(s string) bool {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return false
+	}
+	for _, n := range o.proxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// traceID returns the trace id of the span, or the traceparent header.
+func traceID(req *http.Request) string {
+	if sc := trace.SpanContextFromContext(req.Context()); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	// traceparent: 00-{trace-id}-{parent-id}-{flags}
+	if parts := strings.Split(req.Header.Get("traceparent"), "-"); len(parts) == 4 && len(parts[1]) == 32 {
+		return parts[1]
+	}
+	return ""
+}
+
+// accessLogWriter records the status and the bytes of the response.
+type accessLogWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (w *accessLogWriter) WriteHeader(code int) {
+	if !w.wroteHeader && !informational(code) {
+		w.wroteHeader = true
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *accessLogWriter) Write(data []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(data)
+	w.bytes += n
+	return n, err
+}
+
+func (w *accessLogWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *accessLogWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, ErrNotHijacker
+	}
+	w.status = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+type testLogger struct {
+	mu   sync.Mutex
+	logs []map[string]interface{}
+}
+
+func (l *testLogger) Log(level log.Level, keyvals ...interface{}) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	m := map[string]interface{}{"level": level}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		m[keyvals[i].(string)] = keyvals[i+1]
+	}
+	l.logs = append(l.logs, m)
+	return nil
+}
+
+func TestAccessLog(t *testing.T) {
+	logger := &testLogger{}
+	srv := NewServer(Filter(AccessLog(logger, AccessLogTrustedProxies("10.0.0.0/8", "127.0.0.1"))))
+	srv.Route("/").GET("/users/{id}", func(ctx Context) error {
+		return ctx.String(http.StatusOK, "kratos")
+	})
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.RemoteAddr = "127.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "1.2.3.4, 10.0.0.2")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	srv.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(logger.logs) != 1 {
+		t.Fatalf("expect 1 log, got %v", len(logger.logs))
+	}
+	got := logger.logs[0]
+	if _, ok := got[AccessLogLatency].(float64); !ok {
+		t.Errorf("expect the latency, got %v", got[AccessLogLatency])
+	}
+	delete(got, AccessLogLatency)
+	want := map[string]interface{}{
+		"level":           log.LevelInfo,
+		AccessLogMethod:   http.MethodGet,
+		AccessLogPath:     "/users/1",
+		AccessLogStatus:   http.StatusOK,
+		AccessLogBytes:    6,
+		AccessLogRemoteIP: "1.2.3.4",
+		AccessLogTraceID:  "4bf92f3577b34da6a3ce929d0e0e4736",
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("expect %v, got %v", want, got)
+	}
+}
+
+func TestAccessLogSampling(t *testing.T) {
+	logger := &testLogger{}
+	srv := NewServer(Filter(AccessLog(logger, AccessLogSampling(0), AccessLogFields(AccessLogStatus))))
+	srv.Route("/").GET("/ok", func(ctx Context) error {
+		return ctx.String(http.StatusOK, "ok")
+	})
+	srv.Route("/").GET("/error", func(ctx Context) error {
+		return ctx.String(http.StatusInternalServerError, "error")
+	})
+	srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ok", nil))
+	srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/error", nil))
+	want := []map[string]interface{}{{"level": log.LevelError, AccessLogStatus: http.StatusInternalServerError}}
+	if !reflect.DeepEqual(want, logger.logs) {
+		t.Errorf("expect %v, got %v", want, logger.logs)
+	}
+}
+
+func TestAccessLogRemoteIP(t *testing.T) {
+	o := &accessLogOptions{}
+	AccessLogTrustedProxies("10.0.0.0/8", "invalid/cidr")(o)
+	tests := []struct {
+		remote string
+		xff    string
+		want   string
+	}{
+		{"1.1.1.1:80", "2.2.2.2", "1.1.1.1"},
+		{"10.0.0.1:80", "2.2.2.2", "2.2.2.2"},
+		{"10.0.0.1:80", "3.3.3.3, 2.2.2.2, 10.0.0.3", "2.2.2.2"},
+		{"10.0.0.1:80", "10.0.0.3", "10.0.0.3"},
+		{"10.0.0.1:80", "", "10.0.0.1"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = test.remote
+		if test.xff != "" {
+			req.Header.Set("X-Forwarded-For", test.xff)
+		}
+		if got := o.remoteIP(req); got != test.want {
+			t.Errorf("%v %v: expect %v, got %v", test.remote, test.xff, test.want, got)
+		}
+	}
+}
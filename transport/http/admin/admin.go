@@ -0,0 +1,174 @@
+// Package admin provides the opt-in admin endpoints of the HTTP server: pprof,
+// the memory stats, the build info and the runtime stats. The profiles and the memory
+// stats are served by net/http/pprof and expvar, which register them on http.DefaultServeMux
+// as well, the default fallback of the servers for the unmatched requests, set the
+// NotFoundHandler of the servers not serving the admin endpoints to avoid serving them
+// without the auth.
+//
+// The endpoints are served on the server:
+//
+//	admin.Register(srv, admin.WithAuth(admin.BasicAuth("admin", "secret")))
+//
+// or on a separate listener, e.g. only reachable in the cluster:
+//
+//	app := kratos.New(kratos.Server(srv, admin.NewServer("127.0.0.1:9090")))
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/go-kratos/kratos/v2"
+	"github.com/go-kratos/kratos/v2/errors"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// Prefix is the path prefix of the admin endpoints.
+const Prefix = "/debug/"
+
+// ErrUnauthorized is returned by BasicAuth when the credentials are invalid.
+var ErrUnauthorized = errors.Unauthorized("UNAUTHORIZED", "admin: unauthorized")
+
+// Option is the admin endpoints option.
+type Option func(*options)
+
+type options struct {
+	filters []khttp.FilterFunc
+}
+
+// WithAuth with the filters protecting the admin endpoints, e.g. BasicAuth.
+func WithAuth(filters ...khttp.FilterFunc) Option {
+	return func(o *options) {
+		o.filters = filters
+	}
+}
+
+// NewHandler returns the handler of the admin endpoints:
+//   - /debug/pprof/: the pprof profiles
+//   - /debug/vars: the expvar variables, e.g. the command line and the memory stats
+//   - /debug/build: the application info and the build info
+//   - /debug/runtime: the goroutine, memory and GC stats
+//
+// The application info is read from the kratos.AppInfo of the request context,
+// which is the context the server is started with by kratos.App.
+func NewHandler(opts ...Option) http.Handler {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(Prefix+"pprof/", pprofHandler())
+	mux.Handle(Prefix+"vars", expvar.Handler())
+	mux.HandleFunc(Prefix+"build", buildHandler)
+	mux.HandleFunc(Prefix+"runtime", runtimeHandler)
+	return khttp.FilterChain(o.filters...)(mux)
+}
+
+// Register registers the admin endpoints on the server, the long profiles, e.g.
+// /debug/pprof/profile, need http.RouteTimeout("/debug/*", 0) on the server.
+func Register(srv *khttp.Server, opts ...Option) {
+	srv.HandlePrefix(Prefix, NewHandler(opts...))
+}
+
+// NewServer returns an HTTP server serving only the admin endpoints on the address,
+// it should be run by kratos.App with the other servers.
+func NewServer(addr string, opts ...Option) *khttp.Server {
+	srv := khttp.NewServer(khttp.Address(addr), khttp.Timeout(0))
+	Register(srv, opts...)
+	return srv
+}
+
+// BasicAuth returns a filter checking the HTTP basic authentication credentials,
+// the requests with invalid credentials are rejected by ErrUnauthorized.
+func BasicAuth(username, password string) khttp.FilterFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			u, p, ok := req.BasicAuth()
+			if !ok ||
+				subtle.ConstantTimeCompare([]byte(u), []byte(username)) != 1 ||
+				subtle.ConstantTimeCompare([]byte(p), []byte(password)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Basic realm="admin"`)
+				khttp.DefaultErrorEncoder(w, req, ErrUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+type buildInfo struct {
+	ID        string            `json:"id,omitempty"`
+	Name      string            `json:"name,omitempty"`
+	Version   string            `json:"version,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	GoVersion string            `json:"go_version"`
+	Path      string            `json:"path,omitempty"`
+	Module    string            `json:"module,omitempty"`
+	// Settings 构建参数，例如 vcs.revision, vcs.time
+	Settings map[string]string `json:"settings,omitempty"`
+}
+
+func buildHandler(w http.ResponseWriter, req *http.Request) {
+	info := buildInfo{GoVersion: runtime.Version()}
+	if app, ok := kratos.FromContext(req.Context()); ok {
+		info.ID = app.ID()
+		info.Name = app.Name()
+		info.Version = app.Version()
+		info.Metadata = app.Metadata()
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.Path = bi.Path
+		info.Module = bi.Main.Version
+		if len(bi.Settings) > 0 {
+			info.Settings = make(map[string]string, len(bi.Settings))
+			for _, s := range bi.Settings {
+				info.Settings[s.Key] = s.Value
+			}
+		}
+	}
+	writeJSON(w, info)
+}
+
+type runtimeStats struct {
+	Goroutines  int       `json:"goroutines"`
+	GOMAXPROCS  int       `json:"gomaxprocs"`
+	NumCPU      int       `json:"num_cpu"`
+	HeapAlloc   uint64    `json:"heap_alloc"`
+	HeapSys     uint64    `json:"heap_sys"`
+	HeapObjects uint64    `json:"heap_objects"`
+	NumGC       uint32    `json:"num_gc"`
+	PauseTotal  string    `json:"gc_pause_total"`
+	LastGC      time.Time `json:"last_gc"`
+}
+
+func runtimeHandler(w http.ResponseWriter, _ *http.Request) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	stats := runtimeStats{
+		Goroutines:  runtime.NumGoroutine(),
+		GOMAXPROCS:  runtime.GOMAXPROCS(0),
+		NumCPU:      runtime.NumCPU(),
+		HeapAlloc:   ms.HeapAlloc,
+		HeapSys:     ms.HeapSys,
+		HeapObjects: ms.HeapObjects,
+		NumGC:       ms.NumGC,
+		PauseTotal:  time.Duration(ms.PauseTotalNs).String(),
+	}
+	if ms.LastGC > 0 {
+		stats.LastGC = time.Unix(0, int64(ms.LastGC))
+	}
+	writeJSON(w, stats)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, fmt.Sprintf("admin: %v", err), http.StatusInternalServerError)
+	}
+}
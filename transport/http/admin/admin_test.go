@@ -0,0 +1,87 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+func TestNewHandler(t *testing.T) {
+	h := NewHandler(WithAuth(BasicAuth("admin", "secret")))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/runtime", nil))
+	if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("expect 401, got %d", w.Code)
+	}
+
+	app := kratos.New(kratos.ID("1"), kratos.Name("helloworld"), kratos.Version("v1.0.0"))
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req = req.WithContext(kratos.NewContext(context.Background(), app))
+		req.SetBasicAuth("admin", "secret")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: expect 200, got %d", path, w.Code)
+		}
+		return w
+	}
+
+	var stats runtimeStats
+	if err := json.Unmarshal(get("/debug/runtime").Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Goroutines == 0 || stats.NumCPU == 0 {
+		t.Errorf("unexpected runtime stats: %+v", stats)
+	}
+	var info buildInfo
+	if err := json.Unmarshal(get("/debug/build").Body.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "helloworld" || info.Version != "v1.0.0" || info.GoVersion == "" {
+		t.Errorf("unexpected build info: %+v", info)
+	}
+	if body := get("/debug/vars").Body.String(); !strings.Contains(body, "memstats") {
+		t.Errorf("expect the memory stats, got %s", body)
+	}
+	if body := get("/debug/pprof/").Body.String(); !strings.Contains(body, "goroutine") {
+		t.Errorf("expect the profiles listed, got %s", body)
+	}
+	if body := get("/debug/pprof/goroutine?debug=1").Body.String(); !strings.Contains(body, "goroutine profile") {
+		t.Errorf("expect the goroutine profile, got %s", body)
+	}
+	if w := get("/debug/pprof/profile?seconds=1"); w.Body.Len() == 0 {
+		t.Error("expect the cpu profile")
+	}
+	get("/debug/pprof/cmdline")
+	get("/debug/pprof/symbol")
+}
+
+// TestNotFoundHandler checks that the endpoints registered on http.DefaultServeMux by
+// net/http/pprof and expvar are not served by the servers with the NotFoundHandler.
+func TestNotFoundHandler(t *testing.T) {
+	srv := khttp.NewServer(khttp.NotFoundHandler(func(khttp.Context) error { return khttp.ErrNotFound }))
+	for _, path := range []string{"/debug/vars", "/debug/pprof/"} {
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s: expect 404, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestNewServer(t *testing.T) {
+	srv := NewServer("127.0.0.1:0")
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/runtime", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expect 200, got %d", w.Code)
+	}
+}
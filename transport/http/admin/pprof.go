@@ -0,0 +1,17 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/pprof"
+)
+
+// pprofHandler serves the profiles by net/http/pprof.
+func pprofHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(Prefix+"pprof/", pprof.Index)
+	mux.HandleFunc(Prefix+"pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc(Prefix+"pprof/profile", pprof.Profile)
+	mux.HandleFunc(Prefix+"pprof/symbol", pprof.Symbol)
+	mux.HandleFunc(Prefix+"pprof/trace", pprof.Trace)
+	return mux
+}
@@ -27,3 +27,21 @@ func BindForm(req *http.Request, target interface{}) error {
 	}
 	return nil
 }
+
+// BindMultipartForm bind the values of the multipart form to target, the files
+// larger than maxMemory are stored in temporary files, see http.Request.ParseMultipartForm.
+func BindMultipartForm(req *http.Request, maxMemory int64, target interface{}) error {
+	if req.MultipartForm == nil {
+		if err := req.ParseMultipartForm(maxMemory); err != nil {
+			if se := new(errors.Error); errors.As(err, &se) {
+				return err
+			}
+			return errors.BadRequest("CODEC", err.Error())
+		}
+	}
+	values := url.Values(req.MultipartForm.Value)
+	if err := encoding.GetCodec(form.Name).Unmarshal([]byte(values.Encode()), target); err != nil {
+		return errors.BadRequest("CODEC", err.Error())
+	}
+	return nil
+}
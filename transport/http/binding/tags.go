@@ -0,0 +1,145 @@
+package binding
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// Reason is the reason of the errors binding the parameters.
+const Reason = "PARAM"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// BindValue parses the string value into the target pointer,
+// e.g. *int, *bool, *string, *float64, *time.Duration.
+func BindValue(value string, target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("binding: target must be a non-nil pointer, got %T", target)
+	}
+	return setValue(rv.Elem(), value)
+}
+
+// BindTags binds the path vars, the query and the header into the struct fields
+// by the `path`, `query` and `header` tags, e.g.:
+//
+//	type Request struct {
+//		ID    int64    `path:"id"`
+//		Tags  []string `query:"tag"`
+//		Token string   `header:"X-Token"`
+//	}
+//
+// A field failed to parse is returned as a 400 error.
+func BindTags(req *http.Request, vars map[string]string, target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("binding: target must be a non-nil struct pointer, got %T", target)
+	}
+	rv = rv.Elem()
+	query := req.URL.Query()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		var (
+			source string
+			name   string
+			values []string
+		)
+		if name = field.Tag.Get("path"); name != "" {
+			source = "path"
+			if v, ok := vars[name]; ok {
+				values = []string{v}
+			}
+		} else if name = field.Tag.Get("query"); name != "" {
+			source = "query"
+			values = query[name]
+		} else if name = field.Tag.Get("header"); name != "" {
+			source = "header"
+			values = req.Header.Values(name)
+		} else {
+			continue
+		}
+		if len(values) == 0 {
+			continue
+		}
+		if err := setValues(rv.Field(i), values); err != nil {
+			return errors.BadRequest(Reason, fmt.Sprintf("invalid %s parameter %q: %v", source, name, err))
+		}
+	}
+	return nil
+}
+
+func setValues(rv reflect.Value, values []string) error {
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+		slice := reflect.MakeSlice(rv.Type(), len(values), len(values))
+		for i, v := range values {
+			if err := setValue(slice.Index(i), v); err != nil {
+				return err
+			}
+		}
+		rv.Set(slice)
+		return nil
+	}
+	return setValue(rv, values[0])
+}
+
+func setValue(rv reflect.Value, value string) error {
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	if rv.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		rv.SetInt(int64(d))
+		return nil
+	}
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(f)
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported type %s", rv.Type())
+		}
+		rv.SetBytes([]byte(value))
+	default:
+		return fmt.Errorf("unsupported type %s", rv.Type())
+	}
+	return nil
+}
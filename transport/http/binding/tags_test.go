@@ -0,0 +1,77 @@
+package binding
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestBindValue(t *testing.T) {
+	var (
+		i int32
+		u uint
+		f float64
+		b bool
+		s string
+		d time.Duration
+	)
+	tests := []struct {
+		value  string
+		target interface{}
+		want   interface{}
+	}{
+		{"-12", &i, int32(-12)},
+		{"12", &u, uint(12)},
+		{"1.5", &f, 1.5},
+		{"true", &b, true},
+		{"kratos", &s, "kratos"},
+		{"1.5s", &d, time.Millisecond * 1500},
+	}
+	for _, test := range tests {
+		if err := BindValue(test.value, test.target); err != nil {
+			t.Fatalf("%s: expect no error, got %v", test.value, err)
+		}
+		if got := reflect.ValueOf(test.target).Elem().Interface(); !reflect.DeepEqual(test.want, got) {
+			t.Errorf("expect %v, got %v", test.want, got)
+		}
+	}
+	if err := BindValue("300", new(int8)); err == nil {
+		t.Errorf("expect an out of range error")
+	}
+	if err := BindValue("1", i); err == nil {
+		t.Errorf("expect a non-pointer error")
+	}
+}
+
+func TestBindTags(t *testing.T) {
+	type request struct {
+		ID      int64    `path:"id"`
+		Tags    []string `query:"tag"`
+		Page    *int     `query:"page"`
+		Token   string   `header:"X-Token"`
+		Missing string   `query:"missing"`
+		Ignored string
+	}
+	req := httptest.NewRequest("GET", "/users/1?tag=a&tag=b&page=2", nil)
+	req.Header.Set("X-Token", "secret")
+	var got request
+	if err := BindTags(req, map[string]string{"id": "1"}, &got); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	page := 2
+	want := request{ID: 1, Tags: []string{"a", "b"}, Page: &page, Token: "secret"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("expect %+v, got %+v", want, got)
+	}
+
+	err := BindTags(req, map[string]string{"id": "abc"}, &got)
+	if e := errors.FromError(err); e.Code != 400 || e.Reason != Reason {
+		t.Errorf("expect a 400 error, got %v", err)
+	}
+	if err = BindTags(req, nil, got); err == nil {
+		t.Errorf("expect a non-pointer error")
+	}
+}
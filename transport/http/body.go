@@ -0,0 +1,33 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// ErrRequestBodyTooLarge is returned when the request body exceeds the limit.
+var ErrRequestBodyTooLarge = errors.New(http.StatusRequestEntityTooLarge, "REQUEST_BODY_TOO_LARGE", "request body too large")
+
+// maxBytesReader wraps http.MaxBytesReader, and reports ErrRequestBodyTooLarge
+// instead of the plain error once the limit is exceeded.
+type maxBytesReader struct {
+	io.ReadCloser
+	limit int64
+	read  int64
+}
+
+func newMaxBytesReader(w http.ResponseWriter, body io.ReadCloser, limit int64) *maxBytesReader {
+	return &maxBytesReader{ReadCloser: http.MaxBytesReader(w, body, limit), limit: limit}
+}
+
+func (r *maxBytesReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.read += int64(n)
+	if err != nil && err != io.EOF && r.read >= r.limit {
+		return n, ErrRequestBodyTooLarge.WithCause(fmt.Errorf("request body exceeds %d bytes", r.limit))
+	}
+	return n, err
+}
@@ -0,0 +1,22 @@
+package http
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestMaxBytesReader(t *testing.T) {
+	r := newMaxBytesReader(httptest.NewRecorder(), io.NopCloser(strings.NewReader("hello")), 5)
+	if data, err := io.ReadAll(r); err != nil || string(data) != "hello" {
+		t.Errorf("expect hello, got %s %v", data, err)
+	}
+	r = newMaxBytesReader(httptest.NewRecorder(), io.NopCloser(strings.NewReader("hello kratos")), 5)
+	_, err := io.ReadAll(r)
+	if !errors.Is(err, ErrRequestBodyTooLarge) {
+		t.Errorf("expect %v, got %v", ErrRequestBodyTooLarge, err)
+	}
+}
@@ -2,6 +2,9 @@ package http
 
 import (
 	"net/http"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/selector"
 )
 
 // CallOption configures a Call before it starts or extracts information from
@@ -20,6 +23,20 @@ type callInfo struct {
 	contentType  string
 	operation    string
 	pathTemplate string
+	// nodeFilters overrides the node filters of the client if not nil.
+	nodeFilters []selector.NodeFilter
+	balancer    selector.Balancer
+	// idempotent marks the call as safe to retry.
+	idempotent bool
+	// noRetry disables the retry and the hedging of the client.
+	noRetry bool
+	// timeout overrides the timeout of the client if it is positive.
+	timeout time.Duration
+	header  http.Header
+	// stream returns the response body to the caller instead of decoding it.
+	stream bool
+	// doneOnClose reports the selector when the response body is closed.
+	doneOnClose bool
 }
 
 // EmptyCallOption does not alter the Call configuration.
@@ -50,6 +67,15 @@ func (o ContentTypeCallOption) before(c *callInfo) error {
 	return nil
 }
 
+// addHeader adds the request headers of the call to h.
+func (c *callInfo) addHeader(h http.Header) {
+	for k, vs := range c.header {
+		for _, v := range vs {
+			h.Add(k, v)
+		}
+	}
+}
+
 func defaultCallInfo(path string) callInfo {
 	return callInfo{
 		contentType:  "application/json",
@@ -107,3 +133,126 @@ func (o HeaderCallOption) after(_ *callInfo, cs *csAttempt) {
 		*o.header = cs.res.Header
 	}
 }
+
+// Trailer returns a CallOptions that retrieves the http response trailer
+// from server reply, it is available once the response body is read.
+func Trailer(trailer *http.Header) CallOption {
+	return TrailerCallOption{trailer: trailer}
+}
+
+// TrailerCallOption is retrieve response trailer for client call
+type TrailerCallOption struct {
+	EmptyCallOption
+	trailer *http.Header
+}
+
+func (o TrailerCallOption) after(_ *callInfo, cs *csAttempt) {
+	if cs.res != nil && cs.res.Trailer != nil {
+		*o.trailer = cs.res.Trailer
+	}
+}
+
+// NodeFilter returns a CallOptions that overrides the node filters of the
+// client for this call, e.g. routing some requests to canary nodes.
+func NodeFilter(filters ...selector.NodeFilter) CallOption {
+	return NodeFilterCallOption{Filters: append([]selector.NodeFilter{}, filters...)}
+}
+
+// NodeFilterCallOption is set node filters for client call
+type NodeFilterCallOption struct {
+	EmptyCallOption
+	Filters []selector.NodeFilter
+}
+
+func (o NodeFilterCallOption) before(c *callInfo) error {
+	c.nodeFilters = o.Filters
+	return nil
+}
+
+// Balancer returns a CallOptions that overrides the balancer of the client
+// selector for this call. The balancer should be built once and reused.
+func Balancer(b selector.Balancer) CallOption {
+	return BalancerCallOption{Balancer: b}
+}
+
+// BalancerCallOption is set balancer for client call
+type BalancerCallOption struct {
+	EmptyCallOption
+	Balancer selector.Balancer
+}
+
+func (o BalancerCallOption) before(c *callInfo) error {
+	c.balancer = o.Balancer
+	return nil
+}
+
+// Idempotent marks the call as idempotent, so that it is retried by the
+// retry policy of the client whatever the method is.
+func Idempotent() CallOption {
+	return IdempotentCallOption{}
+}
+
+// IdempotentCallOption is the call option marking the call as idempotent.
+type IdempotentCallOption struct {
+	EmptyCallOption
+}
+
+func (IdempotentCallOption) before(c *callInfo) error {
+	c.idempotent = true
+	return nil
+}
+
+// NoRetry disables the retry and the hedging of the client for this call.
+func NoRetry() CallOption {
+	return NoRetryCallOption{}
+}
+
+// NoRetryCallOption is the call option disabling the retry and the hedging.
+type NoRetryCallOption struct {
+	EmptyCallOption
+}
+
+func (NoRetryCallOption) before(c *callInfo) error {
+	c.noRetry = true
+	return nil
+}
+
+// CallTimeout returns a CallOptions that overrides the timeout of the client
+// for this call, it applies to each attempt of the retries.
+func CallTimeout(d time.Duration) CallOption {
+	return CallTimeoutCallOption{Timeout: d}
+}
+
+// CallTimeoutCallOption is set timeout for client call
+type CallTimeoutCallOption struct {
+	EmptyCallOption
+	Timeout time.Duration
+}
+
+func (o CallTimeoutCallOption) before(c *callInfo) error {
+	c.timeout = o.Timeout
+	return nil
+}
+
+// RequestHeader returns a CallOptions that adds the headers to the request.
+func RequestHeader(header http.Header) CallOption {
+	return RequestHeaderCallOption{Header: header}
+}
+
+// RequestHeaderCallOption is add request header for client call
+type RequestHeaderCallOption struct {
+	EmptyCallOption
+	Header http.Header
+}
+
+func (o RequestHeaderCallOption) before(c *callInfo) error {
+	if c.header == nil {
+		c.header = make(http.Header, len(o.Header))
+	}
+	for k, vs := range o.Header {
+		for _, v := range vs {
+			c.header.Add(k, v)
+		}
+	}
+	return nil
+}
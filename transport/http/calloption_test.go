@@ -1,9 +1,16 @@
 package http
 
 import (
+	"context"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/selector/wrr"
 )
 
 func TestEmptyCallOptions(t *testing.T) {
@@ -96,3 +103,104 @@ func TestHeaderCallOption_after(t *testing.T) {
 		t.Errorf("want: %v,got: %v", &h, o.(HeaderCallOption).header)
 	}
 }
+
+func TestNodeFilterCallOption_before(t *testing.T) {
+	c := &callInfo{}
+	if err := NodeFilter().before(c); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if c.nodeFilters == nil || len(c.nodeFilters) != 0 {
+		t.Errorf("want: empty filters, got: %v", c.nodeFilters)
+	}
+	f := func(_ context.Context, nodes []selector.Node) []selector.Node { return nodes }
+	if err := NodeFilter(f).before(c); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(1, len(c.nodeFilters)) {
+		t.Errorf("want: %v, got: %v", 1, len(c.nodeFilters))
+	}
+}
+
+func TestBalancerCallOption_before(t *testing.T) {
+	b := (&wrr.Builder{}).Build()
+	c := &callInfo{}
+	if err := Balancer(b).before(c); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(b, c.balancer) {
+		t.Errorf("want: %v, got: %v", b, c.balancer)
+	}
+}
+
+func TestNoRetryCallOption_before(t *testing.T) {
+	c := &callInfo{}
+	if err := NoRetry().before(c); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !c.noRetry {
+		t.Errorf("want: %v, got: %v", true, c.noRetry)
+	}
+}
+
+func TestCallTimeoutCallOption_before(t *testing.T) {
+	c := &callInfo{}
+	if err := CallTimeout(time.Second).before(c); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(time.Second, c.timeout) {
+		t.Errorf("want: %v, got: %v", time.Second, c.timeout)
+	}
+}
+
+func TestRequestHeaderCallOption_before(t *testing.T) {
+	c := &callInfo{}
+	_ = RequestHeader(http.Header{"X-A": {"1"}}).before(c)
+	_ = RequestHeader(http.Header{"X-A": {"2"}, "X-B": {"3"}}).before(c)
+	want := http.Header{"X-A": {"1", "2"}, "X-B": {"3"}}
+	if !reflect.DeepEqual(want, c.header) {
+		t.Errorf("want: %v, got: %v", want, c.header)
+	}
+}
+
+func TestPerCallOptions(t *testing.T) {
+	var count int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(time.Millisecond * 100)
+			return
+		}
+		atomic.AddInt64(&count, 1)
+		if r.Header.Get("X-Fail") != "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Echo", r.Header.Get("X-Echo"))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+	client, err := NewClient(context.Background(),
+		WithEndpoint(ts.URL),
+		WithTimeout(time.Second),
+		WithRetry(RetryPolicy{Backoff: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		reply  map[string]string
+		header http.Header
+	)
+	err = client.Invoke(context.Background(), http.MethodGet, "/", nil, &reply, RequestHeader(http.Header{"X-Echo": {"kratos"}}), Header(&header))
+	if err != nil || header.Get("X-Echo") != "kratos" {
+		t.Errorf("expect the header to be echoed, got %v %v", header, err)
+	}
+	if err = client.Invoke(context.Background(), http.MethodGet, "/slow", nil, &reply, CallTimeout(time.Millisecond*10), NoRetry()); err == nil {
+		t.Errorf("expect a timeout error")
+	}
+	atomic.StoreInt64(&count, 0)
+	err = client.Invoke(context.Background(), http.MethodGet, "/", nil, &reply, RequestHeader(http.Header{"X-Fail": {"1"}}), NoRetry())
+	if err == nil || atomic.LoadInt64(&count) != 1 {
+		t.Errorf("expect 1 attempt, got %v %v", atomic.LoadInt64(&count), err)
+	}
+}
@@ -7,12 +7,14 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/go-kratos/kratos/v2/encoding"
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/internal/host"
 	"github.com/go-kratos/kratos/v2/internal/httputil"
+	"github.com/go-kratos/kratos/v2/metadata"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/selector"
@@ -54,6 +56,16 @@ type clientOptions struct {
 	middleware   []middleware.Middleware
 	block        bool
 	subsetSize   int
+	retry        *RetryPolicy
+	pool         poolOptions
+	hedge        *hedger
+	proxy        func(*http.Request) (*url.URL, error)
+	dial         dialOptions
+	exchange     func(http.RoundTripper) http.RoundTripper
+	mirror       *selector.MirrorConfig
+	// deadlineMargin is subtracted from the deadline propagated by the TimeoutHeader.
+	deadlineMargin    time.Duration
+	propagateDeadline bool
 }
 
 // WithSubset with client disocvery subset size.
@@ -92,7 +104,9 @@ func WithMiddleware(m ...middleware.Middleware) ClientOption {
 	}
 }
 
-// WithEndpoint with client addr.
+// WithEndpoint with client addr, e.g. 127.0.0.1:8000, discovery:///helloworld with
+// WithDiscovery, or static://10.0.0.1:8000,10.0.0.2:8000 balanced by the selector
+// without the discovery.
 func WithEndpoint(endpoint string) ClientOption {
 	return func(o *clientOptions) {
 		o.endpoint = endpoint
@@ -156,9 +170,35 @@ type Client struct {
 	cc       *http.Client
 	insecure bool
 	selector selector.Selector
+	pool     *connPool
 }
 
-// NewClient returns an HTTP client.
+// newTransport applies the proxy, dial, pool and TLS options to a clone of the transport,
+// so that the transport of the caller, e.g. http.DefaultTransport, is never modified.
+// The transports other than *http.Transport are returned as they are.
+func newTransport(o *clientOptions) (http.RoundTripper, *connPool) {
+	tr, ok := o.transport.(*http.Transport)
+	if !ok || (o.proxy == nil && o.dial.empty() && o.pool.empty() && o.tlsConf == nil) {
+		return o.transport, nil
+	}
+	tr = tr.Clone()
+	if o.proxy != nil {
+		tr.Proxy = o.proxy
+	}
+	if o.tlsConf != nil {
+		tr.TLSClientConfig = o.tlsConf
+	}
+	dialTransport(tr, o.dial)
+	return tr, tuneTransport(tr, o.pool)
+}
+
+// NewClient returns an HTTP client. The query of the endpoint overrides the options,
+// so that one binary talking to many upstreams can tune each of them, e.g.
+// discovery:///payments?subset=20&version=v2&selector=p2c:
+//
+//	subset: the discovery subset size, 0 means the subset is disabled.
+//	version: keeps the nodes of the version, appended to the node filters.
+//	selector: the selector builder registered by selector.RegisterBuilder.
 func NewClient(ctx context.Context, opts ...ClientOption) (*Client, error) {
 	options := clientOptions{
 		ctx:          ctx,
@@ -172,10 +212,10 @@ func NewClient(ctx context.Context, opts ...ClientOption) (*Client, error) {
 	for _, o := range opts {
 		o(&options)
 	}
-	if options.tlsConf != nil {
-		if tr, ok := options.transport.(*http.Transport); ok {
-			tr.TLSClientConfig = options.tlsConf
-		}
+	var pool *connPool
+	options.transport, pool = newTransport(&options)
+	if options.exchange != nil {
+		options.transport = options.exchange(options.transport)
 	}
 	insecure := options.tlsConf == nil
 	// target 实际上就是go的url.Parse()的结果。
@@ -184,10 +224,22 @@ func NewClient(ctx context.Context, opts ...ClientOption) (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	// 在当前代码源文件的第一行，使用init()函数，为GlobalSelector 做了注册，注册为轮循的负载均衡器
-	selector := selector.GlobalSelector().Build()
+	// 目标URI的参数覆盖client的选项
+	builder, err := applyTarget(&options, target)
+	if err != nil {
+		return nil, err
+	}
+	if options.mirror != nil {
+		builder = selector.MirrorBuilder(builder, options.mirror)
+	}
+	selector := builder.Build()
 	var r *resolver
-	if options.discovery != nil { // 在有服务发现的前提下，我们才做负载均衡
+	if target.Scheme == "static" {
+		// 静态地址列表，不依赖服务发现做负载均衡
+		if r, err = newStaticResolver(target, selector); err != nil {
+			return nil, err
+		}
+	} else if options.discovery != nil { // 在有服务发现的前提下，我们才做负载均衡
 		// 如果要做服务发现，target.Scheme必须是discovery，不能写成http,https.
 		if target.Scheme == "discovery" {
 			if r, err = newResolver(ctx, options.discovery, target, selector, options.block, insecure, options.subsetSize); err != nil {
@@ -207,41 +259,78 @@ func NewClient(ctx context.Context, opts ...ClientOption) (*Client, error) {
 			Transport: options.transport,
 		},
 		selector: selector,
+		pool:     pool,
 	}, nil
 }
 
+// propagateHeader sets the request id and the baggage of ctx to the header if they are not set.
+func propagateHeader(ctx context.Context, h http.Header) {
+	if id, ok := transport.FromRequestIDContext(ctx); ok && h.Get(transport.RequestIDHeader) == "" {
+		h.Set(transport.RequestIDHeader, id)
+	}
+	if b := metadata.InjectBaggage(ctx); b != "" && h.Get(metadata.BaggageHeader) == "" {
+		h.Set(metadata.BaggageHeader, b)
+	}
+}
+
 // Invoke makes a rpc call procedure for remote service.
 func (client *Client) Invoke(ctx context.Context, method, path string, args interface{}, reply interface{}, opts ...CallOption) error {
+	ctx, req, c, err := client.newRequest(ctx, method, path, args, opts)
+	if err != nil {
+		return err
+	}
+	return client.invoke(ctx, req, args, reply, c, opts...)
+}
+
+// newRequest encodes the args to the request of the call, and returns it with the client context.
+func (client *Client) newRequest(ctx context.Context, method, path string, args interface{}, opts []CallOption) (context.Context, *http.Request, callInfo, error) {
 	var (
 		contentType string
 		body        io.Reader
 	)
-	c := defaultCallInfo(path)
-	// 调用前的钩子函数
-	for _, o := range opts {
-		if err := o.before(&c); err != nil {
-			return err
-		}
+	c, err := newCallInfo(path, opts)
+	if err != nil {
+		return nil, nil, c, err
 	}
 	if args != nil {
 		data, err := client.opts.encoder(ctx, c.contentType, args)
 		if err != nil {
-			return err
+			return nil, nil, c, err
 		}
 		contentType = c.contentType
 		body = bytes.NewReader(data)
 	}
+	ctx, req, err := client.buildRequest(ctx, method, path, contentType, body, c)
+	return ctx, req, c, err
+}
+
+// newCallInfo returns the call info of the path applied the call options.
+func newCallInfo(path string, opts []CallOption) (callInfo, error) {
+	c := defaultCallInfo(path)
+	// 调用前的钩子函数
+	for _, o := range opts {
+		if err := o.before(&c); err != nil {
+			return c, err
+		}
+	}
+	return c, nil
+}
+
+// buildRequest returns the request of the call with the body, and the client context.
+func (client *Client) buildRequest(ctx context.Context, method, path, contentType string, body io.Reader, c callInfo) (context.Context, *http.Request, error) {
 	url := fmt.Sprintf("%s://%s%s", client.target.Scheme, client.target.Authority, path)
 	req, err := http.NewRequest(method, url, body)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	if contentType != "" {
-		req.Header.Set("Content-Type", c.contentType)
+		req.Header.Set("Content-Type", contentType)
 	}
 	if client.opts.userAgent != "" {
 		req.Header.Set("User-Agent", client.opts.userAgent)
 	}
+	c.addHeader(req.Header)
+	propagateHeader(ctx, req.Header)
 	ctx = transport.NewClientContext(ctx, &Transport{
 		endpoint:     client.opts.endpoint,
 		reqHeader:    headerCarrier(req.Header),
@@ -249,23 +338,30 @@ func (client *Client) Invoke(ctx context.Context, method, path string, args inte
 		request:      req,
 		pathTemplate: c.pathTemplate,
 	})
-	return client.invoke(ctx, req, args, reply, c, opts...)
+	return ctx, req, nil
 }
 
 func (client *Client) invoke(ctx context.Context, req *http.Request, args interface{}, reply interface{}, c callInfo, opts ...CallOption) error {
+	c.doneOnClose = true
 	h := func(ctx context.Context, in interface{}) (interface{}, error) {
-		res, err := client.do(req.WithContext(ctx))
-		if res != nil {
+		res, err := client.do(req.WithContext(ctx), c)
+		after := func() {
 			cs := csAttempt{res: res}
 			for _, o := range opts {
 				o.after(&c, &cs)
 			}
 		}
 		if err != nil {
+			if res != nil {
+				after()
+			}
 			return nil, err
 		}
 		defer res.Body.Close()
-		if err := client.opts.decoder(ctx, res, reply); err != nil {
+		err = client.opts.decoder(ctx, res, reply)
+		// 读取完body之后，trailer才可用
+		after()
+		if err != nil {
 			return nil, err
 		}
 		return reply, nil
@@ -288,11 +384,35 @@ func (client *Client) Do(req *http.Request, opts ...CallOption) (*http.Response,
 			return nil, err
 		}
 	}
+	c.addHeader(req.Header)
+	propagateHeader(req.Context(), req.Header)
+	return client.do(req, c)
+}
 
-	return client.do(req)
+func (client *Client) do(req *http.Request, c callInfo) (*http.Response, error) {
+	if client.opts.propagateDeadline || client.r != nil {
+		timeout := client.cc.Timeout
+		if c.timeout > 0 || c.stream {
+			timeout = c.timeout
+		}
+		req = withTimeoutHeader(req, timeout, client.opts.deadlineMargin)
+	}
+	if h := client.opts.hedge; h != nil && !c.noRetry && h.allow(req, c) {
+		return client.doHedge(req, c, h)
+	}
+	if p := client.opts.retry; p != nil && !c.noRetry && p.allow(req, c) {
+		return client.doRetry(req, c, p)
+	}
+	resp, err := client.roundTrip(req, c)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
-func (client *Client) do(req *http.Request) (*http.Response, error) {
+// roundTrip sends the request to a node once, the response is returned
+// with the error if the node replied, e.g. with the Retry-After header.
+func (client *Client) roundTrip(req *http.Request, c callInfo) (*http.Response, error) {
 	var done func(context.Context, selector.DoneInfo)
 	if client.r != nil {
 		// 有服务发现的情况
@@ -302,7 +422,21 @@ func (client *Client) do(req *http.Request) (*http.Response, error) {
 		)
 		// 负载均衡器来选择请求的节点
 		// done 执行完成http请求之后，调用done方法，来做一些统计，用于计算负载吧？
-		if node, done, err = client.selector.Select(req.Context(), selector.WithNodeFilter(client.opts.nodeFilters...)); err != nil { // 用负载均衡selector选出一个可用节点
+		// 调用级别的过滤器和负载均衡器优先
+		filters := client.opts.nodeFilters
+		if c.nodeFilters != nil {
+			filters = c.nodeFilters
+		}
+		opts := []selector.SelectOption{selector.WithNodeFilter(filters...)}
+		if c.balancer != nil {
+			opts = append(opts, selector.WithBalancer(c.balancer))
+		}
+		ctx := req.Context()
+		var m selector.Mirror
+		if client.opts.mirror != nil {
+			ctx = selector.NewMirrorContext(ctx, &m)
+		}
+		if node, done, err = client.selector.Select(ctx, opts...); err != nil { // 用负载均衡selector选出一个可用节点
 			return nil, errors.ServiceUnavailable("NODE_NOT_FOUND", err.Error())
 		}
 		if client.insecure {
@@ -314,20 +448,76 @@ func (client *Client) do(req *http.Request) (*http.Response, error) {
 		// 依据服务发现获取到的地址，修改请求地址
 		req.URL.Host = node.Address()
 		req.Host = node.Address()
+		notifyPicked(req.Context(), node.Address())
+		if m.Node != nil {
+			client.mirror(req, m.Node.Address())
+		}
 	}
 
 	// 使用原生http client发送请求
-	resp, err := client.cc.Do(req)
+	release := func() {}
+	if client.pool != nil {
+		var ctx context.Context
+		ctx, release = client.pool.trace(req.Context())
+		req = req.WithContext(ctx)
+	}
+	start := time.Now()
+	cc := client.cc
+	if c.timeout > 0 || c.stream {
+		// 调用级别的超时，流式调用的body由调用方读取，不使用client的超时
+		cp := *cc
+		cp.Timeout = c.timeout
+		cc = &cp
+	}
+	resp, err := cc.Do(req)
+	if err != nil {
+		release()
+	} else if client.pool != nil {
+		resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	}
 	if err == nil {
 		err = client.opts.errorDecoder(req.Context(), resp)
 	}
 	if done != nil {
-		done(req.Context(), selector.DoneInfo{Err: err})
+		di := selector.DoneInfo{
+			Err:     err,
+			Latency: time.Since(start),
+			Attempt: selector.AttemptFromContext(req.Context()),
+		}
+		if req.ContentLength > 0 {
+			di.RequestSize = req.ContentLength
+		}
+		if resp != nil {
+			// 响应头中可能携带后端上报的负载
+			di.ReplyMD = resp.Header
+			if resp.ContentLength > 0 {
+				di.ResponseSize = resp.ContentLength
+			}
+		}
+		if c.doneOnClose && err == nil {
+			// 在body关闭时上报，trailer在读取完body之后才可用
+			ctx := req.Context()
+			resp.Body = &doneBody{ReadCloser: resp.Body, done: func(n int64, err error) {
+				di.Err = err
+				di.ReplyMD = replyMD{header: resp.Header, trailer: resp.Trailer}
+				di.Latency = time.Since(start)
+				di.ResponseSize = n
+				done(ctx, di)
+			}}
+		} else {
+			done(req.Context(), di)
+		}
 	}
-	if err != nil {
-		return nil, err
+	return resp, err
+}
+
+// Inspect returns a snapshot of the client selector for debugging,
+// e.g. srv.Handle("/debug/selector", selector.InspectHandler(client)).
+func (client *Client) Inspect() selector.Snapshot {
+	if i, ok := client.selector.(selector.Inspector); ok {
+		return i.Inspect()
 	}
-	return resp, nil
+	return selector.Snapshot{}
 }
 
 // Close tears down the Transport and all underlying connections.
@@ -16,9 +16,11 @@ import (
 	"time"
 
 	kratoserrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/metadata"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/transport"
 )
 
 type mockRoundTripper struct{}
@@ -362,3 +364,19 @@ func TestNewClient(t *testing.T) {
 		t.Error("err should be equal to encoder error")
 	}
 }
+
+func TestPropagateHeader(t *testing.T) {
+	ctx := transport.NewRequestIDContext(context.Background(), "id")
+	ctx, _ = metadata.WithBaggage(ctx, "tenant", "kratos")
+	h := http.Header{}
+	propagateHeader(ctx, h)
+	if h.Get(transport.RequestIDHeader) != "id" || h.Get(metadata.BaggageHeader) != "tenant=kratos" {
+		t.Errorf("unexpected header: %v", h)
+	}
+	h = http.Header{}
+	h.Set(transport.RequestIDHeader, "explicit")
+	propagateHeader(ctx, h)
+	if h.Get(transport.RequestIDHeader) != "explicit" {
+		t.Errorf("expect the explicit header to be kept, got %v", h.Get(transport.RequestIDHeader))
+	}
+}
@@ -6,8 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-
-	"github.com/gorilla/mux"
+	"strings"
 
 	"github.com/go-kratos/kratos/v2/encoding"
 	"github.com/go-kratos/kratos/v2/errors"
@@ -44,7 +43,7 @@ type EncodeErrorFunc func(http.ResponseWriter, *http.Request, error)
 
 // DefaultRequestVars decodes the request vars to object.
 func DefaultRequestVars(r *http.Request, v interface{}) error {
-	raws := mux.Vars(r)
+	raws := routeVars(r)
 	vars := make(url.Values, len(raws))
 	for k, v := range raws {
 		vars[k] = []string{v}
@@ -59,6 +58,9 @@ func DefaultRequestQuery(r *http.Request, v interface{}) error {
 
 // DefaultRequestDecoder decodes the request body to object.
 func DefaultRequestDecoder(r *http.Request, v interface{}) error {
+	if isMultipart(r) {
+		return binding.BindMultipartForm(r, defaultMultipartMemory, v)
+	}
 	codec, ok := CodecForRequest(r, "Content-Type")
 	if !ok {
 		return errors.BadRequest("CODEC", fmt.Sprintf("unregister Content-Type: %s", r.Header.Get("Content-Type")))
@@ -69,6 +71,9 @@ func DefaultRequestDecoder(r *http.Request, v interface{}) error {
 	r.Body = io.NopCloser(bytes.NewBuffer(data))
 
 	if err != nil {
+		if errors.Is(err, ErrRequestBodyTooLarge) {
+			return err
+		}
 		return errors.BadRequest("CODEC", err.Error())
 	}
 	if len(data) == 0 {
@@ -90,7 +95,10 @@ func DefaultResponseEncoder(w http.ResponseWriter, r *http.Request, v interface{
 		http.Redirect(w, r, url, code)
 		return nil
 	}
-	codec, _ := CodecForRequest(r, "Accept")
+	codec, ok := NegotiateCodec(r)
+	if !ok {
+		return errors.New(http.StatusNotAcceptable, "NOT_ACCEPTABLE", fmt.Sprintf("unsupported Accept: %s", r.Header.Get("Accept")))
+	}
 	data, err := codec.Marshal(v)
 	if err != nil {
 		return err
@@ -103,10 +111,32 @@ func DefaultResponseEncoder(w http.ResponseWriter, r *http.Request, v interface{
 	return nil
 }
 
+// RawResponseEncoder returns a response encoder writing the []byte, string and io.Reader
+// replies as they are with the content type, the other replies are encoded by
+// DefaultResponseEncoder. It is used with RouteResponseEncoder, e.g. for file downloads.
+func RawResponseEncoder(contentType string) EncodeResponseFunc {
+	return func(w http.ResponseWriter, r *http.Request, v interface{}) error {
+		var rd io.Reader
+		switch data := v.(type) {
+		case []byte:
+			rd = bytes.NewReader(data)
+		case string:
+			rd = strings.NewReader(data)
+		case io.Reader:
+			rd = data
+		default:
+			return DefaultResponseEncoder(w, r, v)
+		}
+		w.Header().Set("Content-Type", contentType)
+		_, err := io.Copy(w, rd)
+		return err
+	}
+}
+
 // DefaultErrorEncoder encodes the error to the HTTP response.
 func DefaultErrorEncoder(w http.ResponseWriter, r *http.Request, err error) {
 	se := errors.FromError(err)
-	codec, _ := CodecForRequest(r, "Accept")
+	codec, _ := NegotiateCodec(r)
 	body, err := codec.Marshal(se)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
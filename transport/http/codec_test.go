@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/go-kratos/kratos/v2/errors"
@@ -151,3 +152,23 @@ func TestCodecForRequest(t *testing.T) {
 		t.Errorf("expected %v, got %v", "json", c.Name())
 	}
 }
+
+func TestRawResponseEncoder(t *testing.T) {
+	enc := RawResponseEncoder("text/csv")
+	for _, v := range []interface{}{[]byte("a,b\n"), "a,b\n", bytes.NewBufferString("a,b\n")} {
+		w := httptest.NewRecorder()
+		if err := enc(w, httptest.NewRequest(http.MethodGet, "/export", nil), v); err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		if w.Header().Get("Content-Type") != "text/csv" || w.Body.String() != "a,b\n" {
+			t.Errorf("expect the raw body, got %s %q", w.Header().Get("Content-Type"), w.Body.String())
+		}
+	}
+	w := httptest.NewRecorder()
+	if err := enc(w, httptest.NewRequest(http.MethodGet, "/export", nil), map[string]string{"a": "b"}); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("expect the json fallback, got %s", w.Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// ErrServerOverloaded is returned when the concurrent requests exceed MaxConcurrency.
+var ErrServerOverloaded = errors.New(http.StatusServiceUnavailable, "SERVER_OVERLOADED", "too many concurrent requests")
+
+// ConcurrencyOption is MaxConcurrency option.
+type ConcurrencyOption func(*concurrencyLimiter)
+
+// ConcurrencyQueue with the max requests waiting for the in-flight ones, default is 0,
+// the requests over the limit are rejected immediately.
+func ConcurrencyQueue(size int) ConcurrencyOption {
+	return func(l *concurrencyLimiter) {
+		l.queue = int64(size)
+	}
+}
+
+// ConcurrencyWait with the max time of the requests waiting in the queue, default is
+// 0, they wait until the client gives up.
+func ConcurrencyWait(d time.Duration) ConcurrencyOption {
+	return func(l *concurrencyLimiter) {
+		l.wait = d
+	}
+}
+
+// ConcurrencyRetryAfter with the Retry-After of the rejected requests, default is 1s.
+func ConcurrencyRetryAfter(d time.Duration) ConcurrencyOption {
+	return func(l *concurrencyLimiter) {
+		l.retryAfter = d
+	}
+}
+
+// MaxConcurrency with the max concurrent in-flight requests of the server, the requests
+// over it wait in the ConcurrencyQueue, and are rejected with 503 and the Retry-After
+// header if the queue is full or the wait times out. It is enforced before the filters
+// and the routing, as a blunt protection independent of the adaptive ratelimit middleware.
+// The long-lived requests, e.g. the websockets, hold the slots until they are done.
+// Zero or negative n means no limit.
+func MaxConcurrency(n int, opts ...ConcurrencyOption) ServerOption {
+	return func(s *Server) {
+		if n <= 0 {
+			s.limiter = nil
+			return
+		}
+		l := &concurrencyLimiter{sem: make(chan struct{}, n), retryAfter: time.Second}
+		for _, o := range opts {
+			o(l)
+		}
+		s.limiter = l
+	}
+}
+
+type concurrencyLimiter struct {
+	sem        chan struct{}
+	queue      int64
+	waiting    int64
+	wait       time.Duration
+	retryAfter time.Duration
+}
+
+// handler limits the concurrent requests of next, the rejected ones are encoded by ene.
+func (l *concurrencyLimiter) handler(next http.Handler, ene EncodeErrorFunc) http.Handler {
+	retryAfter := strconv.Itoa(int(math.Ceil(l.retryAfter.Seconds())))
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !l.acquire(req) {
+			w.Header().Set("Retry-After", retryAfter)
+			ene(w, req, ErrServerOverloaded)
+			return
+		}
+		defer l.release()
+		next.ServeHTTP(w, req)
+	})
+}
+
+func (l *concurrencyLimiter) acquire(req *http.Request) bool {
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	default:
+	}
+	if atomic.AddInt64(&l.waiting, 1) > l.queue {
+		atomic.AddInt64(&l.waiting, -1)
+		return false
+	}
+	defer atomic.AddInt64(&l.waiting, -1)
+	var timeout <-chan time.Time
+	if l.wait > 0 {
+		timer := time.NewTimer(l.wait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	case <-timeout:
+		return false
+	case <-req.Context().Done():
+		return false
+	}
+}
+
+func (l *concurrencyLimiter) release() {
+	<-l.sem
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMaxConcurrency(t *testing.T) {
+	started, release := make(chan struct{}, 2), make(chan struct{})
+	srv := NewServer(MaxConcurrency(1, ConcurrencyQueue(1), ConcurrencyWait(time.Millisecond*50), ConcurrencyRetryAfter(time.Second*2)))
+	srv.HandleFunc("/block", func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+	})
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/block", nil))
+		return w
+	}
+
+	first := make(chan *httptest.ResponseRecorder)
+	go func() { first <- serve() }()
+	<-started
+
+	// the queued request times out
+	if w := serve(); w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "2" {
+		t.Errorf("expect 503 with Retry-After, got %d %v", w.Code, w.Header())
+	}
+
+	// the queued request is served once the in-flight one is done
+	queued := make(chan *httptest.ResponseRecorder)
+	go func() { queued <- serve() }()
+	time.Sleep(time.Millisecond * 10)
+	// the queue is full
+	if w := serve(); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expect 503, got %d", w.Code)
+	}
+	release <- struct{}{}
+	if w := <-first; w.Code != http.StatusOK {
+		t.Errorf("expect 200, got %d", w.Code)
+	}
+	<-started
+	release <- struct{}{}
+	if w := <-queued; w.Code != http.StatusOK {
+		t.Errorf("expect the queued request to be served, got %d", w.Code)
+	}
+}
+
+func TestMaxConcurrencyNoLimit(t *testing.T) {
+	for _, n := range []int{0, -1} {
+		srv := NewServer(MaxConcurrency(n))
+		if srv.limiter != nil {
+			t.Errorf("%d: expect no limit", n)
+		}
+		srv.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ok", nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("%d: expect 200, got %d", n, w.Code)
+		}
+	}
+}
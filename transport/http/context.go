@@ -4,16 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"encoding/xml"
+	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"time"
 
-	"github.com/gorilla/mux"
-
+	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
 	"github.com/go-kratos/kratos/v2/transport/http/binding"
+	"github.com/go-kratos/kratos/v2/transport/http/session"
 )
 
 var _ Context = (*wrapper)(nil)
@@ -32,13 +34,25 @@ type Context interface {
 	BindVars(interface{}) error
 	BindQuery(interface{}) error
 	BindForm(interface{}) error
+	BindParams(interface{}) error
+	MultipartForm() (*multipart.Form, error)
+	FormFile(string) (*multipart.FileHeader, error)
+	GetCookie(string) (*http.Cookie, error)
+	SetCookie(*http.Cookie)
+	Session() (*session.Session, error)
 	Returns(interface{}, error) error
 	Result(int, interface{}) error
 	JSON(int, interface{}) error
 	XML(int, interface{}) error
 	String(int, string) error
 	Blob(int, string, []byte) error
+	Redirect(int, string) error
+	NoContent() error
+	EarlyHints(...string)
+	Trailer() http.Header
+	SetTrailer(string, string)
 	Stream(int, string, io.Reader) error
+	SSE(...SSEOption) (*SSEWriter, error)
 	Reset(http.ResponseWriter, *http.Request)
 }
 
@@ -63,6 +77,9 @@ type wrapper struct {
 	req    *http.Request
 	res    http.ResponseWriter
 	w      responseWriter
+	sse    *SSEWriter
+
+	session *session.Session
 }
 
 func (c *wrapper) Header() http.Header {
@@ -70,7 +87,7 @@ func (c *wrapper) Header() http.Header {
 }
 
 func (c *wrapper) Vars() url.Values {
-	raws := mux.Vars(c.req)
+	raws := routeVars(c.req)
 	vars := make(url.Values, len(raws))
 	for k, v := range raws {
 		vars[k] = []string{v}
@@ -96,20 +113,31 @@ func (c *wrapper) Middleware(h middleware.Handler) middleware.Handler {
 	}
 	return middleware.Chain(c.router.srv.middleware.Match(c.req.URL.Path)...)(h)
 }
-func (c *wrapper) Bind(v interface{}) error      { return c.router.srv.decBody(c.req, v) }
+func (c *wrapper) Bind(v interface{}) error {
+	if c.req.MultipartForm == nil && isMultipart(c.req) {
+		// 按照server配置的内存上限解析，超出的文件写入临时文件
+		if _, err := c.MultipartForm(); err != nil {
+			return err
+		}
+	}
+	return c.router.srv.decBody(c.req, v)
+}
 func (c *wrapper) BindVars(v interface{}) error  { return c.router.srv.decVars(c.req, v) }
 func (c *wrapper) BindQuery(v interface{}) error { return c.router.srv.decQuery(c.req, v) }
 func (c *wrapper) BindForm(v interface{}) error  { return binding.BindForm(c.req, v) }
+func (c *wrapper) BindParams(v interface{}) error {
+	return binding.BindTags(c.req, routeVars(c.req), v)
+}
 func (c *wrapper) Returns(v interface{}, err error) error {
 	if err != nil {
 		return err
 	}
-	return c.router.srv.enc(&c.w, c.req, v)
+	return c.router.srv.encoder(c.req)(&c.w, c.req, v)
 }
 
 func (c *wrapper) Result(code int, v interface{}) error {
 	c.w.WriteHeader(code)
-	return c.router.srv.enc(&c.w, c.req, v)
+	return c.router.srv.encoder(c.req)(&c.w, c.req, v)
 }
 
 func (c *wrapper) JSON(code int, v interface{}) error {
@@ -144,6 +172,22 @@ func (c *wrapper) Blob(code int, contentType string, data []byte) error {
 	return nil
 }
 
+// Redirect replies to the request with a redirect to url, which may be a path
+// relative to the request path, the code must be in the 3xx range.
+func (c *wrapper) Redirect(code int, url string) error {
+	if code < http.StatusMultipleChoices || code > http.StatusPermanentRedirect {
+		return errors.InternalServer("REDIRECT", fmt.Sprintf("invalid redirect code: %d", code))
+	}
+	http.Redirect(c.res, c.req, url, code)
+	return nil
+}
+
+// NoContent replies to the request with 204 No Content.
+func (c *wrapper) NoContent() error {
+	c.res.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
 func (c *wrapper) Stream(code int, contentType string, rd io.Reader) error {
 	c.res.Header().Set("Content-Type", contentType)
 	c.res.WriteHeader(code)
@@ -151,7 +195,28 @@ func (c *wrapper) Stream(code int, contentType string, rd io.Reader) error {
 	return err
 }
 
+// SSE returns an SSEWriter streaming server-sent events to the client,
+// the stream is done when the client disconnected or the server is stopping.
+func (c *wrapper) SSE(opts ...SSEOption) (*SSEWriter, error) {
+	sw, err := NewSSEWriter(c.router.srv.drain.streamContext(c.req.Context()), c.res, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.sse = sw
+	return sw, nil
+}
+
 func (c *wrapper) Reset(res http.ResponseWriter, req *http.Request) {
+	if c.sse != nil {
+		// handler返回后，停止心跳
+		c.sse.Close()
+		c.sse = nil
+	}
+	if c.req != nil && c.req.MultipartForm != nil {
+		// 清理上传的临时文件
+		_ = c.req.MultipartForm.RemoveAll()
+	}
+	c.session = nil
 	c.w.reset(res)
 	c.res = res
 	c.req = req
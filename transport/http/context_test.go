@@ -170,6 +170,44 @@ func TestContextResponseReturn(t *testing.T) {
 	}
 }
 
+func TestContextRedirect(t *testing.T) {
+	writer := httptest.NewRecorder()
+	w := wrapper{
+		router: testRouter,
+		req:    httptest.NewRequest(http.MethodGet, "/users/1", nil),
+		res:    writer,
+		w:      responseWriter{},
+	}
+	if err := w.Redirect(http.StatusFound, "../login"); err != nil {
+		t.Errorf("expected %v, got %v", nil, err)
+	}
+	if writer.Code != http.StatusFound {
+		t.Errorf("expected %v, got %v", http.StatusFound, writer.Code)
+	}
+	if loc := writer.Header().Get("Location"); loc != "/login" {
+		t.Errorf("expected %v, got %v", "/login", loc)
+	}
+	if err := w.Redirect(http.StatusOK, "/login"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestContextNoContent(t *testing.T) {
+	writer := httptest.NewRecorder()
+	w := wrapper{
+		router: testRouter,
+		req:    nil,
+		res:    writer,
+		w:      responseWriter{},
+	}
+	if err := w.NoContent(); err != nil {
+		t.Errorf("expected %v, got %v", nil, err)
+	}
+	if writer.Code != http.StatusNoContent || writer.Body.Len() != 0 {
+		t.Errorf("expected %v, got %v %v", http.StatusNoContent, writer.Code, writer.Body.String())
+	}
+}
+
 func TestContextCtx(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/transport/http/session"
+)
+
+// ErrNoSessions is returned by Context.Session if the server has no session manager.
+var ErrNoSessions = errors.New("http: sessions are not enabled")
+
+// GetCookie returns the named cookie of the request, http.ErrNoCookie if not found.
+func (c *wrapper) GetCookie(name string) (*http.Cookie, error) {
+	return c.req.Cookie(name)
+}
+
+// SetCookie adds a Set-Cookie header to the response, it must be called
+// before the response body is written.
+func (c *wrapper) SetCookie(cookie *http.Cookie) {
+	http.SetCookie(c.res, cookie)
+}
+
+// Session returns the session of the request, it is loaded once per request.
+// The changes must be saved by Session.Save before the response body is written.
+func (c *wrapper) Session() (*session.Session, error) {
+	if c.session != nil {
+		return c.session, nil
+	}
+	m := c.router.srv.sessions
+	if m == nil {
+		return nil, ErrNoSessions
+	}
+	s, err := m.Load(c.req)
+	if err != nil {
+		return nil, err
+	}
+	c.session = s
+	return s, nil
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport/http/session"
+)
+
+func TestCookie(t *testing.T) {
+	srv := NewServer()
+	srv.Route("/").GET("/cookie", func(ctx Context) error {
+		c, err := ctx.GetCookie("name")
+		if err != nil {
+			return err
+		}
+		ctx.SetCookie(&http.Cookie{Name: "echo", Value: c.Value})
+		return ctx.String(http.StatusOK, c.Value)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/cookie", nil)
+	req.AddCookie(&http.Cookie{Name: "name", Value: "kratos"})
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expect %v, got %v", http.StatusOK, rec.Code)
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "echo" || cookies[0].Value != "kratos" {
+		t.Errorf("expect echo=kratos, got %v", cookies)
+	}
+}
+
+func TestSession(t *testing.T) {
+	m, err := session.NewManager([][]byte{[]byte("secret")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := NewServer(Sessions(m))
+	srv.Route("/").GET("/count", func(ctx Context) error {
+		s, err := ctx.Session()
+		if err != nil {
+			return err
+		}
+		v, _ := s.Get("count")
+		s.Set("count", v+"1")
+		if err = s.Save(ctx, ctx.Response()); err != nil {
+			return err
+		}
+		return ctx.String(http.StatusOK, v+"1")
+	})
+	var cookies []*http.Cookie
+	for _, want := range []string{"1", "11", "111"} {
+		req := httptest.NewRequest(http.MethodGet, "/count", nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if rec.Body.String() != want {
+			t.Errorf("expect %v, got %v", want, rec.Body.String())
+		}
+		cookies = rec.Result().Cookies()
+	}
+}
+
+func TestSessionDisabled(t *testing.T) {
+	srv := NewServer()
+	var got error
+	srv.Route("/").GET("/session", func(ctx Context) error {
+		_, got = ctx.Session()
+		return nil
+	})
+	srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/session", nil))
+	if !errors.Is(got, ErrNoSessions) {
+		t.Errorf("expect %v, got %v", ErrNoSessions, got)
+	}
+}
@@ -0,0 +1,177 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOption is CORS filter option.
+type CORSOption func(*corsOptions)
+
+type corsOptions struct {
+	origins     []string
+	originFunc  func(origin string) bool
+	methods     []string
+	headers     []string
+	exposed     []string
+	credentials bool
+	maxAge      time.Duration
+}
+
+// CORSAllowOrigins with the allowed origins, "*" allows any origin,
+// and "https://*.example.com" allows the subdomains.
+func CORSAllowOrigins(origins ...string) CORSOption {
+	return func(o *corsOptions) {
+		o.origins = origins
+	}
+}
+
+// CORSAllowOriginFunc with the function deciding whether the origin is allowed,
+// it is checked after the origins of CORSAllowOrigins.
+func CORSAllowOriginFunc(f func(origin string) bool) CORSOption {
+	return func(o *corsOptions) {
+		o.originFunc = f
+	}
+}
+
+// CORSAllowMethods with the allowed methods of the preflight requests.
+func CORSAllowMethods(methods ...string) CORSOption {
+	return func(o *corsOptions) {
+		o.methods = methods
+	}
+}
+
+// CORSAllowHeaders with the allowed request headers, the requested headers
+// are allowed if it is not set.
+func CORSAllowHeaders(headers ...string) CORSOption {
+	return func(o *corsOptions) {
+		o.headers = headers
+	}
+}
+
+// CORSExposeHeaders with the response headers exposed to the client.
+func CORSExposeHeaders(headers ...string) CORSOption {
+	return func(o *corsOptions) {
+		o.exposed = headers
+	}
+}
+
+// CORSAllowCredentials with allowing the credentials, e.g. cookies.
+func CORSAllowCredentials() CORSOption {
+	return func(o *corsOptions) {
+		o.credentials = true
+	}
+}
+
+// CORSMaxAge with how long the results of the preflight requests can be cached.
+func CORSMaxAge(d time.Duration) CORSOption {
+	return func(o *corsOptions) {
+		o.maxAge = d
+	}
+}
+
+// CORS returns a filter handling the cross-origin requests, the preflight requests
+// are replied directly. It can be used globally by the Filter server option,
+// or per router group by Server.Route and Router.Group.
+func CORS(opts ...CORSOption) FilterFunc {
+	o := &corsOptions{
+		methods: []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	methods := strings.Join(o.methods, ", ")
+	headers := strings.Join(o.headers, ", ")
+	exposed := strings.Join(o.exposed, ", ")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			origin := req.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, req)
+				return
+			}
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			preflight := isPreflight(req)
+			if !o.allowOrigin(origin) {
+				if preflight {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				next.ServeHTTP(w, req)
+				return
+			}
+			if o.credentials || !o.anyOrigin() {
+				h.Set("Access-Control-Allow-Origin", origin)
+			} else {
+				h.Set("Access-Control-Allow-Origin", "*")
+			}
+			if o.credentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+			if !preflight {
+				if exposed != "" {
+					h.Set("Access-Control-Expose-Headers", exposed)
+				}
+				next.ServeHTTP(w, req)
+				return
+			}
+			// 预检请求直接返回，不再进入路由
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			if !o.allowMethod(req.Header.Get("Access-Control-Request-Method")) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			h.Set("Access-Control-Allow-Methods", methods)
+			if headers != "" {
+				h.Set("Access-Control-Allow-Headers", headers)
+			} else if requested := req.Header.Get("Access-Control-Request-Headers"); requested != "" {
+				h.Set("Access-Control-Allow-Headers", requested)
+			}
+			if o.maxAge > 0 {
+				h.Set("Access-Control-Max-Age", strconv.Itoa(int(o.maxAge/time.Second)))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+func isPreflight(req *http.Request) bool {
+	return req.Method == http.MethodOptions && req.Header.Get("Access-Control-Request-Method") != ""
+}
+
+func (o *corsOptions) anyOrigin() bool {
+	for _, origin := range o.origins {
+		if origin == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *corsOptions) allowOrigin(origin string) bool {
+	for _, allowed := range o.origins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+		if i := strings.Index(allowed, "*"); i >= 0 {
+			prefix, suffix := allowed[:i], allowed[i+1:]
+			if len(origin) >= len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+				return true
+			}
+		}
+	}
+	return o.originFunc != nil && o.originFunc(origin)
+}
+
+func (o *corsOptions) allowMethod(method string) bool {
+	for _, m := range o.methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORS(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	h := CORS(
+		CORSAllowOrigins("https://go-kratos.dev", "https://*.example.com"),
+		CORSAllowMethods(http.MethodGet, http.MethodPost),
+		CORSExposeHeaders("X-Request-Id"),
+		CORSAllowCredentials(),
+		CORSMaxAge(time.Minute),
+	)(next)
+	tests := []struct {
+		name   string
+		method string
+		header map[string]string
+		code   int
+		want   map[string]string
+	}{
+		{"no origin", http.MethodGet, nil, http.StatusOK, map[string]string{"Access-Control-Allow-Origin": ""}},
+		{"actual", http.MethodGet, map[string]string{"Origin": "https://go-kratos.dev"}, http.StatusOK, map[string]string{
+			"Access-Control-Allow-Origin":      "https://go-kratos.dev",
+			"Access-Control-Allow-Credentials": "true",
+			"Access-Control-Expose-Headers":    "X-Request-Id",
+		}},
+		{"subdomain", http.MethodGet, map[string]string{"Origin": "https://api.example.com"}, http.StatusOK, map[string]string{
+			"Access-Control-Allow-Origin": "https://api.example.com",
+		}},
+		{"disallowed", http.MethodGet, map[string]string{"Origin": "https://evil.com"}, http.StatusOK, map[string]string{
+			"Access-Control-Allow-Origin": "",
+		}},
+		{"preflight", http.MethodOptions, map[string]string{
+			"Origin":                         "https://go-kratos.dev",
+			"Access-Control-Request-Method":  http.MethodPost,
+			"Access-Control-Request-Headers": "Content-Type",
+		}, http.StatusNoContent, map[string]string{
+			"Access-Control-Allow-Origin":  "https://go-kratos.dev",
+			"Access-Control-Allow-Methods": "GET, POST",
+			"Access-Control-Allow-Headers": "Content-Type",
+			"Access-Control-Max-Age":       "60",
+		}},
+		{"preflight method", http.MethodOptions, map[string]string{
+			"Origin":                        "https://go-kratos.dev",
+			"Access-Control-Request-Method": http.MethodDelete,
+		}, http.StatusForbidden, nil},
+		{"preflight origin", http.MethodOptions, map[string]string{
+			"Origin":                        "https://evil.com",
+			"Access-Control-Request-Method": http.MethodGet,
+		}, http.StatusForbidden, nil},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, "/", nil)
+		for k, v := range test.header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != test.code {
+			t.Errorf("%s: expected %v got %v", test.name, test.code, rec.Code)
+		}
+		for k, v := range test.want {
+			if got := rec.Header().Get(k); got != v {
+				t.Errorf("%s: expected %s %q got %q", test.name, k, v, got)
+			}
+		}
+	}
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	h := CORS(CORSAllowOrigins("*"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://go-kratos.dev")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("expected * got %q", got)
+	}
+}
+
+func TestCORSRouteGroup(t *testing.T) {
+	srv := NewServer()
+	api := srv.Route("/api", CORS(CORSAllowOrigins("https://go-kratos.dev")))
+	api.POST("/users", func(ctx Context) error { return ctx.String(http.StatusOK, "ok") })
+	api.PUT("/users", func(ctx Context) error { return ctx.String(http.StatusOK, "ok") })
+	srv.Route("/").POST("/internal", func(ctx Context) error { return ctx.String(http.StatusOK, "ok") })
+
+	preflight := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, path, nil)
+		req.Header.Set("Origin", "https://go-kratos.dev")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := preflight("/api/users"); rec.Code != http.StatusNoContent {
+		t.Errorf("expected %v got %v", http.StatusNoContent, rec.Code)
+	}
+	if rec := preflight("/internal"); rec.Code == http.StatusNoContent {
+		t.Errorf("expected the preflight not to be handled")
+	}
+
+	var routes []RouteInfo
+	_ = srv.WalkRoute(func(info RouteInfo) error {
+		routes = append(routes, info)
+		return nil
+	})
+	if len(routes) != 3 {
+		t.Errorf("expected 3 routes got %v", routes)
+	}
+}
+
+func TestCORSPreflightMethodFilters(t *testing.T) {
+	srv := NewServer()
+	auth := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+	}
+	r := srv.Route("/")
+	ok := func(ctx Context) error { return ctx.String(http.StatusOK, "ok") }
+	r.GET("/x", ok, auth)
+	r.POST("/x", ok, CORS(CORSAllowOrigins("https://go-kratos.dev")))
+
+	preflight := func(method string) int {
+		req := httptest.NewRequest(http.MethodOptions, "/x", nil)
+		req.Header.Set("Origin", "https://go-kratos.dev")
+		req.Header.Set("Access-Control-Request-Method", method)
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	// 预检请求由所请求方法的路由的filters处理
+	if code := preflight(http.MethodPost); code != http.StatusNoContent {
+		t.Errorf("expected %v got %v", http.StatusNoContent, code)
+	}
+	if code := preflight(http.MethodGet); code != http.StatusUnauthorized {
+		t.Errorf("expected %v got %v", http.StatusUnauthorized, code)
+	}
+	if code := preflight(http.MethodPut); code == http.StatusNoContent {
+		t.Errorf("expected the preflight of the method without route not to be handled")
+	}
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// TimeoutHeader is the header of the remaining time of the request deadline, in the
+// format of the grpc-timeout, e.g. "100m". The server shortens the timeout of the request
+// to it, the client sets it by the deadline of the context and the timeout of the call,
+// so the deadline is propagated across the services instead of re-applying the full
+// default timeout in each of them. The client sends it to the discovery targets, which
+// are the kratos services, or to any endpoint with WithDeadlinePropagation.
+const TimeoutHeader = "Grpc-Timeout"
+
+// WithDeadlinePropagation with the TimeoutHeader sent to the endpoints other than the
+// discovery targets, e.g. the kratos services behind a load balancer.
+func WithDeadlinePropagation() ClientOption {
+	return func(o *clientOptions) {
+		o.propagateDeadline = true
+	}
+}
+
+// WithDeadlineMargin with the margin subtracted from the deadline propagated to the
+// server, leaving the time for the client to handle the reply or the timeout error.
+func WithDeadlineMargin(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.deadlineMargin = d
+	}
+}
+
+// withTimeoutHeader returns a clone of the request with the TimeoutHeader set by the
+// remaining time of the context deadline and the timeout, the smaller one applies.
+// The request of the caller is not modified.
+func withTimeoutHeader(req *http.Request, timeout, margin time.Duration) *http.Request {
+	if deadline, ok := req.Context().Deadline(); ok {
+		if d := time.Until(deadline); timeout <= 0 || d < timeout {
+			timeout = d
+		}
+	}
+	if timeout <= 0 {
+		return req
+	}
+	if timeout-margin > 0 {
+		timeout -= margin
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set(TimeoutHeader, encodeTimeout(timeout))
+	return req
+}
+
+// encodeTimeout encodes the timeout in the grpc-timeout format, the value has 8 digits at most.
+func encodeTimeout(t time.Duration) string {
+	if t <= 0 {
+		return "0n"
+	}
+	const max = 100000000
+	units := []struct {
+		d time.Duration
+		u string
+	}{
+		{time.Nanosecond, "n"},
+		{time.Microsecond, "u"},
+		{time.Millisecond, "m"},
+		{time.Second, "S"},
+		{time.Minute, "M"},
+		{time.Hour, "H"},
+	}
+	for _, unit := range units {
+		// 向上取整，避免传递的超时比实际剩余的短成0
+		if n := (t + unit.d - 1) / unit.d; n < max {
+			return strconv.FormatInt(int64(n), 10) + unit.u
+		}
+	}
+	return strconv.FormatInt(max-1, 10) + "H"
+}
+
+// decodeTimeout decodes the timeout in the grpc-timeout format.
+func decodeTimeout(s string) (time.Duration, bool) {
+	if len(s) < 2 || len(s) > 9 {
+		return 0, false
+	}
+	var d time.Duration
+	switch s[len(s)-1] {
+	case 'H':
+		d = time.Hour
+	case 'M':
+		d = time.Minute
+	case 'S':
+		d = time.Second
+	case 'm':
+		d = time.Millisecond
+	case 'u':
+		d = time.Microsecond
+	case 'n':
+		d = time.Nanosecond
+	default:
+		return 0, false
+	}
+	n, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	if max := int64(1<<63-1) / int64(d); n > max {
+		return time.Duration(1<<63 - 1), true
+	}
+	return time.Duration(n) * d, true
+}
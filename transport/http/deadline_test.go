@@ -0,0 +1,106 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeoutCodec(t *testing.T) {
+	for _, d := range []time.Duration{time.Nanosecond, 150 * time.Millisecond, 3 * time.Second, 2 * time.Hour} {
+		got, ok := decodeTimeout(encodeTimeout(d))
+		if !ok || got != d {
+			t.Errorf("%v: expected round trip got %v %v", d, got, ok)
+		}
+	}
+	if s := encodeTimeout(100 * time.Second); s != "100000000u" && s != "100000m" {
+		t.Errorf("expected at most 8 digits got %s", s)
+	}
+	for _, s := range []string{"", "1", "10x", "-1S", "1234567890S"} {
+		if _, ok := decodeTimeout(s); ok {
+			t.Errorf("%q: expected invalid", s)
+		}
+	}
+}
+
+func TestServerTimeoutHeader(t *testing.T) {
+	srv := NewServer(Timeout(10 * time.Second))
+	srv.HandleFunc("/index", func(w http.ResponseWriter, r *http.Request) {
+		d, _ := r.Context().Deadline()
+		_, _ = w.Write([]byte(fmt.Sprint(time.Until(d).Round(time.Second))))
+	})
+	for header, want := range map[string]string{"": "10s", "2S": "2s", "20S": "10s", "bad": "10s"} {
+		req := httptest.NewRequest(http.MethodGet, "/index", nil)
+		if header != "" {
+			req.Header.Set(TimeoutHeader, header)
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if rec.Body.String() != want {
+			t.Errorf("%q: expected %v got %v", header, want, rec.Body.String())
+		}
+	}
+}
+
+func TestClientTimeoutHeader(t *testing.T) {
+	var header string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get(TimeoutHeader)
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer ts.Close()
+	var reply struct{}
+	// 非服务发现的目标默认不传递
+	plain, err := NewClient(context.Background(), WithEndpoint(ts.URL), WithTimeout(10*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plain.Close()
+	if err = plain.Invoke(context.Background(), http.MethodGet, "/index", nil, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if header != "" {
+		t.Errorf("expected no header without the propagation got %q", header)
+	}
+
+	client, err := NewClient(context.Background(), WithEndpoint(ts.URL), WithTimeout(10*time.Second),
+		WithDeadlinePropagation(), WithDeadlineMargin(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	decode := func() time.Duration {
+		d, ok := decodeTimeout(header)
+		if !ok {
+			t.Fatalf("unexpected header %q", header)
+		}
+		return d.Round(time.Second)
+	}
+	if err = client.Invoke(context.Background(), http.MethodGet, "/index", nil, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if d := decode(); d != 9*time.Second {
+		t.Errorf("expected the client timeout got %v", d)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err = client.Invoke(ctx, http.MethodGet, "/index", nil, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if d := decode(); d != 2*time.Second {
+		t.Errorf("expected the context deadline got %v", d)
+	}
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/index", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if header == "" || req.Header.Get(TimeoutHeader) != "" {
+		t.Errorf("expected the header on a clone of the request got %q %q", header, req.Header.Get(TimeoutHeader))
+	}
+}
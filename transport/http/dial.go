@@ -0,0 +1,68 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+type dialOptions struct {
+	dialer   *net.Dialer
+	resolver *net.Resolver
+	dial     func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// WithDialer with the dialer of the connections, e.g. the dial timeout,
+// the keep-alive period and the fallback delay of happy eyeballs.
+func WithDialer(d *net.Dialer) ClientOption {
+	return func(o *clientOptions) {
+		o.dial.dialer = d
+	}
+}
+
+// WithResolver with the DNS resolver of the dialer, e.g. the per-client DNS servers.
+func WithResolver(r *net.Resolver) ClientOption {
+	return func(o *clientOptions) {
+		o.dial.resolver = r
+	}
+}
+
+// WithDialContext with the function dialing the connections, it overrides WithDialer and WithResolver.
+func WithDialContext(f func(ctx context.Context, network, addr string) (net.Conn, error)) ClientOption {
+	return func(o *clientOptions) {
+		o.dial.dial = f
+	}
+}
+
+// WithUnixSocket with dialing the unix socket for all the requests, the host of the endpoint is ignored.
+func WithUnixSocket(path string) ClientOption {
+	return WithDialContext(func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", path)
+	})
+}
+
+func (o dialOptions) empty() bool {
+	return o.dial == nil && o.dialer == nil && o.resolver == nil
+}
+
+// dialTransport sets the dialer of the transport cloned by newTransport.
+func dialTransport(tr *http.Transport, o dialOptions) {
+	if o.empty() {
+		return
+	}
+	if o.dial != nil {
+		tr.DialContext = o.dial
+		return
+	}
+	d := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if o.dialer != nil {
+		dd := *o.dialer
+		d = &dd
+	}
+	if o.resolver != nil {
+		d.Resolver = o.resolver
+	}
+	tr.DialContext = d.DialContext
+}
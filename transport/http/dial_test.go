@@ -0,0 +1,101 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+)
+
+func TestWithUnixSocket(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "kratos.sock")
+	lis, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("unix socket is not supported: %v", err)
+	}
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"message":"unix"}`))
+	}))
+	ts.Listener = lis
+	ts.Start()
+	defer ts.Close()
+
+	client, err := NewClient(context.Background(), WithEndpoint("127.0.0.1:1"), WithUnixSocket(sock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reply struct {
+		Message string `json:"message"`
+	}
+	if err = client.Invoke(context.Background(), http.MethodGet, "/hello", nil, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Message != "unix" {
+		t.Errorf("expect %v, got %v", "unix", reply.Message)
+	}
+}
+
+func TestWithDialer(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+	var dials int32
+	client, err := NewClient(context.Background(), WithEndpoint(ts.Listener.Addr().String()), WithDialer(&net.Dialer{
+		Control: func(string, string, syscall.RawConn) error {
+			atomic.AddInt32(&dials, 1)
+			return nil
+		},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.Invoke(context.Background(), http.MethodGet, "/", nil, &struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&dials) != 1 {
+		t.Errorf("expect %v, got %v", 1, atomic.LoadInt32(&dials))
+	}
+}
+
+func TestWithResolver(t *testing.T) {
+	var lookups int32
+	client, err := NewClient(context.Background(), WithEndpoint("kratos.invalid:80"), WithResolver(&net.Resolver{
+		PreferGo: true,
+		Dial: func(context.Context, string, string) (net.Conn, error) {
+			atomic.AddInt32(&lookups, 1)
+			return nil, errors.New("no dns")
+		},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.Invoke(context.Background(), http.MethodGet, "/", nil, &struct{}{}); err == nil {
+		t.Errorf("expect error, got nil")
+	}
+	if atomic.LoadInt32(&lookups) == 0 {
+		t.Errorf("expect the resolver to be used")
+	}
+}
+
+func TestDialTransport(t *testing.T) {
+	if rt, _ := newTransport(&clientOptions{transport: http.DefaultTransport}); rt != http.DefaultTransport {
+		t.Errorf("expect the transport to be kept without dial options")
+	}
+	d := &net.Dialer{}
+	r := &net.Resolver{}
+	rt, _ := newTransport(&clientOptions{transport: http.DefaultTransport, dial: dialOptions{dialer: d, resolver: r}})
+	if tr := rt.(*http.Transport); tr == http.DefaultTransport || tr.DialContext == nil {
+		t.Errorf("expect http.DefaultTransport to be cloned")
+	}
+	if d.Resolver != nil {
+		t.Errorf("expect the dialer not to be modified")
+	}
+}
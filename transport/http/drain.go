@@ -0,0 +1,156 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// ErrDraining is the cause of the streams done since the server is stopping.
+var ErrDraining = errors.New(http.StatusServiceUnavailable, "DRAINING", "server is draining")
+
+type drainKey struct{}
+
+// drainer tracks the connections and the in-flight requests of the server,
+// and signals the streams when the server starts draining.
+type drainer struct {
+	requests int64
+
+	mu       sync.Mutex
+	conns    map[net.Conn]http.ConnState
+	deadline time.Time
+	once     sync.Once
+	ch       chan struct{}
+}
+
+func newDrainer() *drainer {
+	return &drainer{
+		conns: make(map[net.Conn]http.ConnState),
+		ch:    make(chan struct{}),
+	}
+}
+
+// connState is the http.Server ConnState hook.
+func (d *drainer) connState(conn net.Conn, state http.ConnState) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	switch state {
+	case http.StateHijacked, http.StateClosed:
+		delete(d.conns, conn)
+	default:
+		d.conns[conn] = state
+	}
+}
+
+func (d *drainer) begin() {
+	if d != nil {
+		atomic.AddInt64(&d.requests, 1)
+	}
+}
+
+func (d *drainer) end() {
+	if d != nil {
+		atomic.AddInt64(&d.requests, -1)
+	}
+}
+
+// start signals the streams to finish before the deadline, zero means no deadline.
+func (d *drainer) start(deadline time.Time) {
+	if d != nil {
+		d.once.Do(func() {
+			d.mu.Lock()
+			d.deadline = deadline
+			d.mu.Unlock()
+			close(d.ch)
+		})
+	}
+}
+
+// draining returns a channel that's closed when the server starts draining.
+func (d *drainer) draining() <-chan struct{} {
+	if d == nil {
+		return nil
+	}
+	return d.ch
+}
+
+// active returns the number of the active connections and the in-flight requests.
+func (d *drainer) active() (conns int, requests int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, state := range d.conns {
+		if state != http.StateIdle {
+			conns++
+		}
+	}
+	return conns, atomic.LoadInt64(&d.requests)
+}
+
+// streamContext returns a new context canceled when the server starts draining,
+// so that the long-lived streams, e.g. SSE, can finish before the shutdown.
+func (d *drainer) streamContext(ctx context.Context) context.Context {
+	ch := d.draining()
+	if ch == nil {
+		return ctx
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	select {
+	case <-ch:
+		cancel()
+		return ctx
+	default:
+	}
+	go func() {
+		select {
+		case <-ch:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+	return ctx
+}
+
+// StreamContext returns a new context of the request canceled when the server starts
+// draining, so that the long-lived streams, e.g. websocket, can send the terminal events
+// to the clients before they are cut off, the cause is reported by DrainCause and the
+// deadline by Draining. It returns ctx as it is if it is not a request context of the server.
+func StreamContext(ctx context.Context) context.Context {
+	if w, ok := ctx.(*wrapper); ok && w.req != nil {
+		// Context会被Reset复用，使用请求的ctx
+		ctx = w.req.Context()
+	}
+	d, _ := ctx.Value(drainKey{}).(*drainer)
+	return d.streamContext(ctx)
+}
+
+// Draining reports whether the server of the request context is draining, and the
+// deadline of the shutdown, which is zero if there is none.
+func Draining(ctx context.Context) (deadline time.Time, ok bool) {
+	d, _ := ctx.Value(drainKey{}).(*drainer)
+	select {
+	case <-d.draining():
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		return d.deadline, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// DrainCause returns ErrDraining if ctx is done and the server of it is draining,
+// otherwise ctx.Err().
+func DrainCause(ctx context.Context) error {
+	err := ctx.Err()
+	if err == nil {
+		return nil
+	}
+	if _, ok := Draining(ctx); ok {
+		return ErrDraining
+	}
+	return err
+}
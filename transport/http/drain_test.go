@@ -0,0 +1,210 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestDrainer(t *testing.T) {
+	d := newDrainer()
+	c1, c2 := &net.TCPConn{}, &net.UDPConn{}
+	d.connState(c1, http.StateNew)
+	d.connState(c2, http.StateActive)
+	d.connState(c1, http.StateIdle)
+	d.begin()
+	if conns, requests := d.active(); conns != 1 || requests != 1 {
+		t.Errorf("expect 1 1, got %v %v", conns, requests)
+	}
+	d.connState(c2, http.StateHijacked)
+	d.end()
+	if conns, requests := d.active(); conns != 0 || requests != 0 {
+		t.Errorf("expect 0 0, got %v %v", conns, requests)
+	}
+
+	ctx := d.streamContext(context.Background())
+	d.start(time.Time{})
+	d.start(time.Time{})
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Errorf("expect the stream context to be canceled")
+	}
+
+	var nilDrainer *drainer
+	nilDrainer.begin()
+	nilDrainer.end()
+	nilDrainer.start(time.Time{})
+	if ctx := context.Background(); nilDrainer.streamContext(ctx) != ctx {
+		t.Errorf("expect the context as it is")
+	}
+}
+
+func startTestServer(t *testing.T, srv *Server) string {
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		if err := srv.Start(context.Background()); err != nil {
+			t.Error(err)
+		}
+	}()
+	time.Sleep(time.Millisecond * 10)
+	return e.String()
+}
+
+func TestServerStopDrain(t *testing.T) {
+	srv := NewServer(Address("127.0.0.1:0"), Timeout(0))
+	started := make(chan struct{})
+	srv.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(time.Millisecond * 200)
+		w.WriteHeader(http.StatusOK)
+	})
+	srv.Route("/").GET("/events", func(ctx Context) error {
+		sw, err := ctx.SSE()
+		if err != nil {
+			return err
+		}
+		<-sw.Done()
+		return nil
+	})
+	addr := startTestServer(t, srv)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		resp, err := http.Get(addr + "/slow")
+		if err != nil {
+			t.Errorf("expect the in-flight request to complete, got %v", err)
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("expect %v, got %v", http.StatusOK, resp.StatusCode)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		resp, err := http.Get(addr + "/events")
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+	time.Sleep(time.Millisecond * 10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := srv.Stop(ctx); err != nil {
+		t.Errorf("expect no error, got %v", err)
+	}
+	wg.Wait()
+}
+
+func TestServerStopForceClose(t *testing.T) {
+	srv := NewServer(Address("127.0.0.1:0"), Timeout(0))
+	started := make(chan struct{})
+	srv.HandleFunc("/block", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(time.Second)
+	})
+	addr := startTestServer(t, srv)
+
+	done := make(chan error, 1)
+	go func() {
+		resp, err := http.Get(addr + "/block")
+		if err == nil {
+			resp.Body.Close()
+		}
+		done <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	start := time.Now()
+	if err := srv.Stop(ctx); err == nil {
+		t.Errorf("expect a timeout error")
+	}
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Errorf("expect the straggler to be closed")
+		}
+	case <-time.After(time.Millisecond * 500):
+		t.Errorf("expect the straggler to be closed in time, elapsed %v", time.Since(start))
+	}
+}
+
+func TestServerStopStreams(t *testing.T) {
+	srv := NewServer(Address("127.0.0.1:0"), Timeout(0), DrainGrace(time.Second))
+	causes := make(chan error, 2)
+	srv.Route("/").GET("/events", func(ctx Context) error {
+		sw, err := ctx.SSE()
+		if err != nil {
+			return err
+		}
+		<-sw.Done()
+		if _, ok := Draining(ctx); !ok {
+			t.Errorf("expect the server to be draining")
+		}
+		causes <- DrainCause(StreamContext(ctx))
+		return sw.Send(SSEEvent{Event: "bye"})
+	})
+	srv.Route("/").GET("/ws", WebSocket(func(ctx Context, conn *websocket.Conn) {
+		sctx := StreamContext(ctx)
+		<-sctx.Done()
+		if deadline, ok := Draining(sctx); !ok || deadline.IsZero() {
+			t.Errorf("expect the deadline of the drain grace, got %v %v", deadline, ok)
+		}
+		causes <- DrainCause(sctx)
+		_ = websocket.Message.Send(conn, "bye")
+	}))
+	addr := startTestServer(t, srv)
+
+	resp, err := http.Get(addr + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	u, _ := url.Parse(addr)
+	conn, err := websocket.Dial("ws://"+u.Host+"/ws", "", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	time.Sleep(time.Millisecond * 10)
+
+	if _, ok := Draining(context.Background()); ok {
+		t.Errorf("expect no draining of a plain context")
+	}
+	if err = srv.Stop(context.Background()); err != nil {
+		t.Errorf("expect no error, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-causes; !errors.Is(err, ErrDraining) {
+			t.Errorf("expect ErrDraining, got %v", err)
+		}
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "event: bye") {
+		t.Errorf("expect the terminal event, got %q", body)
+	}
+	var msg string
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	if err = websocket.Message.Receive(conn, &msg); err != nil || msg != "bye" {
+		t.Errorf("expect the terminal message, got %q %v", msg, err)
+	}
+}
@@ -0,0 +1,29 @@
+package http
+
+import "net/http"
+
+// PreloadLink returns the Link header value preloading the target, e.g.
+// PreloadLink("/style.css", "style") is </style.css>; rel=preload; as=style.
+func PreloadLink(target, as string) string {
+	return "<" + target + ">; rel=preload; as=" + as
+}
+
+// EarlyHints adds the Link headers and sends them by the 103 Early Hints response before
+// the final response, so that the clients can preload the resources while the handler is
+// still running. The links are kept in the final response. It must be called before the
+// final response is written, and only adds the headers before Go 1.19 or for HTTP/1.0.
+func (c *wrapper) EarlyHints(links ...string) {
+	h := c.res.Header()
+	for _, link := range links {
+		h.Add("Link", link)
+	}
+	if c.req.ProtoAtLeast(1, 1) {
+		writeEarlyHints(c.res)
+	}
+}
+
+// informational reports whether the code is an informational response, e.g. 103 Early Hints,
+// which is followed by the final response. 101 Switching Protocols is final.
+func informational(code int) bool {
+	return code >= 100 && code < 200 && code != http.StatusSwitchingProtocols
+}
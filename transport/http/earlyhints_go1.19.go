@@ -0,0 +1,11 @@
+//go:build go1.19
+// +build go1.19
+
+package http
+
+import "net/http"
+
+// writeEarlyHints sends the 103 Early Hints response, the 1xx responses are supported since Go 1.19.
+func writeEarlyHints(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusEarlyHints)
+}
@@ -0,0 +1,56 @@
+//go:build go1.19
+// +build go1.19
+
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"testing"
+)
+
+func TestPreloadLink(t *testing.T) {
+	if link := PreloadLink("/style.css", "style"); link != "</style.css>; rel=preload; as=style" {
+		t.Errorf("unexpected link: %s", link)
+	}
+}
+
+func TestEarlyHints(t *testing.T) {
+	srv := NewServer()
+	srv.Route("/", ETag()).GET("/index", func(ctx Context) error {
+		ctx.EarlyHints(PreloadLink("/style.css", "style"))
+		return ctx.String(http.StatusOK, "hello")
+	})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	var hints []string
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			if code == http.StatusEarlyHints {
+				hints = append(hints, header.Get("Link"))
+			}
+			return nil
+		},
+	}
+	req, _ := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), trace), http.MethodGet, ts.URL+"/index", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "hello" {
+		t.Errorf("unexpected response: %d %s", resp.StatusCode, body)
+	}
+	if len(hints) != 1 || hints[0] != "</style.css>; rel=preload; as=style" {
+		t.Errorf("expect the early hints, got %v", hints)
+	}
+	if resp.Header.Get("Link") == "" || resp.Header.Get("ETag") == "" {
+		t.Errorf("expect the links and the etag in the final response, got %v", resp.Header)
+	}
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// RouterEngine is the router engine of the routes registered by Router.Handle, e.g. an
+// adapter of a radix tree router like chi or httprouter, replacing gorilla/mux for the
+// performance without changing the generated code.
+type RouterEngine interface {
+	http.Handler
+	// Handle registers the handler of the method and the path template, e.g. /users/{id}.
+	Handle(method, path string, h http.Handler)
+	// Vars returns the path variables of the request matched by the engine.
+	Vars(req *http.Request) map[string]string
+	// Walk calls fn for each route registered.
+	Walk(fn func(method, path string) error) error
+}
+
+// Engine with the router engine of the routes registered by Router.Handle, the requests
+// matching no route of gorilla/mux, e.g. Server.Handle, Server.HandlePrefix and
+// Server.Mount, are served by the engine. The unmatched requests of the engine are
+// served by the NotFoundHandler if the engine implements NotFound(http.Handler),
+// otherwise by the engine itself. PathPrefix and StrictSlash do not apply to it.
+func Engine(e RouterEngine) ServerOption {
+	return func(s *Server) {
+		s.engine = e
+	}
+}
+
+// engineRoute is the key of the metadata of the routes registered to the engine.
+type engineRoute struct {
+	method string
+	path   string
+}
+
+type varsKey struct{}
+
+// handleEngine registers the handler of the route to the engine inside the filters of the server.
+func (s *Server) handleEngine(method, path string, h http.Handler) {
+	next := s.routeFilter(path)(h)
+	s.engine.Handle(method, path, http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		ctx := context.WithValue(req.Context(), varsKey{}, s.engine.Vars(req))
+		next.ServeHTTP(res, req.WithContext(ctx))
+	}))
+}
+
+// routeVars returns the path variables of the request matched by the engine or gorilla/mux.
+func routeVars(req *http.Request) map[string]string {
+	if vars, ok := req.Context().Value(varsKey{}).(map[string]string); ok {
+		return vars
+	}
+	return mux.Vars(req)
+}
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type testVarsKey struct{}
+
+// testEngine is a minimal engine matching the path segments.
+type testEngine struct {
+	routes   []engineRoute
+	handlers []http.Handler
+	notFound http.Handler
+}
+
+func (e *testEngine) Handle(method, path string, h http.Handler) {
+	e.routes = append(e.routes, engineRoute{method: method, path: path})
+	e.handlers = append(e.handlers, h)
+}
+
+func (e *testEngine) NotFound(h http.Handler) {
+	e.notFound = h
+}
+
+func (e *testEngine) Vars(req *http.Request) map[string]string {
+	vars, _ := req.Context().Value(testVarsKey{}).(map[string]string)
+	return vars
+}
+
+func (e *testEngine) Walk(fn func(method, path string) error) error {
+	for _, r := range e.routes {
+		if err := fn(r.method, r.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *testEngine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	for i, r := range e.routes {
+		if r.method != req.Method {
+			continue
+		}
+		if vars, ok := matchSegments(r.path, req.URL.Path); ok {
+			e.handlers[i].ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), testVarsKey{}, vars)))
+			return
+		}
+	}
+	if e.notFound != nil {
+		e.notFound.ServeHTTP(w, req)
+		return
+	}
+	http.NotFound(w, req)
+}
+
+func matchSegments(template, path string) (map[string]string, bool) {
+	ts, ps := strings.Split(template, "/"), strings.Split(path, "/")
+	if len(ts) != len(ps) {
+		return nil, false
+	}
+	vars := make(map[string]string)
+	for i, t := range ts {
+		if strings.HasPrefix(t, "{") && strings.HasSuffix(t, "}") {
+			vars[t[1:len(t)-1]] = ps[i]
+		} else if t != ps[i] {
+			return nil, false
+		}
+	}
+	return vars, true
+}
+
+func TestEngine(t *testing.T) {
+	e := &testEngine{}
+	srv := NewServer(Engine(e), NotFoundHandler(func(ctx Context) error {
+		return ErrNotFound
+	}))
+	srv.Route("/v1").GET("/users/{name}", func(ctx Context) error {
+		var in struct {
+			Name string `json:"name"`
+		}
+		if err := ctx.BindVars(&in); err != nil {
+			return err
+		}
+		tr, _ := transport.FromServerContext(ctx)
+		return ctx.String(http.StatusOK, in.Name+" "+tr.Operation()+" "+ctx.Vars().Get("name"))
+	}, CORS(CORSAllowOrigins("*")))
+	srv.HandleFunc("/mux", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("mux"))
+	})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	for path, want := range map[string]string{
+		"/v1/users/kratos": "kratos /v1/users/{name} kratos",
+		"/mux":             "mux",
+	} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || string(body) != want {
+			t.Errorf("%s: unexpected response: %d %q", path, resp.StatusCode, body)
+		}
+	}
+
+	resp, err := http.Get(ts.URL + "/v1/none")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound || resp.Header.Get("Content-Type") == "" {
+		t.Errorf("expect the not found error encoded, got %d %v", resp.StatusCode, resp.Header)
+	}
+
+	req, _ := http.NewRequest(http.MethodOptions, ts.URL+"/v1/users/kratos", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.Header.Get("Access-Control-Allow-Origin") == "" {
+		t.Errorf("expect the preflight replied by the filters, got %d %v", resp.StatusCode, resp.Header)
+	}
+
+	var routes []RouteInfo
+	_ = srv.WalkRoute(func(info RouteInfo) error {
+		routes = append(routes, info)
+		return nil
+	})
+	if len(routes) != 1 || routes[0].Method != http.MethodGet || routes[0].Path != "/v1/users/{name}" || routes[0].Operation != "/v1/users/{name}" {
+		t.Errorf("unexpected routes: %+v", routes)
+	}
+}
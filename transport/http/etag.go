@@ -0,0 +1,179 @@
+package http
+
+import (
+	"bytes"
+	"encoding/hex"
+	"hash"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultETagMaxSize = 1 << 20
+
+// ETagOption is ETag filter option.
+type ETagOption func(*etagOptions)
+
+type etagOptions struct {
+	weak    bool
+	maxSize int
+}
+
+// ETagWeak with generating the weak validators, e.g. W/"xyz".
+func ETagWeak() ETagOption {
+	return func(o *etagOptions) {
+		o.weak = true
+	}
+}
+
+// ETagMaxSize with the max size in bytes of the buffered responses, the larger
+// responses are streamed without the ETag, default is 1MB.
+func ETagMaxSize(n int) ETagOption {
+	return func(o *etagOptions) {
+		o.maxSize = n
+	}
+}
+
+// ETag returns a filter generating the ETag of the GET and HEAD responses and
+// replying 304 Not Modified if the If-None-Match or If-Modified-Since of the request
+// matches, the ETag and the Last-Modified set by the handler are respected.
+// The responses are hashed as they are written, and streamed through if they are
+// flushed or exceed the max size. It is opt-in per router group by Server.Route
+// and Router.Group, or per route by Router.Handle.
+func ETag(opts ...ETagOption) FilterFunc {
+	o := &etagOptions{maxSize: defaultETagMaxSize}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if (req.Method != http.MethodGet && req.Method != http.MethodHead) || req.Header.Get("Upgrade") != "" {
+				next.ServeHTTP(w, req)
+				return
+			}
+			ew := &etagWriter{w: w, opts: o, code: http.StatusOK, hash: fnv.New64a()}
+			next.ServeHTTP(ew, req)
+			ew.finish(req)
+		})
+	}
+}
+
+type etagWriter struct {
+	w    http.ResponseWriter
+	opts *etagOptions
+	code int
+	hash hash.Hash64
+	buf  bytes.Buffer
+	// 已经直接写出，不再缓存
+	passthrough bool
+	wroteHeader bool
+}
+
+func (w *etagWriter) Header() http.Header { return w.w.Header() }
+
+func (w *etagWriter) WriteHeader(code int) {
+	if informational(code) {
+		w.w.WriteHeader(code)
+		return
+	}
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.code = code
+	if w.passthrough {
+		w.w.WriteHeader(code)
+	}
+}
+
+func (w *etagWriter) Write(data []byte) (int, error) {
+	w.wroteHeader = true
+	if w.passthrough {
+		return w.w.Write(data)
+	}
+	if w.buf.Len()+len(data) > w.opts.maxSize {
+		if err := w.stream(); err != nil {
+			return 0, err
+		}
+		return w.w.Write(data)
+	}
+	_, _ = w.hash.Write(data)
+	return w.buf.Write(data)
+}
+
+// Flush streams the response through, e.g. the server-sent events.
+func (w *etagWriter) Flush() {
+	if err := w.stream(); err != nil {
+		return
+	}
+	if f, ok := w.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *etagWriter) stream() error {
+	if w.passthrough {
+		return nil
+	}
+	w.passthrough = true
+	w.w.WriteHeader(w.code)
+	_, err := w.w.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+func (w *etagWriter) finish(req *http.Request) {
+	if w.passthrough {
+		return
+	}
+	h := w.w.Header()
+	if w.code == http.StatusOK && h.Get("ETag") == "" {
+		tag := `"` + hex.EncodeToString(w.hash.Sum(nil)) + `"`
+		if w.opts.weak {
+			tag = "W/" + tag
+		}
+		h.Set("ETag", tag)
+	}
+	if w.code >= 200 && w.code < 300 && notModified(req, h) {
+		h.Del("Content-Type")
+		h.Del("Content-Length")
+		w.w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.w.WriteHeader(w.code)
+	if req.Method != http.MethodHead {
+		_, _ = w.w.Write(w.buf.Bytes())
+	}
+}
+
+// notModified reports whether the validators of the request match the response,
+// the If-Modified-Since is ignored if the If-None-Match is present (RFC 7232).
+func notModified(req *http.Request, h http.Header) bool {
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		etag := h.Get("ETag")
+		if etag == "" {
+			return false
+		}
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || trimWeak(tag) == trimWeak(etag) {
+				return true
+			}
+		}
+		return false
+	}
+	ims, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	lm, err := http.ParseTime(h.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+	return !lm.Truncate(time.Second).After(ims)
+}
+
+func trimWeak(tag string) string {
+	return strings.TrimPrefix(tag, "W/")
+}
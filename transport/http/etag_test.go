@@ -0,0 +1,110 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestETag(t *testing.T) {
+	srv := NewServer()
+	r := srv.Route("/", ETag())
+	r.GET("/users", func(ctx Context) error {
+		return ctx.String(http.StatusOK, "kratos")
+	})
+	r.GET("/error", func(ctx Context) error {
+		return ctx.String(http.StatusInternalServerError, "error")
+	})
+	r.POST("/users", func(ctx Context) error {
+		return ctx.String(http.StatusOK, "kratos")
+	})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" || rec.Body.String() != "kratos" {
+		t.Fatalf("unexpected response: %v %v %v", rec.Code, etag, rec.Body.String())
+	}
+
+	tests := []struct {
+		method      string
+		path        string
+		ifNoneMatch string
+		code        int
+	}{
+		{http.MethodGet, "/users", etag, http.StatusNotModified},
+		{http.MethodGet, "/users", "W/" + etag, http.StatusNotModified},
+		{http.MethodGet, "/users", `"other", ` + etag, http.StatusNotModified},
+		{http.MethodGet, "/users", "*", http.StatusNotModified},
+		{http.MethodGet, "/users", `"other"`, http.StatusOK},
+		{http.MethodGet, "/error", etag, http.StatusInternalServerError},
+		{http.MethodPost, "/users", etag, http.StatusOK},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.path, nil)
+		req.Header.Set("If-None-Match", test.ifNoneMatch)
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if rec.Code != test.code {
+			t.Errorf("%s %s %s: expect %v, got %v", test.method, test.path, test.ifNoneMatch, test.code, rec.Code)
+		}
+		if rec.Code == http.StatusNotModified && rec.Body.Len() != 0 {
+			t.Errorf("expect empty body, got %v", rec.Body.String())
+		}
+	}
+}
+
+func TestETagLastModified(t *testing.T) {
+	modified := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	srv := NewServer()
+	srv.Route("/", ETag(ETagWeak())).GET("/users", func(ctx Context) error {
+		ctx.Response().Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+		return ctx.String(http.StatusOK, "kratos")
+	})
+	tests := []struct {
+		since time.Time
+		code  int
+	}{
+		{modified, http.StatusNotModified},
+		{modified.Add(time.Hour), http.StatusNotModified},
+		{modified.Add(-time.Hour), http.StatusOK},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Set("If-Modified-Since", test.since.Format(http.TimeFormat))
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if rec.Code != test.code {
+			t.Errorf("%v: expect %v, got %v", test.since, test.code, rec.Code)
+		}
+		if etag := rec.Header().Get("ETag"); !strings.HasPrefix(etag, "W/") {
+			t.Errorf("expect weak etag, got %v", etag)
+		}
+	}
+}
+
+func TestETagStreaming(t *testing.T) {
+	srv := NewServer()
+	r := srv.Route("/", ETag(ETagMaxSize(4)))
+	r.GET("/large", func(ctx Context) error {
+		return ctx.String(http.StatusOK, "kratos")
+	})
+	r.GET("/stream", func(ctx Context) error {
+		ctx.Response().(http.Flusher).Flush()
+		return ctx.String(http.StatusOK, "ok")
+	})
+	for _, path := range []string{"/large", "/stream"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("If-None-Match", "*")
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: expect %v, got %v", path, http.StatusOK, rec.Code)
+		}
+		if etag := rec.Header().Get("ETag"); etag != "" {
+			t.Errorf("%s: expect no etag, got %v", path, etag)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// sensitiveHeaders are redacted in the exchanges by default.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// Exchange is the raw request and response of a client round trip, the headers
+// are copies and the bodies are captured up to the limit.
+type Exchange struct {
+	Method         string
+	URL            string
+	RequestHeader  http.Header
+	RequestBody    []byte
+	StatusCode     int
+	ResponseHeader http.Header
+	ResponseBody   []byte
+	// Err is the error of the round trip or reading the response body.
+	Err error
+	// Latency is the duration until the response body is closed.
+	Latency time.Duration
+}
+
+// ExchangeHook is called with the exchange once the response body is closed,
+// or the round trip failed. The retries and the hedged requests are observed separately.
+type ExchangeHook func(ctx context.Context, ex *Exchange)
+
+// ExchangeOption is exchange hook option.
+type ExchangeOption func(*exchangeOptions)
+
+type exchangeOptions struct {
+	limit  int
+	redact func(*Exchange)
+}
+
+// ExchangeBodyLimit with the max bytes of the captured bodies, default is 0
+// that only the headers are captured.
+func ExchangeBodyLimit(n int) ExchangeOption {
+	return func(o *exchangeOptions) {
+		o.limit = n
+	}
+}
+
+// ExchangeRedact with the function redacting the exchange before the hook is called,
+// e.g. masking the passwords of the bodies. The Authorization, Proxy-Authorization,
+// Cookie and Set-Cookie headers are always redacted.
+func ExchangeRedact(f func(*Exchange)) ExchangeOption {
+	return func(o *exchangeOptions) {
+		o.redact = f
+	}
+}
+
+// WithExchangeHook with the hook observing the raw requests and responses of the client,
+// e.g. audit logging. The response bodies are captured as they are read, so streaming is kept.
+func WithExchangeHook(h ExchangeHook, opts ...ExchangeOption) ClientOption {
+	return func(o *clientOptions) {
+		eo := exchangeOptions{}
+		for _, opt := range opts {
+			opt(&eo)
+		}
+		o.exchange = func(next http.RoundTripper) http.RoundTripper {
+			return &exchangeTransport{next: next, hook: h, opts: eo}
+		}
+	}
+}
+
+type exchangeTransport struct {
+	next http.RoundTripper
+	hook ExchangeHook
+	opts exchangeOptions
+}
+
+func (t *exchangeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	e := &exchange{
+		t:     t,
+		ctx:   req.Context(),
+		start: time.Now(),
+		ex: Exchange{
+			Method:        req.Method,
+			URL:           req.URL.String(),
+			RequestHeader: req.Header.Clone(),
+		},
+	}
+	if t.opts.limit > 0 && req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody != nil {
+			// 读取副本，不影响请求体
+			if body, err := req.GetBody(); err == nil {
+				e.ex.RequestBody, _ = io.ReadAll(io.LimitReader(body, int64(t.opts.limit)))
+				_ = body.Close()
+			}
+		} else {
+			r := *req
+			r.Body = &captureBody{ReadCloser: req.Body, limit: t.opts.limit, buf: &e.reqBody}
+			req = &r
+		}
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		e.finish(err)
+		return nil, err
+	}
+	e.ex.StatusCode = resp.StatusCode
+	e.ex.ResponseHeader = resp.Header.Clone()
+	resp.Body = &captureBody{ReadCloser: resp.Body, limit: t.opts.limit, buf: &e.resBody, done: e.finish}
+	return resp, nil
+}
+
+// exchange is an exchange in progress.
+type exchange struct {
+	t       *exchangeTransport
+	ctx     context.Context
+	start   time.Time
+	once    sync.Once
+	ex      Exchange
+	reqBody lockedBuffer
+	resBody lockedBuffer
+}
+
+func (e *exchange) finish(err error) {
+	e.once.Do(func() {
+		e.ex.Err = err
+		e.ex.Latency = time.Since(e.start)
+		if e.ex.RequestBody == nil {
+			e.ex.RequestBody = e.reqBody.bytes()
+		}
+		e.ex.ResponseBody = e.resBody.bytes()
+		for _, h := range sensitiveHeaders {
+			redactHeader(e.ex.RequestHeader, h)
+			redactHeader(e.ex.ResponseHeader, h)
+		}
+		if e.t.opts.redact != nil {
+			e.t.opts.redact(&e.ex)
+		}
+		e.t.hook(e.ctx, &e.ex)
+	})
+}
+
+func redactHeader(h http.Header, key string) {
+	if vs := h.Values(key); len(vs) > 0 {
+		h[http.CanonicalHeaderKey(key)] = []string{"REDACTED"}
+	}
+}
+
+// lockedBuffer is written by the transport and read by the hook concurrently,
+// e.g. the request body of HTTP/2 is sent after the response.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) write(p []byte, limit int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if n := limit - b.buf.Len(); n > 0 {
+		if len(p) > n {
+			p = p[:n]
+		}
+		b.buf.Write(p)
+	}
+}
+
+func (b *lockedBuffer) bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.buf.Len() == 0 {
+		return nil
+	}
+	return append([]byte(nil), b.buf.Bytes()...)
+}
+
+// captureBody captures the body up to the limit as it is read,
+// done is called once it is fully read or closed.
+type captureBody struct {
+	io.ReadCloser
+	limit int
+	buf   *lockedBuffer
+	done  func(error)
+}
+
+func (b *captureBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 && b.limit > 0 {
+		b.buf.write(p[:n], b.limit)
+	}
+	if err != nil && b.done != nil {
+		if err == io.EOF {
+			b.done(nil)
+		} else {
+			b.done(err)
+		}
+	}
+	return n, err
+}
+
+func (b *captureBody) Close() error {
+	err := b.ReadCloser.Close()
+	if b.done != nil {
+		b.done(nil)
+	}
+	return err
+}
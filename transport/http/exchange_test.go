@@ -0,0 +1,110 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestExchangeHook(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		_, _ = w.Write([]byte(`{"echo":` + string(body) + `}`))
+	}))
+	defer ts.Close()
+
+	var (
+		mu        sync.Mutex
+		exchanges []*Exchange
+	)
+	client, err := NewClient(context.Background(),
+		WithEndpoint(ts.Listener.Addr().String()),
+		WithExchangeHook(func(_ context.Context, ex *Exchange) {
+			mu.Lock()
+			defer mu.Unlock()
+			exchanges = append(exchanges, ex)
+		}, ExchangeBodyLimit(20), ExchangeRedact(func(ex *Exchange) {
+			ex.RequestBody = []byte(strings.ReplaceAll(string(ex.RequestBody), "kratos", "******"))
+		})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reply struct {
+		Echo struct {
+			Name string `json:"name"`
+		} `json:"echo"`
+	}
+	err = client.Invoke(context.Background(), http.MethodPost, "/echo", map[string]string{"name": "kratos"}, &reply,
+		RequestHeader(http.Header{"Authorization": {"Bearer token"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Echo.Name != "kratos" {
+		t.Errorf("expect %v, got %v", "kratos", reply.Echo.Name)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(exchanges) != 1 {
+		t.Fatalf("expect 1 exchange, got %v", len(exchanges))
+	}
+	ex := exchanges[0]
+	if ex.Method != http.MethodPost || !strings.HasSuffix(ex.URL, "/echo") || ex.StatusCode != http.StatusOK {
+		t.Errorf("unexpected exchange: %+v", ex)
+	}
+	if got := string(ex.RequestBody); got != `{"name":"******"}` {
+		t.Errorf("expect the redacted and truncated request body, got %v", got)
+	}
+	if got := string(ex.ResponseBody); got != `{"echo":{"name":"kra` {
+		t.Errorf("expect the truncated response body, got %v", got)
+	}
+	if ex.RequestHeader.Get("Authorization") != "REDACTED" || ex.ResponseHeader.Get("Set-Cookie") != "REDACTED" {
+		t.Errorf("expect the sensitive headers to be redacted, got %v %v", ex.RequestHeader, ex.ResponseHeader)
+	}
+	if ex.Latency <= 0 {
+		t.Errorf("expect the latency, got %v", ex.Latency)
+	}
+}
+
+type errorRoundTripper struct{ err error }
+
+func (rt errorRoundTripper) RoundTrip(*http.Request) (*http.Response, error) { return nil, rt.err }
+
+func TestExchangeTransportError(t *testing.T) {
+	want := errors.New("dial failed")
+	var got *Exchange
+	rt := &exchangeTransport{next: errorRoundTripper{want}, hook: func(_ context.Context, ex *Exchange) { got = ex }}
+	req := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/", nil)
+	if _, err := rt.RoundTrip(req); !errors.Is(err, want) {
+		t.Errorf("expect %v, got %v", want, err)
+	}
+	if got == nil || !errors.Is(got.Err, want) {
+		t.Errorf("expect the error to be observed, got %+v", got)
+	}
+}
+
+func TestCaptureBody(t *testing.T) {
+	var (
+		buf  lockedBuffer
+		done int
+	)
+	b := &captureBody{ReadCloser: io.NopCloser(strings.NewReader("streaming")), limit: 6, buf: &buf, done: func(error) { done++ }}
+	data, _ := io.ReadAll(b)
+	_ = b.Close()
+	if string(data) != "streaming" {
+		t.Errorf("expect the body not to be truncated, got %v", string(data))
+	}
+	if string(buf.bytes()) != "stream" {
+		t.Errorf("expect %v, got %v", "stream", string(buf.bytes()))
+	}
+	if done == 0 {
+		t.Errorf("expect done to be called")
+	}
+}
@@ -0,0 +1,141 @@
+package http
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	grpcWebContentType     = "application/grpc-web"
+	grpcWebTextContentType = "application/grpc-web-text"
+	// grpcWebTrailerFlag marks the frame of the trailers in the response body.
+	grpcWebTrailerFlag = 0x80
+)
+
+// GRPCWeb returns a filter serving the gRPC-Web and gRPC-Web-Text requests by the gRPC
+// server, e.g. the Server of transport/grpc, they are translated to the gRPC requests in
+// process, so the browsers call the services without deploying a proxy like Envoy.
+// The other requests pass through. For the cross-origin calls, the CORS filter should be
+// in front of it, exposing the grpc-status and grpc-message headers.
+func GRPCWeb(grpcSrv http.Handler) FilterFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ct := req.Header.Get("Content-Type")
+			if req.Method != http.MethodPost || !strings.HasPrefix(ct, grpcWebContentType) {
+				next.ServeHTTP(w, req)
+				return
+			}
+			text := strings.HasPrefix(ct, grpcWebTextContentType)
+			subtype := strings.TrimPrefix(ct, grpcWebContentType)
+			if text {
+				subtype = strings.TrimPrefix(ct, grpcWebTextContentType)
+			}
+			r := req.Clone(req.Context())
+			// gRPC服务端只接受HTTP/2的请求
+			r.Proto, r.ProtoMajor, r.ProtoMinor = "HTTP/2", 2, 0
+			r.Header.Set("Content-Type", "application/grpc"+subtype)
+			r.Header.Del("Content-Length")
+			r.ContentLength = -1
+			if text {
+				r.Body = struct {
+					io.Reader
+					io.Closer
+				}{base64.NewDecoder(base64.StdEncoding, req.Body), req.Body}
+			}
+			gw := &grpcWebWriter{w: w, header: make(http.Header), contentType: ct, text: text}
+			grpcSrv.ServeHTTP(gw, r)
+			gw.finish()
+		})
+	}
+}
+
+// grpcWebWriter translates the gRPC response to the gRPC-Web one, the trailers are
+// written as the last frame of the body.
+type grpcWebWriter struct {
+	w           http.ResponseWriter
+	header      http.Header
+	contentType string
+	text        bool
+	enc         io.WriteCloser
+	wroteHeader bool
+}
+
+func (w *grpcWebWriter) Header() http.Header { return w.header }
+
+func (w *grpcWebWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	h := w.w.Header()
+	trailers := make(map[string]bool)
+	for _, k := range w.header.Values("Trailer") {
+		trailers[http.CanonicalHeaderKey(k)] = true
+	}
+	for k, vs := range w.header {
+		if k == "Trailer" || trailers[k] || strings.HasPrefix(k, http.TrailerPrefix) {
+			continue
+		}
+		h[k] = vs
+	}
+	h.Set("Content-Type", w.contentType)
+	h.Del("Content-Length")
+	w.w.WriteHeader(code)
+}
+
+func (w *grpcWebWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	if !w.text {
+		return w.w.Write(p)
+	}
+	if w.enc == nil {
+		w.enc = base64.NewEncoder(base64.StdEncoding, w.w)
+	}
+	return w.enc.Write(p)
+}
+
+func (w *grpcWebWriter) Flush() {
+	w.WriteHeader(http.StatusOK)
+	if w.enc != nil {
+		// 每次刷新都结束当前的base64块，客户端按块解码
+		_ = w.enc.Close()
+		w.enc = nil
+	}
+	if f, ok := w.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// finish writes the frame of the trailers, they are the declared ones and the ones of
+// the http.TrailerPrefix set by the gRPC server after the body.
+func (w *grpcWebWriter) finish() {
+	var b bytes.Buffer
+	for _, k := range w.header.Values("Trailer") {
+		for _, v := range w.header.Values(k) {
+			writeGRPCWebTrailer(&b, k, v)
+		}
+	}
+	for k, vs := range w.header {
+		if strings.HasPrefix(k, http.TrailerPrefix) {
+			for _, v := range vs {
+				writeGRPCWebTrailer(&b, strings.TrimPrefix(k, http.TrailerPrefix), v)
+			}
+		}
+	}
+	frame := make([]byte, 5, 5+b.Len())
+	frame[0] = grpcWebTrailerFlag
+	binary.BigEndian.PutUint32(frame[1:], uint32(b.Len()))
+	_, _ = w.Write(append(frame, b.Bytes()...))
+	w.Flush()
+}
+
+func writeGRPCWebTrailer(b *bytes.Buffer, k, v string) {
+	b.WriteString(strings.ToLower(k))
+	b.WriteString(": ")
+	b.WriteString(v)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func grpcWebFrames(t *testing.T, body []byte) (data []byte, trailer string) {
+	for len(body) >= 5 {
+		n := binary.BigEndian.Uint32(body[1:5])
+		if body[0]&grpcWebTrailerFlag != 0 {
+			trailer = string(body[5 : 5+n])
+		} else {
+			data = body[5 : 5+n]
+		}
+		body = body[5+n:]
+	}
+	if len(body) != 0 {
+		t.Fatalf("unexpected trailing bytes: %q", body)
+	}
+	return
+}
+
+func TestGRPCWeb(t *testing.T) {
+	gs := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(gs, health.NewServer())
+	srv := NewServer(Filter(GRPCWeb(gs)))
+	srv.HandleFunc("/index", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("index"))
+	})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	call := func(ct, service string) (*http.Response, []byte) {
+		msg, _ := proto.Marshal(&grpc_health_v1.HealthCheckRequest{Service: service})
+		frame := make([]byte, 5, 5+len(msg))
+		binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+		frame = append(frame, msg...)
+		if strings.HasPrefix(ct, grpcWebTextContentType) {
+			frame = []byte(base64.StdEncoding.EncodeToString(frame))
+		}
+		res, err := http.Post(ts.URL+"/grpc.health.v1.Health/Check", ct, bytes.NewReader(frame))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		if strings.HasPrefix(ct, grpcWebTextContentType) {
+			var b bytes.Buffer
+			// 响应由多个带填充的base64块组成，每块的长度都是4的倍数
+			for i := 0; i+4 <= len(body); i += 4 {
+				d, err := base64.StdEncoding.DecodeString(string(body[i : i+4]))
+				if err != nil {
+					t.Fatal(err)
+				}
+				b.Write(d)
+			}
+			body = b.Bytes()
+		}
+		return res, body
+	}
+
+	for _, ct := range []string{"application/grpc-web+proto", "application/grpc-web-text"} {
+		res, body := call(ct, "")
+		if res.Header.Get("Content-Type") != ct {
+			t.Errorf("%s: unexpected content type %s", ct, res.Header.Get("Content-Type"))
+		}
+		data, trailer := grpcWebFrames(t, body)
+		var reply grpc_health_v1.HealthCheckResponse
+		if err := proto.Unmarshal(data, &reply); err != nil {
+			t.Fatal(err)
+		}
+		if reply.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+			t.Errorf("%s: unexpected reply %v", ct, reply.Status)
+		}
+		if !strings.Contains(trailer, "grpc-status: 0\r\n") {
+			t.Errorf("%s: unexpected trailer %q", ct, trailer)
+		}
+	}
+
+	_, body := call("application/grpc-web", "unknown")
+	if _, trailer := grpcWebFrames(t, body); !strings.Contains(trailer, "grpc-status: 5\r\n") || !strings.Contains(trailer, "grpc-message: unknown service\r\n") {
+		t.Errorf("unexpected trailer %q", trailer)
+	}
+
+	res, err := http.Get(ts.URL + "/index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if b, _ := io.ReadAll(res.Body); string(b) != "index" {
+		t.Errorf("expect the other requests passed through, got %q", b)
+	}
+}
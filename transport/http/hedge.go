@@ -0,0 +1,230 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+const (
+	defaultHedgeDelay    = 50 * time.Millisecond
+	defaultHedgeAttempts = 2
+	defaultHedgeBudget   = 0.1
+	// maxHedgeTokens caps the tokens saved by the budget, to bound the bursts of hedging.
+	maxHedgeTokens = 10
+)
+
+// HedgePolicy is the hedging policy of the client calls: if an attempt does not
+// complete after the delay, another attempt is sent to a different node, the first
+// successful response wins and the other attempts are canceled.
+// Only the idempotent calls are hedged like RetryPolicy, and hedging takes
+// precedence over retrying.
+type HedgePolicy struct {
+	// Delay is the delay before sending the next hedged attempt, default is 50ms.
+	Delay time.Duration
+	// MaxAttempts is the max number of attempts including the first one, default is 2.
+	MaxAttempts int
+	// Budget is the max ratio of the hedged attempts to the calls, default is 0.1,
+	// a burst of 10 hedged attempts is allowed.
+	Budget float64
+	// Hedgeable decides whether the other attempts can go on after an attempt failed,
+	// the network errors and 502, 503, 504 are hedgeable if it is nil.
+	Hedgeable func(err error) bool
+}
+
+// WithHedging with client hedging policy.
+func WithHedging(policy HedgePolicy) ClientOption {
+	return func(o *clientOptions) {
+		if policy.Delay <= 0 {
+			policy.Delay = defaultHedgeDelay
+		}
+		if policy.MaxAttempts <= 0 {
+			policy.MaxAttempts = defaultHedgeAttempts
+		}
+		if policy.Budget <= 0 {
+			policy.Budget = defaultHedgeBudget
+		}
+		o.hedge = &hedger{policy: policy, tokens: maxHedgeTokens}
+	}
+}
+
+type hedger struct {
+	policy HedgePolicy
+	mu     sync.Mutex
+	tokens float64
+}
+
+func (h *hedger) allow(req *http.Request, c callInfo) bool {
+	return h.policy.MaxAttempts > 1 && replayable(req, c)
+}
+
+// deposit adds the budget of a call.
+func (h *hedger) deposit() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.tokens += h.policy.Budget; h.tokens > maxHedgeTokens {
+		h.tokens = maxHedgeTokens
+	}
+}
+
+// withdraw takes the budget of a hedged attempt.
+func (h *hedger) withdraw() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.tokens < 1 {
+		return false
+	}
+	h.tokens--
+	return true
+}
+
+func (h *hedger) hedgeable(err error) bool {
+	if h.policy.Hedgeable != nil {
+		return h.policy.Hedgeable(err)
+	}
+	se := new(errors.Error)
+	if !errors.As(err, &se) {
+		return true
+	}
+	return se.Reason != "NODE_NOT_FOUND" &&
+		(se.Code == http.StatusBadGateway || se.Code == http.StatusServiceUnavailable || se.Code == http.StatusGatewayTimeout)
+}
+
+type pickedKey struct{}
+
+// withPicked returns a new context with the function called with the address of the picked node.
+func withPicked(ctx context.Context, f func(addr string)) context.Context {
+	return context.WithValue(ctx, pickedKey{}, f)
+}
+
+func notifyPicked(ctx context.Context, addr string) {
+	if f, ok := ctx.Value(pickedKey{}).(func(string)); ok {
+		f(addr)
+	}
+}
+
+type hedgeResult struct {
+	attempt int
+	resp    *http.Response
+	err     error
+}
+
+// doHedge sends the request with the hedging policy.
+func (client *Client) doHedge(req *http.Request, c callInfo, h *hedger) (*http.Response, error) {
+	h.deposit()
+	ctx := req.Context()
+	var (
+		mu      sync.Mutex
+		picked  []string
+		cancels []context.CancelFunc
+	)
+	results := make(chan hedgeResult, h.policy.MaxAttempts)
+	launch := func(attempt int) error {
+		actx, cancel := context.WithCancel(selector.NewAttemptContext(ctx, attempt))
+		mu.Lock()
+		if len(picked) > 0 {
+			// 避开其他尝试已经选中的节点
+			actx = selector.WithExcludedNodes(actx, picked...)
+		}
+		mu.Unlock()
+		actx = withPicked(actx, func(addr string) {
+			mu.Lock()
+			picked = append(picked, addr)
+			mu.Unlock()
+		})
+		r := req.Clone(actx)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				cancel()
+				return err
+			}
+			r.Body = body
+		}
+		cancels = append(cancels, cancel)
+		go func() {
+			resp, err := client.roundTrip(r, c)
+			results <- hedgeResult{attempt: attempt, resp: resp, err: err}
+		}()
+		return nil
+	}
+	// cancelOthers cancels the attempts but the winner, and drains their results.
+	cancelOthers := func(winner, inflight int) {
+		for i, cancel := range cancels {
+			if i != winner {
+				cancel()
+			}
+		}
+		go func() {
+			for ; inflight > 0; inflight-- {
+				if res := <-results; res.resp != nil {
+					res.resp.Body.Close()
+				}
+			}
+		}()
+	}
+	if err := launch(0); err != nil {
+		return nil, err
+	}
+	var (
+		attempts = 1
+		inflight = 1
+		lastErr  error
+	)
+	timer := time.NewTimer(h.policy.Delay)
+	defer timer.Stop()
+	for {
+		select {
+		case res := <-results:
+			inflight--
+			if res.err == nil {
+				cancelOthers(res.attempt, inflight)
+				res.resp.Body = &releaseBody{ReadCloser: res.resp.Body, release: cancels[res.attempt]}
+				return res.resp, nil
+			}
+			if res.resp != nil {
+				res.resp.Body.Close()
+			}
+			lastErr = res.err
+			if !h.hedgeable(res.err) || ctx.Err() != nil {
+				cancelOthers(-1, inflight)
+				return nil, res.err
+			}
+			if inflight > 0 {
+				continue
+			}
+			if attempts >= h.policy.MaxAttempts || !h.withdraw() {
+				cancelOthers(-1, inflight)
+				return nil, lastErr
+			}
+			// 所有尝试都失败了，立即发起下一个
+		case <-timer.C:
+			if attempts >= h.policy.MaxAttempts || !h.withdraw() {
+				continue
+			}
+		case <-ctx.Done():
+			cancelOthers(-1, inflight)
+			if lastErr == nil {
+				lastErr = errors.ClientClosed("CLIENT_CLOSED", ctx.Err().Error())
+			}
+			return nil, lastErr
+		}
+		if err := launch(attempts); err != nil {
+			cancelOthers(-1, inflight)
+			return nil, err
+		}
+		attempts++
+		inflight++
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(h.policy.Delay)
+	}
+}
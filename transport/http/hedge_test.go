@@ -0,0 +1,100 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestHedging(t *testing.T) {
+	var canceled int64
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			atomic.AddInt64(&canceled, 1)
+		case <-time.After(time.Second):
+		}
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"fast"}`))
+	}))
+	defer fast.Close()
+	client, err := NewClient(context.Background(),
+		WithEndpoint("discovery:///kratos"),
+		WithDiscovery(newStaticDiscovery(slow.URL, fast.URL)),
+		WithBlock(),
+		WithHedging(HedgePolicy{Delay: time.Millisecond * 20}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	for i := 0; i < 6; i++ {
+		start := time.Now()
+		var reply map[string]string
+		if err = client.Invoke(context.Background(), http.MethodGet, "/", nil, &reply); err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		if reply["name"] != "fast" || time.Since(start) > time.Millisecond*500 {
+			t.Errorf("expect the fast node to win, got %v in %v", reply, time.Since(start))
+		}
+	}
+	time.Sleep(time.Millisecond * 50)
+	if atomic.LoadInt64(&canceled) == 0 {
+		t.Errorf("expect the slow attempts to be canceled")
+	}
+}
+
+func TestHedgingFailure(t *testing.T) {
+	var count int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&count, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	client, err := NewClient(context.Background(), WithEndpoint(ts.URL), WithHedging(HedgePolicy{MaxAttempts: 3, Delay: time.Second}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reply map[string]string
+	if err = client.Invoke(context.Background(), http.MethodGet, "/", nil, &reply); !errors.IsServiceUnavailable(err) {
+		t.Errorf("expect %v, got %v", http.StatusServiceUnavailable, err)
+	}
+	if count != 3 {
+		t.Errorf("expect 3 attempts, got %v", count)
+	}
+
+	atomic.StoreInt64(&count, 0)
+	if err = client.Invoke(context.Background(), http.MethodPost, "/", nil, &reply); !errors.IsServiceUnavailable(err) || count != 1 {
+		t.Errorf("expect POST not to be hedged, got %v %v", count, err)
+	}
+}
+
+func TestHedgerBudget(t *testing.T) {
+	o := &clientOptions{}
+	WithHedging(HedgePolicy{Budget: 0.5})(o)
+	h := o.hedge
+	for i := 0; i < maxHedgeTokens; i++ {
+		if !h.withdraw() {
+			t.Fatalf("expect the burst of %d hedges", maxHedgeTokens)
+		}
+	}
+	if h.withdraw() {
+		t.Errorf("expect the budget to be exhausted")
+	}
+	h.deposit()
+	if h.withdraw() {
+		t.Errorf("expect the budget to be exhausted")
+	}
+	h.deposit()
+	if !h.withdraw() {
+		t.Errorf("expect a hedge after 2 calls")
+	}
+}
@@ -0,0 +1,55 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	icontext "github.com/go-kratos/kratos/v2/internal/context"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+// WithMirror with client traffic mirroring, a fraction of the requests is teed to a
+// shadow node selected by the config, e.g. a new version for dark launch. The shadow
+// requests are sent in the background with the timeout of the client, their responses
+// are discarded and they are excluded from the node statistics. Only the requests with
+// a replayable body are mirrored. It applies to the discovery endpoints with the
+// selectors built by selector.DefaultBuilder, e.g. the builtin ones.
+func WithMirror(c *selector.MirrorConfig) ClientOption {
+	return func(o *clientOptions) {
+		o.mirror = c
+	}
+}
+
+// mirror sends a copy of the request to the shadow node in the background.
+func (client *Client) mirror(req *http.Request, addr string) {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return
+	}
+	// 影子请求不随原请求取消，使用client的超时
+	ctx := icontext.WithoutCancel(req.Context())
+	cancel := context.CancelFunc(func() {})
+	if client.cc.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, client.cc.Timeout)
+	}
+	r := req.Clone(ctx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return
+		}
+		r.Body = body
+	}
+	r.URL.Host = addr
+	r.Host = addr
+	go func() {
+		defer cancel()
+		resp, err := client.cc.Do(r)
+		if err != nil {
+			return
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+}
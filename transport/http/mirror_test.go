@@ -0,0 +1,56 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+func TestMirror(t *testing.T) {
+	var hits, bodies int64
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if string(b) == `{"name":"kratos"}` {
+			atomic.AddInt64(&bodies, 1)
+		}
+		atomic.AddInt64(&hits, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	})
+	srv1 := httptest.NewServer(handler)
+	defer srv1.Close()
+	srv2 := httptest.NewServer(handler)
+	defer srv2.Close()
+	client, err := NewClient(context.Background(),
+		WithEndpoint("discovery:///kratos"),
+		WithDiscovery(newStaticDiscovery(srv1.URL, srv2.URL)),
+		WithBlock(),
+		WithMirror(&selector.MirrorConfig{Percent: 100}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	for i := 0; i < 3; i++ {
+		var reply map[string]string
+		if err = client.Invoke(context.Background(), http.MethodPost, "/", map[string]string{"name": "kratos"}, &reply); err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt64(&hits) < 6 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 10)
+	}
+	if n := atomic.LoadInt64(&hits); n != 6 {
+		t.Errorf("expect every request to be mirrored, got %d requests", n)
+	}
+	if n := atomic.LoadInt64(&bodies); n != 6 {
+		t.Errorf("expect the body to be replayed to the shadow node, got %d bodies", n)
+	}
+}
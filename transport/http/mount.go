@@ -0,0 +1,82 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+// Mount mounts the handler under the path prefix, e.g. an existing router or the
+// swagger UI, the prefix is stripped from the request path before it is served:
+//
+//	srv.Mount("/swagger", swaggerUI) // /swagger/index.html -> /index.html
+//
+// The handler runs inside the filters of the server and the filters given, and the
+// service middleware matching the prefix, e.g. '/swagger/*', are applied around it,
+// the errors of them are encoded by the error encoder. It serves all the methods.
+func (s *Server) Mount(prefix string, h http.Handler, filters ...FilterFunc) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	op := prefix + "/*"
+	next := s.mountMiddleware(op, stripPrefix(prefix, h))
+	next = FilterChain(filters...)(next)
+	template := prefix
+	if template == "" {
+		template = "/"
+	}
+	route := s.router.PathPrefix(template).MatcherFunc(func(req *http.Request, _ *mux.RouteMatch) bool {
+		// /swagger matches /swagger/index.html, but not /swaggerui
+		return prefix == "" || len(req.URL.Path) == len(prefix) || req.URL.Path[len(prefix)] == '/'
+	}).Handler(next)
+	meta := &routeMeta{filters: filterNames(filters), mount: true}
+	meta.operation.Store(op)
+	s.routes.Store(route, meta)
+}
+
+// mountMiddleware sets the operation and applies the service middleware matching it around h.
+func (s *Server) mountMiddleware(op string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// 挂载的handler以前缀模式作为operation，例如 /swagger/*
+		SetOperation(req.Context(), op)
+		ms := s.middleware.Match(op)
+		if len(ms) == 0 {
+			h.ServeHTTP(w, req)
+			return
+		}
+		next := func(ctx context.Context, _ interface{}) (interface{}, error) {
+			h.ServeHTTP(w, req.WithContext(ctx))
+			return nil, nil
+		}
+		if _, err := middleware.Chain(ms...)(next)(req.Context(), req); err != nil {
+			s.ene(w, req, err)
+		}
+	})
+}
+
+// stripPrefix is http.StripPrefix, but the path of the prefix itself becomes '/'.
+func stripPrefix(prefix string, h http.Handler) http.Handler {
+	if prefix == "" {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r2 := new(http.Request)
+		*r2 = *req
+		r2.URL = new(url.URL)
+		*r2.URL = *req.URL
+		r2.URL.Path = strings.TrimPrefix(req.URL.Path, prefix)
+		if r2.URL.Path == "" {
+			r2.URL.Path = "/"
+		}
+		if req.URL.RawPath != "" {
+			r2.URL.RawPath = strings.TrimPrefix(req.URL.RawPath, prefix)
+			if r2.URL.RawPath == "" {
+				r2.URL.RawPath = "/"
+			}
+		}
+		h.ServeHTTP(w, r2)
+	})
+}
@@ -0,0 +1,75 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+func TestMount(t *testing.T) {
+	var (
+		operation string
+		filtered  bool
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		if tr, ok := transport.FromServerContext(req.Context()); ok {
+			operation = tr.Operation()
+		}
+		_, _ = w.Write([]byte(req.URL.Path))
+	})
+	srv := NewServer()
+	srv.Use("/admin/*", func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if r, ok := req.(*http.Request); ok && r.Header.Get("Authorization") == "" {
+				return nil, errors.Unauthorized("UNAUTHORIZED", "unauthorized")
+			}
+			return handler(ctx, req)
+		}
+	})
+	srv.Mount("/swagger/", mux, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			filtered = true
+			next.ServeHTTP(w, req)
+		})
+	})
+	srv.Mount("/admin", mux)
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/swagger/index.html", http.StatusOK, "/index.html"},
+		{"/swagger", http.StatusOK, "/"},
+		{"/swaggerui", http.StatusNotFound, ""},
+		{"/admin/index.html", http.StatusUnauthorized, ""},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, httptest.NewRequest(http.MethodPost, test.path, nil))
+		if w.Code != test.code {
+			t.Errorf("%s: expect %d, got %d", test.path, test.code, w.Code)
+		}
+		if test.body != "" && w.Body.String() != test.body {
+			t.Errorf("%s: expect %q, got %q", test.path, test.body, w.Body.String())
+		}
+	}
+	if !filtered || operation != "/swagger/*" {
+		t.Errorf("expect the filters and the operation to be applied, got %v %q", filtered, operation)
+	}
+
+	var infos []RouteInfo
+	_ = srv.WalkRoute(func(info RouteInfo) error {
+		infos = append(infos, info)
+		return nil
+	})
+	if len(infos) != 2 || infos[0].Method != "*" || infos[0].Path != "/swagger/*" || infos[0].Operation != "/swagger/*" || len(infos[0].Filters) != 1 {
+		t.Errorf("unexpected routes: %+v", infos)
+	}
+}
@@ -0,0 +1,147 @@
+package http
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func (c testCert) tls() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key, Leaf: c.cert}
+}
+
+func newTestCert(t *testing.T, tmpl *x509.Certificate, parent *testCert) testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl.SerialNumber = big.NewInt(time.Now().UnixNano())
+	tmpl.NotBefore = time.Now().Add(-time.Hour)
+	tmpl.NotAfter = time.Now().Add(time.Hour)
+	parentCert, parentKey := tmpl, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parentCert, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testCert{cert: cert, key: key}
+}
+
+func TestMTLS(t *testing.T) {
+	ca := newTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "ca"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+	serverCert := newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "server"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, &ca)
+	spiffeID, _ := url.Parse("spiffe://example.org/ns/default/sa/api")
+	clientCert := newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "client"},
+		URIs:        []*url.URL{spiffeID},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, &ca)
+	untrusted := newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "untrusted"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, nil)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	var verified []string
+	srv := NewServer(
+		TLSConfig(&tls.Config{Certificates: []tls.Certificate{serverCert.tls()}}),
+		ClientCAs(pool),
+		VerifyClientCert(func(id transport.Identity) error {
+			verified = append(verified, id.CommonName)
+			if id.SPIFFEID != spiffeID.String() {
+				return errors.New("untrusted spiffe id")
+			}
+			return nil
+		}),
+	)
+	srv.Route("/").GET("/whoami", func(ctx Context) error {
+		id, ok := transport.FromIdentityContext(ctx)
+		if !ok {
+			return errors.New("no identity")
+		}
+		return ctx.String(http.StatusOK, id.SPIFFEID)
+	})
+	ts := httptest.NewUnstartedServer(srv)
+	ts.TLS = srv.TLSConfig
+	ts.StartTLS()
+	defer ts.Close()
+
+	do := func(certs ...tls.Certificate) (string, error) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      pool,
+			Certificates: certs,
+		}}}
+		resp, err := client.Get(ts.URL + "/whoami")
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		return string(b), err
+	}
+	body, err := do(clientCert.tls())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != spiffeID.String() {
+		t.Errorf("expect %v, got %v", spiffeID.String(), body)
+	}
+	if _, err = do(); err == nil {
+		t.Errorf("expect error without client certificate")
+	}
+	if _, err = do(untrusted.tls()); err == nil {
+		t.Errorf("expect error with untrusted client certificate")
+	}
+	if len(verified) != 1 || verified[0] != "client" {
+		t.Errorf("expect only the trusted certificate to be verified, got %v", verified)
+	}
+}
+
+func TestMTLSConfig(t *testing.T) {
+	if c := NewServer(ClientCAs(x509.NewCertPool())).tlsConf; c != nil {
+		t.Errorf("expect nil without TLSConfig, got %v", c)
+	}
+	conf := &tls.Config{}
+	srv := NewServer(TLSConfig(conf), ClientAuth(tls.VerifyClientCertIfGiven))
+	if srv.tlsConf.ClientAuth != tls.VerifyClientCertIfGiven {
+		t.Errorf("expect %v, got %v", tls.VerifyClientCertIfGiven, srv.tlsConf.ClientAuth)
+	}
+	if conf.ClientAuth != tls.NoClientCert {
+		t.Errorf("expect the original config not to be modified")
+	}
+}
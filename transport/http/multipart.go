@@ -0,0 +1,46 @@
+package http
+
+import (
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport/http/binding"
+)
+
+// defaultMultipartMemory is the max memory of the multipart forms,
+// the files exceeding it are streamed to temporary files.
+const defaultMultipartMemory = 32 << 20
+
+func isMultipart(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "multipart/form-data"
+}
+
+// MultipartForm returns the parsed multipart form, the temporary files are
+// removed after the handler returns.
+func (c *wrapper) MultipartForm() (*multipart.Form, error) {
+	if c.req.MultipartForm == nil {
+		if !isMultipart(c.req) {
+			return nil, errors.BadRequest("CODEC", fmt.Sprintf("not a multipart request: %s", c.req.Header.Get("Content-Type")))
+		}
+		if err := binding.BindMultipartForm(c.req, c.router.srv.maxMemory, &struct{}{}); err != nil {
+			return nil, err
+		}
+	}
+	return c.req.MultipartForm, nil
+}
+
+// FormFile returns the first uploaded file of the form key.
+func (c *wrapper) FormFile(name string) (*multipart.FileHeader, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, err
+	}
+	if files := form.File[name]; len(files) > 0 {
+		return files[0], nil
+	}
+	return nil, errors.BadRequest(binding.Reason, fmt.Sprintf("missing file %q", name))
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newMultipartRequest(t *testing.T, fields map[string]string, files map[string]string) *http.Request {
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	for k, v := range fields {
+		if err := mw.WriteField(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for k, v := range files {
+		fw, err := mw.CreateFormFile(k, k+".txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = fw.Write([]byte(v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/upload", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestMultipart(t *testing.T) {
+	srv := NewServer(MultipartMemory(1))
+	srv.Route("/").POST("/upload", func(ctx Context) error {
+		var in struct {
+			Name string `json:"name"`
+			Age  int    `json:"age"`
+		}
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		fh, err := ctx.FormFile("file")
+		if err != nil {
+			return err
+		}
+		f, err := fh.Open()
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		return ctx.String(http.StatusOK, in.Name+":"+string(data))
+	})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, newMultipartRequest(t, map[string]string{"name": "kratos", "age": "3"}, map[string]string{"file": "hello"}))
+	if rec.Code != http.StatusOK || rec.Body.String() != "kratos:hello" {
+		t.Errorf("expected %v got %v %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, newMultipartRequest(t, map[string]string{"name": "kratos"}, nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected %v got %v", http.StatusBadRequest, rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, newMultipartRequest(t, map[string]string{"age": "x"}, map[string]string{"file": "hello"}))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected %v got %v", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestMultipartFormNotMultipart(t *testing.T) {
+	ctx := &wrapper{router: testRouter, req: httptest.NewRequest(http.MethodPost, "/upload", nil)}
+	if _, err := ctx.MultipartForm(); err == nil {
+		t.Errorf("expect an error")
+	}
+}
+
+func TestDefaultRequestDecoderMultipart(t *testing.T) {
+	var in struct {
+		Name string `json:"name"`
+	}
+	if err := DefaultRequestDecoder(newMultipartRequest(t, map[string]string{"name": "kratos"}, nil), &in); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if in.Name != "kratos" {
+		t.Errorf("expect kratos, got %v", in.Name)
+	}
+}
@@ -0,0 +1,156 @@
+package http
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+)
+
+// codecAliases maps the media subtypes to the names of the codecs.
+var codecAliases = map[string]string{
+	"x-protobuf": "proto",
+	"protobuf":   "proto",
+	"x-yaml":     "yaml",
+	"x-msgpack":  "msgpack",
+}
+
+type defaultCodecKey struct{}
+
+// DefaultCodec with the codec name of the responses when the Accept header is
+// missing or accepts any type, default is json.
+func DefaultCodec(name string) ServerOption {
+	return func(o *Server) {
+		o.codec = name
+	}
+}
+
+func withDefaultCodec(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, defaultCodecKey{}, name)
+}
+
+func defaultCodec(ctx context.Context) encoding.Codec {
+	if name, ok := ctx.Value(defaultCodecKey{}).(string); ok {
+		if codec := encoding.GetCodec(name); codec != nil {
+			return codec
+		}
+	}
+	return encoding.GetCodec("json")
+}
+
+type acceptRange struct {
+	typ     string
+	subtype string
+	q       float64
+}
+
+// parseAccept parses the Accept header into the media ranges, ordered by the
+// q-values, the ranges with q=0 are dropped.
+func parseAccept(values []string) []acceptRange {
+	var ranges []acceptRange
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+			q := 1.0
+			if v, ok := params["q"]; ok {
+				if q, err = strconv.ParseFloat(v, 64); err != nil {
+					continue
+				}
+			}
+			if q <= 0 {
+				continue
+			}
+			typ, subtype, _ := strings.Cut(mediaType, "/")
+			ranges = append(ranges, acceptRange{typ: typ, subtype: subtype, q: q})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+	return ranges
+}
+
+// codec returns the registered codec of the media range, the default codec for the
+// wildcards, nil if it is not registered.
+func (ar acceptRange) codec(def encoding.Codec) encoding.Codec {
+	if ar.subtype == "*" {
+		if ar.typ == "*" || ar.typ == "application" {
+			return def
+		}
+		return nil
+	}
+	name := ar.subtype
+	if alias, ok := codecAliases[name]; ok {
+		name = alias
+	} else if strings.HasSuffix(name, "+json") {
+		// 结构化后缀，例如application/problem+json
+		name = "json"
+	}
+	return encoding.GetCodec(name)
+}
+
+// NegotiateCodec returns the codec of the response by the Accept header of the request,
+// false if none of the accepted types is registered, and then the default codec is returned.
+// The default codec is preferred among the types of the same quality, e.g. for */*, and
+// for the headers of the browsers listing */* with text/html of the top quality,
+// e.g. "text/html, */*".
+func NegotiateCodec(r *http.Request) (encoding.Codec, bool) {
+	def := defaultCodec(r.Context())
+	values := r.Header.Values("Accept")
+	if len(values) == 0 {
+		return def, true
+	}
+	ranges := parseAccept(values)
+	if len(ranges) == 0 {
+		return def, true
+	}
+	if browserAccept(ranges) {
+		return def, true
+	}
+	for i := 0; i < len(ranges); {
+		j := i
+		var match encoding.Codec
+		for ; j < len(ranges) && ranges[j].q == ranges[i].q; j++ {
+			codec := ranges[j].codec(def)
+			if codec == nil {
+				continue
+			}
+			if codec.Name() == def.Name() {
+				return def, true
+			}
+			if match == nil {
+				match = codec
+			}
+		}
+		if match != nil {
+			return match, true
+		}
+		i = j
+	}
+	return def, false
+}
+
+// browserAccept reports whether */* is of the top quality with text/html, e.g. the
+// navigation of the browsers, which is not a preference of the response format.
+func browserAccept(ranges []acceptRange) bool {
+	var html, wildcard bool
+	for _, ar := range ranges {
+		if ar.q != ranges[0].q {
+			break
+		}
+		switch {
+		case ar.typ == "text" && ar.subtype == "html":
+			html = true
+		case ar.typ == "*" && ar.subtype == "*":
+			wildcard = true
+		}
+	}
+	return html && wildcard
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	_ "github.com/go-kratos/kratos/v2/encoding/proto"
+	_ "github.com/go-kratos/kratos/v2/encoding/xml"
+)
+
+func TestParseAccept(t *testing.T) {
+	ranges := parseAccept([]string{"text/html, application/xml;q=0.9, */*;q=0.8", "application/json;q=0, bad;;"})
+	want := []acceptRange{
+		{typ: "text", subtype: "html", q: 1},
+		{typ: "application", subtype: "xml", q: 0.9},
+		{typ: "*", subtype: "*", q: 0.8},
+	}
+	if !reflect.DeepEqual(want, ranges) {
+		t.Errorf("expect %v, got %v", want, ranges)
+	}
+}
+
+func TestNegotiateCodec(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+		ok     bool
+	}{
+		{"", "json", true},
+		{"*/*", "json", true},
+		{"application/xml", "xml", true},
+		{"text/html, application/xml;q=0.9, */*;q=0.8", "xml", true},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8", "xml", true},
+		{"text/html, application/xml, */*", "json", true},
+		{"application/xml, */*;q=0.1", "xml", true},
+		{"application/xml, */*", "json", true},
+		{"application/xml, application/json", "json", true},
+		{"application/xml, application/x-protobuf", "xml", true},
+		{"application/json;q=0.5, application/xml", "xml", true},
+		{"application/x-protobuf", "proto", true},
+		{"application/problem+json", "json", true},
+		{"application/*", "json", true},
+		{"text/html", "json", false},
+		{"text/*", "json", false},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if test.accept != "" {
+			r.Header.Set("Accept", test.accept)
+		}
+		codec, ok := NegotiateCodec(r)
+		if codec.Name() != test.want || ok != test.ok {
+			t.Errorf("%s: expect %v %v, got %v %v", test.accept, test.want, test.ok, codec.Name(), ok)
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(withDefaultCodec(context.Background(), "xml"))
+	if codec, _ := NegotiateCodec(r); codec.Name() != "xml" {
+		t.Errorf("expect xml, got %v", codec.Name())
+	}
+}
+
+type negotiateReply struct {
+	Name string `json:"name" xml:"name"`
+}
+
+func TestServerNegotiation(t *testing.T) {
+	handler := func(ctx Context) error {
+		return ctx.Result(http.StatusOK, &negotiateReply{Name: "kratos"})
+	}
+	xmlSrv := NewServer(DefaultCodec("xml"))
+	xmlSrv.Route("/").GET("/users", handler)
+	srv := NewServer()
+	srv.Route("/").GET("/users", handler)
+	tests := []struct {
+		srv         *Server
+		accept      string
+		code        int
+		contentType string
+	}{
+		{xmlSrv, "*/*", http.StatusOK, "application/xml"},
+		{xmlSrv, "application/json", http.StatusOK, "application/json"},
+		{srv, "*/*", http.StatusOK, "application/json"},
+		{srv, "text/html", http.StatusNotAcceptable, "application/json"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Set("Accept", test.accept)
+		rec := httptest.NewRecorder()
+		test.srv.ServeHTTP(rec, req)
+		if rec.Code != test.code || rec.Header().Get("Content-Type") != test.contentType {
+			t.Errorf("%s: expect %v %v, got %v %v", test.accept, test.code, test.contentType, rec.Code, rec.Header().Get("Content-Type"))
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+var (
+	// ErrNotFound is the error of the requests matching no route.
+	ErrNotFound = errors.New(http.StatusNotFound, "NOT_FOUND", "not found")
+	// ErrMethodNotAllowed is the error of the requests matching a route but not its methods.
+	ErrMethodNotAllowed = errors.New(http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+)
+
+// NotFoundHandler with the handler of the requests matching no route, it runs inside
+// the filters of the server, and the error returned is encoded by the error encoder, e.g.
+//
+//	NotFoundHandler(func(Context) error { return ErrNotFound })
+//
+// The operation of the requests is the URL path, so the service middleware can be
+// applied by Context.Middleware. Default is http.DefaultServeMux.
+func NotFoundHandler(h HandlerFunc) ServerOption {
+	return func(s *Server) {
+		s.notFound = h
+	}
+}
+
+// MethodNotAllowedHandler with the handler of the requests matching a route but not
+// its methods, it runs like the NotFoundHandler. Default is http.DefaultServeMux.
+func MethodNotAllowedHandler(h HandlerFunc) ServerOption {
+	return func(s *Server) {
+		s.notAllowed = h
+	}
+}
+
+// fallback returns the handler serving the unmatched requests by h inside the filters
+// of the server, or http.DefaultServeMux if h is nil.
+func (s *Server) fallback(h HandlerFunc) http.Handler {
+	if h == nil {
+		return http.DefaultServeMux
+	}
+	r := newRouter("", s)
+	return s.filter()(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		r.serve(res, req, h)
+	}))
+}
+
+// serve serves the request by h with a context of the router, the error returned
+// is encoded by the error encoder of the server.
+func (r *Router) serve(res http.ResponseWriter, req *http.Request, h HandlerFunc) {
+	ctx := r.pool.Get().(Context)
+	ctx.Reset(res, req)
+	if err := h(ctx); err != nil {
+		r.srv.ene(res, req, err)
+	}
+	ctx.Reset(nil, nil)
+	r.pool.Put(ctx)
+}
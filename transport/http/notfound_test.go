@@ -0,0 +1,55 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+func TestNotFoundHandler(t *testing.T) {
+	var operation string
+	srv := NewServer(
+		NotFoundHandler(func(ctx Context) error {
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				operation = tr.Operation()
+			}
+			return ErrNotFound
+		}),
+		MethodNotAllowedHandler(func(Context) error {
+			return ErrMethodNotAllowed
+		}),
+	)
+	srv.Route("/").GET("/index", func(ctx Context) error {
+		return ctx.String(http.StatusOK, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), `"reason":"NOT_FOUND"`) {
+		t.Errorf("unexpected reply: %d %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expect the reply to be encoded by the error encoder, got %q", ct)
+	}
+	if operation != "/missing" {
+		t.Errorf("expect the handler to run inside the filters, got operation %q", operation)
+	}
+
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/index", nil))
+	if w.Code != http.StatusMethodNotAllowed || !strings.Contains(w.Body.String(), `"reason":"METHOD_NOT_ALLOWED"`) {
+		t.Errorf("unexpected reply: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestDefaultNotFoundHandler(t *testing.T) {
+	srv := NewServer()
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expect 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport/http/binding"
+)
+
+// Scalar is the type of the typed parameters.
+type Scalar interface {
+	~string | ~bool |
+		~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// Param returns the typed path parameter, e.g. http.Param[int64](ctx, "id").
+// A missing or malformed parameter is returned as a 400 error.
+func Param[T Scalar](ctx Context, name string) (T, error) {
+	return parseParam[T]("path", name, ctx.Vars()[name])
+}
+
+// QueryParam returns the typed query parameter.
+// A missing or malformed parameter is returned as a 400 error.
+func QueryParam[T Scalar](ctx Context, name string) (T, error) {
+	return parseParam[T]("query", name, ctx.Query()[name])
+}
+
+// QueryParamOr returns the typed query parameter, or def if it is missing.
+func QueryParamOr[T Scalar](ctx Context, name string, def T) (T, error) {
+	if _, ok := ctx.Query()[name]; !ok {
+		return def, nil
+	}
+	return QueryParam[T](ctx, name)
+}
+
+// HeaderParam returns the typed header parameter.
+// A missing or malformed parameter is returned as a 400 error.
+func HeaderParam[T Scalar](ctx Context, name string) (T, error) {
+	return parseParam[T]("header", name, ctx.Header().Values(name))
+}
+
+// DurationParam returns the query parameter as a time.Duration, e.g. "1.5s".
+func DurationParam(ctx Context, name string) (time.Duration, error) {
+	return parseParam[time.Duration]("query", name, ctx.Query()[name])
+}
+
+func parseParam[T Scalar](source, name string, values []string) (v T, err error) {
+	if len(values) == 0 {
+		return v, errors.BadRequest(binding.Reason, fmt.Sprintf("missing %s parameter %q", source, name))
+	}
+	if err = binding.BindValue(values[0], &v); err != nil {
+		return v, errors.BadRequest(binding.Reason, fmt.Sprintf("invalid %s parameter %q: %v", source, name, err))
+	}
+	return v, nil
+}
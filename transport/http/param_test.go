@@ -0,0 +1,63 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestParam(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users/42?page=2&ratio=0.5&timeout=3s&bad=x", nil)
+	req.Header.Set("X-Enabled", "true")
+	req = mux.SetURLVars(req, map[string]string{"id": "42"})
+	ctx := &wrapper{router: testRouter, req: req}
+
+	if id, err := Param[int64](ctx, "id"); err != nil || id != 42 {
+		t.Errorf("expect 42, got %v %v", id, err)
+	}
+	if page, err := QueryParam[uint](ctx, "page"); err != nil || page != 2 {
+		t.Errorf("expect 2, got %v %v", page, err)
+	}
+	if ratio, err := QueryParam[float64](ctx, "ratio"); err != nil || ratio != 0.5 {
+		t.Errorf("expect 0.5, got %v %v", ratio, err)
+	}
+	if size, err := QueryParamOr(ctx, "size", 10); err != nil || size != 10 {
+		t.Errorf("expect 10, got %v %v", size, err)
+	}
+	if enabled, err := HeaderParam[bool](ctx, "X-Enabled"); err != nil || !enabled {
+		t.Errorf("expect true, got %v %v", enabled, err)
+	}
+	if d, err := DurationParam(ctx, "timeout"); err != nil || d != time.Second*3 {
+		t.Errorf("expect 3s, got %v %v", d, err)
+	}
+
+	for _, err := range []error{
+		func() error { _, err := Param[int](ctx, "name"); return err }(),
+		func() error { _, err := QueryParam[int](ctx, "bad"); return err }(),
+	} {
+		if e := errors.FromError(err); e.Code != http.StatusBadRequest {
+			t.Errorf("expect a 400 error, got %v", err)
+		}
+	}
+}
+
+func TestContextBindParams(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users/42?name=kratos", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "42"})
+	ctx := &wrapper{router: testRouter, req: req}
+	var v struct {
+		ID   int    `path:"id"`
+		Name string `query:"name"`
+	}
+	if err := ctx.BindParams(&v); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if v.ID != 42 || v.Name != "kratos" {
+		t.Errorf("expect 42 kratos, got %v %v", v.ID, v.Name)
+	}
+}
@@ -0,0 +1,230 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/metrics"
+)
+
+// PoolMetrics is the connection pool metrics of the client.
+type PoolMetrics struct {
+	// InUse is the gauge of the connections serving requests.
+	InUse metrics.Gauge
+	// Idle is the gauge of the idle connections.
+	Idle metrics.Gauge
+	// Wait is the observer of the seconds waiting for a connection.
+	Wait metrics.Observer
+}
+
+type poolOptions struct {
+	maxIdleConnsPerHost int
+	maxConnsPerHost     int
+	idleConnTimeout     time.Duration
+	tlsHandshakeTimeout time.Duration
+	metrics             *PoolMetrics
+}
+
+func (o poolOptions) empty() bool {
+	return o == poolOptions{}
+}
+
+// WithMaxIdleConnsPerHost with the max idle connections to keep per host.
+func WithMaxIdleConnsPerHost(n int) ClientOption {
+	return func(o *clientOptions) {
+		o.pool.maxIdleConnsPerHost = n
+	}
+}
+
+// WithMaxConnsPerHost with the max connections per host, the requests
+// wait for a connection when it is reached.
+func WithMaxConnsPerHost(n int) ClientOption {
+	return func(o *clientOptions) {
+		o.pool.maxConnsPerHost = n
+	}
+}
+
+// WithIdleConnTimeout with how long an idle connection is kept.
+func WithIdleConnTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.pool.idleConnTimeout = d
+	}
+}
+
+// WithTLSHandshakeTimeout with the timeout of the TLS handshakes.
+func WithTLSHandshakeTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.pool.tlsHandshakeTimeout = d
+	}
+}
+
+// WithPoolMetrics with the connection pool metrics.
+func WithPoolMetrics(m PoolMetrics) ClientOption {
+	return func(o *clientOptions) {
+		o.pool.metrics = &m
+	}
+}
+
+// tuneTransport applies the pool options to the transport cloned by newTransport, the
+// pool is returned when the metrics are enabled.
+func tuneTransport(tr *http.Transport, o poolOptions) *connPool {
+	if o.maxIdleConnsPerHost > 0 {
+		tr.MaxIdleConnsPerHost = o.maxIdleConnsPerHost
+	}
+	if o.maxConnsPerHost > 0 {
+		tr.MaxConnsPerHost = o.maxConnsPerHost
+	}
+	if o.idleConnTimeout > 0 {
+		tr.IdleConnTimeout = o.idleConnTimeout
+	}
+	if o.tlsHandshakeTimeout > 0 {
+		tr.TLSHandshakeTimeout = o.tlsHandshakeTimeout
+	}
+	if o.metrics == nil {
+		return nil
+	}
+	pool := &connPool{m: *o.metrics}
+	dial := tr.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	}
+	tr.DialContext = pool.dial(dial)
+	if tr.DialTLSContext != nil {
+		tr.DialTLSContext = pool.dial(tr.DialTLSContext)
+	}
+	return pool
+}
+
+// connPool counts the connections of the transport.
+type connPool struct {
+	m     PoolMetrics
+	mu    sync.Mutex
+	open  int
+	inUse int
+}
+
+// poolConn is a connection counted by the pool, the refs is the number of
+// the requests using it, e.g. the streams of HTTP/2.
+type poolConn struct {
+	net.Conn
+	pool   *connPool
+	refs   int
+	closed bool
+}
+
+func (c *poolConn) Close() error {
+	c.pool.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		c.pool.open--
+		if c.refs > 0 {
+			c.refs = 0
+			c.pool.inUse--
+		}
+		c.pool.report()
+	}
+	c.pool.mu.Unlock()
+	return c.Conn.Close()
+}
+
+func (p *connPool) dial(dial func(context.Context, string, string) (net.Conn, error)) func(context.Context, string, string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		p.mu.Lock()
+		p.open++
+		p.report()
+		p.mu.Unlock()
+		return &poolConn{Conn: conn, pool: p}, nil
+	}
+}
+
+func (p *connPool) acquire(c *poolConn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if c.closed {
+		return
+	}
+	if c.refs++; c.refs == 1 {
+		p.inUse++
+		p.report()
+	}
+}
+
+func (p *connPool) release(c *poolConn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if c.refs == 0 {
+		return
+	}
+	if c.refs--; c.refs == 0 {
+		p.inUse--
+		p.report()
+	}
+}
+
+// report must be called with mu held.
+func (p *connPool) report() {
+	if p.m.InUse != nil {
+		p.m.InUse.Set(float64(p.inUse))
+	}
+	if p.m.Idle != nil {
+		p.m.Idle.Set(float64(p.open - p.inUse))
+	}
+}
+
+// trace returns the context tracing the connection of a request, and the function
+// releasing the connection once the request is done.
+func (p *connPool) trace(ctx context.Context) (context.Context, func()) {
+	var (
+		start time.Time
+		conn  *poolConn
+		once  sync.Once
+	)
+	t := &httptrace.ClientTrace{
+		GetConn: func(string) {
+			start = time.Now()
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			if p.m.Wait != nil && !start.IsZero() {
+				p.m.Wait.Observe(time.Since(start).Seconds())
+			}
+			c := info.Conn
+			if tc, ok := c.(*tls.Conn); ok {
+				c = tc.NetConn()
+			}
+			if pc, ok := c.(*poolConn); ok {
+				conn = pc
+				p.acquire(pc)
+			}
+		},
+	}
+	release := func() {
+		once.Do(func() {
+			if conn != nil {
+				p.release(conn)
+			}
+		})
+	}
+	return httptrace.WithClientTrace(ctx, t), release
+}
+
+// releaseBody releases the connection of the response when it is closed.
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
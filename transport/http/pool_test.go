@@ -0,0 +1,110 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/metrics"
+)
+
+type mockGauge struct {
+	mu    sync.Mutex
+	value float64
+}
+
+func (g *mockGauge) With(...string) metrics.Gauge { return g }
+func (g *mockGauge) Set(value float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.value = value
+}
+func (g *mockGauge) Add(delta float64) { g.Set(g.get() + delta) }
+func (g *mockGauge) Sub(delta float64) { g.Set(g.get() - delta) }
+func (g *mockGauge) get() float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.value
+}
+
+type mockObserver struct {
+	mu     sync.Mutex
+	values []float64
+}
+
+func (o *mockObserver) With(...string) metrics.Observer { return o }
+func (o *mockObserver) Observe(v float64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.values = append(o.values, v)
+}
+
+func TestTuneTransport(t *testing.T) {
+	rt, pool := newTransport(&clientOptions{transport: http.DefaultTransport})
+	if rt != http.DefaultTransport || pool != nil {
+		t.Errorf("expect the transport not to be tuned")
+	}
+	o := &clientOptions{transport: http.DefaultTransport}
+	for _, opt := range []ClientOption{
+		WithMaxIdleConnsPerHost(10),
+		WithMaxConnsPerHost(20),
+		WithIdleConnTimeout(time.Minute),
+		WithTLSHandshakeTimeout(time.Second),
+	} {
+		opt(o)
+	}
+	rt, _ = newTransport(o)
+	tr := rt.(*http.Transport)
+	if tr == http.DefaultTransport {
+		t.Fatalf("expect http.DefaultTransport to be cloned")
+	}
+	if tr.MaxIdleConnsPerHost != 10 || tr.MaxConnsPerHost != 20 || tr.IdleConnTimeout != time.Minute || tr.TLSHandshakeTimeout != time.Second {
+		t.Errorf("expect the transport to be tuned, got %+v", tr)
+	}
+}
+
+func TestPoolMetrics(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+	inUse, idle, wait := &mockGauge{}, &mockGauge{}, &mockObserver{}
+	client, err := NewClient(context.Background(),
+		WithEndpoint(ts.URL),
+		WithTransport(&http.Transport{}),
+		WithPoolMetrics(PoolMetrics{InUse: inUse, Idle: idle, Wait: wait}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inUse.get() != 1 || idle.get() != 0 {
+		t.Errorf("expect 1 in use 0 idle, got %v %v", inUse.get(), idle.get())
+	}
+	// 未读完就关闭的连接会被transport异步关闭，读到EOF后连接才会同步放回空闲池
+	_, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if inUse.get() != 0 || idle.get() != 1 {
+		t.Errorf("expect 0 in use 1 idle, got %v %v", inUse.get(), idle.get())
+	}
+	var reply map[string]string
+	if err = client.Invoke(context.Background(), http.MethodGet, "/", nil, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if inUse.get() != 0 || idle.get() != 1 || len(wait.values) != 2 {
+		t.Errorf("expect the idle connection to be reused, got %v %v %v", inUse.get(), idle.get(), wait.values)
+	}
+	client.cc.CloseIdleConnections()
+	if idle.get() != 0 {
+		t.Errorf("expect 0 idle, got %v", idle.get())
+	}
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// ProblemOption is problem details error encoder option.
+type ProblemOption func(*problemOptions)
+
+type problemOptions struct {
+	typeFunc func(*errors.Error) string
+	instance func(*http.Request) string
+}
+
+// ProblemType with the function returning the type URI of the error,
+// e.g. https://example.com/errors/USER_NOT_FOUND, default is about:blank.
+func ProblemType(f func(*errors.Error) string) ProblemOption {
+	return func(o *problemOptions) {
+		o.typeFunc = f
+	}
+}
+
+// ProblemInstance with the function returning the instance URI of the request,
+// default is the request path.
+func ProblemInstance(f func(*http.Request) string) ProblemOption {
+	return func(o *problemOptions) {
+		o.instance = f
+	}
+}
+
+// ProblemDetails with the error encoder emitting application/problem+json (RFC 7807).
+func ProblemDetails(opts ...ProblemOption) ServerOption {
+	return func(s *Server) {
+		s.ene = NewProblemErrorEncoder(opts...)
+	}
+}
+
+// NewProblemErrorEncoder returns an error encoder emitting application/problem+json (RFC 7807),
+// the reason and the metadata of the error are the extension members:
+//
+//	{"type":"about:blank","title":"Not Found","status":404,"detail":"user not found",
+//	 "instance":"/users/1","reason":"USER_NOT_FOUND","id":"1"}
+func NewProblemErrorEncoder(opts ...ProblemOption) EncodeErrorFunc {
+	o := &problemOptions{
+		typeFunc: func(*errors.Error) string { return "about:blank" },
+		instance: func(r *http.Request) string { return r.URL.Path },
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		se := errors.FromError(err)
+		problem := make(map[string]interface{}, len(se.Metadata)+6)
+		// 扩展成员不能覆盖标准成员
+		for k, v := range se.Metadata {
+			problem[k] = v
+		}
+		if se.Reason != "" {
+			problem["reason"] = se.Reason
+		}
+		problem["type"] = o.typeFunc(se)
+		problem["title"] = http.StatusText(int(se.Code))
+		problem["status"] = se.Code
+		if se.Message != "" {
+			problem["detail"] = se.Message
+		}
+		if instance := o.instance(r); instance != "" {
+			problem["instance"] = instance
+		}
+		body, err := json.Marshal(problem)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(int(se.Code))
+		_, _ = w.Write(body)
+	}
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestProblemDetails(t *testing.T) {
+	srv := NewServer(ProblemDetails(ProblemType(func(se *errors.Error) string {
+		return "https://example.com/errors/" + se.Reason
+	})))
+	srv.Route("/").GET("/users/{id}", func(ctx Context) error {
+		return errors.NotFound("USER_NOT_FOUND", "user not found").WithMetadata(map[string]string{
+			"id":     "1",
+			"status": "ignored",
+		})
+	})
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expect %v, got %v", http.StatusNotFound, rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("expect %v, got %v", "application/problem+json", ct)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"type":     "https://example.com/errors/USER_NOT_FOUND",
+		"title":    "Not Found",
+		"status":   float64(404),
+		"detail":   "user not found",
+		"instance": "/users/1",
+		"reason":   "USER_NOT_FOUND",
+		"id":       "1",
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("expect %v, got %v", want, got)
+	}
+}
+
+func TestProblemErrorEncoder(t *testing.T) {
+	enc := NewProblemErrorEncoder(ProblemInstance(func(*http.Request) string { return "" }))
+	rec := httptest.NewRecorder()
+	enc(rec, httptest.NewRequest(http.MethodGet, "/", nil), errors.New(500, "", ""))
+	want := `{"status":500,"title":"Internal Server Error","type":"about:blank"}`
+	if rec.Body.String() != want {
+		t.Errorf("expect %v, got %v", want, rec.Body.String())
+	}
+}
@@ -0,0 +1,31 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// WithProxy with the proxy of the client, the schemes http, https and socks5 are
+// supported, e.g. socks5://127.0.0.1:1080. Only the connections are proxied, the
+// endpoints are still resolved by the discovery.
+func WithProxy(proxyURL *url.URL) ClientOption {
+	return func(o *clientOptions) {
+		o.proxy = http.ProxyURL(proxyURL)
+	}
+}
+
+// WithProxyFromEnvironment with the proxy of the environment variables
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY.
+func WithProxyFromEnvironment() ClientOption {
+	return func(o *clientOptions) {
+		o.proxy = http.ProxyFromEnvironment
+	}
+}
+
+// WithProxyFunc with the function returning the proxy of the requests,
+// the requests are not proxied if it returns a nil URL.
+func WithProxyFunc(f func(*http.Request) (*url.URL, error)) ClientOption {
+	return func(o *clientOptions) {
+		o.proxy = f
+	}
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestWithProxy(t *testing.T) {
+	var hosts []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 代理收到的是绝对URL
+		hosts = append(hosts, r.URL.Host)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"message":"proxied"}`))
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	client, err := NewClient(context.Background(), WithEndpoint("127.0.0.1:1"), WithProxy(proxyURL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reply struct {
+		Message string `json:"message"`
+	}
+	if err = client.Invoke(context.Background(), http.MethodGet, "/hello", nil, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Message != "proxied" {
+		t.Errorf("expect %v, got %v", "proxied", reply.Message)
+	}
+	if len(hosts) != 1 || hosts[0] != "127.0.0.1:1" {
+		t.Errorf("expect the target host to be proxied, got %v", hosts)
+	}
+	if client.opts.transport == http.DefaultTransport {
+		t.Errorf("expect http.DefaultTransport not to be modified")
+	}
+}
+
+func TestProxyTransport(t *testing.T) {
+	rt, _ := newTransport(&clientOptions{transport: http.DefaultTransport})
+	if rt != http.DefaultTransport {
+		t.Errorf("expect the transport to be kept without proxy")
+	}
+	orig := &http.Transport{}
+	o := &clientOptions{transport: orig}
+	WithProxyFromEnvironment()(o)
+	WithDialer(&net.Dialer{})(o)
+	WithMaxIdleConnsPerHost(10)(o)
+	rt, _ = newTransport(o)
+	tr := rt.(*http.Transport)
+	if tr == orig || tr.Proxy == nil || tr.DialContext == nil || tr.MaxIdleConnsPerHost != 10 {
+		t.Errorf("expect the options to be applied to one clone, got %+v", tr)
+	}
+	if orig.Proxy != nil || orig.DialContext != nil || orig.MaxIdleConnsPerHost != 0 {
+		t.Errorf("expect the transport of the caller not to be modified")
+	}
+	WithProxyFunc(func(*http.Request) (*url.URL, error) { return nil, nil })(o)
+	if u, err := o.proxy(nil); u != nil || err != nil {
+		t.Errorf("expect the proxy func to be set, got %v %v", u, err)
+	}
+}
@@ -0,0 +1,206 @@
+package http
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// ErrRateLimited is returned if the client exceeds the rate limit of the route.
+var ErrRateLimited = errors.New(http.StatusTooManyRequests, "RATELIMIT", "too many requests")
+
+// Rate is the rate of the requests, Limit requests per Period with the Burst.
+type Rate struct {
+	Limit  int
+	Period time.Duration
+	// Burst is the max requests at once, default is Limit.
+	Burst int
+}
+
+func (r Rate) burst() int {
+	if r.Burst > 0 {
+		return r.Burst
+	}
+	return r.Limit
+}
+
+type routeRate struct {
+	pattern string
+	rate    Rate
+}
+
+// RateStore is the storage of the rate limits, e.g. the in-memory token buckets,
+// or a redis store for the limits shared by the instances.
+type RateStore interface {
+	// Take takes a token of the key, it returns false and how long to wait for
+	// the next token if the rate is exceeded.
+	Take(ctx context.Context, key string, rate Rate) (ok bool, retryAfter time.Duration, err error)
+}
+
+// RateLimitOption is rate limit filter option.
+type RateLimitOption func(*rateLimitOptions)
+
+type rateLimitOptions struct {
+	rate   Rate
+	routes routeTable[routeRate]
+	key    func(*http.Request) string
+	store  RateStore
+}
+
+// RateLimitDefault with the rate of the paths not matching any route, default is unlimited.
+func RateLimitDefault(r Rate) RateLimitOption {
+	return func(o *rateLimitOptions) {
+		o.rate = r
+	}
+}
+
+// RateLimitRoute with the rate of the paths matching the pattern, each client has its own rate:
+//   - '/login'
+//   - '/api/*'
+func RateLimitRoute(pattern string, r Rate) RateLimitOption {
+	return func(o *rateLimitOptions) {
+		o.routes.add(pattern, routeRate{pattern: pattern, rate: r})
+	}
+}
+
+// RateLimitKey with the function returning the identity of the client, e.g. the API key
+// or the user ID, the remote IP is used if it returns empty. Default is RemoteIPKey.
+func RateLimitKey(f func(*http.Request) string) RateLimitOption {
+	return func(o *rateLimitOptions) {
+		o.key = f
+	}
+}
+
+// RateLimitStore with the rate store, default is an in-memory store.
+func RateLimitStore(s RateStore) RateLimitOption {
+	return func(o *rateLimitOptions) {
+		o.store = s
+	}
+}
+
+// RemoteIPKey returns the remote IP of the request.
+func RemoteIPKey(req *http.Request) string {
+	ip, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return ip
+}
+
+// HeaderKey returns the function returning the header of the request, e.g. X-API-Key.
+func HeaderKey(name string) func(*http.Request) string {
+	return func(req *http.Request) string {
+		return req.Header.Get(name)
+	}
+}
+
+// RateLimit returns a filter limiting the rate of the requests per route and per client,
+// the requests exceeding the rate are rejected with 429 and the Retry-After header.
+// Unlike the ratelimit middleware protecting the whole server, it keeps the clients fair.
+func RateLimit(opts ...RateLimitOption) FilterFunc {
+	o := &rateLimitOptions{key: RemoteIPKey}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.store == nil {
+		o.store = NewMemoryRateStore()
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			// 每个路由的配额是独立的
+			pattern, rate := "", o.rate
+			if r, ok := o.routes.match(req.URL.Path); ok {
+				pattern, rate = r.pattern, r.rate
+			}
+			if rate.Limit <= 0 || rate.Period <= 0 {
+				next.ServeHTTP(w, req)
+				return
+			}
+			key := o.key(req)
+			if key == "" {
+				key = RemoteIPKey(req)
+			}
+			ok, retryAfter, err := o.store.Take(req.Context(), pattern+"|"+key, rate)
+			if err != nil {
+				// 存储不可用时放行
+				log.Errorf("[HTTP] rate limit store failed: %v", err)
+				next.ServeHTTP(w, req)
+				return
+			}
+			if !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				errorEncoder(req)(w, req, ErrRateLimited)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+var _ RateStore = (*MemoryRateStore)(nil)
+
+// MemoryRateStore is an in-memory RateStore of the token buckets,
+// the limits are per instance.
+type MemoryRateStore struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	swept   time.Time
+	now     func() time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time
+}
+
+// NewMemoryRateStore new an in-memory rate store.
+func NewMemoryRateStore() *MemoryRateStore {
+	return &MemoryRateStore{
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// Take takes a token of the key.
+func (s *MemoryRateStore) Take(_ context.Context, key string, rate Rate) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.sweep(now)
+	burst := float64(rate.burst())
+	perToken := rate.Period / time.Duration(rate.Limit)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: burst, last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+float64(now.Sub(b.last))/float64(perToken))
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) * float64(perToken)), nil
+	}
+	b.tokens--
+	b.full = now.Add(time.Duration((burst - b.tokens) * float64(perToken)))
+	return true, 0, nil
+}
+
+// sweep removes the buckets which are full again, they are the same as the new ones.
+func (s *MemoryRateStore) sweep(now time.Time) {
+	if now.Sub(s.swept) < time.Minute {
+		return
+	}
+	s.swept = now
+	for k, b := range s.buckets {
+		if now.After(b.full) {
+			delete(s.buckets, k)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type failingRateStore struct{}
+
+func (failingRateStore) Take(context.Context, string, Rate) (bool, time.Duration, error) {
+	return false, 0, errors.New("unavailable")
+}
+
+func TestRateLimit(t *testing.T) {
+	srv := NewServer(Filter(RateLimit(
+		RateLimitRoute("/login", Rate{Limit: 1, Period: time.Minute}),
+		RateLimitRoute("/api/*", Rate{Limit: 2, Period: time.Minute}),
+		RateLimitKey(HeaderKey("X-API-Key")),
+	)))
+	for _, path := range []string{"/login", "/api/users", "/api/orders", "/health"} {
+		srv.Route("/").GET(path, func(ctx Context) error {
+			return ctx.String(http.StatusOK, "ok")
+		})
+	}
+	do := func(path, key, remote string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remote
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+	tests := []struct {
+		path   string
+		key    string
+		remote string
+		code   int
+	}{
+		{"/login", "a", "1.1.1.1:1", http.StatusOK},
+		{"/login", "a", "1.1.1.1:1", http.StatusTooManyRequests},
+		// 每个客户端的配额是独立的
+		{"/login", "b", "1.1.1.1:1", http.StatusOK},
+		// 没有key时按IP限流
+		{"/login", "", "2.2.2.2:1", http.StatusOK},
+		{"/login", "", "2.2.2.2:1", http.StatusTooManyRequests},
+		// 前缀路由共享配额
+		{"/api/users", "a", "1.1.1.1:1", http.StatusOK},
+		{"/api/orders", "a", "1.1.1.1:1", http.StatusOK},
+		{"/api/users", "a", "1.1.1.1:1", http.StatusTooManyRequests},
+		// 未匹配的路由不限流
+		{"/health", "a", "1.1.1.1:1", http.StatusOK},
+		{"/health", "a", "1.1.1.1:1", http.StatusOK},
+	}
+	for i, test := range tests {
+		rec := do(test.path, test.key, test.remote)
+		if rec.Code != test.code {
+			t.Errorf("#%d %s: expect %v, got %v", i, test.path, test.code, rec.Code)
+		}
+		if rec.Code == http.StatusTooManyRequests && rec.Header().Get("Retry-After") == "" {
+			t.Errorf("#%d %s: expect the Retry-After header", i, test.path)
+		}
+	}
+}
+
+func TestRateLimitErrorEncoder(t *testing.T) {
+	srv := NewServer(
+		Filter(RateLimit(RateLimitDefault(Rate{Limit: 1, Period: time.Minute}))),
+		ErrorEncoder(func(w http.ResponseWriter, r *http.Request, err error) {
+			w.WriteHeader(http.StatusTeapot)
+		}),
+	)
+	srv.Route("/").GET("/", func(ctx Context) error {
+		return ctx.String(http.StatusOK, "ok")
+	})
+	var codes []int
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		codes = append(codes, rec.Code)
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusTeapot {
+		t.Errorf("expect the error encoder of the server, got %v", codes)
+	}
+}
+
+func TestRateLimitStoreFailure(t *testing.T) {
+	h := RateLimit(RateLimitDefault(Rate{Limit: 1, Period: time.Second}), RateLimitStore(failingRateStore{}))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expect the requests to be allowed, got %v", rec.Code)
+	}
+}
+
+func TestMemoryRateStore(t *testing.T) {
+	now := time.Now()
+	s := NewMemoryRateStore()
+	s.now = func() time.Time { return now }
+	rate := Rate{Limit: 10, Period: time.Second, Burst: 2}
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if ok, _, _ := s.Take(ctx, "k", rate); !ok {
+			t.Fatalf("expect the burst to be allowed")
+		}
+	}
+	ok, retryAfter, _ := s.Take(ctx, "k", rate)
+	if ok || retryAfter != 100*time.Millisecond {
+		t.Errorf("expect to wait %v, got %v %v", 100*time.Millisecond, ok, retryAfter)
+	}
+	now = now.Add(100 * time.Millisecond)
+	if ok, _, _ = s.Take(ctx, "k", rate); !ok {
+		t.Errorf("expect a token to be refilled")
+	}
+	now = now.Add(2 * time.Minute)
+	_, _, _ = s.Take(ctx, "other", rate)
+	if _, ok := s.buckets["k"]; ok {
+		t.Errorf("expect the idle bucket to be swept")
+	}
+}
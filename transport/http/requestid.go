@@ -0,0 +1,46 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// RequestIDOption is RequestID option.
+type RequestIDOption func(*requestIDOptions)
+
+type requestIDOptions struct {
+	generate func() string
+}
+
+// RequestIDGenerator with the generator of the request ids, default is UUID v4.
+func RequestIDGenerator(f func() string) RequestIDOption {
+	return func(o *requestIDOptions) {
+		o.generate = f
+	}
+}
+
+// RequestID returns a filter reading the request id of the X-Request-ID header or
+// generating one, the id is stored in the request context and the server metadata,
+// and echoed in the response header. The HTTP and gRPC clients propagate it. The
+// invalid incoming ids are replaced by the generated ones, see transport.ValidRequestID.
+func RequestID(opts ...RequestIDOption) FilterFunc {
+	o := requestIDOptions{generate: uuid.NewString}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			id := req.Header.Get(transport.RequestIDHeader)
+			if !transport.ValidRequestID(id) {
+				// 不可信的id不回显，避免注入日志和响应头
+				id = o.generate()
+				req.Header.Set(transport.RequestIDHeader, id)
+			}
+			w.Header().Set(transport.RequestIDHeader, id)
+			next.ServeHTTP(w, req.WithContext(transport.NewRequestIDContext(req.Context(), id)))
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+func TestRequestID(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"` + r.Header.Get(transport.RequestIDHeader) + `"}`))
+	}))
+	defer upstream.Close()
+	client, err := NewClient(context.Background(), WithEndpoint(upstream.Listener.Addr().String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := NewServer(Filter(RequestID(RequestIDGenerator(func() string { return "generated" }))))
+	srv.Route("/").GET("/hop", func(ctx Context) error {
+		var reply struct {
+			ID string `json:"id"`
+		}
+		if err := client.Invoke(ctx.Request().Context(), http.MethodGet, "/", nil, &reply); err != nil {
+			return err
+		}
+		return ctx.String(http.StatusOK, reply.ID)
+	})
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"from-header", "from-header"},
+		{"", "generated"},
+		// 非法的id被替换
+		{"bad id\r\n", "generated"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/hop", nil)
+		if test.header != "" {
+			req.Header.Set(transport.RequestIDHeader, test.header)
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if got := rec.Body.String(); got != test.want {
+			t.Errorf("expect the id %v to be propagated, got %v", test.want, got)
+		}
+		if got := rec.Header().Get(transport.RequestIDHeader); got != test.want {
+			t.Errorf("expect the id %v to be echoed, got %v", test.want, got)
+		}
+	}
+}
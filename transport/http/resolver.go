@@ -3,6 +3,7 @@ package http
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/url"
 	"strings"
 	"time"
@@ -21,6 +22,9 @@ type Target struct {
 	Scheme    string
 	Authority string
 	Endpoint  string
+	// Query is the query of the target, e.g. discovery:///payments?subset=20,
+	// it overrides the options of the client, see NewClient.
+	Query url.Values
 }
 
 func parseTarget(endpoint string, insecure bool) (*Target, error) {
@@ -37,6 +41,9 @@ func parseTarget(endpoint string, insecure bool) (*Target, error) {
 		return nil, err
 	}
 	target := &Target{Scheme: u.Scheme, Authority: u.Host}
+	if u.RawQuery != "" {
+		target.Query = u.Query()
+	}
 	if len(u.Path) > 1 {
 		target.Endpoint = u.Path[1:]
 	}
@@ -167,6 +174,31 @@ func (r *resolver) update(services []*registry.ServiceInstance) bool {
 	return true
 }
 
+// newStaticResolver applies the addresses of the static target to the rebalancer, e.g.
+// static://10.0.0.1:9000,10.0.0.2:9000, they are balanced without the discovery.
+func newStaticResolver(target *Target, rebalancer selector.Rebalancer) (*resolver, error) {
+	list := target.Endpoint
+	if list == "" {
+		list = target.Authority
+	}
+	nodes := make([]selector.Node, 0)
+	for _, addr := range strings.Split(list, ",") {
+		if addr == "" {
+			continue
+		}
+		nodes = append(nodes, selector.NewNode("http", addr, nil))
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("[http client] no address in the static target: %v", list)
+	}
+	rebalancer.Apply(nodes)
+	return &resolver{target: target, rebalancer: rebalancer}, nil
+}
+
 func (r *resolver) Close() error {
+	// 静态地址没有watcher
+	if r.watcher == nil {
+		return nil
+	}
 	return r.watcher.Stop()
 }
@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strconv"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -173,3 +176,35 @@ func TestResolver(t *testing.T) {
 		t.Errorf("expect ctx cancel err, got nil")
 	}
 }
+
+func TestStaticResolver(t *testing.T) {
+	var servers []*httptest.Server
+	hits := make([]int32, 2)
+	for i := range hits {
+		i := i
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&hits[i], 1)
+			_, _ = w.Write([]byte("{}"))
+		}))
+		defer ts.Close()
+		servers = append(servers, ts)
+	}
+	endpoint := "static://" + servers[0].Listener.Addr().String() + "," + servers[1].Listener.Addr().String()
+	client, err := NewClient(context.Background(), WithEndpoint(endpoint))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	for i := 0; i < 20; i++ {
+		if err = client.Invoke(context.Background(), http.MethodGet, "/", nil, &struct{}{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if atomic.LoadInt32(&hits[0]) == 0 || atomic.LoadInt32(&hits[1]) == 0 {
+		t.Errorf("expect both the nodes called, got %v", hits)
+	}
+
+	if _, err = NewClient(context.Background(), WithEndpoint("static://")); err == nil {
+		t.Errorf("expect error of the empty static target")
+	}
+}
@@ -0,0 +1,198 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+const (
+	defaultRetryAttempts   = 3
+	defaultRetryBackoff    = 50 * time.Millisecond
+	defaultRetryMaxBackoff = time.Second
+)
+
+var defaultRetryStatuses = []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+// RetryPolicy is the retry policy of the client calls. Only the idempotent methods,
+// the calls with the Idempotent call option or the Idempotency-Key header are retried,
+// and the retries avoid the nodes already tried.
+type RetryPolicy struct {
+	// MaxAttempts is the max number of attempts including the first one, default is 3.
+	MaxAttempts int
+	// Statuses is the retryable status codes, default is 502, 503 and 504.
+	Statuses []int
+	// Retryable decides whether the error of an attempt is retryable, it overrides
+	// Statuses, the network errors and the Statuses are retryable if it is nil.
+	Retryable func(err error) bool
+	// Backoff is the delay before the first retry, it is doubled per retry
+	// with jitter, default is 50ms.
+	Backoff time.Duration
+	// MaxBackoff caps the delay of the backoff, default is 1s. The Retry-After header
+	// of the response is respected if it is present, capped at MaxBackoff too.
+	MaxBackoff time.Duration
+	// Rand is the source of the jitter.
+	Rand selector.Rand
+}
+
+// WithRetry with client retry policy.
+func WithRetry(policy RetryPolicy) ClientOption {
+	return func(o *clientOptions) {
+		if policy.MaxAttempts <= 0 {
+			policy.MaxAttempts = defaultRetryAttempts
+		}
+		if policy.Statuses == nil {
+			policy.Statuses = defaultRetryStatuses
+		}
+		if policy.Backoff <= 0 {
+			policy.Backoff = defaultRetryBackoff
+		}
+		if policy.MaxBackoff <= 0 {
+			policy.MaxBackoff = defaultRetryMaxBackoff
+		}
+		if policy.Rand == nil {
+			policy.Rand = selector.NewRand(time.Now().UnixNano())
+		}
+		o.retry = &policy
+	}
+}
+
+// allow reports whether the request can be retried.
+func (p *RetryPolicy) allow(req *http.Request, c callInfo) bool {
+	return p.MaxAttempts > 1 && replayable(req, c)
+}
+
+// replayable reports whether the request can be sent more than once,
+// i.e. it is idempotent and its body can be replayed.
+func replayable(req *http.Request, c callInfo) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		// 请求体无法重放
+		return false
+	}
+	if c.idempotent || req.Header.Get("Idempotency-Key") != "" {
+		return true
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func (p *RetryPolicy) retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	se := new(errors.Error)
+	if !errors.As(err, &se) {
+		// 网络错误
+		return true
+	}
+	if se.Reason == "NODE_NOT_FOUND" {
+		return false
+	}
+	for _, status := range p.Statuses {
+		if int(se.Code) == status {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the delay before the retry, the retry starts from 1. It is capped
+// at MaxBackoff and at the remaining deadline of ctx.
+func (p *RetryPolicy) backoff(ctx context.Context, retry int, resp *http.Response) time.Duration {
+	d := p.delay(retry, resp)
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); remaining < d {
+			d = remaining
+		}
+	}
+	return d
+}
+
+func (p *RetryPolicy) delay(retry int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			// 服务端可能返回很长的Retry-After，不能无限等待
+			if d > p.MaxBackoff {
+				d = p.MaxBackoff
+			}
+			return d
+		}
+	}
+	d := p.Backoff
+	for i := 1; i < retry && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	// equal jitter: [d/2, d)
+	return d/2 + time.Duration(p.Rand.Float64()*float64(d/2))
+}
+
+// retryAfter parses the Retry-After header, in seconds or an HTTP date.
+func retryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// doRetry sends the request with the retry policy.
+func (client *Client) doRetry(req *http.Request, c callInfo, p *RetryPolicy) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		r := req.Clone(selector.NewAttemptContext(ctx, attempt))
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+		resp, err := client.roundTrip(r, c)
+		if err == nil {
+			return resp, nil
+		}
+		if attempt+1 >= p.MaxAttempts || !p.retryable(ctx, err) {
+			return nil, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		delay := p.backoff(ctx, attempt+1, resp)
+		if deadline, ok := ctx.Deadline(); ok && !time.Now().Add(delay).Before(deadline) {
+			// 等待到截止时间也来不及重试
+			return nil, err
+		}
+		if client.r != nil {
+			// 重试时避开已经失败的节点
+			ctx = selector.WithExcludedNodes(ctx, r.URL.Host)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
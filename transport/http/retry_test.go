@@ -0,0 +1,203 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+// staticDiscovery returns the fixed instances once, and then blocks until the watch is stopped.
+type staticDiscovery struct {
+	instances []*registry.ServiceInstance
+}
+
+func newStaticDiscovery(endpoints ...string) *staticDiscovery {
+	d := &staticDiscovery{}
+	for _, e := range endpoints {
+		d.instances = append(d.instances, &registry.ServiceInstance{ID: e, Name: "kratos", Endpoints: []string{e}})
+	}
+	return d
+}
+
+func (d *staticDiscovery) GetService(_ context.Context, _ string) ([]*registry.ServiceInstance, error) {
+	return d.instances, nil
+}
+
+func (d *staticDiscovery) Watch(ctx context.Context, _ string) (registry.Watcher, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &staticWatcher{ctx: ctx, cancel: cancel, instances: d.instances}, nil
+}
+
+type staticWatcher struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	instances []*registry.ServiceInstance
+	done      bool
+}
+
+func (w *staticWatcher) Next() ([]*registry.ServiceInstance, error) {
+	if !w.done {
+		w.done = true
+		return w.instances, nil
+	}
+	<-w.ctx.Done()
+	return nil, w.ctx.Err()
+}
+
+func (w *staticWatcher) Stop() error {
+	w.cancel()
+	return nil
+}
+
+func TestRetry(t *testing.T) {
+	var count int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&count, 1)%3 != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"kratos"}`))
+	}))
+	defer ts.Close()
+	client, err := NewClient(context.Background(), WithEndpoint(ts.URL), WithRetry(RetryPolicy{Backoff: time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reply map[string]string
+	if err = client.Invoke(context.Background(), http.MethodGet, "/", nil, &reply); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if count != 3 || reply["name"] != "kratos" {
+		t.Errorf("expect 3 attempts, got %v %v", count, reply)
+	}
+
+	// POST is not idempotent
+	atomic.StoreInt64(&count, 0)
+	err = client.Invoke(context.Background(), http.MethodPost, "/", map[string]string{"name": "kratos"}, &reply)
+	if !errors.IsServiceUnavailable(err) || count != 1 {
+		t.Errorf("expect 1 attempt, got %v %v", count, err)
+	}
+	atomic.StoreInt64(&count, 0)
+	err = client.Invoke(context.Background(), http.MethodPost, "/", map[string]string{"name": "kratos"}, &reply, Idempotent())
+	if err != nil || count != 3 {
+		t.Errorf("expect 3 attempts, got %v %v", count, err)
+	}
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	var count int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&count, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+	client, err := NewClient(context.Background(), WithEndpoint(ts.URL), WithRetry(RetryPolicy{Backoff: time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reply map[string]string
+	if err = client.Invoke(context.Background(), http.MethodGet, "/", nil, &reply); !errors.IsBadRequest(err) || count != 1 {
+		t.Errorf("expect 1 attempt, got %v %v", count, err)
+	}
+}
+
+func TestRetryExcludesNodes(t *testing.T) {
+	var bad, good int64
+	ts1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&bad, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts1.Close()
+	ts2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&good, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer ts2.Close()
+	client, err := NewClient(context.Background(),
+		WithEndpoint("discovery:///kratos"),
+		WithDiscovery(newStaticDiscovery(ts1.URL, ts2.URL)),
+		WithBlock(),
+		WithRetry(RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	for i := 0; i < 10; i++ {
+		var reply map[string]string
+		if err = client.Invoke(context.Background(), http.MethodGet, "/", nil, &reply); err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+	}
+	if good != 10 || bad == 0 {
+		t.Errorf("expect the retries to go to the good node, got %v %v", good, bad)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	o := &clientOptions{}
+	WithRetry(RetryPolicy{Backoff: time.Millisecond * 10, MaxBackoff: time.Millisecond * 30})(o)
+	for retry, max := range map[int]time.Duration{1: time.Millisecond * 10, 2: time.Millisecond * 20, 5: time.Millisecond * 30} {
+		if d := o.retry.backoff(context.Background(), retry, nil); d < max/2 || d >= max {
+			t.Errorf("retry %d: expect [%v, %v), got %v", retry, max/2, max, d)
+		}
+	}
+	resp := &http.Response{Header: http.Header{"Retry-After": {"2"}}}
+	if d := o.retry.backoff(context.Background(), 1, resp); d != time.Millisecond*30 {
+		t.Errorf("expect the Retry-After to be capped at %v, got %v", time.Millisecond*30, d)
+	}
+	WithRetry(RetryPolicy{MaxBackoff: time.Minute})(o)
+	if d := o.retry.backoff(context.Background(), 1, resp); d != time.Second*2 {
+		t.Errorf("expect %v, got %v", time.Second*2, d)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if d := o.retry.backoff(ctx, 1, resp); d > time.Second {
+		t.Errorf("expect the delay to be capped at the deadline, got %v", d)
+	}
+	if d, ok := retryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)); !ok || d <= time.Minute*59 {
+		t.Errorf("expect about an hour, got %v", d)
+	}
+	if _, ok := retryAfter("soon"); ok {
+		t.Errorf("expect an invalid Retry-After")
+	}
+}
+
+func TestRetryPolicyAllow(t *testing.T) {
+	p := &RetryPolicy{MaxAttempts: 2}
+	req, _ := http.NewRequest(http.MethodPost, "http://127.0.0.1", strings.NewReader("body"))
+	if p.allow(req, callInfo{}) {
+		t.Errorf("expect POST not to be retried")
+	}
+	if !p.allow(req, callInfo{idempotent: true}) {
+		t.Errorf("expect the idempotent call to be retried")
+	}
+	req.Header.Set("Idempotency-Key", "1")
+	if !p.allow(req, callInfo{}) {
+		t.Errorf("expect the request with Idempotency-Key to be retried")
+	}
+	req, _ = http.NewRequest(http.MethodPut, "http://127.0.0.1", nil)
+	req.Body = http.NoBody
+	if !p.allow(req, callInfo{}) {
+		t.Errorf("expect PUT to be retried")
+	}
+	req.Body = readCloser{strings.NewReader("body")}
+	if p.allow(req, callInfo{}) {
+		t.Errorf("expect the body not rewindable not to be retried")
+	}
+}
+
+type readCloser struct {
+	*strings.Reader
+}
+
+func (readCloser) Close() error { return nil }
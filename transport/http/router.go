@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"path"
 	"sync"
+
+	"github.com/gorilla/mux"
 )
 
 // WalkRouteFunc is the type of the function called for each route visited by Walk.
@@ -13,6 +15,14 @@ type WalkRouteFunc func(RouteInfo) error
 type RouteInfo struct {
 	Path   string
 	Method string
+	// Operation is the operation of the handler, e.g. /helloworld.v1.Greeter/SayHello,
+	// it is empty until the route is requested since it is set by the handler, unless it
+	// is declared by Route.WithOperation.
+	Operation string
+	// Filters is the names of the filters of the route, e.g. http.CORS.
+	Filters []string
+	// Middleware is the names of the service middleware matching the operation.
+	Middleware []string
 }
 
 // HandlerFunc defines a function to serve HTTP requests.
@@ -24,6 +34,8 @@ type Router struct {
 	pool    sync.Pool
 	srv     *Server
 	filters []FilterFunc
+	// 已注册预检路由的path
+	preflights map[string]*preflightRoute
 }
 
 func newRouter(prefix string, srv *Server, filters ...FilterFunc) *Router {
@@ -46,8 +58,24 @@ func (r *Router) Group(prefix string, filters ...FilterFunc) *Router {
 	return newRouter(path.Join(r.prefix, prefix), r.srv, newFilters...)
 }
 
+// Route is a route registered by Router.Handle.
+type Route struct {
+	meta *routeMeta
+}
+
+// WithOperation sets the operation of the hand-written route, e.g. /helloworld.v1.Greeter/SayHello,
+// it is set before the filters of the route, so that the middleware matchers, the tracing and
+// the metrics see a stable name instead of the path template. It should be called before the
+// server is started, the operation set by the handler, e.g. the generated one, still overrides it.
+func (rt *Route) WithOperation(op string) *Route {
+	rt.meta.declared = op
+	rt.meta.operation.Store(op)
+	return rt
+}
+
 // Handle registers a new route with a matcher for the URL path and method.
-func (r *Router) Handle(method, relativePath string, h HandlerFunc, filters ...FilterFunc) {
+func (r *Router) Handle(method, relativePath string, h HandlerFunc, filters ...FilterFunc) *Route {
+	meta := &routeMeta{filters: append(filterNames(r.filters), filterNames(filters)...)}
 	// 参数h是用户处理函数(实际上是业务中间件+处理逻辑)，即proto文件定义的接口的具体实现，再用业务层的中间件进行了一层层的包裹
 	// 由于上层传过来的是kratos的HandlerFunc类型，所以要转换成net.http.Hander类型。因为这个函数要注册到gorilla/mux里面，所以他要遵循规则（路由处理函数要实现net.http.Hander）
 	next := http.Handler(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
@@ -59,6 +87,7 @@ func (r *Router) Handle(method, relativePath string, h HandlerFunc, filters ...F
 			// 业务处理函数返回错误，使用server的error encoder
 			r.srv.ene(res, req, err)
 		}
+		meta.learn(req)
 		ctx.Reset(nil, nil) // 同理，用完之后，扔回pool之前，要reset为nil
 		r.pool.Put(ctx)
 	}))
@@ -68,51 +97,147 @@ func (r *Router) Handle(method, relativePath string, h HandlerFunc, filters ...F
 	next = FilterChain(filters...)(next)
 	// 这个filters，我也没找到哪里会注册。 估计也是用户自己实现http，然后在Group里面加
 	next = FilterChain(r.filters...)(next)
-	// 在mux上注册一个新的路由(因为kratos使用的是gorilla/mux，所以最终要是要注册到这上面的)
-	r.srv.router.Handle(path.Join(r.prefix, relativePath), next).Methods(method)
+	next = declareOperation(next, meta)
+	if r.srv.engine != nil {
+		fullPath := path.Join(r.prefix, relativePath)
+		r.srv.handleEngine(method, fullPath, next)
+		r.srv.routes.Store(engineRoute{method: method, path: fullPath}, meta)
+	} else {
+		// 在mux上注册一个新的路由(因为kratos使用的是gorilla/mux，所以最终要是要注册到这上面的)
+		route := r.srv.router.Handle(path.Join(r.prefix, relativePath), next).Methods(method)
+		r.srv.routes.Store(route, meta)
+	}
+	if method != http.MethodOptions && len(filters)+len(r.filters) > 0 {
+		r.handlePreflight(path.Join(r.prefix, relativePath), method, filters...)
+	}
+	return &Route{meta: meta}
+}
+
+// declareOperation sets the operation declared by Route.WithOperation before the filters.
+func declareOperation(next http.Handler, meta *routeMeta) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		// 默认的operation是server的filter中设置的path模板
+		if meta.declared != "" {
+			SetOperation(req.Context(), meta.declared)
+		}
+		next.ServeHTTP(res, req)
+	})
+}
+
+// preflightRoutePrefix is the name prefix of the preflight routes, they are not walked by WalkRoute.
+const preflightRoutePrefix = "preflight:"
+
+// preflightRoute replies to the CORS preflight requests of a path by the filters of
+// the route of the requested method, so the filters of the other methods, e.g. the auth
+// of GET, do not reject the preflight of POST.
+type preflightRoute struct {
+	mu      sync.RWMutex
+	methods map[string]http.Handler
+	// fallback 请求的方法没有路由时使用，只有router的filters
+	fallback http.Handler
+}
+
+func (p *preflightRoute) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	p.mu.RLock()
+	h, ok := p.methods[req.Header.Get("Access-Control-Request-Method")]
+	p.mu.RUnlock()
+	if !ok {
+		h = p.fallback
+	}
+	h.ServeHTTP(res, req)
+}
+
+// handlePreflight registers the route of the CORS preflight requests of the path,
+// so that the filters of the route of the method, e.g. CORS, can reply to them.
+func (r *Router) handlePreflight(fullPath, method string, filters ...FilterFunc) {
+	notAllowed := http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		// 没有filter处理预检请求时，保持原有的行为
+		if h := r.srv.notAllowed; h != nil {
+			// 已经在server的filter中，直接调用
+			r.serve(res, req, h)
+			return
+		}
+		if h := r.srv.router.MethodNotAllowedHandler; h != nil {
+			h.ServeHTTP(res, req)
+			return
+		}
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	})
+	next := FilterChain(filters...)(notAllowed)
+	next = FilterChain(r.filters...)(next)
+	if p, ok := r.preflights[fullPath]; ok {
+		p.mu.Lock()
+		if _, ok := p.methods[method]; !ok {
+			p.methods[method] = next
+		}
+		p.mu.Unlock()
+		return
+	}
+	if r.preflights == nil {
+		r.preflights = make(map[string]*preflightRoute)
+	}
+	p := &preflightRoute{
+		methods:  map[string]http.Handler{method: next},
+		fallback: FilterChain(r.filters...)(notAllowed),
+	}
+	r.preflights[fullPath] = p
+	if r.srv.engine != nil {
+		// engine没有匹配器，非预检的OPTIONS请求同样回复method not allowed
+		r.srv.handleEngine(http.MethodOptions, fullPath, http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			if !isPreflight(req) {
+				notAllowed(res, req)
+				return
+			}
+			p.ServeHTTP(res, req)
+		}))
+		return
+	}
+	r.srv.router.Handle(fullPath, p).Methods(http.MethodOptions).MatcherFunc(func(req *http.Request, _ *mux.RouteMatch) bool {
+		return isPreflight(req)
+	}).Name(preflightRoutePrefix + fullPath)
 }
 
 // GET registers a new GET route for a path with matching handler in the router.
-func (r *Router) GET(path string, h HandlerFunc, m ...FilterFunc) {
-	r.Handle(http.MethodGet, path, h, m...)
+func (r *Router) GET(path string, h HandlerFunc, m ...FilterFunc) *Route {
+	return r.Handle(http.MethodGet, path, h, m...)
 }
 
 // HEAD registers a new HEAD route for a path with matching handler in the router.
-func (r *Router) HEAD(path string, h HandlerFunc, m ...FilterFunc) {
-	r.Handle(http.MethodHead, path, h, m...)
+func (r *Router) HEAD(path string, h HandlerFunc, m ...FilterFunc) *Route {
+	return r.Handle(http.MethodHead, path, h, m...)
 }
 
 // POST registers a new POST route for a path with matching handler in the router.
-func (r *Router) POST(path string, h HandlerFunc, m ...FilterFunc) {
-	r.Handle(http.MethodPost, path, h, m...)
+func (r *Router) POST(path string, h HandlerFunc, m ...FilterFunc) *Route {
+	return r.Handle(http.MethodPost, path, h, m...)
 }
 
 // PUT registers a new PUT route for a path with matching handler in the router.
-func (r *Router) PUT(path string, h HandlerFunc, m ...FilterFunc) {
-	r.Handle(http.MethodPut, path, h, m...)
+func (r *Router) PUT(path string, h HandlerFunc, m ...FilterFunc) *Route {
+	return r.Handle(http.MethodPut, path, h, m...)
 }
 
 // PATCH registers a new PATCH route for a path with matching handler in the router.
-func (r *Router) PATCH(path string, h HandlerFunc, m ...FilterFunc) {
-	r.Handle(http.MethodPatch, path, h, m...)
+func (r *Router) PATCH(path string, h HandlerFunc, m ...FilterFunc) *Route {
+	return r.Handle(http.MethodPatch, path, h, m...)
 }
 
 // DELETE registers a new DELETE route for a path with matching handler in the router.
-func (r *Router) DELETE(path string, h HandlerFunc, m ...FilterFunc) {
-	r.Handle(http.MethodDelete, path, h, m...)
+func (r *Router) DELETE(path string, h HandlerFunc, m ...FilterFunc) *Route {
+	return r.Handle(http.MethodDelete, path, h, m...)
 }
 
 // CONNECT registers a new CONNECT route for a path with matching handler in the router.
-func (r *Router) CONNECT(path string, h HandlerFunc, m ...FilterFunc) {
-	r.Handle(http.MethodConnect, path, h, m...)
+func (r *Router) CONNECT(path string, h HandlerFunc, m ...FilterFunc) *Route {
+	return r.Handle(http.MethodConnect, path, h, m...)
 }
 
 // OPTIONS registers a new OPTIONS route for a path with matching handler in the router.
-func (r *Router) OPTIONS(path string, h HandlerFunc, m ...FilterFunc) {
-	r.Handle(http.MethodOptions, path, h, m...)
+func (r *Router) OPTIONS(path string, h HandlerFunc, m ...FilterFunc) *Route {
+	return r.Handle(http.MethodOptions, path, h, m...)
 }
 
 // TRACE registers a new TRACE route for a path with matching handler in the router.
-func (r *Router) TRACE(path string, h HandlerFunc, m ...FilterFunc) {
-	r.Handle(http.MethodTrace, path, h, m...)
+func (r *Router) TRACE(path string, h HandlerFunc, m ...FilterFunc) *Route {
+	return r.Handle(http.MethodTrace, path, h, m...)
 }
@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-kratos/kratos/v2/internal/host"
+	"github.com/go-kratos/kratos/v2/transport"
 )
 
 const appJSONStr = "application/json"
@@ -192,3 +194,44 @@ func TestHandle(_ *testing.T) {
 	r.OPTIONS("/options", h)
 	r.TRACE("/trace", h)
 }
+
+func TestRouteWithOperation(t *testing.T) {
+	srv := NewServer()
+	var filterOp, handlerOp string
+	filter := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if tr, ok := transport.FromServerContext(r.Context()); ok {
+				filterOp = tr.Operation()
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	srv.Route("/v1", filter).GET("/users/{id}", func(ctx Context) error {
+		if tr, ok := transport.FromServerContext(ctx); ok {
+			handlerOp = tr.Operation()
+		}
+		return ctx.String(http.StatusOK, "ok")
+	}).WithOperation("/user.v1.User/GetUser")
+
+	var walked string
+	_ = srv.WalkRoute(func(info RouteInfo) error {
+		if info.Path == "/v1/users/{id}" {
+			walked = info.Operation
+		}
+		return nil
+	})
+	if walked != "/user.v1.User/GetUser" {
+		t.Errorf("expect the operation before the route is requested, got %q", walked)
+	}
+
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	resp, err := http.Get(ts.URL + "/v1/users/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if filterOp != "/user.v1.User/GetUser" || handlerOp != "/user.v1.User/GetUser" {
+		t.Errorf("unexpected operation: %q %q", filterOp, handlerOp)
+	}
+}
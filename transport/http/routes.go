@@ -0,0 +1,132 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"text/tabwriter"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// routeMeta is the metadata of a route registered by Router.Handle.
+type routeMeta struct {
+	filters []string
+	// operation是由handler设置的，请求后才可知
+	operation atomic.Value
+	// mount 由Server.Mount注册，匹配路径前缀和所有的method
+	mount bool
+	// declared 由Route.WithOperation声明的operation
+	declared string
+}
+
+// learn records the operation set by the handler.
+func (m *routeMeta) learn(req *http.Request) {
+	tr, ok := transport.FromServerContext(req.Context())
+	if !ok {
+		return
+	}
+	if op, _ := m.operation.Load().(string); op != tr.Operation() {
+		m.operation.Store(tr.Operation())
+	}
+}
+
+var funcSuffix = regexp.MustCompile(`(\.func\d+)+$`)
+
+// funcName returns the short name of the function, e.g. http.CORS.
+func funcName(f interface{}) string {
+	v := reflect.ValueOf(f)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	fn := runtime.FuncForPC(v.Pointer())
+	if fn == nil {
+		return ""
+	}
+	name := funcSuffix.ReplaceAllString(fn.Name(), "")
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+func filterNames(filters []FilterFunc) []string {
+	names := make([]string, 0, len(filters))
+	for _, f := range filters {
+		names = append(names, funcName(f))
+	}
+	return names
+}
+
+func middlewareNames(ms []middleware.Middleware) []string {
+	names := make([]string, 0, len(ms))
+	for _, m := range ms {
+		names = append(names, funcName(m))
+	}
+	return names
+}
+
+// routeDump is a route of the route table.
+type routeDump struct {
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	Operation  string   `json:"operation,omitempty"`
+	Filters    []string `json:"filters,omitempty"`
+	Middleware []string `json:"middleware,omitempty"`
+	// Shadowed 同样的method和path已被更早注册的路由匹配，不会被请求到
+	Shadowed bool `json:"shadowed,omitempty"`
+}
+
+// RoutesHandler returns a handler rendering the route table for debugging, the
+// operations are shown after the routes are requested since they are set by the
+// handlers. It renders JSON with the query ?format=json, and a text table otherwise.
+// It is opt-in and should not be exposed publicly, e.g.
+//
+//	srv.Handle("/debug/routes", srv.RoutesHandler())
+func (s *Server) RoutesHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var routes []routeDump
+		seen := make(map[string]struct{})
+		err := s.WalkRoute(func(info RouteInfo) error {
+			key := info.Method + " " + info.Path
+			_, shadowed := seen[key]
+			seen[key] = struct{}{}
+			routes = append(routes, routeDump{
+				Method:     info.Method,
+				Path:       info.Path,
+				Operation:  info.Operation,
+				Filters:    info.Filters,
+				Middleware: info.Middleware,
+				Shadowed:   shadowed,
+			})
+			return nil
+		})
+		if err != nil {
+			s.ene(w, req, err)
+			return
+		}
+		if req.URL.Query().Get("format") == "json" {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(routes)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "METHOD\tPATH\tOPERATION\tFILTERS\tMIDDLEWARE\t")
+		for _, r := range routes {
+			path := r.Path
+			if r.Shadowed {
+				path += " (shadowed)"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t\n", r.Method, path, r.Operation,
+				strings.Join(r.Filters, ","), strings.Join(r.Middleware, ","))
+		}
+		_ = tw.Flush()
+	})
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+func testMiddleware(handler middleware.Handler) middleware.Handler {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return handler(ctx, req)
+	}
+}
+
+func TestFuncName(t *testing.T) {
+	if name := funcName(CORS()); name != "http.CORS" {
+		t.Errorf("expect %v, got %v", "http.CORS", name)
+	}
+	if name := funcName(testMiddleware); name != "http.testMiddleware" {
+		t.Errorf("expect %v, got %v", "http.testMiddleware", name)
+	}
+	if name := funcName(nil); name != "" {
+		t.Errorf("expect empty, got %v", name)
+	}
+}
+
+func TestRoutesHandler(t *testing.T) {
+	srv := NewServer(Middleware(testMiddleware))
+	r := srv.Route("/", ETag())
+	r.GET("/users/{id}", func(ctx Context) error {
+		SetOperation(ctx, "/user.v1.User/GetUser")
+		return ctx.String(http.StatusOK, "ok")
+	}, CORS())
+	r.GET("/users/{id}", func(ctx Context) error {
+		return ctx.String(http.StatusOK, "shadowed")
+	})
+	srv.Handle("/debug/routes", srv.RoutesHandler())
+
+	srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/routes?format=json", nil))
+	var routes []routeDump
+	if err := json.Unmarshal(rec.Body.Bytes(), &routes); err != nil {
+		t.Fatal(err)
+	}
+	want := []routeDump{
+		{
+			Method:     http.MethodGet,
+			Path:       "/users/{id}",
+			Operation:  "/user.v1.User/GetUser",
+			Filters:    []string{"http.ETag", "http.CORS"},
+			Middleware: []string{"http.testMiddleware"},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/users/{id}",
+			Filters:  []string{"http.ETag"},
+			Shadowed: true,
+		},
+	}
+	if !reflect.DeepEqual(want, routes) {
+		t.Errorf("expect %+v, got %+v", want, routes)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/routes", nil))
+	text := rec.Body.String()
+	for _, s := range []string{"METHOD", "/user.v1.User/GetUser", "http.ETag,http.CORS", "(shadowed)"} {
+		if !strings.Contains(text, s) {
+			t.Errorf("expect %q in %v", s, text)
+		}
+	}
+}
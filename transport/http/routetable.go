@@ -0,0 +1,39 @@
+package http
+
+import (
+	"sort"
+	"strings"
+)
+
+// routeTable is the values of the routes, matched by the exact pattern
+// and then the longest prefix pattern ending with '*'.
+type routeTable[T any] struct {
+	prefix []string
+	matchs map[string]T
+}
+
+func (t *routeTable[T]) add(pattern string, v T) {
+	if t.matchs == nil {
+		t.matchs = make(map[string]T)
+	}
+	if strings.HasSuffix(pattern, "*") {
+		pattern = strings.TrimSuffix(pattern, "*")
+		t.prefix = append(t.prefix, pattern)
+		sort.Slice(t.prefix, func(i, j int) bool {
+			return t.prefix[i] > t.prefix[j]
+		})
+	}
+	t.matchs[pattern] = v
+}
+
+func (t *routeTable[T]) match(path string) (v T, ok bool) {
+	if v, ok = t.matchs[path]; ok {
+		return v, true
+	}
+	for _, prefix := range t.prefix {
+		if strings.HasPrefix(path, prefix) {
+			return t.matchs[prefix], true
+		}
+	}
+	return v, false
+}
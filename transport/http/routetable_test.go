@@ -0,0 +1,19 @@
+package http
+
+import "testing"
+
+func TestRouteTable(t *testing.T) {
+	var l routeTable[int64]
+	if _, ok := l.match("/upload"); ok {
+		t.Errorf("expect no limit")
+	}
+	l.add("/upload/*", 100)
+	l.add("/upload/large/*", 0)
+	l.add("/upload/file", 10)
+	tests := map[string]int64{"/upload/file": 10, "/upload/large/file": 0, "/upload/small": 100}
+	for path, want := range tests {
+		if n, ok := l.match(path); !ok || n != want {
+			t.Errorf("%s: expect %v, got %v %v", path, want, n, ok)
+		}
+	}
+}
@@ -3,20 +3,30 @@ package http
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"net"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/mux"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"golang.org/x/net/websocket"
 
 	"github.com/go-kratos/kratos/v2/internal/endpoint"
 	"github.com/go-kratos/kratos/v2/internal/host"
 	"github.com/go-kratos/kratos/v2/internal/matcher"
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metadata"
 	"github.com/go-kratos/kratos/v2/middleware"
+	mtimeout "github.com/go-kratos/kratos/v2/middleware/timeout"
 	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http/session"
 )
 
 var (
@@ -49,6 +59,66 @@ func Timeout(timeout time.Duration) ServerOption {
 	}
 }
 
+// RouteTimeout with the timeout of the routes matching the path pattern, it overrides
+// the server timeout, zero means no timeout, e.g. for the streams:
+//   - '/upload'
+//   - '/upload/*'
+func RouteTimeout(pattern string, timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.timeoutOpts = append(s.timeoutOpts, mtimeout.WithOperation(pattern, timeout))
+	}
+}
+
+// RouteTimeoutRegexp with the timeout of the routes matching the regular expression,
+// it is checked after the path patterns of RouteTimeout.
+func RouteTimeoutRegexp(expr *regexp.Regexp, timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.timeoutOpts = append(s.timeoutOpts, mtimeout.WithRegexp(expr, timeout))
+	}
+}
+
+// MaxRequestBody with the max size of the request bodies in bytes, the requests
+// exceeding it are rejected by ErrRequestBodyTooLarge, zero means no limit.
+func MaxRequestBody(n int64) ServerOption {
+	return func(s *Server) {
+		s.maxBody = n
+	}
+}
+
+// RouteMaxRequestBody with the max size of the request bodies of the routes matching
+// the path pattern, it overrides MaxRequestBody, zero means no limit:
+//   - '/upload'
+//   - '/upload/*'
+func RouteMaxRequestBody(pattern string, n int64) ServerOption {
+	return func(s *Server) {
+		s.bodyLimits.add(pattern, n)
+	}
+}
+
+// MultipartMemory with the max memory in bytes of the multipart forms, the uploaded
+// files exceeding it are streamed to temporary files, default is 32MB.
+func MultipartMemory(n int64) ServerOption {
+	return func(s *Server) {
+		s.maxMemory = n
+	}
+}
+
+// Sessions with the session manager, the sessions are accessed by Context.Session.
+func Sessions(m *session.Manager) ServerOption {
+	return func(s *Server) {
+		s.sessions = m
+	}
+}
+
+// DrainGrace with the grace period of the websocket connections on Stop, they are
+// signaled by StreamContext and closed after it, so that the handlers can send the
+// terminal messages. Default is 0, they are closed immediately.
+func DrainGrace(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.drainGrace = d
+	}
+}
+
 // Logger with server logger.
 // Deprecated: use global logger instead.
 func Logger(_ log.Logger) ServerOption {
@@ -97,6 +167,16 @@ func ResponseEncoder(en EncodeResponseFunc) ServerOption {
 	}
 }
 
+// RouteResponseEncoder with the response encoder of the routes matching the operation
+// or the path pattern, it overrides ResponseEncoder, e.g. for CSV exports:
+//   - '/helloworld.v1.Greeter/SayHello'
+//   - '/export/*'
+func RouteResponseEncoder(pattern string, en EncodeResponseFunc) ServerOption {
+	return func(o *Server) {
+		o.encoders.add(pattern, en)
+	}
+}
+
 // ErrorEncoder with error encoder.
 func ErrorEncoder(en EncodeErrorFunc) ServerOption {
 	return func(o *Server) {
@@ -134,6 +214,32 @@ func PathPrefix(prefix string) ServerOption {
 	}
 }
 
+// H2C with cleartext HTTP/2, the server serves both HTTP/1.1 and HTTP/2 without TLS,
+// so the endpoint scheme is still http.
+func H2C() ServerOption {
+	return func(s *Server) {
+		s.h2c = true
+	}
+}
+
+// HTTP3Server is an HTTP/3 server over QUIC, e.g. the http3.Server of quic-go
+// with the kratos server as its handler. If it has a Shutdown(context.Context) error
+// method, it is shut down gracefully on Stop before it is closed.
+type HTTP3Server interface {
+	Serve(conn net.PacketConn) error
+	Close() error
+}
+
+// HTTP3 with an experimental HTTP/3 server, it serves on the UDP port of the
+// server address, and is closed on Stop. HTTP/3 requires TLS, the clients
+// discover it by the Alt-Svc header of the responses, so the endpoint scheme
+// is still https.
+func HTTP3(h3 HTTP3Server) ServerOption {
+	return func(s *Server) {
+		s.h3 = h3
+	}
+}
+
 // Server is an HTTP server wrapper.
 type Server struct {
 	*http.Server
@@ -153,6 +259,31 @@ type Server struct {
 	ene         EncodeErrorFunc
 	strictSlash bool
 	router      *mux.Router // 使用的是著名的gorilla/mux
+	wsMu        sync.Mutex
+	wsConns     map[*websocket.Conn]struct{}
+	h2c         bool
+	h3          HTTP3Server
+	h3mu        sync.Mutex
+	h3conn      net.PacketConn
+	altSvc      string
+	timeoutOpts []mtimeout.Option
+	timeouts    *mtimeout.Matcher
+	maxBody     int64
+	bodyLimits  routeTable[int64]
+	maxMemory   int64
+	drain       *drainer
+	encoders    routeTable[EncodeResponseFunc]
+	codec       string
+	sessions    *session.Manager
+	clientCAs   *x509.CertPool
+	clientAuth  tls.ClientAuthType
+	verifyPeer  func(transport.Identity) error
+	routes      sync.Map // *mux.Route -> *routeMeta
+	notFound    HandlerFunc
+	notAllowed  HandlerFunc
+	drainGrace  time.Duration
+	limiter     *concurrencyLimiter
+	engine      RouterEngine
 }
 
 // NewServer creates an HTTP server by options.
@@ -169,18 +300,49 @@ func NewServer(opts ...ServerOption) *Server {
 		ene:         DefaultErrorEncoder,
 		strictSlash: true,
 		router:      mux.NewRouter(),
+		maxMemory:   defaultMultipartMemory,
+		drain:       newDrainer(),
 	}
 	for _, o := range opts {
 		o(srv)
 	}
+	srv.timeouts = mtimeout.NewMatcher(srv.timeoutOpts...)
+	srv.tlsConf = srv.mtlsConfig()
 	// 路由处理器(著名的gorilla/mux),将http请求路由到指定的用户函数中。 这里的router一定是实现了原生net.http.Handler接口，所有的请求都需要到这里。
 	srv.router.StrictSlash(srv.strictSlash)
-	srv.router.NotFoundHandler = http.DefaultServeMux
-	srv.router.MethodNotAllowedHandler = http.DefaultServeMux
+	srv.router.NotFoundHandler = srv.fallback(srv.notFound)
+	srv.router.MethodNotAllowedHandler = srv.fallback(srv.notAllowed)
 	srv.router.Use(srv.filter()) // 对gorilla/mux的路由注册middleware。在路由匹配成功时，会用中间件包裹处理函数 Handler
 	srv.Server = &http.Server{   // 原生HTTP Server
 		Handler:   FilterChain(srv.filters...)(srv.router), // 把srv.router(gorilla/mux)当作洋葱芯，包裹外层用户自定义的中间件。
 		TLSConfig: srv.tlsConf,
+		ConnState: srv.drain.connState,
+	}
+	if len(srv.filters) > 0 {
+		// filter拒绝请求时（例如RateLimit）使用server的ErrorEncoder
+		srv.Server.Handler = withErrorEncoder(srv.Server.Handler, srv.ene)
+	}
+	if srv.engine != nil {
+		// gorilla/mux未匹配的请求交给engine
+		if e, ok := srv.engine.(interface{ NotFound(http.Handler) }); ok && srv.notFound != nil {
+			e.NotFound(srv.router.NotFoundHandler)
+		}
+		srv.router.NotFoundHandler = srv.engine
+	}
+	if srv.limiter != nil {
+		// 在路由和filter之前限制并发
+		srv.Server.Handler = srv.limiter.handler(srv.Server.Handler, srv.ene)
+	}
+	if srv.h3 != nil {
+		srv.Server.Handler = srv.altSvcHandler(srv.Server.Handler)
+	}
+	// websocket连接被劫持，Shutdown不会关闭它们
+	srv.Server.RegisterOnShutdown(srv.drainWebSockets)
+	if srv.h2c {
+		h2s := &http2.Server{}
+		// 注册http2的优雅关闭（发送GOAWAY）
+		_ = http2.ConfigureServer(srv.Server, h2s)
+		srv.Server.Handler = h2c.NewHandler(srv.Server.Handler, h2s)
 	}
 	return srv
 }
@@ -196,22 +358,62 @@ func (s *Server) Use(selector string, m ...middleware.Middleware) {
 
 // WalkRoute walks the router and all its sub-routers, calling walkFn for each route in the tree.
 func (s *Server) WalkRoute(fn WalkRouteFunc) error {
-	return s.router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+	err := s.router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		if strings.HasPrefix(route.GetName(), preflightRoutePrefix) {
+			return nil
+		}
+		var meta *routeMeta
+		if v, ok := s.routes.Load(route); ok {
+			meta = v.(*routeMeta)
+		}
 		methods, err := route.GetMethods()
 		if err != nil {
-			return nil // ignore no methods
+			if meta == nil || !meta.mount {
+				return nil // ignore no methods
+			}
+			methods = []string{"*"}
 		}
 		path, err := route.GetPathTemplate()
 		if err != nil {
 			return err
 		}
+		info := s.routeInfo(path, meta)
 		for _, method := range methods {
-			if err := fn(RouteInfo{Method: method, Path: path}); err != nil {
+			info.Method = method
+			if err := fn(info); err != nil {
 				return err
 			}
 		}
 		return nil
 	})
+	if err != nil || s.engine == nil {
+		return err
+	}
+	return s.engine.Walk(func(method, path string) error {
+		v, ok := s.routes.Load(engineRoute{method: method, path: path})
+		if !ok {
+			return nil // ignore the preflight routes
+		}
+		info := s.routeInfo(path, v.(*routeMeta))
+		info.Method = method
+		return fn(info)
+	})
+}
+
+// routeInfo returns the info of the route, the meta is nil if it is not registered by Router.Handle.
+func (s *Server) routeInfo(path string, meta *routeMeta) RouteInfo {
+	info := RouteInfo{Path: path}
+	if meta != nil {
+		if meta.mount {
+			info.Path = strings.TrimSuffix(path, "/") + "/*"
+		}
+		info.Filters = meta.filters
+		if op, _ := meta.operation.Load().(string); op != "" {
+			info.Operation = op
+			info.Middleware = middlewareNames(s.middleware.Match(op))
+		}
+	}
+	return info
 }
 
 // Route registers an HTTP router.
@@ -239,12 +441,34 @@ func (s *Server) HandleHeader(key, val string, h http.HandlerFunc) {
 	s.router.Headers(key, val).Handler(h)
 }
 
+// encoder returns the response encoder of the request, matched by the operation and then the path.
+func (s *Server) encoder(req *http.Request) EncodeResponseFunc {
+	if len(s.encoders.matchs) == 0 {
+		return s.enc
+	}
+	if tr, ok := transport.FromServerContext(req.Context()); ok {
+		if en, ok := s.encoders.match(tr.Operation()); ok {
+			return en
+		}
+	}
+	if en, ok := s.encoders.match(req.URL.Path); ok {
+		return en
+	}
+	return s.enc
+}
+
 // ServeHTTP should write reply headers and data to the ResponseWriter and then return.
 func (s *Server) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 	s.Handler.ServeHTTP(res, req)
 }
 
 func (s *Server) filter() mux.MiddlewareFunc {
+	return s.routeFilter("")
+}
+
+// routeFilter returns the filter of the server, the path template is the template of
+// the route matched by gorilla/mux if it is empty.
+func (s *Server) routeFilter(template string) mux.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		// 强制转换成http.HandlerFunc类型，http.handlerFunc实现了http.Handler接口,它的ServerHTTP()方法，就是调用http.HandlerFunc自己
 		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
@@ -252,17 +476,48 @@ func (s *Server) filter() mux.MiddlewareFunc {
 				ctx    context.Context
 				cancel context.CancelFunc
 			)
-			if s.timeout > 0 {
-				ctx, cancel = context.WithTimeout(req.Context(), s.timeout)
+			timeout := s.timeout
+			if s.timeouts != nil {
+				if d, ok := s.timeouts.Match(req.URL.Path); ok {
+					timeout = d
+				}
+			}
+			// 上游传递的剩余超时更短时，以它为准
+			if d, ok := decodeTimeout(req.Header.Get(TimeoutHeader)); ok && (timeout <= 0 || d < timeout) {
+				timeout = d
+				if timeout <= 0 {
+					timeout = time.Nanosecond
+				}
+			}
+			if timeout > 0 {
+				ctx, cancel = context.WithTimeout(req.Context(), timeout)
 			} else {
 				ctx, cancel = context.WithCancel(req.Context())
 			}
 			defer cancel()
+			s.drain.begin()
+			defer s.drain.end()
+			ctx = context.WithValue(ctx, drainKey{}, s.drain)
 
-			pathTemplate := req.URL.Path
-			if route := mux.CurrentRoute(req); route != nil {
-				// /path/123 -> /path/{id}
-				pathTemplate, _ = route.GetPathTemplate()
+			limit := s.maxBody
+			if n, ok := s.bodyLimits.match(req.URL.Path); ok {
+				limit = n
+			}
+			if limit > 0 && req.Body != nil && req.Body != http.NoBody {
+				if req.ContentLength > limit {
+					s.ene(w, req, ErrRequestBodyTooLarge)
+					return
+				}
+				req.Body = newMaxBytesReader(w, req.Body, limit)
+			}
+
+			pathTemplate := template
+			if pathTemplate == "" {
+				pathTemplate = req.URL.Path
+				if route := mux.CurrentRoute(req); route != nil {
+					// /path/123 -> /path/{id}
+					pathTemplate, _ = route.GetPathTemplate()
+				}
 			}
 
 			// 其实就是，给ctx套了一层value, 值是tr，再重新复制了一份request，并且新的request使用新的ctx.
@@ -277,12 +532,40 @@ func (s *Server) filter() mux.MiddlewareFunc {
 			if s.endpoint != nil {
 				tr.endpoint = s.endpoint.String()
 			}
+			if s.codec != "" {
+				ctx = withDefaultCodec(ctx, s.codec)
+			}
+			// 与gRPC一致，合并多个baggage请求头
+			if vals := req.Header.Values(metadata.BaggageHeader); len(vals) > 0 {
+				ctx = metadata.ExtractBaggage(ctx, strings.Join(vals, ","))
+			}
+			if id, ok := s.peerIdentity(req); ok {
+				ctx = transport.NewIdentityContext(ctx, id)
+			}
 			tr.request = req.WithContext(transport.NewServerContext(ctx, tr))
 			next.ServeHTTP(w, tr.request)
 		})
 	}
 }
 
+type errorEncoderKey struct{}
+
+// withErrorEncoder passes the error encoder of the server to the filters.
+func withErrorEncoder(next http.Handler, ene EncodeErrorFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), errorEncoderKey{}, ene)))
+	})
+}
+
+// errorEncoder returns the error encoder of the server serving the request,
+// DefaultErrorEncoder is returned outside of a server.
+func errorEncoder(req *http.Request) EncodeErrorFunc {
+	if ene, ok := req.Context().Value(errorEncoderKey{}).(EncodeErrorFunc); ok {
+		return ene
+	}
+	return DefaultErrorEncoder
+}
+
 // Endpoint return a real address to registry endpoint.
 // examples:
 //
@@ -304,6 +587,23 @@ func (s *Server) Start(ctx context.Context) error {
 		return ctx
 	}
 	log.Infof("[HTTP] server listening on: %s", s.lis.Addr().String())
+	if s.h3 != nil {
+		conn, err := net.ListenPacket("udp", s.lis.Addr().String())
+		if err != nil {
+			return err
+		}
+		s.h3mu.Lock()
+		s.h3conn = conn
+		s.h3mu.Unlock()
+		if _, port, err := net.SplitHostPort(s.lis.Addr().String()); err == nil {
+			s.altSvc = `h3=":` + port + `"; ma=86400`
+		}
+		go func() {
+			if err := s.h3.Serve(conn); err != nil {
+				log.Errorf("[HTTP] http3 server error: %v", err)
+			}
+		}()
+	}
 	var err error
 	if s.tlsConf != nil {
 		err = s.ServeTLS(s.lis, "", "")
@@ -319,7 +619,63 @@ func (s *Server) Start(ctx context.Context) error {
 // Stop stop the HTTP server.
 func (s *Server) Stop(ctx context.Context) error {
 	log.Info("[HTTP] server stopping")
-	return s.Shutdown(ctx)
+	if s.h3 != nil {
+		// 与HTTP/1、HTTP/2的连接同时优雅关闭
+		h3done := make(chan struct{})
+		go func() {
+			defer close(h3done)
+			s.stopHTTP3(ctx)
+		}()
+		defer func() { <-h3done }()
+	}
+	// 不再复用连接，并通知SSE等长连接结束
+	s.SetKeepAlivesEnabled(false)
+	deadline, _ := ctx.Deadline()
+	if s.drainGrace > 0 {
+		if grace := time.Now().Add(s.drainGrace); deadline.IsZero() || grace.Before(deadline) {
+			deadline = grace
+		}
+	}
+	s.drain.start(deadline)
+	err := s.Shutdown(ctx)
+	if err != nil && ctx.Err() != nil && s.drain != nil {
+		conns, requests := s.drain.active()
+		log.Warnf("[HTTP] server stop timeout, force closing %d connections with %d requests in flight", conns, requests)
+		if cerr := s.Close(); cerr != nil {
+			log.Errorf("[HTTP] server close error: %v", cerr)
+		}
+		s.closeWebSockets()
+	}
+	return err
+}
+
+// stopHTTP3 shuts the HTTP/3 server down gracefully if it supports, then closes it.
+func (s *Server) stopHTTP3(ctx context.Context) {
+	if gs, ok := s.h3.(interface{ Shutdown(context.Context) error }); ok {
+		if err := gs.Shutdown(ctx); err != nil {
+			log.Errorf("[HTTP] http3 server shutdown error: %v", err)
+		}
+	}
+	if err := s.h3.Close(); err != nil {
+		log.Errorf("[HTTP] http3 server close error: %v", err)
+	}
+	s.h3mu.Lock()
+	conn := s.h3conn
+	s.h3conn = nil
+	s.h3mu.Unlock()
+	if conn != nil {
+		_ = conn.Close()
+	}
+}
+
+// altSvcHandler advertises the HTTP/3 server by the Alt-Svc header.
+func (s *Server) altSvcHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if s.altSvc != "" && req.ProtoMajor < 3 {
+			w.Header().Set("Alt-Svc", s.altSvc)
+		}
+		next.ServeHTTP(w, req)
+	})
 }
 
 func (s *Server) listenAndEndpoint() error {
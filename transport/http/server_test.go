@@ -9,13 +9,18 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
 
+	"golang.org/x/net/http2"
+
 	kratoserrors "github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/internal/host"
+	"github.com/go-kratos/kratos/v2/metadata"
 )
 
 var h = func(w http.ResponseWriter, r *http.Request) {
@@ -371,3 +376,211 @@ func TestListener(t *testing.T) {
 		t.Errorf("expected not empty")
 	}
 }
+
+func TestH2C(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(H2C())
+	srv.HandleFunc("/index", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	})
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Scheme != "http" {
+		t.Errorf("expected %v got %v", "http", e.Scheme)
+	}
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Millisecond * 100)
+	defer func() { _ = srv.Stop(ctx) }()
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+	resp, err := client.Get("http://" + e.Host + "/index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Errorf("expected %v got %v", 2, resp.ProtoMajor)
+	}
+	// HTTP/1.1 is still served
+	resp1, err := http.Get("http://" + e.Host + "/index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp1.Body.Close()
+	if resp1.ProtoMajor != 1 {
+		t.Errorf("expected %v got %v", 1, resp1.ProtoMajor)
+	}
+}
+
+type mockHTTP3Server struct {
+	served   chan net.PacketConn
+	shutdown bool
+	closed   bool
+}
+
+func (s *mockHTTP3Server) Serve(conn net.PacketConn) error {
+	s.served <- conn
+	return nil
+}
+
+func (s *mockHTTP3Server) Shutdown(context.Context) error {
+	s.shutdown = true
+	return nil
+}
+
+func (s *mockHTTP3Server) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestHTTP3(t *testing.T) {
+	ctx := context.Background()
+	h3 := &mockHTTP3Server{served: make(chan net.PacketConn, 1)}
+	srv := NewServer(HTTP3(h3), Address("127.0.0.1:0"))
+	if _, err := srv.Endpoint(); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	conn := <-h3.served
+	if conn.LocalAddr().String() != srv.lis.Addr().String() {
+		t.Errorf("expected %v got %v", srv.lis.Addr(), conn.LocalAddr())
+	}
+	resp, err := http.Get("http://" + srv.lis.Addr().String() + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	_, port, _ := net.SplitHostPort(srv.lis.Addr().String())
+	if v := resp.Header.Get("Alt-Svc"); v != `h3=":`+port+`"; ma=86400` {
+		t.Errorf("expected the Alt-Svc header got %q", v)
+	}
+	if err = srv.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !h3.shutdown || !h3.closed {
+		t.Errorf("expected the http3 server to be shut down and closed")
+	}
+}
+
+func TestRouteTimeout(t *testing.T) {
+	srv := NewServer(
+		Timeout(time.Second),
+		RouteTimeout("/upload/*", time.Second*10),
+		RouteTimeoutRegexp(regexp.MustCompile(`^/events/.+$`), 0),
+	)
+	deadline := func(w http.ResponseWriter, r *http.Request) {
+		d, ok := r.Context().Deadline()
+		if !ok {
+			_, _ = w.Write([]byte("0"))
+			return
+		}
+		_, _ = w.Write([]byte(fmt.Sprint(time.Until(d).Round(time.Second))))
+	}
+	srv.HandleFunc("/upload/file", deadline)
+	srv.HandleFunc("/events/1", deadline)
+	srv.HandleFunc("/index", deadline)
+	for path, want := range map[string]string{"/upload/file": "10s", "/events/1": "0", "/index": "1s"} {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Body.String() != want {
+			t.Errorf("%s: expected %v got %v", path, want, rec.Body.String())
+		}
+	}
+}
+
+func TestMaxRequestBody(t *testing.T) {
+	srv := NewServer(MaxRequestBody(8), RouteMaxRequestBody("/upload/*", 0))
+	route := srv.Route("/")
+	handler := func(ctx Context) error {
+		var in map[string]string
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		return ctx.Result(http.StatusOK, in)
+	}
+	route.POST("/echo", handler)
+	route.POST("/upload/file", handler)
+	body := `{"name":"kratos"}`
+	tests := []struct {
+		path          string
+		contentLength bool
+		code          int
+	}{
+		{"/echo", true, http.StatusRequestEntityTooLarge},
+		{"/echo", false, http.StatusRequestEntityTooLarge},
+		{"/upload/file", true, http.StatusOK},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodPost, test.path, io.NopCloser(strings.NewReader(body)))
+		req.Header.Set("Content-Type", "application/json")
+		if test.contentLength {
+			req.ContentLength = int64(len(body))
+		} else {
+			req.ContentLength = -1
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if rec.Code != test.code {
+			t.Errorf("%s: expected %v got %v %s", test.path, test.code, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestRouteResponseEncoder(t *testing.T) {
+	csv := func(w http.ResponseWriter, r *http.Request, v interface{}) error {
+		w.Header().Set("Content-Type", "text/csv")
+		_, err := fmt.Fprintf(w, "name\n%s\n", v.(map[string]string)["name"])
+		return err
+	}
+	srv := NewServer(
+		RouteResponseEncoder("/export/*", csv),
+		RouteResponseEncoder("/helloworld.v1.Greeter/Export", csv),
+	)
+	reply := func(ctx Context) error {
+		return ctx.Result(http.StatusOK, map[string]string{"name": "kratos"})
+	}
+	r := srv.Route("/")
+	r.GET("/export/users", reply)
+	r.GET("/users", reply)
+	r.GET("/v1/export", func(ctx Context) error {
+		SetOperation(ctx, "/helloworld.v1.Greeter/Export")
+		return reply(ctx)
+	})
+	tests := map[string]string{"/export/users": "text/csv", "/users": "application/json", "/v1/export": "text/csv"}
+	for path, want := range tests {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if got := rec.Header().Get("Content-Type"); got != want {
+			t.Errorf("%s: expected %v got %v", path, want, got)
+		}
+	}
+}
+
+func TestServerBaggage(t *testing.T) {
+	srv := NewServer()
+	srv.Route("/").GET("/baggage", func(ctx Context) error {
+		return ctx.String(http.StatusOK, metadata.GetBaggage(ctx, "tenant")+","+metadata.GetBaggage(ctx, "region"))
+	})
+	req := httptest.NewRequest(http.MethodGet, "/baggage", nil)
+	req.Header.Add(metadata.BaggageHeader, "tenant=kratos")
+	req.Header.Add(metadata.BaggageHeader, "region=eu")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Body.String() != "kratos,eu" {
+		t.Errorf("expect %v, got %v", "kratos,eu", rec.Body.String())
+	}
+}
@@ -0,0 +1,229 @@
+// Package session provides the HTTP sessions, the session ids are signed in the
+// cookies and the values are kept in a Store, e.g. the in-memory store, or a
+// redis store implementing the Store interface for the multi-instance deployments.
+package session
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultCookieName = "kratos_session"
+	defaultMaxAge     = 24 * time.Hour
+)
+
+// Option is session manager option.
+type Option func(*Manager)
+
+// WithStore with the session store, default is an in-memory store.
+func WithStore(s Store) Option {
+	return func(m *Manager) {
+		m.store = s
+	}
+}
+
+// WithCookieName with the name of the session cookie, default is kratos_session.
+func WithCookieName(name string) Option {
+	return func(m *Manager) {
+		m.cookie.Name = name
+	}
+}
+
+// WithMaxAge with the lifetime of the sessions, default is 24h.
+func WithMaxAge(d time.Duration) Option {
+	return func(m *Manager) {
+		m.maxAge = d
+	}
+}
+
+// WithCookie with the attributes of the session cookie, e.g. Domain, Path,
+// Secure and SameSite, the Name and the Value are ignored.
+func WithCookie(c http.Cookie) Option {
+	return func(m *Manager) {
+		name := m.cookie.Name
+		m.cookie = c
+		m.cookie.Name = name
+	}
+}
+
+// Manager loads and saves the sessions, the session ids are signed in the cookies
+// by HMAC-SHA256, so that they can not be forged.
+type Manager struct {
+	keys   [][]byte
+	store  Store
+	maxAge time.Duration
+	cookie http.Cookie
+}
+
+// NewManager new a session manager with the signing keys, the first key signs the
+// session ids and all of them verify, so that the keys can be rotated.
+func NewManager(keys [][]byte, opts ...Option) (*Manager, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("session: signing key is required")
+	}
+	m := &Manager{
+		keys:   keys,
+		store:  NewMemoryStore(),
+		maxAge: defaultMaxAge,
+		cookie: http.Cookie{
+			Name:     defaultCookieName,
+			Path:     "/",
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		},
+	}
+	for _, o := range opts {
+		o(m)
+	}
+	return m, nil
+}
+
+// Load returns the session of the request, a new session is returned if the
+// cookie is missing, invalid or the session expired.
+func (m *Manager) Load(r *http.Request) (*Session, error) {
+	c, err := r.Cookie(m.cookie.Name)
+	if err != nil {
+		return m.newSession()
+	}
+	id, ok := m.verify(c.Value)
+	if !ok {
+		return m.newSession()
+	}
+	values, err := m.store.Load(r.Context(), id)
+	if errors.Is(err, ErrNotFound) {
+		return m.newSession()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Session{id: id, values: values, m: m}, nil
+}
+
+func (m *Manager) newSession() (*Session, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	return &Session{id: id, values: make(map[string]string), m: m, isNew: true}, nil
+}
+
+func (m *Manager) sign(id string) string {
+	return id + "." + base64.RawURLEncoding.EncodeToString(m.mac(m.keys[0], id))
+}
+
+func (m *Manager) verify(value string) (string, bool) {
+	i := strings.LastIndexByte(value, '.')
+	if i < 0 {
+		return "", false
+	}
+	id := value[:i]
+	sig, err := base64.RawURLEncoding.DecodeString(value[i+1:])
+	if err != nil {
+		return "", false
+	}
+	for _, key := range m.keys {
+		if hmac.Equal(sig, m.mac(key, id)) {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+func (m *Manager) mac(key []byte, id string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(id))
+	return h.Sum(nil)
+}
+
+func (m *Manager) setCookie(w http.ResponseWriter, value string, maxAge int) {
+	c := m.cookie
+	c.Value = value
+	c.MaxAge = maxAge
+	if maxAge > 0 {
+		c.Expires = time.Now().Add(time.Duration(maxAge) * time.Second)
+	}
+	http.SetCookie(w, &c)
+}
+
+func newID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Session is a set of the values of a client, it must be saved before the
+// response body is written, since the cookie is a header.
+type Session struct {
+	id     string
+	values map[string]string
+	m      *Manager
+	isNew  bool
+}
+
+// ID returns the session id.
+func (s *Session) ID() string { return s.id }
+
+// IsNew reports whether the session is created by the request.
+func (s *Session) IsNew() bool { return s.isNew }
+
+// Get returns the value of the key.
+func (s *Session) Get(key string) (string, bool) {
+	v, ok := s.values[key]
+	return v, ok
+}
+
+// Set sets the value of the key.
+func (s *Session) Set(key, value string) {
+	s.values[key] = value
+}
+
+// Delete removes the key.
+func (s *Session) Delete(key string) {
+	delete(s.values, key)
+}
+
+// Save stores the session and writes the cookie.
+func (s *Session) Save(ctx context.Context, w http.ResponseWriter) error {
+	if err := s.m.store.Save(ctx, s.id, s.values, s.m.maxAge); err != nil {
+		return err
+	}
+	s.m.setCookie(w, s.m.sign(s.id), int(s.m.maxAge/time.Second))
+	s.isNew = false
+	return nil
+}
+
+// Regenerate renews the session id and keeps the values, it should be called
+// on login to prevent session fixation. The session must be saved afterwards.
+func (s *Session) Regenerate(ctx context.Context) error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	if !s.isNew {
+		if err = s.m.store.Delete(ctx, s.id); err != nil {
+			return err
+		}
+	}
+	s.id = id
+	return nil
+}
+
+// Destroy removes the session and expires the cookie.
+func (s *Session) Destroy(ctx context.Context, w http.ResponseWriter) error {
+	if err := s.m.store.Delete(ctx, s.id); err != nil {
+		return err
+	}
+	s.values = make(map[string]string)
+	s.m.setCookie(w, "", -1)
+	return nil
+}
@@ -0,0 +1,115 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func saveSession(t *testing.T, m *Manager, key, value string) *http.Cookie {
+	s, err := m.Load(httptest.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.IsNew() {
+		t.Errorf("expect a new session")
+	}
+	s.Set(key, value)
+	rec := httptest.NewRecorder()
+	if err = s.Save(context.Background(), rec); err != nil {
+		t.Fatal(err)
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expect 1 cookie, got %v", cookies)
+	}
+	return cookies[0]
+}
+
+func loadSession(t *testing.T, m *Manager, c *http.Cookie) *Session {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(c)
+	s, err := m.Load(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestManager(t *testing.T) {
+	m, err := NewManager([][]byte{[]byte("secret")}, WithCookieName("sid"), WithMaxAge(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := saveSession(t, m, "user", "kratos")
+	if c.Name != "sid" || !c.HttpOnly || c.MaxAge != 3600 {
+		t.Errorf("unexpected cookie: %v", c)
+	}
+	s := loadSession(t, m, c)
+	if s.IsNew() {
+		t.Errorf("expect an existing session")
+	}
+	if v, _ := s.Get("user"); v != "kratos" {
+		t.Errorf("expect %v, got %v", "kratos", v)
+	}
+
+	// forged session id
+	forged := *c
+	forged.Value = "forged" + forged.Value[len(s.ID()):]
+	if s := loadSession(t, m, &forged); !s.IsNew() {
+		t.Errorf("expect a new session for the forged cookie")
+	}
+
+	rec := httptest.NewRecorder()
+	if err = s.Destroy(context.Background(), rec); err != nil {
+		t.Fatal(err)
+	}
+	if cookies := rec.Result().Cookies(); len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+		t.Errorf("expect the cookie to be expired, got %v", cookies)
+	}
+	if s := loadSession(t, m, c); !s.IsNew() {
+		t.Errorf("expect a new session after destroy")
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	store := NewMemoryStore()
+	old, _ := NewManager([][]byte{[]byte("old")}, WithStore(store))
+	c := saveSession(t, old, "user", "kratos")
+
+	m, _ := NewManager([][]byte{[]byte("new"), []byte("old")}, WithStore(store))
+	if s := loadSession(t, m, c); s.IsNew() {
+		t.Errorf("expect the session signed by the old key to be valid")
+	}
+	m, _ = NewManager([][]byte{[]byte("new")}, WithStore(store))
+	if s := loadSession(t, m, c); !s.IsNew() {
+		t.Errorf("expect the session signed by the removed key to be invalid")
+	}
+}
+
+func TestRegenerate(t *testing.T) {
+	m, _ := NewManager([][]byte{[]byte("secret")})
+	c := saveSession(t, m, "user", "kratos")
+	s := loadSession(t, m, c)
+	id := s.ID()
+	if err := s.Regenerate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s.ID() == id {
+		t.Errorf("expect a new session id")
+	}
+	if v, _ := s.Get("user"); v != "kratos" {
+		t.Errorf("expect %v, got %v", "kratos", v)
+	}
+	if s := loadSession(t, m, c); !s.IsNew() {
+		t.Errorf("expect the old session to be removed")
+	}
+}
+
+func TestNewManager(t *testing.T) {
+	if _, err := NewManager(nil); err == nil {
+		t.Errorf("expect an error without keys")
+	}
+}
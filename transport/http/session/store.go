@@ -0,0 +1,103 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned by Store.Load if the session does not exist or expired.
+var ErrNotFound = errors.New("session: not found")
+
+// Store is the storage of the sessions, e.g. memory or redis.
+type Store interface {
+	// Load returns the values of the session, ErrNotFound if it does not exist.
+	Load(ctx context.Context, id string) (map[string]string, error)
+	// Save stores the values of the session, it expires after the ttl.
+	Save(ctx context.Context, id string, values map[string]string, ttl time.Duration) error
+	// Delete removes the session.
+	Delete(ctx context.Context, id string) error
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore is an in-memory Store, it is for the single instance deployments and tests,
+// the sessions are lost on restart.
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions map[string]memorySession
+	swept    time.Time
+	now      func() time.Time
+}
+
+type memorySession struct {
+	values   map[string]string
+	expireAt time.Time
+}
+
+// NewMemoryStore new an in-memory session store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: make(map[string]memorySession),
+		now:      time.Now,
+	}
+}
+
+// Load returns the values of the session.
+func (s *MemoryStore) Load(_ context.Context, id string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if !sess.expireAt.IsZero() && s.now().After(sess.expireAt) {
+		delete(s.sessions, id)
+		return nil, ErrNotFound
+	}
+	return copyValues(sess.values), nil
+}
+
+// Save stores the values of the session, the expired sessions are swept every minute.
+func (s *MemoryStore) Save(_ context.Context, id string, values map[string]string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.sweep(now)
+	sess := memorySession{values: copyValues(values)}
+	if ttl > 0 {
+		sess.expireAt = now.Add(ttl)
+	}
+	s.sessions[id] = sess
+	return nil
+}
+
+// sweep removes the expired sessions, at most once a minute so that Save is not O(n).
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.swept) < time.Minute {
+		return
+	}
+	s.swept = now
+	for k, sess := range s.sessions {
+		if !sess.expireAt.IsZero() && now.After(sess.expireAt) {
+			delete(s.sessions, k)
+		}
+	}
+}
+
+// Delete removes the session.
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+	return nil
+}
+
+func copyValues(values map[string]string) map[string]string {
+	m := make(map[string]string, len(values))
+	for k, v := range values {
+		m[k] = v
+	}
+	return m
+}
@@ -0,0 +1,58 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	values := map[string]string{"user": "kratos"}
+	if err := s.Save(ctx, "id", values, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	values["user"] = "changed"
+	got, err := s.Load(ctx, "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["user"] != "kratos" {
+		t.Errorf("expect %v, got %v", "kratos", got["user"])
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err = s.Load(ctx, "id"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expect %v, got %v", ErrNotFound, err)
+	}
+
+	_ = s.Save(ctx, "id", values, 0)
+	_ = s.Delete(ctx, "id")
+	if _, err = s.Load(ctx, "id"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expect %v, got %v", ErrNotFound, err)
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	_ = s.Save(ctx, "a", nil, time.Second)
+	now = now.Add(2 * time.Second)
+	_ = s.Save(ctx, "b", nil, time.Second)
+	if len(s.sessions) != 2 {
+		t.Errorf("expect no sweep within a minute, got %v sessions", len(s.sessions))
+	}
+	now = now.Add(time.Minute)
+	_ = s.Save(ctx, "c", nil, 0)
+	if len(s.sessions) != 1 {
+		t.Errorf("expect the expired sessions to be swept, got %v sessions", len(s.sessions))
+	}
+}
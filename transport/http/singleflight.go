@@ -0,0 +1,109 @@
+package http
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// SingleflightOption is Singleflight filter option.
+type SingleflightOption func(*singleflightOptions)
+
+type singleflightOptions struct {
+	key func(*http.Request) string
+}
+
+// SingleflightKey with the function returning the key of the identical requests, the
+// requests of the empty key are not coalesced. Default is the method, the host, the path,
+// the sorted query and the Accept, Accept-Encoding, Authorization and Cookie headers.
+func SingleflightKey(fn func(*http.Request) string) SingleflightOption {
+	return func(o *singleflightOptions) {
+		o.key = fn
+	}
+}
+
+// Singleflight returns a filter coalescing the concurrent identical GET requests into
+// one execution of the handler, its response is buffered and replied to all of them,
+// protecting the expensive read endpoints from the thundering herds. The handler runs
+// with the request and the context of the first one, so it should only be used for the
+// responses depending on nothing but the key. It is opt-in per router group by
+// Server.Route and Router.Group, or per route by Router.Handle.
+func Singleflight(opts ...SingleflightOption) FilterFunc {
+	o := &singleflightOptions{key: singleflightKey}
+	for _, opt := range opts {
+		opt(o)
+	}
+	var group singleflight.Group
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Method != http.MethodGet || req.Header.Get("Upgrade") != "" {
+				next.ServeHTTP(w, req)
+				return
+			}
+			key := o.key(req)
+			if key == "" {
+				next.ServeHTTP(w, req)
+				return
+			}
+			v, _, _ := group.Do(key, func() (interface{}, error) {
+				sw := &sharedWriter{header: make(http.Header), code: http.StatusOK}
+				next.ServeHTTP(sw, req)
+				return sw, nil
+			})
+			v.(*sharedWriter).reply(w)
+		})
+	}
+}
+
+// singleflightKey is the default key of the identical requests.
+func singleflightKey(req *http.Request) string {
+	var b strings.Builder
+	b.WriteString(req.Method)
+	b.WriteByte(' ')
+	b.WriteString(req.Host)
+	b.WriteString(req.URL.Path)
+	b.WriteByte('?')
+	b.WriteString(req.URL.Query().Encode())
+	for _, h := range []string{"Accept", "Accept-Encoding", "Authorization", "Cookie"} {
+		b.WriteByte('\n')
+		b.WriteString(req.Header.Get(h))
+	}
+	return b.String()
+}
+
+// sharedWriter buffers the response shared by the coalesced requests.
+type sharedWriter struct {
+	header      http.Header
+	code        int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (w *sharedWriter) Header() http.Header { return w.header }
+
+func (w *sharedWriter) WriteHeader(code int) {
+	// 合并的请求不发送1xx响应
+	if w.wroteHeader || informational(code) {
+		return
+	}
+	w.wroteHeader = true
+	w.code = code
+}
+
+func (w *sharedWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(p)
+}
+
+// reply writes the shared response, the header values are copied since they
+// may be modified by the filters of each request.
+func (w *sharedWriter) reply(rw http.ResponseWriter) {
+	h := rw.Header()
+	for k, vs := range w.header {
+		h[k] = append([]string(nil), vs...)
+	}
+	rw.WriteHeader(w.code)
+	_, _ = rw.Write(w.body.Bytes())
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSingleflight(t *testing.T) {
+	const n = 5
+	var waiting, calls int32
+	filter := Singleflight(SingleflightKey(func(req *http.Request) string {
+		atomic.AddInt32(&waiting, 1)
+		return singleflightKey(req)
+	}))
+	h := filter(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		// 等待所有请求进入singleflight
+		for atomic.LoadInt32(&waiting) < n {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(10 * time.Millisecond)
+		w.Header().Set("X-Value", "kratos")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("hello"))
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/index?b=2&a=1", nil))
+			if w.Code != http.StatusAccepted || w.Body.String() != "hello" || w.Header().Get("X-Value") != "kratos" {
+				t.Errorf("unexpected reply: %d %s %v", w.Code, w.Body.String(), w.Header())
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("expect the handler to be called once, got %d", calls)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/index", nil))
+	if calls != 2 {
+		t.Errorf("expect the POST requests not to be coalesced, got %d", calls)
+	}
+}
+
+func TestSingleflightKey(t *testing.T) {
+	r1 := httptest.NewRequest(http.MethodGet, "/index?b=2&a=1", nil)
+	r2 := httptest.NewRequest(http.MethodGet, "/index?a=1&b=2", nil)
+	if singleflightKey(r1) != singleflightKey(r2) {
+		t.Errorf("expect the query to be normalized")
+	}
+	r2.Header.Set("Authorization", "Bearer token")
+	if singleflightKey(r1) == singleflightKey(r2) {
+		t.Errorf("expect the requests of different credentials not to be coalesced")
+	}
+}
@@ -0,0 +1,158 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFlusher is returned if the response writer can not be flushed, so it can not stream events.
+	ErrNotFlusher = errors.New("http: response writer does not implement http.Flusher")
+	// ErrSSEClosed is returned by Send after the SSEWriter is closed.
+	ErrSSEClosed = errors.New("http: sse writer is closed")
+)
+
+// SSEEvent is a server-sent event.
+type SSEEvent struct {
+	// ID is the event id, the client reconnects with it as Last-Event-ID.
+	ID string
+	// Event is the event type, empty means "message".
+	Event string
+	// Data is the event payload, multiple lines are sent as multiple data fields.
+	Data string
+	// Retry is the reconnection time of the client, zero means not set.
+	Retry time.Duration
+}
+
+// SSEOption is SSEWriter option.
+type SSEOption func(*SSEWriter)
+
+// SSEHeartbeat with the interval to send the heartbeat comments, it keeps the
+// idle connection alive through the proxies, zero disables it.
+func SSEHeartbeat(d time.Duration) SSEOption {
+	return func(w *SSEWriter) {
+		w.heartbeat = d
+	}
+}
+
+// SSEWriter writes server-sent events, every event is flushed immediately.
+// Note that the server timeout also applies to the stream.
+type SSEWriter struct {
+	ctx       context.Context
+	heartbeat time.Duration
+
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+	closed  chan struct{}
+	once    sync.Once
+}
+
+// NewSSEWriter writes the event stream headers and returns an SSEWriter,
+// the stream is done when the ctx is done, e.g. the client disconnected.
+func NewSSEWriter(ctx context.Context, w http.ResponseWriter, opts ...SSEOption) (*SSEWriter, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, ErrNotFlusher
+	}
+	sw := &SSEWriter{
+		ctx:     ctx,
+		w:       w,
+		flusher: flusher,
+		closed:  make(chan struct{}),
+	}
+	for _, o := range opts {
+		o(sw)
+	}
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	// 禁止nginx缓冲事件流
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	if sw.heartbeat > 0 {
+		go sw.keepalive()
+	}
+	return sw, nil
+}
+
+// Send writes the event and flushes it, it returns the ctx error if the stream is done,
+// the terminal events can still be sent once the server starts draining.
+func (w *SSEWriter) Send(e SSEEvent) error {
+	var buf bytes.Buffer
+	if e.ID != "" {
+		fmt.Fprintf(&buf, "id: %s\n", e.ID)
+	}
+	if e.Event != "" {
+		fmt.Fprintf(&buf, "event: %s\n", e.Event)
+	}
+	if e.Retry > 0 {
+		fmt.Fprintf(&buf, "retry: %d\n", e.Retry.Milliseconds())
+	}
+	for _, line := range strings.Split(e.Data, "\n") {
+		fmt.Fprintf(&buf, "data: %s\n", line)
+	}
+	buf.WriteByte('\n')
+	return w.write(buf.Bytes())
+}
+
+// Done returns a channel that's closed when the stream is done, e.g. the client
+// disconnected or the server is stopping.
+func (w *SSEWriter) Done() <-chan struct{} {
+	return w.ctx.Done()
+}
+
+// Close stops the heartbeats and the writes, it is called automatically
+// when the handler registered by the Router returns.
+func (w *SSEWriter) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.once.Do(func() {
+		close(w.closed)
+	})
+}
+
+func (w *SSEWriter) write(data []byte) error {
+	// 服务停止时，仍然可以发送结束事件
+	if err := DrainCause(w.ctx); err != nil && err != ErrDraining {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	select {
+	case <-w.closed:
+		return ErrSSEClosed
+	default:
+	}
+	if _, err := w.w.Write(data); err != nil {
+		return err
+	}
+	w.flusher.Flush()
+	return nil
+}
+
+// keepalive sends the heartbeat comments until the stream is done.
+func (w *SSEWriter) keepalive() {
+	ticker := time.NewTicker(w.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-w.closed:
+			return
+		case <-ticker.C:
+			if err := w.write([]byte(": ping\n\n")); err != nil {
+				return
+			}
+		}
+	}
+}
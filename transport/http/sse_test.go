@@ -0,0 +1,97 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSSEWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	ctx, cancel := context.WithCancel(context.Background())
+	w, err := NewSSEWriter(ctx, rec)
+	if err != nil {
+		t.Fatalf("expect %v, got %v", nil, err)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("expect %v, got %v", "text/event-stream", got)
+	}
+	if err = w.Send(SSEEvent{ID: "1", Event: "greeting", Data: "hello\nworld", Retry: time.Second}); err != nil {
+		t.Fatalf("expect %v, got %v", nil, err)
+	}
+	want := "id: 1\nevent: greeting\nretry: 1000\ndata: hello\ndata: world\n\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("expect %q, got %q", want, got)
+	}
+	if !rec.Flushed {
+		t.Errorf("expect the event to be flushed")
+	}
+
+	// the client disconnected
+	cancel()
+	if err = w.Send(SSEEvent{Data: "bye"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expect %v, got %v", context.Canceled, err)
+	}
+	w.Close()
+	if err = (&SSEWriter{ctx: context.Background(), closed: w.closed}).Send(SSEEvent{}); !errors.Is(err, ErrSSEClosed) {
+		t.Errorf("expect %v, got %v", ErrSSEClosed, err)
+	}
+}
+
+type noFlusher struct {
+	http.ResponseWriter
+}
+
+func TestSSEWriterNotFlusher(t *testing.T) {
+	if _, err := NewSSEWriter(context.Background(), noFlusher{httptest.NewRecorder()}); !errors.Is(err, ErrNotFlusher) {
+		t.Errorf("expect %v, got %v", ErrNotFlusher, err)
+	}
+}
+
+func TestContextSSE(t *testing.T) {
+	srv := NewServer(Timeout(0))
+	srv.Route("/").GET("/events", func(ctx Context) error {
+		w, err := ctx.SSE(SSEHeartbeat(time.Millisecond * 10))
+		if err != nil {
+			return err
+		}
+		time.Sleep(time.Millisecond * 30)
+		return w.Send(SSEEvent{Data: "done"})
+	})
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+	body := rec.Body.String()
+	if !strings.Contains(body, ": ping\n\n") {
+		t.Errorf("expect heartbeats, got %q", body)
+	}
+	if !strings.HasSuffix(body, "data: done\n\n") {
+		t.Errorf("expect the event, got %q", body)
+	}
+}
+
+// An SSE route is registered with the Router alongside the proto-generated routes,
+// e.g. v1.RegisterGreeterHTTPServer(srv, greeter).
+func ExampleSSEWriter() {
+	srv := NewServer(Timeout(0))
+	srv.Route("/").GET("/v1/events", func(ctx Context) error {
+		w, err := ctx.SSE(SSEHeartbeat(time.Second * 15))
+		if err != nil {
+			return err
+		}
+		for i := 0; ; i++ {
+			select {
+			case <-w.Done():
+				return nil
+			case <-time.After(time.Second):
+				if err := w.Send(SSEEvent{ID: fmt.Sprint(i), Data: "tick"}); err != nil {
+					return nil
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+// ErrStreamReply is returned by InvokeStream if the middleware replaced the response.
+var ErrStreamReply = errors.New("http: the reply of the stream is not the response")
+
+// InvokeStream makes a call like Invoke, but returns the raw response body and header
+// instead of decoding the body, e.g. for proxying large downloads without buffering them.
+// The body must be closed by the caller, the selector is reported once it is closed.
+// The timeout of the client does not apply since the body is read after the call returns,
+// use CallTimeout or the deadline of the context instead.
+func (client *Client) InvokeStream(ctx context.Context, method, path string, args interface{}, opts ...CallOption) (io.ReadCloser, http.Header, error) {
+	ctx, req, c, err := client.newRequest(ctx, method, path, args, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.stream = true
+	c.doneOnClose = true
+	h := func(ctx context.Context, in interface{}) (interface{}, error) {
+		res, err := client.do(req.WithContext(ctx), c)
+		if res != nil {
+			cs := csAttempt{res: res}
+			for _, o := range opts {
+				o.after(&c, &cs)
+			}
+		}
+		if err != nil {
+			if res != nil {
+				res.Body.Close()
+			}
+			return nil, err
+		}
+		return res, nil
+	}
+	var p selector.Peer
+	ctx = selector.NewPeerContext(ctx, &p)
+	if len(client.opts.middleware) > 0 {
+		h = middleware.Chain(client.opts.middleware...)(h)
+	}
+	reply, err := h(ctx, args)
+	res, ok := reply.(*http.Response)
+	if err != nil {
+		if ok {
+			res.Body.Close()
+		}
+		return nil, nil, err
+	}
+	if !ok {
+		return nil, nil, ErrStreamReply
+	}
+	return res.Body, res.Header, nil
+}
+
+// doneBody reports the bytes read and the read error once the body is closed.
+type doneBody struct {
+	io.ReadCloser
+	n    int64
+	err  error
+	once sync.Once
+	done func(n int64, err error)
+}
+
+func (b *doneBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	if err != nil && err != io.EOF {
+		b.err = err
+	}
+	return n, err
+}
+
+func (b *doneBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.done(b.n, b.err)
+	})
+	return err
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+type doneBalancer struct {
+	done  int32
+	size  int64
+	error error
+}
+
+func (b *doneBalancer) Pick(_ context.Context, nodes []selector.WeightedNode) (selector.WeightedNode, selector.DoneFunc, error) {
+	return nodes[0], func(_ context.Context, di selector.DoneInfo) {
+		b.size = di.ResponseSize
+		b.error = di.Err
+		atomic.AddInt32(&b.done, 1)
+	}, nil
+}
+
+func TestInvokeStream(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Name", "kratos")
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write([]byte("hello "))
+		w.(http.Flusher).Flush()
+		time.Sleep(10 * time.Millisecond)
+		_, _ = w.Write([]byte("stream"))
+	}))
+	defer ts.Close()
+	client, err := NewClient(context.Background(),
+		WithEndpoint("discovery:///kratos"),
+		WithDiscovery(newStaticDiscovery(ts.URL)),
+		WithBlock(),
+		WithTimeout(time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	b := &doneBalancer{}
+	body, header, err := client.InvokeStream(context.Background(), http.MethodGet, "/download", nil, Balancer(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Get("X-Name") != "kratos" {
+		t.Errorf("unexpected header: %v", header)
+	}
+	if atomic.LoadInt32(&b.done) != 0 {
+		t.Errorf("expect the selector not to be reported before the body is closed")
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello stream" {
+		t.Errorf("unexpected body: %q", data)
+	}
+	_ = body.Close()
+	_ = body.Close()
+	if atomic.LoadInt32(&b.done) != 1 || b.size != int64(len(data)) || b.error != nil {
+		t.Errorf("expect the selector to be reported once on close, got %d %d %v", b.done, b.size, b.error)
+	}
+}
@@ -0,0 +1,36 @@
+package http
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/selector/filter"
+)
+
+// applyTarget applies the query of the target to the client options, and returns
+// the selector builder of the target.
+func applyTarget(o *clientOptions, target *Target) (selector.Builder, error) {
+	q := target.Query
+	if v := q.Get("subset"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("[http client] invalid subset of the target: %s", o.endpoint)
+		}
+		o.subsetSize = size
+	}
+	if v := q.Get("version"); v != "" {
+		o.nodeFilters = append(o.nodeFilters[:len(o.nodeFilters):len(o.nodeFilters)], filter.Version(v))
+	}
+	if v := q.Get("selector"); v != "" {
+		builder, ok := selector.BuilderByName(v)
+		if !ok {
+			return nil, fmt.Errorf("[http client] unknown selector of the target: %s", o.endpoint)
+		}
+		return builder, nil
+	}
+	// 为目标服务单独注册的selector优先，注册时不带参数，否则使用GlobalSelector
+	endpoint, _, _ := strings.Cut(o.endpoint, "?")
+	return selector.BuilderFor(endpoint), nil
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/selector/p2c"
+	"github.com/go-kratos/kratos/v2/selector/wrr"
+)
+
+func TestApplyTarget(t *testing.T) {
+	builder := wrr.NewBuilder()
+	selector.RegisterBuilder("target_test", builder)
+
+	o := &clientOptions{endpoint: "discovery:///kratos?subset=3&version=v2&selector=target_test", subsetSize: 25}
+	target, err := parseTarget(o.endpoint, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target.Endpoint != "kratos" {
+		t.Errorf("unexpected endpoint: %s", target.Endpoint)
+	}
+	b, err := applyTarget(o, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b != builder || o.subsetSize != 3 || len(o.nodeFilters) != 1 {
+		t.Errorf("unexpected options: %v %d %d", b, o.subsetSize, len(o.nodeFilters))
+	}
+
+	for _, endpoint := range []string{
+		"discovery:///kratos?subset=-1",
+		"discovery:///kratos?subset=x",
+		"discovery:///kratos?selector=none",
+	} {
+		o = &clientOptions{endpoint: endpoint}
+		target, _ = parseTarget(endpoint, true)
+		if _, err = applyTarget(o, target); err == nil {
+			t.Errorf("%s: expect an error", endpoint)
+		}
+	}
+}
+
+func TestApplyTargetBuiltinBuilders(t *testing.T) {
+	for name, want := range map[string]selector.BalancerBuilder{
+		p2c.Name: &p2c.Builder{},
+		wrr.Name: &wrr.Builder{},
+	} {
+		o := &clientOptions{endpoint: "discovery:///kratos?selector=" + name}
+		target, _ := parseTarget(o.endpoint, true)
+		b, err := applyTarget(o, target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		db, ok := b.(*selector.DefaultBuilder)
+		if !ok || reflect.TypeOf(db.Balancer) != reflect.TypeOf(want) {
+			t.Errorf("%s: expect the builtin builder, got %v", name, b)
+		}
+	}
+}
+
+func TestApplyTargetBuilderFor(t *testing.T) {
+	builder := wrr.NewBuilder()
+	selector.RegisterBuilderFor("discovery:///target_test", builder)
+
+	o := &clientOptions{endpoint: "discovery:///target_test?subset=0"}
+	target, _ := parseTarget(o.endpoint, true)
+	b, err := applyTarget(o, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b != builder || o.subsetSize != 0 {
+		t.Errorf("expect the builder registered for the target, got %v %d", b, o.subsetSize)
+	}
+}
+
+func TestClientTargetSubset(t *testing.T) {
+	client, err := NewClient(context.Background(),
+		WithEndpoint("discovery:///kratos?subset=1"),
+		WithDiscovery(newStaticDiscovery("http://127.0.0.1:8001", "http://127.0.0.1:8002", "http://127.0.0.1:8003")),
+		WithBlock(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if client.r.subsetSize != 1 {
+		t.Errorf("expect the subset size 1, got %d", client.r.subsetSize)
+	}
+	if nodes := client.Inspect().Nodes; len(nodes) != 1 {
+		t.Errorf("expect 1 node in the subset, got %d", len(nodes))
+	}
+}
@@ -0,0 +1,29 @@
+package http
+
+import "net/http"
+
+// Trailer returns the trailer of the request, it is only available once the request body
+// is read to the end, e.g. after Bind.
+func (c *wrapper) Trailer() http.Header {
+	return c.req.Trailer
+}
+
+// SetTrailer sets the trailer sent after the response body, e.g. the checksum or the
+// status computed while streaming. It can be called before or after the body is written,
+// but the trailers are not sent if the Content-Length header is set.
+func (c *wrapper) SetTrailer(key, value string) {
+	c.res.Header().Set(http.TrailerPrefix+key, value)
+}
+
+// replyMD is the reply metadata of the selector, the trailer overrides the header.
+type replyMD struct {
+	header  http.Header
+	trailer http.Header
+}
+
+func (md replyMD) Get(key string) string {
+	if v := md.trailer.Get(key); v != "" {
+		return v
+	}
+	return md.header.Get(key)
+}
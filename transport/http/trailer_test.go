@@ -0,0 +1,93 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+type replyMDBalancer struct {
+	md selector.ReplyMD
+}
+
+func (b *replyMDBalancer) Pick(_ context.Context, nodes []selector.WeightedNode) (selector.WeightedNode, selector.DoneFunc, error) {
+	return nodes[0], func(_ context.Context, di selector.DoneInfo) {
+		b.md = di.ReplyMD
+	}, nil
+}
+
+func TestContextTrailer(t *testing.T) {
+	srv := NewServer()
+	srv.Route("/").POST("/trailer", func(ctx Context) error {
+		_, _ = io.ReadAll(ctx.Request().Body)
+		ctx.SetTrailer("X-Checksum", ctx.Trailer().Get("X-Checksum"))
+		return ctx.String(http.StatusOK, "hello")
+	})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/trailer", io.NopCloser(strings.NewReader("body")))
+	req.Trailer = http.Header{"X-Checksum": []string{"abc"}}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "hello" {
+		t.Errorf("unexpected body: %s", body)
+	}
+	if v := resp.Trailer.Get("X-Checksum"); v != "abc" {
+		t.Errorf("expect the trailer abc, got %q", v)
+	}
+}
+
+func TestClientTrailer(t *testing.T) {
+	srv := NewServer()
+	srv.Route("/").GET("/trailer", func(ctx Context) error {
+		ctx.SetTrailer("X-Load", "10")
+		return ctx.Result(http.StatusOK, map[string]string{"name": "kratos"})
+	})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	client, err := NewClient(context.Background(),
+		WithEndpoint("discovery:///kratos"),
+		WithDiscovery(newStaticDiscovery(ts.URL)),
+		WithBlock(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	b := &replyMDBalancer{}
+	var trailer http.Header
+	var reply map[string]string
+	if err = client.Invoke(context.Background(), http.MethodGet, "/trailer", nil, &reply, Trailer(&trailer), Balancer(b)); err != nil {
+		t.Fatal(err)
+	}
+	if reply["name"] != "kratos" {
+		t.Errorf("unexpected reply: %v", reply)
+	}
+	if v := trailer.Get("X-Load"); v != "10" {
+		t.Errorf("expect the trailer 10, got %q", v)
+	}
+	if b.md == nil || b.md.Get("X-Load") != "10" || b.md.Get("Content-Type") == "" {
+		t.Errorf("expect the trailer and the header in the reply md, got %v", b.md)
+	}
+}
+
+func TestReplyMD(t *testing.T) {
+	md := replyMD{
+		header:  http.Header{"X-Load": []string{"1"}, "X-Name": []string{"kratos"}},
+		trailer: http.Header{"X-Load": []string{"2"}},
+	}
+	if md.Get("X-Load") != "2" || md.Get("X-Name") != "kratos" || md.Get("X-None") != "" {
+		t.Errorf("unexpected reply md: %v", md)
+	}
+}
@@ -0,0 +1,323 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// Transcode serves the RESTful JSON of the methods annotated by google.api.http of the
+// services, without the generated http stubs. The requests are bound like the generated
+// handlers, and dispatched to the gRPC server in process, e.g. the Server of
+// transport/grpc, so the services implemented once are served by both transports. The
+// descriptors are looked up in the files, e.g. protoregistry.GlobalFiles for the linked
+// protos, or the ones loaded by FilesFromDescriptorSet. The middleware of the server
+// applies with the operation of the gRPC method.
+func (s *Server) Transcode(grpcSrv http.Handler, files *protoregistry.Files, services ...string) error {
+	if files == nil {
+		files = protoregistry.GlobalFiles
+	}
+	r := s.Route("/")
+	for _, name := range services {
+		d, err := files.FindDescriptorByName(protoreflect.FullName(name))
+		if err != nil {
+			return err
+		}
+		sd, ok := d.(protoreflect.ServiceDescriptor)
+		if !ok {
+			return fmt.Errorf("http: %s is not a service", name)
+		}
+		methods := sd.Methods()
+		for i := 0; i < methods.Len(); i++ {
+			md := methods.Get(i)
+			if md.IsStreamingClient() || md.IsStreamingServer() {
+				continue
+			}
+			rule, ok := httpRule(md)
+			if !ok {
+				continue
+			}
+			for _, b := range append([]*annotations.HttpRule{rule}, rule.AdditionalBindings...) {
+				method, path := rulePattern(b)
+				if path == "" {
+					continue
+				}
+				r.Handle(method, transcodePath(path), transcodeHandler(grpcSrv, md, b))
+			}
+		}
+	}
+	return nil
+}
+
+// FilesFromDescriptorSet returns the files of the serialized FileDescriptorSet, e.g. the
+// output of protoc --descriptor_set_out --include_imports.
+func FilesFromDescriptorSet(b []byte) (*protoregistry.Files, error) {
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(b, &set); err != nil {
+		return nil, err
+	}
+	return protodesc.NewFiles(&set)
+}
+
+// httpRule returns the google.api.http option of the method.
+func httpRule(md protoreflect.MethodDescriptor) (*annotations.HttpRule, bool) {
+	opts, ok := md.Options().(*descriptorpb.MethodOptions)
+	if !ok || opts == nil {
+		return nil, false
+	}
+	// 动态加载的描述中，扩展可能是未解析的字段，重新解析一次
+	if !proto.HasExtension(opts, annotations.E_Http) {
+		b, err := proto.Marshal(opts)
+		if err != nil {
+			return nil, false
+		}
+		opts = &descriptorpb.MethodOptions{}
+		if err = proto.Unmarshal(b, opts); err != nil || !proto.HasExtension(opts, annotations.E_Http) {
+			return nil, false
+		}
+	}
+	rule, ok := proto.GetExtension(opts, annotations.E_Http).(*annotations.HttpRule)
+	return rule, ok && rule != nil
+}
+
+func rulePattern(rule *annotations.HttpRule) (method, path string) {
+	switch p := rule.Pattern.(type) {
+	case *annotations.HttpRule_Get:
+		return http.MethodGet, p.Get
+	case *annotations.HttpRule_Put:
+		return http.MethodPut, p.Put
+	case *annotations.HttpRule_Post:
+		return http.MethodPost, p.Post
+	case *annotations.HttpRule_Delete:
+		return http.MethodDelete, p.Delete
+	case *annotations.HttpRule_Patch:
+		return http.MethodPatch, p.Patch
+	case *annotations.HttpRule_Custom:
+		return p.Custom.GetKind(), p.Custom.GetPath()
+	}
+	return "", ""
+}
+
+var pathVarPattern = regexp.MustCompile(`{([a-zA-Z0-9_.\s]*)=([^{}]*)}`)
+
+// transcodePath converts the path template to the route, e.g. "/v1/{name=messages/*}"
+// to "/v1/{name:messages/.*}", like protoc-gen-go-http.
+func transcodePath(path string) string {
+	return pathVarPattern.ReplaceAllStringFunc(path, func(s string) string {
+		m := pathVarPattern.FindStringSubmatch(s)
+		return "{" + strings.TrimSpace(m[1]) + ":" + strings.ReplaceAll(m[2], "*", ".*") + "}"
+	})
+}
+
+func transcodeHandler(grpcSrv http.Handler, md protoreflect.MethodDescriptor, rule *annotations.HttpRule) HandlerFunc {
+	operation := "/" + string(md.Parent().FullName()) + "/" + string(md.Name())
+	return func(ctx Context) error {
+		in := dynamicpb.NewMessage(md.Input())
+		switch rule.Body {
+		case "":
+		case "*":
+			if err := ctx.Bind(in); err != nil {
+				return err
+			}
+		default:
+			fd := md.Input().Fields().ByName(protoreflect.Name(rule.Body))
+			if fd == nil || fd.Message() == nil || fd.IsList() || fd.IsMap() {
+				return errors.InternalServer("CODEC", fmt.Sprintf("unsupported body field %s", rule.Body))
+			}
+			if err := ctx.Bind(in.Mutable(fd).Message().Interface()); err != nil {
+				return err
+			}
+		}
+		if err := ctx.BindQuery(in); err != nil {
+			return err
+		}
+		if err := ctx.BindVars(in); err != nil {
+			return err
+		}
+		SetOperation(ctx, operation)
+		h := ctx.Middleware(func(c context.Context, req interface{}) (interface{}, error) {
+			out := dynamicpb.NewMessage(md.Output())
+			header, err := invokeGRPC(c, grpcSrv, operation, ctx.Request().Header, req.(proto.Message), out)
+			for k, vs := range header {
+				ctx.Response().Header()[k] = vs
+			}
+			return out, err
+		})
+		// 使用请求的ctx，Context会被放回池中复用，gRPC服务端在调用返回后仍可能访问它
+		out, err := h(ctx.Request().Context(), in)
+		if err != nil {
+			return err
+		}
+		reply := out.(proto.Message)
+		if rule.ResponseBody != "" {
+			fd := reply.ProtoReflect().Descriptor().Fields().ByName(protoreflect.Name(rule.ResponseBody))
+			if fd == nil {
+				return errors.InternalServer("CODEC", fmt.Sprintf("unknown response body field %s", rule.ResponseBody))
+			}
+			v := reply.ProtoReflect().Get(fd)
+			if fd.Message() != nil && !fd.IsList() && !fd.IsMap() {
+				return ctx.Result(200, v.Message().Interface())
+			}
+			return ctx.Result(200, v.Interface())
+		}
+		return ctx.Result(200, reply)
+	}
+}
+
+// invokeGRPC calls the method of the gRPC server in process, the reply header of the
+// call is returned.
+func invokeGRPC(ctx context.Context, grpcSrv http.Handler, method string, header http.Header, in, out proto.Message) (http.Header, error) {
+	b, err := proto.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+	frame := make([]byte, 5, 5+len(b))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(b)))
+	frame = append(frame, b...)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, method, bytes.NewReader(frame))
+	if err != nil {
+		return nil, err
+	}
+	// gRPC服务端只接受HTTP/2的请求，请求头作为metadata传递
+	req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/2", 2, 0
+	for k, vs := range header {
+		switch k {
+		case "Content-Type", "Content-Length", "Connection", "Te", "Accept-Encoding", "Grpc-Encoding", "Grpc-Accept-Encoding":
+			continue
+		}
+		req.Header[k] = vs
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	w := &transcodeWriter{header: make(http.Header)}
+	grpcSrv.ServeHTTP(w, req)
+
+	if w.code != 0 && w.code != http.StatusOK {
+		return nil, errors.New(w.code, "GRPC", strings.TrimSpace(w.body.String()))
+	}
+	if err = w.status(); err != nil {
+		return w.replyHeader(), err
+	}
+	body := w.body.Bytes()
+	if len(body) < 5 || int(binary.BigEndian.Uint32(body[1:5])) != len(body)-5 {
+		return nil, errors.InternalServer("CODEC", "malformed gRPC response")
+	}
+	if body[0] != 0 {
+		return nil, errors.InternalServer("CODEC", "compressed gRPC response is not supported")
+	}
+	if err = proto.Unmarshal(body[5:], out); err != nil {
+		return nil, errors.InternalServer("CODEC", err.Error())
+	}
+	return w.replyHeader(), nil
+}
+
+// transcodeWriter records the gRPC response in memory.
+type transcodeWriter struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (w *transcodeWriter) Header() http.Header { return w.header }
+
+func (w *transcodeWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *transcodeWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(p)
+}
+
+func (w *transcodeWriter) Flush() {}
+
+// trailer returns the trailer declared or set by the http.TrailerPrefix.
+func (w *transcodeWriter) trailer(k string) string {
+	if v := w.header.Get(http.TrailerPrefix + k); v != "" {
+		return v
+	}
+	return w.header.Get(k)
+}
+
+// status returns the error of the grpc-status trailers.
+func (w *transcodeWriter) status() error {
+	code, err := strconv.Atoi(w.trailer("Grpc-Status"))
+	if err != nil {
+		return errors.InternalServer("CODEC", "missing gRPC status")
+	}
+	if codes.Code(code) == codes.OK {
+		return nil
+	}
+	if v := w.trailer("Grpc-Status-Details-Bin"); v != "" {
+		enc := base64.StdEncoding
+		if len(v)%4 != 0 {
+			enc = base64.RawStdEncoding
+		}
+		var sp spb.Status
+		if b, err := enc.DecodeString(v); err == nil && proto.Unmarshal(b, &sp) == nil {
+			return errors.FromError(status.ErrorProto(&sp))
+		}
+	}
+	msg := w.trailer("Grpc-Message")
+	if m, err := decodeGRPCMessage(msg); err == nil {
+		msg = m
+	}
+	return errors.FromError(status.Error(codes.Code(code), msg))
+}
+
+// replyHeader returns the header of the gRPC response except the gRPC ones.
+func (w *transcodeWriter) replyHeader() http.Header {
+	trailers := make(map[string]bool)
+	for _, k := range w.header.Values("Trailer") {
+		trailers[http.CanonicalHeaderKey(k)] = true
+	}
+	h := make(http.Header)
+	for k, vs := range w.header {
+		if k == "Content-Type" || k == "Trailer" || trailers[k] || strings.HasPrefix(k, "Grpc-") || strings.HasPrefix(k, http.TrailerPrefix) {
+			continue
+		}
+		h[k] = vs
+	}
+	return h
+}
+
+// decodeGRPCMessage decodes the percent encoded grpc-message.
+func decodeGRPCMessage(msg string) (string, error) {
+	if !strings.Contains(msg, "%") {
+		return msg, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		if msg[i] == '%' && i+2 < len(msg) {
+			n, err := strconv.ParseUint(msg[i+1:i+3], 16, 8)
+			if err != nil {
+				return "", err
+			}
+			b.WriteByte(byte(n))
+			i += 2
+			continue
+		}
+		b.WriteByte(msg[i])
+	}
+	return b.String(), nil
+}
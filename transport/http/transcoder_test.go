@@ -0,0 +1,175 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+func transcodeDescriptorSet(t *testing.T) []byte {
+	method := func(name string, rule *annotations.HttpRule) *descriptorpb.MethodDescriptorProto {
+		opts := &descriptorpb.MethodOptions{}
+		proto.SetExtension(opts, annotations.E_Http, rule)
+		return &descriptorpb.MethodDescriptorProto{
+			Name:       proto.String(name),
+			InputType:  proto.String(".transcode.test.EchoRequest"),
+			OutputType: proto.String(".transcode.test.EchoReply"),
+			Options:    opts,
+		}
+	}
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     typ.Enum(),
+		}
+	}
+	fd := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("transcode_test.proto"),
+		Package: proto.String("transcode.test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("EchoRequest"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("times", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+			},
+		}, {
+			Name:  proto.String("EchoReply"),
+			Field: []*descriptorpb.FieldDescriptorProto{field("message", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING)},
+		}},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Echo"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				method("Echo", &annotations.HttpRule{
+					Pattern: &annotations.HttpRule_Get{Get: "/v1/echo/{name}"},
+					AdditionalBindings: []*annotations.HttpRule{
+						{Pattern: &annotations.HttpRule_Post{Post: "/v1/echo"}, Body: "*"},
+					},
+				}),
+				method("Fail", &annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: "/v1/fail/{name=users/*}"}}),
+			},
+		}},
+	}
+	b, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{fd}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestTranscode(t *testing.T) {
+	files, err := FilesFromDescriptorSet(transcodeDescriptorSet(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, _ := files.FindDescriptorByName("transcode.test.EchoRequest")
+	reqDesc := d.(protoreflect.MessageDescriptor)
+	d, _ = files.FindDescriptorByName("transcode.test.EchoReply")
+	replyDesc := d.(protoreflect.MessageDescriptor)
+
+	handler := func(name string, fn func(in *dynamicpb.Message) (interface{}, error)) grpc.MethodDesc {
+		return grpc.MethodDesc{
+			MethodName: name,
+			Handler: func(_ interface{}, _ context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := dynamicpb.NewMessage(reqDesc)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				return fn(in)
+			},
+		}
+	}
+	gs := grpc.NewServer()
+	gs.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "transcode.test.Echo",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{
+			handler("Echo", func(in *dynamicpb.Message) (interface{}, error) {
+				name := in.Get(reqDesc.Fields().ByName("name")).String()
+				times := in.Get(reqDesc.Fields().ByName("times")).Int()
+				reply := dynamicpb.NewMessage(replyDesc)
+				reply.Set(replyDesc.Fields().ByName("message"), protoreflect.ValueOfString(strings.Repeat("hello "+name+";", int(times))))
+				return reply, nil
+			}),
+			handler("Fail", func(in *dynamicpb.Message) (interface{}, error) {
+				return nil, errors.NotFound("USER_NOT_FOUND", in.Get(reqDesc.Fields().ByName("name")).String())
+			}),
+		},
+	}, nil)
+
+	var operation string
+	srv := NewServer(Middleware(func(h middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				operation = tr.Operation()
+			}
+			return h(ctx, req)
+		}
+	}))
+	if err = srv.Transcode(gs, files, "transcode.test.Echo"); err != nil {
+		t.Fatal(err)
+	}
+	if err = srv.Transcode(gs, files, "transcode.test.EchoRequest"); err == nil {
+		t.Error("expect the error of the non service")
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	call := func(method, path, body string) (int, map[string]interface{}) {
+		req, _ := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		b, _ := io.ReadAll(res.Body)
+		m := make(map[string]interface{})
+		if err := json.Unmarshal(b, &m); err != nil {
+			t.Fatalf("%s %s: %s", method, path, b)
+		}
+		return res.StatusCode, m
+	}
+	if code, m := call(http.MethodGet, "/v1/echo/kratos?times=2", ""); code != 200 || m["message"] != "hello kratos;hello kratos;" {
+		t.Errorf("unexpected reply %d %v", code, m)
+	}
+	if operation != "/transcode.test.Echo/Echo" {
+		t.Errorf("unexpected operation %s", operation)
+	}
+	if code, m := call(http.MethodPost, "/v1/echo", `{"name":"go","times":1}`); code != 200 || m["message"] != "hello go;" {
+		t.Errorf("unexpected reply %d %v", code, m)
+	}
+	if code, m := call(http.MethodGet, "/v1/fail/users/1", ""); code != 404 || m["reason"] != "USER_NOT_FOUND" || m["message"] != "users/1" {
+		t.Errorf("unexpected reply %d %v", code, m)
+	}
+}
+
+func TestTranscodePath(t *testing.T) {
+	for path, want := range map[string]string{
+		"/v1/echo/{name}":                 "/v1/echo/{name}",
+		"/v1/{name=messages/*}":           "/v1/{name:messages/.*}",
+		"/v1/{book.name=shelves/*/books}": "/v1/{book.name:shelves/.*/books}",
+	} {
+		if got := transcodePath(path); got != want {
+			t.Errorf("%s: expect %s, got %s", path, want, got)
+		}
+	}
+}
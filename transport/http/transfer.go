@@ -0,0 +1,212 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrChecksumMismatch is returned by Download if the checksum of the body mismatches.
+var ErrChecksumMismatch = errors.New("http: checksum mismatch")
+
+// Progress reports the bytes transferred and the total bytes, the total is -1 if it is unknown.
+type Progress func(transferred, total int64)
+
+// TransferOption is the option of Client.Upload and Client.Download.
+type TransferOption func(*transferOptions)
+
+type transferOptions struct {
+	progress Progress
+	hash     hash.Hash
+	sum      []byte
+	offset   int64
+	fields   map[string]string
+	callOpts []CallOption
+}
+
+// TransferProgress with the progress callback, it is called as the body is transferred.
+func TransferProgress(fn Progress) TransferOption {
+	return func(o *transferOptions) {
+		o.progress = fn
+	}
+}
+
+// TransferChecksum with the hash and the expected sum of the downloaded body, Download
+// returns ErrChecksumMismatch if they mismatch. The hash of a resumed download should be
+// fed with the bytes before the offset.
+func TransferChecksum(h hash.Hash, sum []byte) TransferOption {
+	return func(o *transferOptions) {
+		o.hash = h
+		o.sum = sum
+	}
+}
+
+// TransferOffset with the offset to resume the download from, it is requested by the
+// Range header, and the bytes before it are skipped if the server ignores the range.
+func TransferOffset(n int64) TransferOption {
+	return func(o *transferOptions) {
+		o.offset = n
+	}
+}
+
+// TransferFields with the form fields sent before the uploaded file.
+func TransferFields(fields map[string]string) TransferOption {
+	return func(o *transferOptions) {
+		o.fields = fields
+	}
+}
+
+// TransferCallOptions with the call options of the transfer.
+func TransferCallOptions(opts ...CallOption) TransferOption {
+	return func(o *transferOptions) {
+		o.callOpts = opts
+	}
+}
+
+// UploadFile is the file uploaded by Client.Upload.
+type UploadFile struct {
+	// Field is the form field of the file, default is "file".
+	Field  string
+	Name   string
+	Reader io.Reader
+	// Size is the total bytes reported to the progress, it is unknown if not positive.
+	Size int64
+}
+
+// Upload uploads the file as a multipart form by POST to the path, and decodes the
+// response into reply. The form is streamed as it is encoded, so the call is not retried.
+func (client *Client) Upload(ctx context.Context, path string, file UploadFile, reply interface{}, opts ...TransferOption) error {
+	o := &transferOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	c, err := newCallInfo(path, o.callOpts)
+	if err != nil {
+		return err
+	}
+	pr, pw := io.Pipe()
+	// 请求失败时，结束编码表单的goroutine
+	defer pr.Close()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		pw.CloseWithError(writeUploadForm(mw, file, o))
+	}()
+	ctx, req, err := client.buildRequest(ctx, http.MethodPost, path, mw.FormDataContentType(), pr, c)
+	if err != nil {
+		return err
+	}
+	return client.invoke(ctx, req, nil, reply, c, o.callOpts...)
+}
+
+func writeUploadForm(mw *multipart.Writer, file UploadFile, o *transferOptions) error {
+	keys := make([]string, 0, len(o.fields))
+	for k := range o.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := mw.WriteField(k, o.fields[k]); err != nil {
+			return err
+		}
+	}
+	field := file.Field
+	if field == "" {
+		field = "file"
+	}
+	part, err := mw.CreateFormFile(field, file.Name)
+	if err != nil {
+		return err
+	}
+	total := file.Size
+	if total <= 0 {
+		total = -1
+	}
+	if _, err = io.Copy(part, &progressReader{r: file.Reader, total: total, fn: o.progress}); err != nil {
+		return err
+	}
+	return mw.Close()
+}
+
+// Download downloads the response body of GET the path to w without buffering it, and
+// returns the bytes written. It resumes from TransferOffset, and verifies TransferChecksum.
+func (client *Client) Download(ctx context.Context, path string, w io.Writer, opts ...TransferOption) (int64, error) {
+	o := &transferOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	callOpts := o.callOpts
+	if o.offset > 0 {
+		rng := http.Header{"Range": []string{fmt.Sprintf("bytes=%d-", o.offset)}}
+		callOpts = append(callOpts[:len(callOpts):len(callOpts)], RequestHeader(rng))
+	}
+	body, header, err := client.InvokeStream(ctx, http.MethodGet, path, nil, callOpts...)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+	transferred := int64(0)
+	if o.offset > 0 {
+		if header.Get("Content-Range") == "" {
+			// 服务端不支持Range，跳过已下载的部分
+			if _, err = io.CopyN(io.Discard, body, o.offset); err != nil {
+				return 0, err
+			}
+		}
+		transferred = o.offset
+	}
+	if o.hash != nil {
+		w = io.MultiWriter(w, o.hash)
+	}
+	n, err := io.Copy(w, &progressReader{r: body, n: transferred, total: downloadTotal(header), fn: o.progress})
+	if err != nil {
+		return n, err
+	}
+	if o.hash != nil && !bytes.Equal(o.hash.Sum(nil), o.sum) {
+		return n, ErrChecksumMismatch
+	}
+	return n, nil
+}
+
+// downloadTotal returns the total bytes of the download by the Content-Range, e.g.
+// bytes 100-199/200, or the Content-Length, -1 if it is unknown.
+func downloadTotal(header http.Header) int64 {
+	if cr := header.Get("Content-Range"); cr != "" {
+		if i := strings.LastIndexByte(cr, '/'); i >= 0 {
+			if n, err := strconv.ParseInt(cr[i+1:], 10, 64); err == nil {
+				return n
+			}
+		}
+		return -1
+	}
+	if n, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64); err == nil {
+		return n
+	}
+	return -1
+}
+
+// progressReader reports the progress as it is read.
+type progressReader struct {
+	r     io.Reader
+	n     int64
+	total int64
+	fn    Progress
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.n += int64(n)
+		if r.fn != nil {
+			r.fn(r.n, r.total)
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUpload(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, fh, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(f)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"name": fh.Filename,
+			"data": string(data),
+			"kind": r.FormValue("kind"),
+		})
+	}))
+	defer ts.Close()
+	client, err := NewClient(context.Background(), WithEndpoint(ts.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var transferred, total int64
+	var reply map[string]string
+	err = client.Upload(context.Background(), "/upload", UploadFile{
+		Name:   "hello.txt",
+		Reader: strings.NewReader("hello kratos"),
+		Size:   12,
+	}, &reply,
+		TransferFields(map[string]string{"kind": "text"}),
+		TransferProgress(func(n, t int64) { transferred, total = n, t }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply["name"] != "hello.txt" || reply["data"] != "hello kratos" || reply["kind"] != "text" {
+		t.Errorf("unexpected reply: %v", reply)
+	}
+	if transferred != 12 || total != 12 {
+		t.Errorf("unexpected progress: %d/%d", transferred, total)
+	}
+}
+
+func TestDownload(t *testing.T) {
+	content := []byte("hello kratos download")
+	sum := sha256.Sum256(content)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/norange" {
+			_, _ = w.Write(content)
+			return
+		}
+		http.ServeContent(w, r, "file.txt", time.Time{}, bytes.NewReader(content))
+	}))
+	defer ts.Close()
+	client, err := NewClient(context.Background(), WithEndpoint(ts.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	var transferred, total int64
+	n, err := client.Download(context.Background(), "/file", &buf,
+		TransferChecksum(sha256.New(), sum[:]),
+		TransferProgress(func(n, t int64) { transferred, total = n, t }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(content)) || buf.String() != string(content) || transferred != n || total != n {
+		t.Errorf("unexpected download: %d %q %d/%d", n, buf.String(), transferred, total)
+	}
+
+	buf.Reset()
+	if _, err = client.Download(context.Background(), "/file", &buf, TransferChecksum(sha256.New(), []byte("bad"))); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("expect ErrChecksumMismatch, got %v", err)
+	}
+
+	for _, path := range []string{"/file", "/norange"} {
+		buf.Reset()
+		h := sha256.New()
+		h.Write(content[:6])
+		n, err = client.Download(context.Background(), path, &buf,
+			TransferOffset(6),
+			TransferChecksum(h, sum[:]),
+			TransferProgress(func(n, t int64) { transferred, total = n, t }),
+		)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if buf.String() != string(content[6:]) || n != int64(len(content)-6) {
+			t.Errorf("%s: unexpected resumed download: %q", path, buf.String())
+		}
+		if transferred != int64(len(content)) || total != int64(len(content)) {
+			t.Errorf("%s: unexpected progress: %d/%d", path, transferred, total)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// drainPollInterval is the interval checking whether the websocket connections are finished.
+const drainPollInterval = 10 * time.Millisecond
+
+// ErrNotHijacker is returned if the response writer can not be hijacked, so it can not be upgraded.
+var ErrNotHijacker = errors.New("http: response writer does not implement http.Hijacker")
+
+// ErrCrossOrigin is returned by the default handshake if the Origin is another host.
+var ErrCrossOrigin = errors.New("http: websocket origin is not the host")
+
+// WebSocketHandler handles a websocket connection, the Context carries the
+// transport and the metadata extracted by the server middleware.
+type WebSocketHandler func(Context, *websocket.Conn)
+
+// WebSocketOption is websocket option.
+type WebSocketOption func(*websocket.Server)
+
+// WebSocketHandshake with the handshake func, e.g. checking the Origin header
+// with websocket.Origin against the allowed sites. By default, the requests with
+// an Origin of another host are rejected with 403, preventing the cross-site
+// websocket hijacking by the cookies of the users.
+func WebSocketHandshake(fn func(*websocket.Config, *http.Request) error) WebSocketOption {
+	return func(s *websocket.Server) {
+		s.Handshake = fn
+	}
+}
+
+// WebSocket returns a HandlerFunc upgrading the request to websocket inside
+// the filter chain and the server middleware, e.g. r.GET("/ws", WebSocket(h)).
+// The connections are closed with close frames when the server stops.
+func WebSocket(h WebSocketHandler, opts ...WebSocketOption) HandlerFunc {
+	return func(ctx Context) error {
+		if _, ok := ctx.Response().(http.Hijacker); !ok {
+			return ErrNotHijacker
+		}
+		var srv *Server
+		if w, ok := ctx.(*wrapper); ok {
+			srv = w.router.srv
+		}
+		ws := websocket.Server{
+			Handshake: sameOrigin,
+			Handler: func(conn *websocket.Conn) {
+				if srv != nil {
+					srv.trackWebSocket(conn, true)
+					defer srv.trackWebSocket(conn, false)
+				}
+				h(ctx, conn)
+			},
+		}
+		for _, o := range opts {
+			o(&ws)
+		}
+		next := func(c context.Context, _ interface{}) (interface{}, error) {
+			// 使用中间件处理后的ctx（如metadata）
+			ctx.Reset(ctx.Response(), ctx.Request().WithContext(c))
+			ws.ServeHTTP(ctx.Response(), ctx.Request())
+			return nil, nil
+		}
+		// 使用请求的ctx，避免Reset之后ctx的父级指向自身
+		_, err := ctx.Middleware(next)(ctx.Request().Context(), nil)
+		return err
+	}
+}
+
+// sameOrigin rejects the Origin of another host, the requests without the Origin,
+// e.g. of the non-browser clients, are allowed.
+func sameOrigin(config *websocket.Config, req *http.Request) error {
+	if req.Header.Get("Origin") == "" {
+		return nil
+	}
+	origin, err := websocket.Origin(config, req)
+	if err != nil {
+		return err
+	}
+	if origin == nil || !strings.EqualFold(origin.Host, req.Host) {
+		return ErrCrossOrigin
+	}
+	return nil
+}
+
+// trackWebSocket tracks the websocket connections, they are hijacked so the
+// http.Server does not close them on Shutdown.
+func (s *Server) trackWebSocket(conn *websocket.Conn, add bool) {
+	s.wsMu.Lock()
+	defer s.wsMu.Unlock()
+	if !add {
+		delete(s.wsConns, conn)
+		return
+	}
+	if s.wsConns == nil {
+		s.wsConns = make(map[*websocket.Conn]struct{})
+	}
+	s.wsConns[conn] = struct{}{}
+}
+
+// drainWebSockets waits for the websocket connections to finish in the drain grace
+// period, and then closes them.
+func (s *Server) drainWebSockets() {
+	if s.drainGrace > 0 {
+		timer := time.NewTimer(s.drainGrace)
+		defer timer.Stop()
+		ticker := time.NewTicker(drainPollInterval)
+		defer ticker.Stop()
+		for s.webSockets() > 0 {
+			select {
+			case <-timer.C:
+				s.closeWebSockets()
+				return
+			case <-ticker.C:
+			}
+		}
+	}
+	s.closeWebSockets()
+}
+
+func (s *Server) webSockets() int {
+	s.wsMu.Lock()
+	defer s.wsMu.Unlock()
+	return len(s.wsConns)
+}
+
+// closeWebSockets closes the websocket connections with close frames.
+func (s *Server) closeWebSockets() {
+	s.wsMu.Lock()
+	defer s.wsMu.Unlock()
+	for conn := range s.wsConns {
+		_ = conn.Close()
+	}
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/go-kratos/kratos/v2/metadata"
+	mmd "github.com/go-kratos/kratos/v2/middleware/metadata"
+)
+
+func TestWebSocket(t *testing.T) {
+	srv := NewServer(Timeout(0), Middleware(mmd.Server()))
+	srv.Route("/").GET("/ws", WebSocket(func(ctx Context, conn *websocket.Conn) {
+		md, _ := metadata.FromServerContext(ctx)
+		_ = websocket.Message.Send(conn, md.Get("x-md-global-name"))
+		var msg string
+		for websocket.Message.Receive(conn, &msg) == nil {
+			_ = websocket.Message.Send(conn, msg)
+		}
+	}))
+	ctx := context.Background()
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Millisecond * 100)
+	config, err := websocket.NewConfig("ws://"+e.Host+"/ws", "http://"+e.Host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Header = http.Header{"X-Md-Global-Name": []string{"kratos"}}
+	conn, err := websocket.DialConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var msg string
+	if err = websocket.Message.Receive(conn, &msg); err != nil || msg != "kratos" {
+		t.Errorf("expect %v, got %v %v", "kratos", msg, err)
+	}
+	if err = websocket.Message.Send(conn, "ping"); err != nil {
+		t.Fatal(err)
+	}
+	if err = websocket.Message.Receive(conn, &msg); err != nil || msg != "ping" {
+		t.Errorf("expect %v, got %v %v", "ping", msg, err)
+	}
+
+	// the connection is closed on Stop
+	if err = srv.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	if err = websocket.Message.Receive(conn, &msg); !errors.Is(err, io.EOF) {
+		t.Errorf("expect %v, got %v", io.EOF, err)
+	}
+}
+
+func TestWebSocketNotHijacker(t *testing.T) {
+	srv := NewServer()
+	srv.Route("/").GET("/ws", WebSocket(func(Context, *websocket.Conn) {}))
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ws", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expect %v, got %v", http.StatusInternalServerError, rec.Code)
+	}
+}
+
+func TestWebSocketOrigin(t *testing.T) {
+	srv := NewServer(Timeout(0))
+	srv.Route("/").GET("/ws", WebSocket(func(ctx Context, conn *websocket.Conn) {
+		_ = websocket.Message.Send(conn, "hello")
+	}))
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	u, _ := url.Parse(ts.URL)
+
+	if _, err := websocket.Dial("ws://"+u.Host+"/ws", "", "http://evil.example.com"); err == nil {
+		t.Error("expect the cross-site origin rejected")
+	}
+	conn, err := websocket.Dial("ws://"+u.Host+"/ws", "", ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var msg string
+	if err = websocket.Message.Receive(conn, &msg); err != nil || msg != "hello" {
+		t.Errorf("expect hello, got %v %v", msg, err)
+	}
+}
@@ -0,0 +1,49 @@
+package transport
+
+import (
+	"context"
+	"crypto/x509"
+)
+
+// Identity is the identity of the peer verified by its TLS certificate.
+type Identity struct {
+	// SPIFFEID is the spiffe:// URI SAN, e.g. spiffe://example.org/ns/default/sa/api
+	SPIFFEID string
+	// CommonName is the CN of the subject.
+	CommonName string
+	// DNSNames is the DNS SANs.
+	DNSNames []string
+	// URIs is the URI SANs.
+	URIs []string
+	// Certificate is the leaf certificate of the peer.
+	Certificate *x509.Certificate
+}
+
+// NewIdentity returns the identity of the leaf certificate.
+func NewIdentity(cert *x509.Certificate) Identity {
+	id := Identity{
+		CommonName:  cert.Subject.CommonName,
+		DNSNames:    cert.DNSNames,
+		Certificate: cert,
+	}
+	for _, u := range cert.URIs {
+		id.URIs = append(id.URIs, u.String())
+		if u.Scheme == "spiffe" && id.SPIFFEID == "" {
+			id.SPIFFEID = u.String()
+		}
+	}
+	return id
+}
+
+type identityKey struct{}
+
+// NewIdentityContext returns a new Context that carries the peer identity.
+func NewIdentityContext(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromIdentityContext returns the verified peer identity stored in ctx, if any.
+func FromIdentityContext(ctx context.Context) (id Identity, ok bool) {
+	id, ok = ctx.Value(identityKey{}).(Identity)
+	return
+}
@@ -0,0 +1,39 @@
+package transport
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestIdentity(t *testing.T) {
+	spiffeID, _ := url.Parse("spiffe://example.org/ns/default/sa/api")
+	web, _ := url.Parse("https://example.org")
+	cert := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "api"},
+		DNSNames: []string{"api.example.org"},
+		URIs:     []*url.URL{web, spiffeID},
+	}
+	id := NewIdentity(cert)
+	if id.CommonName != "api" {
+		t.Errorf("expect %v, got %v", "api", id.CommonName)
+	}
+	if id.SPIFFEID != spiffeID.String() {
+		t.Errorf("expect %v, got %v", spiffeID.String(), id.SPIFFEID)
+	}
+	if !reflect.DeepEqual([]string{web.String(), spiffeID.String()}, id.URIs) {
+		t.Errorf("unexpected uris: %v", id.URIs)
+	}
+
+	ctx := NewIdentityContext(context.Background(), id)
+	got, ok := FromIdentityContext(ctx)
+	if !ok || got.Certificate != cert {
+		t.Errorf("expect %v, got %v", id, got)
+	}
+	if _, ok = FromIdentityContext(context.Background()); ok {
+		t.Errorf("expect no identity")
+	}
+}
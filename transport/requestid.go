@@ -0,0 +1,49 @@
+package transport
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/metadata"
+)
+
+// RequestIDHeader is the header carrying the request id, it is propagated by the HTTP and gRPC clients.
+const RequestIDHeader = "x-request-id"
+
+// maxRequestIDLen is the max length of the incoming request id echoed by the servers.
+const maxRequestIDLen = 128
+
+type requestIDKey struct{}
+
+// NewRequestIDContext returns a new Context that carries the request id, it is also stored
+// in the server metadata.
+func NewRequestIDContext(ctx context.Context, id string) context.Context {
+	md, _ := metadata.FromServerContext(ctx)
+	md = md.Clone()
+	md.Set(RequestIDHeader, id)
+	ctx = metadata.NewServerContext(ctx, md)
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// FromRequestIDContext returns the request id stored in ctx, if any.
+func FromRequestIDContext(ctx context.Context) (id string, ok bool) {
+	id, ok = ctx.Value(requestIDKey{}).(string)
+	return
+}
+
+// ValidRequestID reports whether the incoming request id can be echoed and logged, it has
+// 1 to 128 letters, digits or the characters -_.:+/=, e.g. a UUID or a base64 string.
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':', c == '+', c == '/', c == '=':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package transport
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/metadata"
+)
+
+func TestRequestIDContext(t *testing.T) {
+	ctx := metadata.NewServerContext(context.Background(), metadata.New(map[string][]string{"x-md-global-a": {"b"}}))
+	ctx = NewRequestIDContext(ctx, "id")
+	if id, ok := FromRequestIDContext(ctx); !ok || id != "id" {
+		t.Errorf("expect %v, got %v", "id", id)
+	}
+	md, _ := metadata.FromServerContext(ctx)
+	if md.Get(RequestIDHeader) != "id" || md.Get("x-md-global-a") != "b" {
+		t.Errorf("expect the id to be merged into the metadata, got %v", md)
+	}
+	if _, ok := FromRequestIDContext(context.Background()); ok {
+		t.Errorf("expect no request id")
+	}
+}
+
+func TestValidRequestID(t *testing.T) {
+	tests := map[string]bool{
+		"3f2b8c1e-0b7a-4c5e-9d7e-1a2b3c4d5e6f": true,
+		"YWJj+/==":                             true,
+		"":                                     false,
+		"a b":                                  false,
+		"id\r\nX-Injected: 1":                  false,
+		"<script>":                             false,
+		strings.Repeat("a", 128):               true,
+		strings.Repeat("a", 129):               false,
+	}
+	for id, want := range tests {
+		if got := ValidRequestID(id); got != want {
+			t.Errorf("%q: expect %v, got %v", id, want, got)
+		}
+	}
+}
@@ -23,6 +23,13 @@ type Endpointer interface {
 	Endpoint() (*url.URL, error)
 }
 
+// Readier is the server reporting the readiness of the application, e.g. by the health
+// service. kratos.App marks it not ready before starting, ready after the registration
+// and the AfterStart hooks, and not ready again once it starts stopping.
+type Readier interface {
+	SetReady(ready bool)
+}
+
 // Header is the storage medium used by a Header.
 type Header interface {
 	Get(key string) string